@@ -0,0 +1,93 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/decoders"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+	log "github.com/sirupsen/logrus"
+)
+
+// convertCommand subcommand to rewrite link-layer headers of a capture file
+const convertCommand = "convert"
+
+// convertTargets supported conversion link types by name
+var convertTargets = map[string]decoders.LinkType{
+	"ethernet": decoders.LinkTypeEthernet,
+	"sll":      decoders.LinkTypeLinuxSLL,
+	"sll2":     decoders.LinkTypeLinuxSLL2,
+}
+
+// runConvert
+// converts pcap capture between Linux cooked-mode (SLL/SLL2) and Ethernet link types,
+// input is read from stdin when not set, returns the process exit code
+func runConvert(args []string) int {
+	var (
+		inputFile  string
+		outputFile string
+		targetName string
+	)
+	fs := flag.NewFlagSet(convertCommand, flag.ContinueOnError)
+	fs.StringVar(&inputFile, "i", view.EmptyString, "Input file (pcap, optionally gzip compressed), stdin if not set")
+	fs.StringVar(&outputFile, "o", view.EmptyString, "Output file")
+	fs.StringVar(&targetName, "to", "ethernet", "Target link type: ethernet, sll or sll2")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: %s %s [options]\n\nOptions:\n", os.Args[0], convertCommand)
+		fs.PrintDefaults()
+	}
+	err := fs.Parse(args)
+	if err != nil {
+		return 2
+	}
+	target, found := convertTargets[strings.ToLower(targetName)]
+	if !found || outputFile == view.EmptyString {
+		fs.Usage()
+		return 2
+	}
+	var input io.Reader = os.Stdin
+	if inputFile != view.EmptyString {
+		fh, err := os.Open(inputFile)
+		if err != nil {
+			log.Errorf("unable to open input file: %v", err)
+			return 1
+		}
+		defer func(fh *os.File) {
+			_ = fh.Close()
+		}(fh)
+		input = fh
+	}
+	output, err := os.Create(outputFile)
+	if err != nil {
+		log.Errorf("unable to create output file: %v", err)
+		return 1
+	}
+	stats, err := decoders.ConvertCapture(input, output, target)
+	closeErr := output.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Errorf("convert failed: %v", err)
+		return 1
+	}
+	log.Infof("packets read: %d, converted: %d, skipped: %d", stats.Packets, stats.Converted, stats.Skipped)
+	return 0
+}
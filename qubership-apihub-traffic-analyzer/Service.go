@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"io"
 	"net/http"
@@ -28,6 +29,7 @@ import (
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/readers"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/redaction"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/reports/generators"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/repository"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/service"
@@ -113,6 +115,9 @@ func main() {
 		serviceVersion string
 		logLevel       string
 	)
+	if len(os.Args) > 1 && os.Args[1] == convertCommand {
+		os.Exit(runConvert(os.Args[2:]))
+	}
 	sysInfo, err := service.NewSystemInfoService()
 	err = sysInfo.Init()
 	if err != nil {
@@ -185,7 +190,16 @@ func main() {
 	capId := sysInfo.GetCaptureId()
 	headersCache := repository.NewHttpHeadersCache(pdb)
 	peersCache := repository.NewPeersCache(pdb)
-	packetCache := repository.NewPacketCache(pdb, peersCache, headersCache)
+	// traffic is redacted before it is stored
+	redactionRules, err := redaction.LoadRules(sysInfo.GetRedactionRules(), sysInfo.GetRedactionRulesFile())
+	if err != nil {
+		log.Fatalf("redaction rules not valid: %v", err)
+	}
+	redactor, err := redaction.NewRedactor(redactionRules, sysInfo.GetRedactionHashKey())
+	if err != nil {
+		log.Fatalf("redaction rules not valid: %v", err)
+	}
+	packetCache := repository.NewPacketCache(pdb, peersCache, headersCache, sysInfo.GetPacketBatchSize(), redactor)
 	minioCfg := sysInfo.GetMinioStorageCreds()
 	var s3 service.CloudStorage = nil
 	s3, err = service.NewCloudStorage(*minioCfg)
@@ -244,7 +258,11 @@ func main() {
 	}
 	log.Debugf("CaptureId %s==%s", captureId, capId)
 	if capId != view.EmptyString {
-		rdr := readers.NewCaptureReader(headersCache, packetCache, peersCache, pdb, sysInfo.GetWorkDir())
+		err = repository.StartCaptureLoad(pdb, capId)
+		if err != nil {
+			log.Warnf("capture %s is not registered for the retention: %v", capId, err)
+		}
+		rdr := readers.NewCaptureReader(headersCache, packetCache, peersCache, pdb, sysInfo.GetWorkDir(), sysInfo.GetLoadConcurrency())
 		if s3 == nil || !sysInfo.IsMinioStorageActive() {
 			// override mode - no cloud storage
 			err = rdr.ReadCaptureDir(capId, sysInfo.GetWorkDir())
@@ -254,22 +272,35 @@ func main() {
 		} else {
 			// override mode - use cloud storage
 			log.Debugf("MAIN readers.ProcessCaptureFiles %s", capId)
-			fileCount, err := s3.ProcessCaptureFiles(capId, rdr)
+			var fileCount int
+			fileCount, err = s3.ProcessCaptureFiles(context.Background(), capId, rdr)
 			if err != nil {
 				log.Errorf("unable to process capture %s from cloud storage. Error: %v", capId, err)
 			}
 			log.Printf("%d file(s) procesed", fileCount)
 		}
+		if err == nil {
+			err = repository.FinishCaptureLoad(pdb, capId)
+			if err != nil {
+				log.Warnf("capture %s load finish is not registered for the retention: %v", capId, err)
+			}
+		}
 		return
 	}
 	log.Println("entering service mode")
+	// aggregated data retention
+	retention := service.NewRetentionService(pdb, sysInfo.GetRetentionPolicy())
+	retention.Start()
+	// queued capture loads
+	loadJobs := service.NewLoadJobService(sysInfo.GetLoadJobConfig(), pdb, s3, headersCache, packetCache, peersCache)
+	loadJobs.Start()
 	// service mode
 	ws := controllers.NewService(entities.WebServiceConfig{
 		APIkey:         sysInfo.GetAPIKey(),
 		ProductionMode: sysInfo.IsProductionMode(),
 		WorkDir:        sysInfo.GetWorkDir(),
 		AgentName:      sysInfo.GetAgentName(),
-	}, headersCache, packetCache, peersCache, s3, pdb, sysInfo.GetNamespace(), sysInfo.GetWorkspace(), apihubClient)
+	}, loadJobs, s3, pdb, sysInfo.GetNamespace(), sysInfo.GetWorkspace(), apihubClient, retention)
 	r := mux.NewRouter()
 	r.SkipClean(true)
 	r.UseEncodedPath()
@@ -278,7 +309,15 @@ func main() {
 	r.HandleFunc(view.LoadStatusReportPath, ws.OnCaptureLoadStatus).Methods(http.MethodGet)
 	r.HandleFunc(view.ServiceOperationsReportPath, ws.OnServiceOperationsReportGenerate).Methods(http.MethodPost) // generate
 	r.HandleFunc(view.ServiceOperationsRenderPath, ws.OnServiceOperationsReportOutput).Methods(http.MethodGet)    // send it out
+	r.HandleFunc(view.QueryParametersReportPath, ws.OnQueryParametersReport).Methods(http.MethodGet)              // query parameter coverage
 	r.HandleFunc(view.MinioDeleteCapturePath, ws.OnCaptureDelete).Methods(http.MethodDelete)                      // send it out
+	r.HandleFunc(view.ProtobufDescriptorPath, ws.OnProtobufDescriptorUpload).Methods(http.MethodPost)
+	r.HandleFunc(view.ProtobufDescriptorPath, ws.OnProtobufDescriptorDelete).Methods(http.MethodDelete)
+	r.HandleFunc(view.CapturesPath, ws.OnCapturesList).Methods(http.MethodGet)
+	r.HandleFunc(view.CapturePath, ws.OnCaptureInfo).Methods(http.MethodGet)
+	r.HandleFunc(view.CaptureLoadPath, ws.OnCaptureLoadProgress).Methods(http.MethodGet)
+	r.HandleFunc(view.PurgeCapturePath, ws.OnCapturePurge).Methods(http.MethodDelete) // aggregated capture data
+	r.HandleFunc(view.PurgeReportPath, ws.OnReportPurge).Methods(http.MethodDelete)   // report data
 	if !sysInfo.IsProductionMode() {
 		r.HandleFunc(view.MinioCleanupCapturePath, ws.OnCaptureCleanup).Methods(http.MethodDelete) // send it out
 		r.PathPrefix("/debug/").Handler(http.DefaultServeMux)
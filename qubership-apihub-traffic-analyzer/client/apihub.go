@@ -33,7 +33,6 @@ import (
 const (
 	packagesByService = "%s/api/v2/packages"
 	operationsUri     = "%s/api/v2/packages/%s/versions/%s/%s/operations"
-	restOperationType = "rest"
 	operationKind     = "kind"
 	filterLimit       = "limit"
 	filterPage        = "page"
@@ -50,6 +49,9 @@ type apihubClientImpl struct {
 // public interface
 type ApihubClient interface {
 	GetVersionRestOperationsWithData(ctx secctx.SecurityContext, packageId, version string, limit, page int) (*view.RestOperations, error)
+	GetVersionProtobufOperationsWithData(ctx secctx.SecurityContext, packageId, version string, limit, page int) (*view.ProtobufOperations, error)
+	GetVersionGraphqlOperationsWithData(ctx secctx.SecurityContext, packageId, version string, limit, page int) (*view.GraphqlOperations, error)
+	GetVersionAsyncApiOperationsWithData(ctx secctx.SecurityContext, packageId, version string, limit, page int) (*view.AsyncApiOperations, error)
 	GetPackagesVer(ctx secctx.SecurityContext, searchReq view.PackagesSearchReq) (*view.Packages, error)
 	GetPackages(ctx secctx.SecurityContext, searchReq view.PackagesSearchReq) (*view.SimplePackages, error)
 	GetSystemCtx() secctx.SecurityContext
@@ -74,6 +76,50 @@ func NewApihubClient(apihubUrl, accessToken string) ApihubClient {
 // GetVersionRestOperationsWithData
 // get REST operations for package and version
 func (a apihubClientImpl) GetVersionRestOperationsWithData(ctx secctx.SecurityContext, packageId, version string, limit, page int) (*view.RestOperations, error) {
+	var restOperations view.RestOperations
+	found, err := a.getVersionOperationsWithData(ctx, packageId, version, view.RestApiType, limit, page, &restOperations)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &restOperations, nil
+}
+
+// GetVersionProtobufOperationsWithData
+// get gRPC (protobuf) operations for package and version
+func (a apihubClientImpl) GetVersionProtobufOperationsWithData(ctx secctx.SecurityContext, packageId, version string, limit, page int) (*view.ProtobufOperations, error) {
+	var protobufOperations view.ProtobufOperations
+	found, err := a.getVersionOperationsWithData(ctx, packageId, version, view.ProtobufApiType, limit, page, &protobufOperations)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &protobufOperations, nil
+}
+
+// GetVersionGraphqlOperationsWithData
+// get GraphQL operations for package and version
+func (a apihubClientImpl) GetVersionGraphqlOperationsWithData(ctx secctx.SecurityContext, packageId, version string, limit, page int) (*view.GraphqlOperations, error) {
+	var graphqlOperations view.GraphqlOperations
+	found, err := a.getVersionOperationsWithData(ctx, packageId, version, view.GraphqlApiType, limit, page, &graphqlOperations)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &graphqlOperations, nil
+}
+
+// GetVersionAsyncApiOperationsWithData
+// get AsyncAPI operations for package and version
+func (a apihubClientImpl) GetVersionAsyncApiOperationsWithData(ctx secctx.SecurityContext, packageId, version string, limit, page int) (*view.AsyncApiOperations, error) {
+	var asyncApiOperations view.AsyncApiOperations
+	found, err := a.getVersionOperationsWithData(ctx, packageId, version, view.AsyncApiType, limit, page, &asyncApiOperations)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &asyncApiOperations, nil
+}
+
+// getVersionOperationsWithData
+// get operations of the API type for package and version, returns false when the version not found
+func (a apihubClientImpl) getVersionOperationsWithData(ctx secctx.SecurityContext, packageId, version string, apiType view.ApiType, limit, page int, operations interface{}) (bool, error) {
 	req := makeRequest(ctx, a.accessToken, a.apiHubHost)
 	req.SetQueryParam("includeData", "true")
 	req.SetQueryParam(filterLimit, fmt.Sprint(limit))
@@ -82,27 +128,26 @@ func (a apihubClientImpl) GetVersionRestOperationsWithData(ctx secctx.SecurityCo
 		a.apihubUrl,
 		url.PathEscape(packageId),
 		url.PathEscape(version),
-		restOperationType))
+		apiType))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get version rest operations. Error - %s", err.Error())
+		return false, fmt.Errorf("failed to get version %s operations. Error - %s", apiType, err.Error())
 	}
 
 	if resp.StatusCode() != http.StatusOK {
 		if resp.StatusCode() == http.StatusNotFound {
-			return nil, nil
+			return false, nil
 		}
 		if authErr := checkUnauthorized(resp); authErr != nil {
-			return nil, authErr
+			return false, authErr
 		}
-		return nil, fmt.Errorf("failed to get version rest operations: status code %d %v", resp.StatusCode(), err)
+		return false, fmt.Errorf("failed to get version %s operations: status code %d %v", apiType, resp.StatusCode(), err)
 	}
 
-	var restOperations view.RestOperations
-	err = json.Unmarshal(resp.Body(), &restOperations)
+	err = json.Unmarshal(resp.Body(), operations)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	return &restOperations, nil
+	return true, nil
 }
 
 // GetPackagesVer
@@ -16,17 +16,19 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
 
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/client"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/decoders"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/exception"
-	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/readers"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/reports/generators"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/reports/renderers"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/repository"
@@ -46,6 +48,14 @@ type Service interface {
 	Shutdown()
 	OnServiceOperationsReportGenerate(w http.ResponseWriter, r *http.Request)
 	OnServiceOperationsReportOutput(w http.ResponseWriter, r *http.Request)
+	OnQueryParametersReport(w http.ResponseWriter, r *http.Request)
+	OnProtobufDescriptorUpload(w http.ResponseWriter, r *http.Request)
+	OnProtobufDescriptorDelete(w http.ResponseWriter, r *http.Request)
+	OnCapturePurge(w http.ResponseWriter, r *http.Request)
+	OnCapturesList(w http.ResponseWriter, r *http.Request)
+	OnCaptureInfo(w http.ResponseWriter, r *http.Request)
+	OnCaptureLoadProgress(w http.ResponseWriter, r *http.Request)
+	OnReportPurge(w http.ResponseWriter, r *http.Request)
 }
 
 const (
@@ -55,75 +65,42 @@ const (
 	invalidApiKey          = "API key not match"
 	emptyApiKey            = "empty API key not allowed in production mode"
 	emptyCaptureId         = "Capture Id is empty"
+	emptyDescriptorName    = "descriptor name is empty"
+	emptyReportId          = "report id is empty"
 	requestBodyDeferError  = "unable to defer request body. error: %v"
-	StopAsync              = "STOP"
 )
 
 type webService struct {
 	entities.WebServiceConfig
-	Headers       repository.HttpHeadersCache
-	Packets       repository.PacketCache
-	Peers         repository.ServiceAddressRepository
-	history       map[string]view.LoadHistoryValue
-	reports       chan string
+	jobs          service.LoadJobService
 	s3            service.CloudStorage
 	db            db.ConnectionProvider
-	mapLock       sync.Mutex
 	kubeNameSpace string
 	workSpace     string
 	apihubClient  client.ApihubClient
+	retention     service.RetentionService
 }
 
 // NewService
 // creates a new web service instance
 func NewService(cfg entities.WebServiceConfig,
-	headers repository.HttpHeadersCache,
-	packets repository.PacketCache,
-	peers repository.ServiceAddressRepository,
+	jobs service.LoadJobService,
 	s3 service.CloudStorage,
 	pdb db.ConnectionProvider,
 	kubeNameSpace,
 	workSpace string,
-	apihubClient client.ApihubClient) Service {
-	ws := &webService{
+	apihubClient client.ApihubClient,
+	retention service.RetentionService) Service {
+	return &webService{
 		WebServiceConfig: cfg,
-		Headers:          headers,
-		Packets:          packets,
-		Peers:            peers,
-		history:          make(map[string]view.LoadHistoryValue),
-		reports:          make(chan string),
+		jobs:             jobs,
 		s3:               s3,
 		db:               pdb,
-		mapLock:          sync.Mutex{},
 		kubeNameSpace:    kubeNameSpace,
 		workSpace:        workSpace,
 		apihubClient:     apihubClient,
+		retention:        retention,
 	}
-	utils.SafeAsync(func() {
-		for {
-			captureId := <-ws.reports
-			if captureId == StopAsync {
-				break
-			}
-			val, found := ws.history[captureId]
-			if found {
-				if completed, err := view.HistoryRecordCompleted(val); completed {
-					if err == nil {
-						log.Printf("capture '%s' loaded successfully", captureId)
-					} else {
-						log.Errorf("capture '%s' load finished with error: %v", captureId, err)
-					}
-				}
-				ws.mapLock.Lock()
-				val.EndDateTime = view.GetHistoryDateTimeString()
-				ws.history[captureId] = val
-				ws.mapLock.Unlock()
-			} else {
-				log.Errorf("unexpected capture id %s", captureId)
-			}
-		}
-	})
-	return ws
 }
 
 // RespondWithJson
@@ -152,26 +129,44 @@ func getStringParam(r *http.Request, paramName string) string {
 	return params[paramName]
 }
 
-// setCaptureError
-// sets capture status and error, creates record if it does not exist
-func (ws *webService) setCaptureError(captureId string, err error) {
-	ws.mapLock.Lock()
-	defer ws.mapLock.Unlock()
-	val, found := ws.history[captureId]
-	if found {
-		val.Error = err
-		ws.history[captureId] = val
-	} else {
-		ws.history[captureId] = view.LoadHistoryValue{
-			BeginDateTime: view.GetHistoryDateTimeString(),
-			EndDateTime:   view.EmptyString,
-			Error:         err,
+// getIntQueryParam
+// gets integer value of the query parameter, the default value is used when the parameter is omitted.
+// Responds with bad request and returns false when the value is not a number in the range
+func getIntQueryParam(w http.ResponseWriter, r *http.Request, paramName string, def, minValue, maxValue int) (int, bool) {
+	str := r.URL.Query().Get(paramName)
+	if str == view.EmptyString {
+		return def, true
+	}
+	value, err := strconv.Atoi(str)
+	if err != nil || value < minValue || value > maxValue {
+		debug := fmt.Sprintf("value is expected in range %d..%d", minValue, maxValue)
+		if err != nil {
+			debug = err.Error()
 		}
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusBadRequest,
+			Code:    exception.InvalidParameterValue,
+			Message: exception.InvalidParameterValueMsg,
+			Params:  map[string]interface{}{"param": paramName, "value": str},
+			Debug:   debug,
+		})
+		return 0, false
+	}
+	return value, true
+}
+
+// captureLoading
+// checks whether the capture load job is queued or running
+func (ws *webService) captureLoading(captureId string) (bool, error) {
+	job, err := repository.GetLastLoadJob(ws.db, captureId)
+	if err != nil {
+		return false, err
 	}
+	return job != nil && job.Finished == nil, nil
 }
 
 // OnCaptureLoad
-// serves the capture load requests
+// queues the capture load, the load is run by any service instance
 func (ws *webService) OnCaptureLoad(w http.ResponseWriter, r *http.Request) {
 	_, err := ws.checkAndGetBody(w, r)
 	if err != nil {
@@ -187,47 +182,21 @@ func (ws *webService) OnCaptureLoad(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	if captureId == StopAsync {
+	_, created, err := ws.jobs.Enqueue(captureId)
+	if err != nil {
 		RespondWithCustomError(w, &exception.CustomError{
-			Status:  http.StatusBadRequest,
+			Status:  http.StatusInternalServerError,
 			Code:    exception.InvalidParameterValue,
 			Message: exception.InvalidParameterValueMsg,
-			Debug:   StopAsync,
+			Debug:   err.Error(),
 		})
 		return
 	}
-	status, found := ws.history[captureId]
-	if found {
-		completed, _ := view.HistoryRecordCompleted(status)
-		if completed {
-			// if completed - delete it for further re-creation
-			ws.mapLock.Lock()
-			delete(ws.history, captureId)
-			ws.mapLock.Unlock()
-		} else {
-			// incomplete capture
-			if status.Error == nil {
-				// indicate an incomplete state
-				RespondWithJson(w, http.StatusPartialContent, view.EmptyString)
-			} else {
-				// indicate an incomplete state with error
-				RespondWithJson(w, http.StatusPartialContent, status.Error.Error())
-			}
-			return // avoid to open concurrent captures
-		}
+	if !created {
+		// indicate an incomplete state, avoid to open concurrent captures
+		RespondWithJson(w, http.StatusPartialContent, view.EmptyString)
+		return
 	}
-	ws.setCaptureError(captureId, nil)
-	utils.SafeAsync(func() {
-		log.Printf("starting process files for capture %s", captureId)
-		rdr := readers.NewCaptureReader(ws.Headers, ws.Packets, ws.Peers, ws.db, ws.WorkDir)
-		_, err := ws.s3.ProcessCaptureFiles(captureId, rdr)
-		ws.setCaptureError(captureId, err)
-		err = rdr.Close()
-		if err != nil {
-			log.Warnf("unable to close reader: %v", err)
-		}
-		ws.reports <- captureId
-	})
 	RespondWithJson(w, http.StatusAccepted, "loading")
 }
 
@@ -248,29 +217,25 @@ func (ws *webService) OnCaptureLoadStatus(w http.ResponseWriter, r *http.Request
 		})
 		return
 	}
-	if captureId == StopAsync {
+	job, err := repository.GetLastLoadJob(ws.db, captureId)
+	if err != nil {
 		RespondWithCustomError(w, &exception.CustomError{
-			Status:  http.StatusBadRequest,
+			Status:  http.StatusInternalServerError,
 			Code:    exception.InvalidParameterValue,
 			Message: exception.InvalidParameterValueMsg,
-			Debug:   StopAsync,
+			Debug:   err.Error(),
 		})
 		return
 	}
-	status, found := ws.history[captureId]
-	if !found {
+	switch {
+	case job == nil:
 		RespondWithJson(w, http.StatusNotFound, fmt.Sprintf("capture '%s' was not found", captureId))
-	} else {
-		completed, err := view.HistoryRecordCompleted(status)
-		if completed {
-			if err == nil {
-				RespondWithJson(w, http.StatusOK, fmt.Sprintf("capture '%s' was loaded at %s", captureId, status.EndDateTime))
-			} else {
-				RespondWithJson(w, http.StatusExpectationFailed, fmt.Sprintf("capture '%s' was failed at %s : %v", captureId, status.EndDateTime, err))
-			}
-		} else {
-			RespondWithJson(w, http.StatusCreated, fmt.Sprintf("capture '%s' is still loading", captureId))
-		}
+	case job.Finished == nil:
+		RespondWithJson(w, http.StatusCreated, fmt.Sprintf("capture '%s' is still loading", captureId))
+	case job.Error == nil:
+		RespondWithJson(w, http.StatusOK, fmt.Sprintf("capture '%s' was loaded at %s", captureId, job.Finished.Format(view.HistoryDateTimeFormat)))
+	default:
+		RespondWithJson(w, http.StatusExpectationFailed, fmt.Sprintf("capture '%s' was failed at %s : %s", captureId, job.Finished.Format(view.HistoryDateTimeFormat), *job.Error))
 	}
 }
 
@@ -327,7 +292,8 @@ func (ws *webService) checkAndGetBody(w http.ResponseWriter, r *http.Request) ([
 // Shutdown
 // tries to perform a graceful service shutdown
 func (ws *webService) Shutdown() {
-	ws.reports <- StopAsync
+	ws.jobs.Stop()
+	ws.retention.Stop()
 }
 
 // OnServiceOperationsReportGenerate
@@ -481,6 +447,67 @@ func (ws *webService) OnServiceOperationsReportOutput(w http.ResponseWriter, r *
 	}
 }
 
+// OnQueryParametersReport
+// sends query parameter coverage of the service operations report: documented parameters with their usage and undocumented ones sent
+func (ws *webService) OnQueryParametersReport(w http.ResponseWriter, r *http.Request) {
+	_, err := ws.checkAndGetBody(w, r)
+	if err != nil {
+		return
+	}
+	reportUuid := getStringParam(r, view.ReportIdParam)
+	if reportUuid == view.EmptyString {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusBadRequest,
+			Code:    exception.EmptyParameter,
+			Message: exception.EmptyParameterMsg,
+			Params:  map[string]interface{}{"param": view.ReportIdParam},
+			Debug:   emptyReportId,
+		})
+		return
+	}
+	report, _, err := repository.GetReport(ws.db, reportUuid, string(generators.ServiceOperationReport))
+	if err != nil {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusNotFound,
+			Code:    exception.InvalidParameterValue,
+			Message: exception.InvalidParameterValueMsg,
+			Debug:   err.Error(),
+		})
+		return
+	}
+	rows, err := repository.GetReportQueryParameters(ws.db, report.ReportId)
+	if err != nil {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusInternalServerError,
+			Code:    exception.InvalidParameterValue,
+			Message: exception.InvalidParameterValueMsg,
+			Debug:   err.Error(),
+		})
+		return
+	}
+	coverage := view.QueryParameterCoverage{ReportId: reportUuid, Operations: make([]view.OperationQueryParameters, 0)}
+	for _, row := range rows {
+		last := len(coverage.Operations) - 1
+		if last < 0 || coverage.Operations[last].Path != row.Path || coverage.Operations[last].Method != row.Method {
+			coverage.Operations = append(coverage.Operations, view.OperationQueryParameters{
+				OperationId: row.Title,
+				Path:        row.Path,
+				Method:      row.Method,
+				Parameters:  make([]view.QueryParameterUsage, 0),
+			})
+			last++
+		}
+		coverage.Operations[last].Parameters = append(coverage.Operations[last].Parameters, view.QueryParameterUsage{
+			Name:       row.Name,
+			Documented: row.Documented,
+			Required:   row.Required,
+			HitCount:   row.HitCount,
+			Status:     view.QueryParameterStatus(row.Documented, row.HitCount),
+		})
+	}
+	RespondWithJson(w, http.StatusOK, coverage)
+}
+
 // OnCaptureDelete
 // tries to delete capture data from S3/Minio
 func (ws *webService) OnCaptureDelete(w http.ResponseWriter, r *http.Request) {
@@ -498,17 +525,20 @@ func (ws *webService) OnCaptureDelete(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	status, found := ws.history[captureId]
-	if found {
-		completed, _ := view.HistoryRecordCompleted(status)
-		if !completed {
-			// incomplete (in progress) capture
-			if status.Error == nil {
-				// indicates an incomplete state
-				RespondWithJson(w, http.StatusPartialContent, view.EmptyString)
-			}
-			return // avoid to open concurrent captures
-		}
+	loading, err := ws.captureLoading(captureId)
+	if err != nil {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusInternalServerError,
+			Code:    exception.InvalidParameterValue,
+			Message: exception.InvalidParameterValueMsg,
+			Debug:   err.Error(),
+		})
+		return
+	}
+	if loading {
+		// incomplete (in progress) capture
+		RespondWithJson(w, http.StatusPartialContent, view.EmptyString)
+		return // avoid to delete files being read
 	}
 	utils.SafeAsync(func() {
 		log.Printf("trying to delete files for capture %s", captureId)
@@ -548,3 +578,280 @@ func (ws *webService) OnCaptureCleanup(w http.ResponseWriter, r *http.Request) {
 	})
 	RespondWithJson(w, http.StatusAccepted, "cleaning up")
 }
+
+// OnProtobufDescriptorUpload
+// stores FileDescriptorSet (request body) to decode gRPC messages, optional service query parameter binds it to a service
+func (ws *webService) OnProtobufDescriptorUpload(w http.ResponseWriter, r *http.Request) {
+	body, err := ws.checkAndGetBody(w, r)
+	if err != nil {
+		return
+	}
+	name := getStringParam(r, view.DescriptorNameParam)
+	if name == view.EmptyString {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusBadRequest,
+			Code:    exception.EmptyParameter,
+			Message: exception.EmptyParameterMsg,
+			Params:  map[string]interface{}{"param": view.DescriptorNameParam},
+			Debug:   emptyDescriptorName,
+		})
+		return
+	}
+	_, err = decoders.ParseDescriptorSet(body)
+	if err != nil {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusBadRequest,
+			Code:    exception.BadRequestBody,
+			Message: exception.BadRequestBodyMsg,
+			Debug:   err.Error(),
+		})
+		return
+	}
+	err = repository.StoreProtobufDescriptor(ws.db, entities.ProtobufDescriptor{
+		Name:          name,
+		ServiceName:   r.URL.Query().Get(view.ServiceNameQueryParam),
+		DescriptorSet: body,
+	})
+	if err != nil {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusInternalServerError,
+			Code:    exception.InvalidParameterValue,
+			Message: exception.InvalidParameterValueMsg,
+			Debug:   err.Error(),
+		})
+		return
+	}
+	RespondWithJson(w, http.StatusCreated, fmt.Sprintf("descriptor set '%s' stored", name))
+}
+
+// OnProtobufDescriptorDelete
+// deletes the stored FileDescriptorSet
+func (ws *webService) OnProtobufDescriptorDelete(w http.ResponseWriter, r *http.Request) {
+	_, err := ws.checkAndGetBody(w, r)
+	if err != nil {
+		return
+	}
+	name := getStringParam(r, view.DescriptorNameParam)
+	if name == view.EmptyString {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusBadRequest,
+			Code:    exception.EmptyParameter,
+			Message: exception.EmptyParameterMsg,
+			Params:  map[string]interface{}{"param": view.DescriptorNameParam},
+			Debug:   emptyDescriptorName,
+		})
+		return
+	}
+	deleted, err := repository.DeleteProtobufDescriptor(ws.db, name)
+	if err != nil {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusInternalServerError,
+			Code:    exception.InvalidParameterValue,
+			Message: exception.InvalidParameterValueMsg,
+			Debug:   err.Error(),
+		})
+		return
+	}
+	if !deleted {
+		RespondWithJson(w, http.StatusNotFound, fmt.Sprintf("descriptor set '%s' was not found", name))
+		return
+	}
+	RespondWithJson(w, http.StatusOK, fmt.Sprintf("descriptor set '%s' deleted", name))
+}
+
+// OnCapturePurge
+// deletes aggregated data of the capture and the reports generated from it
+func (ws *webService) OnCapturePurge(w http.ResponseWriter, r *http.Request) {
+	_, err := ws.checkAndGetBody(w, r)
+	if err != nil {
+		return
+	}
+	captureId := getStringParam(r, view.CaptureIdParam)
+	if captureId == view.EmptyString {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusBadRequest,
+			Code:    exception.ContentIdNotFound,
+			Message: exception.ContentIdNotFoundMsg,
+			Debug:   emptyCaptureId,
+		})
+		return
+	}
+	result, err := ws.retention.PurgeCapture(captureId)
+	if errors.Is(err, repository.ErrCaptureLoading) {
+		// the capture data is being loaded
+		RespondWithJson(w, http.StatusConflict, fmt.Sprintf("capture '%s' is still loading", captureId))
+		return
+	}
+	if err != nil {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusInternalServerError,
+			Code:    exception.InvalidParameterValue,
+			Message: exception.InvalidParameterValueMsg,
+			Debug:   err.Error(),
+		})
+		return
+	}
+	RespondWithJson(w, http.StatusOK, result)
+}
+
+// OnReportPurge
+// deletes the report data
+func (ws *webService) OnReportPurge(w http.ResponseWriter, r *http.Request) {
+	_, err := ws.checkAndGetBody(w, r)
+	if err != nil {
+		return
+	}
+	reportUuid := getStringParam(r, view.ReportIdParam)
+	if reportUuid == view.EmptyString {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusBadRequest,
+			Code:    exception.EmptyParameter,
+			Message: exception.EmptyParameterMsg,
+			Params:  map[string]interface{}{"param": view.ReportIdParam},
+			Debug:   emptyReportId,
+		})
+		return
+	}
+	deleted, err := ws.retention.PurgeReport(reportUuid)
+	if err != nil {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusInternalServerError,
+			Code:    exception.InvalidParameterValue,
+			Message: exception.InvalidParameterValueMsg,
+			Debug:   err.Error(),
+		})
+		return
+	}
+	if !deleted {
+		RespondWithJson(w, http.StatusNotFound, fmt.Sprintf("report '%s' was not found", reportUuid))
+		return
+	}
+	RespondWithJson(w, http.StatusOK, fmt.Sprintf("report '%s' deleted", reportUuid))
+}
+
+// OnCapturesList
+// sends the captures loaded into DB
+func (ws *webService) OnCapturesList(w http.ResponseWriter, r *http.Request) {
+	_, err := ws.checkAndGetBody(w, r)
+	if err != nil {
+		return
+	}
+	limit, ok := getIntQueryParam(w, r, view.LimitQueryParam, view.CapturesPageSize, 1, view.CapturesMaxPageSize)
+	if !ok {
+		return
+	}
+	page, ok := getIntQueryParam(w, r, view.PageQueryParam, 0, 0, math.MaxInt32/limit)
+	if !ok {
+		return
+	}
+	captures, err := repository.GetCaptures(ws.db, limit, page)
+	if err != nil {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusInternalServerError,
+			Code:    exception.InvalidParameterValue,
+			Message: exception.InvalidParameterValueMsg,
+			Debug:   err.Error(),
+		})
+		return
+	}
+	loadStates := make([]*view.CaptureInfo, 0, len(captures))
+	for i := range captures {
+		loadStates = append(loadStates, &captures[i])
+	}
+	ws.setLoadState(loadStates...)
+	RespondWithJson(w, http.StatusOK, captures)
+}
+
+// OnCaptureInfo
+// sends the capture loaded into DB
+func (ws *webService) OnCaptureInfo(w http.ResponseWriter, r *http.Request) {
+	_, err := ws.checkAndGetBody(w, r)
+	if err != nil {
+		return
+	}
+	captureId := getStringParam(r, view.CaptureIdParam)
+	if captureId == view.EmptyString {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusBadRequest,
+			Code:    exception.ContentIdNotFound,
+			Message: exception.ContentIdNotFoundMsg,
+			Debug:   emptyCaptureId,
+		})
+		return
+	}
+	capture, err := repository.GetCapture(ws.db, captureId)
+	if err != nil {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusInternalServerError,
+			Code:    exception.InvalidParameterValue,
+			Message: exception.InvalidParameterValueMsg,
+			Debug:   err.Error(),
+		})
+		return
+	}
+	if capture == nil {
+		RespondWithJson(w, http.StatusNotFound, fmt.Sprintf("capture '%s' was not found", captureId))
+		return
+	}
+	ws.setLoadState(capture)
+	RespondWithJson(w, http.StatusOK, capture)
+}
+
+// setLoadState
+// overrides the stored load state with the state of the last load job, the jobs are selected by one query
+func (ws *webService) setLoadState(captures ...*view.CaptureInfo) {
+	captureIds := make([]string, 0, len(captures))
+	for _, capture := range captures {
+		captureIds = append(captureIds, capture.CaptureId)
+	}
+	jobs, err := repository.GetLastLoadJobs(ws.db, captureIds)
+	if err != nil {
+		log.Warnf("captures load jobs are not available: %v", err)
+		return
+	}
+	for _, capture := range captures {
+		job := jobs[capture.CaptureId]
+		switch {
+		case job == nil:
+		case job.Finished == nil:
+			capture.LoadState = view.CaptureLoading
+		case job.Error != nil:
+			capture.LoadState = view.CaptureLoadFailed
+			capture.LoadError = *job.Error
+		}
+	}
+}
+
+// OnCaptureLoadProgress
+// sends progress of the last capture load with the packet counters of the read files
+func (ws *webService) OnCaptureLoadProgress(w http.ResponseWriter, r *http.Request) {
+	_, err := ws.checkAndGetBody(w, r)
+	if err != nil {
+		return
+	}
+	captureId := getStringParam(r, view.CaptureIdParam)
+	if captureId == view.EmptyString {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusBadRequest,
+			Code:    exception.ContentIdNotFound,
+			Message: exception.ContentIdNotFoundMsg,
+			Debug:   emptyCaptureId,
+		})
+		return
+	}
+	status, err := repository.GetCaptureLoadStatus(ws.db, captureId)
+	if err != nil {
+		RespondWithCustomError(w, &exception.CustomError{
+			Status:  http.StatusInternalServerError,
+			Code:    exception.InvalidParameterValue,
+			Message: exception.InvalidParameterValueMsg,
+			Debug:   err.Error(),
+		})
+		return
+	}
+	if status == nil {
+		RespondWithJson(w, http.StatusNotFound, fmt.Sprintf("capture '%s' was not found", captureId))
+		return
+	}
+	RespondWithJson(w, http.StatusOK, status)
+}
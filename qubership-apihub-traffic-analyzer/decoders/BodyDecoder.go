@@ -16,10 +16,18 @@ package decoders
 
 import (
 	"bytes"
+	"compress/flate"
 	"compress/gzip"
+	"compress/zlib"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/text/encoding/htmlindex"
 )
 
 type BodyReadResult struct {
@@ -31,38 +39,142 @@ type BodyReadResult struct {
 	Err error
 }
 
+// BodyEncoding
+// HTTP header values describing how a message body is encoded
+type BodyEncoding struct {
+	// Content-Encoding header value, codings are removed in reverse order
+	ContentEncoding string
+	// Content-Type header value, the charset parameter selects the conversion to UTF-8
+	ContentType string
+}
+
+// errBodyTooLarge the decoded body exceeds MaxHttpMessageSize
+var errBodyTooLarge = errors.New("decoded body is too large")
+
 // BodyToString
 // decodes HTTP request or HTTP response body from reader
-func BodyToString(Body io.Reader, Uncompressed bool) BodyReadResult {
+// content codings and the charset are removed step by step (transfer coding is removed by the message reader),
+// the body is left as is after the first step that fails
+func BodyToString(Body io.Reader, encoding BodyEncoding) BodyReadResult {
 	res := BodyReadResult{
 		Body:   nil,
 		Length: -1,
 		Err:    nil,
 	}
 	body, err := io.ReadAll(Body)
-	if err != nil {
+	if err != nil && !errors.Is(err, io.EOF) {
 		res.Err = fmt.Errorf("error reading body: %v", err)
 	}
-	if !Uncompressed {
-		zr, err1 := gzip.NewReader(bytes.NewReader(body))
-		if err1 == nil {
-			res.Body, err = io.ReadAll(zr)
-			_ = zr.Close()
-			if err == nil {
-				res.Length = len(res.Body)
-			} else {
-				res.Err = fmt.Errorf("unable to read all bytes: %w", err)
-			}
-		} else {
-			res.Err = fmt.Errorf("unable to create gzip reader: %w", err1)
-		}
+	if res.Err == nil {
+		body, res.Err = decodeContent(body, encoding.ContentEncoding)
+	}
+	if res.Err == nil {
+		body, res.Err = decodeCharset(body, encoding.ContentType)
 	}
-	if res.Body == nil && body != nil {
+	if body != nil {
 		res.Body = body
 		res.Length = len(body)
 	}
-	if errors.Is(res.Err, io.EOF) {
-		res.Err = nil
-	}
 	return res
 }
+
+// decodeContent
+// removes content codings listed in the Content-Encoding header
+func decodeContent(body []byte, contentEncoding string) ([]byte, error) {
+	codings := strings.Split(contentEncoding, ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.ToLower(strings.TrimSpace(codings[i]))
+		if coding == "" || coding == "identity" || len(body) == 0 {
+			continue
+		}
+		decoded, err := decodeCoding(body, coding)
+		if err != nil {
+			return body, fmt.Errorf("unable to decode %s content: %w", coding, err)
+		}
+		body = decoded
+	}
+	return body, nil
+}
+
+// decodeCoding
+// removes a single content coding
+func decodeCoding(body []byte, coding string) ([]byte, error) {
+	var rdr io.Reader
+	switch coding {
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		rdr = zr
+	case "deflate":
+		// RFC 9110 deflate is zlib wrapped, but raw deflate streams are common as well
+		zr, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			fr := flate.NewReader(bytes.NewReader(body))
+			defer fr.Close()
+			rdr = fr
+		} else {
+			defer zr.Close()
+			rdr = zr
+		}
+	case "br":
+		rdr = brotli.NewReader(bytes.NewReader(body))
+	case "zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(body), zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxMemory(MaxHttpMessageSize))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		rdr = zr
+	default:
+		return nil, fmt.Errorf("unsupported content coding")
+	}
+	decoded, err := io.ReadAll(io.LimitReader(rdr, MaxHttpMessageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) > MaxHttpMessageSize {
+		return nil, errBodyTooLarge
+	}
+	return decoded, nil
+}
+
+// decodeCharset
+// converts the body to UTF-8 according to the Content-Type charset parameter
+func decodeCharset(body []byte, contentType string) ([]byte, error) {
+	if contentType == "" || len(body) == 0 {
+		return body, nil
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// unparsable content type leaves the body as is
+		return body, nil
+	}
+	charset := strings.ToLower(strings.TrimSpace(params["charset"]))
+	if charset == "" || charset == "utf-8" || charset == "utf8" || charset == "us-ascii" {
+		return body, nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return body, fmt.Errorf("unsupported charset %s: %w", charset, err)
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, fmt.Errorf("unable to convert %s body to UTF-8: %w", charset, err)
+	}
+	return decoded, nil
+}
+
+// hasCoding
+// checks if the comma separated header value lists the coding
+func hasCoding(headerValue string, coding string) bool {
+	for _, value := range strings.Split(headerValue, ",") {
+		if strings.EqualFold(strings.TrimSpace(value), coding) {
+			return true
+		}
+	}
+	return false
+}
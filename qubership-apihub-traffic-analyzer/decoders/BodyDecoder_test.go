@@ -0,0 +1,177 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// encoded
+// compresses the data with the writer
+func encoded(t *testing.T, data string, writer func(w io.Writer) io.WriteCloser) string {
+	buf := &bytes.Buffer{}
+	w := writer(buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestBodyToString(t *testing.T) {
+	const body = `{"name":"value"}`
+	zlibWriter := func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }
+	flateWriter := func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return fw
+	}
+	brotliWriter := func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }
+	zstdWriter := func(w io.Writer) io.WriteCloser {
+		zw, _ := zstd.NewWriter(w)
+		return zw
+	}
+	tests := []struct {
+		name     string
+		body     string
+		encoding BodyEncoding
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "gzip",
+			body:     string(gzipped(t, []byte(body))),
+			encoding: BodyEncoding{ContentEncoding: "gzip"},
+			want:     body,
+		},
+		{
+			name:     "zlib deflate",
+			body:     encoded(t, body, zlibWriter),
+			encoding: BodyEncoding{ContentEncoding: "deflate"},
+			want:     body,
+		},
+		{
+			name:     "raw deflate",
+			body:     encoded(t, body, flateWriter),
+			encoding: BodyEncoding{ContentEncoding: "deflate"},
+			want:     body,
+		},
+		{
+			name:     "brotli",
+			body:     encoded(t, body, brotliWriter),
+			encoding: BodyEncoding{ContentEncoding: "br"},
+			want:     body,
+		},
+		{
+			name:     "zstd",
+			body:     encoded(t, body, zstdWriter),
+			encoding: BodyEncoding{ContentEncoding: "zstd"},
+			want:     body,
+		},
+		{
+			name:     "codings are removed in reverse order",
+			body:     encoded(t, string(gzipped(t, []byte(body))), brotliWriter),
+			encoding: BodyEncoding{ContentEncoding: "identity, gzip, BR"},
+			want:     body,
+		},
+		{
+			name:     "charset is converted to UTF-8",
+			body:     "caf\xe9",
+			encoding: BodyEncoding{ContentType: "text/plain; charset=ISO-8859-1"},
+			want:     "café",
+		},
+		{
+			name:     "charset of decoded content",
+			body:     string(gzipped(t, []byte("caf\xe9"))),
+			encoding: BodyEncoding{ContentEncoding: "gzip", ContentType: "text/plain; charset=windows-1252"},
+			want:     "café",
+		},
+		{
+			name:     "unparsable content type",
+			body:     body,
+			encoding: BodyEncoding{ContentType: "application/json; charset"},
+			want:     body,
+		},
+		{
+			name:     "unsupported coding leaves the body",
+			body:     "abc",
+			encoding: BodyEncoding{ContentEncoding: "compress"},
+			want:     "abc",
+			wantErr:  true,
+		},
+		{
+			name:     "corrupted content leaves the body",
+			body:     "not gzip",
+			encoding: BodyEncoding{ContentEncoding: "gzip", ContentType: "text/plain; charset=ISO-8859-1"},
+			want:     "not gzip",
+			wantErr:  true,
+		},
+		{
+			name:     "unsupported charset",
+			body:     "abc",
+			encoding: BodyEncoding{ContentType: "text/plain; charset=x-unknown"},
+			want:     "abc",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := BodyToString(strings.NewReader(tt.body), tt.encoding)
+			if (result.Err != nil) != tt.wantErr {
+				t.Errorf("BodyToString() error = %v, want error %v", result.Err, tt.wantErr)
+			}
+			if string(result.Body) != tt.want || result.Length != len(tt.want) {
+				t.Errorf("BodyToString() = %q (%d), want %q", result.Body, result.Length, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	bomb := gzipped(t, make([]byte, MaxHttpMessageSize+1))
+	result := BodyToString(bytes.NewReader(bomb), BodyEncoding{ContentEncoding: "gzip"})
+	if result.Err == nil || !bytes.Equal(result.Body, bomb) {
+		t.Errorf("BodyToString() = %d bytes, error %v, want the compressed body and an error", result.Length, result.Err)
+	}
+}
+
+func TestTransferEncodedMessage(t *testing.T) {
+	const body = "hello, world"
+	compressed := string(gzipped(t, []byte(body)))
+	chunked := ""
+	for _, chunk := range []string{compressed[:10], compressed[10:]} {
+		chunked += strconv.FormatInt(int64(len(chunk)), 16) + "\r\n" + chunk + "\r\n"
+	}
+	chunked += "0\r\n\r\n"
+	sink := assembleSegments(nil, conversation(
+		testPart{true, "GET /greeting HTTP/1.1\r\nHost: example.com\r\n\r\n"},
+		testPart{false, "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\nContent-Encoding: gzip\r\n\r\n" + chunked})...)
+	if len(sink.exchanges) != 1 || sink.exchanges[0].Response == nil {
+		t.Fatalf("got %d exchanges, want the answered request", len(sink.exchanges))
+	}
+	if got := string(sink.exchanges[0].Response.Body); got != body {
+		t.Errorf("response body %q, want %q", got, body)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// minConvertedFrameSize smaller packets are dropped by the conversion
+	minConvertedFrameSize = 12
+	// arphrdEther ARPHRD_ETHER link-layer address type
+	arphrdEther = 1
+	// ethAddrSize ethernet MAC address size
+	ethAddrSize = 6
+)
+
+// ConvertStats
+// packet counters of a capture conversion
+type ConvertStats struct {
+	// Packets packets read
+	Packets int
+	// Converted packets with the link-layer header rewritten
+	Converted int
+	// Skipped packets dropped as too short or not convertible
+	Skipped int
+}
+
+// linkHeader
+// link-layer header fields common for Ethernet, SLL and SLL2
+type linkHeader struct {
+	packetType uint16
+	addrType   uint16
+	addrLen    int
+	addr       [sllAddrSize]byte
+	protocol   uint16
+	// size header size in the source frame
+	size int
+}
+
+// ConvertCapture
+// rewrites link-layer headers of pcap capture packets into the target link type (Ethernet, SLL or SLL2),
+// input may be gzip compressed and of either byte order, output keeps the input byte order and time stamp resolution.
+// Linux cooked-mode frames saved with Ethernet link type are detected per packet. Converted Ethernet frames
+// get the addresses made of SLL address with last byte 1 for source and 2 for destination.
+func ConvertCapture(r io.Reader, w io.Writer, target LinkType) (ConvertStats, error) {
+	stats := ConvertStats{}
+	switch target {
+	case LinkTypeEthernet, LinkTypeLinuxSLL, LinkTypeLinuxSLL2:
+	default:
+		return stats, fmt.Errorf("conversion into link type %d not supported", target)
+	}
+	br, err := Decompressed(r)
+	if err != nil {
+		return stats, err
+	}
+	pr, err := NewPcapReader(br)
+	if err != nil {
+		return stats, err
+	}
+	source := pr.Header()
+	switch source.LinkType {
+	case LinkTypeEthernet, LinkTypeLinuxSLL, LinkTypeLinuxSLL2:
+	default:
+		return stats, fmt.Errorf("conversion from link type %d not supported", source.LinkType)
+	}
+	order := source.Order
+	header := make([]byte, pcapFileHeaderSize)
+	magic := pcapMagicMicroseconds
+	if source.Nanoseconds {
+		magic = pcapMagicNanoseconds
+	}
+	order.PutUint32(header[0:4], magic)
+	order.PutUint16(header[4:6], pcapVersionMajor)
+	order.PutUint16(header[6:8], pcapVersionMinor)
+	// converted headers may be longer than the original ones
+	order.PutUint32(header[16:20], source.SnapLen+uint32(max(linkHeaderSize(target)-ETHSize, 0)))
+	order.PutUint32(header[20:24], uint32(target))
+	_, err = w.Write(header)
+	if err != nil {
+		return stats, fmt.Errorf("header was not written: %w", err)
+	}
+	packetHeader := make([]byte, pcapPacketHeaderSize)
+	for {
+		data, ci, err := pr.ReadPacket()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return stats, nil
+			}
+			return stats, fmt.Errorf("unable to read packet after %d packets: %w", stats.Packets, err)
+		}
+		stats.Packets++
+		if len(data) < minConvertedFrameSize {
+			stats.Skipped++
+			continue
+		}
+		frame, converted, ok := convertFrame(source.LinkType, target, data)
+		if !ok {
+			stats.Skipped++
+			continue
+		}
+		if converted {
+			stats.Converted++
+		}
+		fraction := ci.Timestamp.Nanosecond()
+		if !source.Nanoseconds {
+			fraction /= int(time.Microsecond)
+		}
+		order.PutUint32(packetHeader[0:4], uint32(ci.Timestamp.Unix()))
+		order.PutUint32(packetHeader[4:8], uint32(fraction))
+		order.PutUint32(packetHeader[8:12], uint32(len(frame)))
+		order.PutUint32(packetHeader[12:16], uint32(max(ci.Length+len(frame)-len(data), len(frame))))
+		_, err = w.Write(packetHeader)
+		if err == nil {
+			_, err = w.Write(frame)
+		}
+		if err != nil {
+			return stats, fmt.Errorf("unable to write packet %d: %w", stats.Packets, err)
+		}
+	}
+}
+
+// linkHeaderSize
+// header size of the link type
+func linkHeaderSize(linkType LinkType) int {
+	switch linkType {
+	case LinkTypeLinuxSLL:
+		return SLLSize
+	case LinkTypeLinuxSLL2:
+		return SLL2Size
+	}
+	return ETHSize
+}
+
+// convertFrame
+// rewrites the frame link-layer header, returns the frame, true if the header was rewritten
+// and false if the frame can not be written into the target capture
+func convertFrame(source, target LinkType, data []byte) ([]byte, bool, bool) {
+	frameType := source
+	if source == LinkTypeEthernet {
+		if _, err := DetectAndParseSll(data); err == nil {
+			frameType = LinkTypeLinuxSLL
+		}
+	}
+	if frameType == target {
+		return data, false, true
+	}
+	header, ok := parseLinkHeader(frameType, data)
+	if !ok {
+		// unknown frames are kept as is in Ethernet captures only
+		return data, false, target == LinkTypeEthernet && frameType == LinkTypeEthernet
+	}
+	frame := make([]byte, linkHeaderSize(target), linkHeaderSize(target)+len(data)-header.size)
+	switch target {
+	case LinkTypeEthernet:
+		copy(frame[0:ethAddrSize-1], header.addr[:])
+		frame[ethAddrSize-1] = 2
+		copy(frame[ethAddrSize:2*ethAddrSize-1], header.addr[:])
+		frame[2*ethAddrSize-1] = 1
+		binary.BigEndian.PutUint16(frame[12:14], header.protocol)
+	case LinkTypeLinuxSLL:
+		binary.BigEndian.PutUint16(frame[0:2], header.packetType)
+		binary.BigEndian.PutUint16(frame[2:4], header.addrType)
+		binary.BigEndian.PutUint16(frame[4:6], uint16(header.addrLen))
+		copy(frame[6:14], header.addr[:])
+		binary.BigEndian.PutUint16(frame[14:16], header.protocol)
+	case LinkTypeLinuxSLL2:
+		// reserved field and interface index are left zero
+		binary.BigEndian.PutUint16(frame[0:2], header.protocol)
+		binary.BigEndian.PutUint16(frame[8:10], header.addrType)
+		frame[10] = byte(header.packetType)
+		frame[11] = byte(header.addrLen)
+		copy(frame[12:20], header.addr[:])
+	}
+	return append(frame, data[header.size:]...), true, true
+}
+
+// parseLinkHeader
+// extracts link-layer header fields of the frame
+func parseLinkHeader(frameType LinkType, data []byte) (linkHeader, bool) {
+	header := linkHeader{size: linkHeaderSize(frameType)}
+	if len(data) < header.size {
+		return header, false
+	}
+	switch frameType {
+	case LinkTypeEthernet:
+		// source MAC address becomes the SLL address
+		header.addrType = arphrdEther
+		header.addrLen = ethAddrSize
+		copy(header.addr[:], data[ethAddrSize:2*ethAddrSize])
+		header.protocol = binary.BigEndian.Uint16(data[12:14])
+	case LinkTypeLinuxSLL:
+		header.packetType = binary.BigEndian.Uint16(data[0:2])
+		header.addrType = binary.BigEndian.Uint16(data[2:4])
+		header.addrLen = min(int(binary.BigEndian.Uint16(data[4:6])), sllAddrSize)
+		copy(header.addr[:], data[6:6+header.addrLen])
+		header.protocol = binary.BigEndian.Uint16(data[14:16])
+	case LinkTypeLinuxSLL2:
+		header.protocol = binary.BigEndian.Uint16(data[0:2])
+		header.addrType = binary.BigEndian.Uint16(data[8:10])
+		header.packetType = uint16(data[10])
+		header.addrLen = min(int(data[11]), sllAddrSize)
+		copy(header.addr[:], data[12:12+header.addrLen])
+	default:
+		return header, false
+	}
+	return header, true
+}
@@ -0,0 +1,213 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// link-layer frames of the same IPv4 packet written in hex, the client address is 02:42:ac:11:00:01
+const (
+	// testIpPacket IPv4 header of 10.0.0.1 -> 10.0.0.2 without payload
+	testIpPacket = "45000014 00000000 40060000 0a000001 0a000002"
+	// testSllFrame SLL header: incoming packet, ARPHRD_ETHER, 6 bytes address, IPv4
+	testSllFrame = "0000 0001 0006 0242ac1100010000 0800" + testIpPacket
+	// testSll2Frame SLL2 header: IPv4, interface 0, ARPHRD_ETHER, incoming packet, 6 bytes address
+	testSll2Frame = "0800 0000 00000000 0001 00 06 0242ac1100010000" + testIpPacket
+	// testEthernetFrame Ethernet header made of the SLL address
+	testEthernetFrame = "0242ac110002 0242ac110001 0800" + testIpPacket
+)
+
+// testTime time stamp of the first test packet, the next ones are a second apart
+var testTime = time.Unix(1700000000, 500000000).UTC()
+
+// pcapBytes
+// makes pcap file of the frames written in hex
+func pcapBytes(t *testing.T, order binary.ByteOrder, nanoseconds bool, linkType LinkType, frames ...string) []byte {
+	header := make([]byte, pcapFileHeaderSize)
+	magic := pcapMagicMicroseconds
+	if nanoseconds {
+		magic = pcapMagicNanoseconds
+	}
+	order.PutUint32(header[0:4], magic)
+	order.PutUint16(header[4:6], pcapVersionMajor)
+	order.PutUint16(header[6:8], pcapVersionMinor)
+	order.PutUint32(header[16:20], 65535)
+	order.PutUint32(header[20:24], uint32(linkType))
+	data := header
+	for i, frame := range frames {
+		packet, err := hex.DecodeString(strings.ReplaceAll(frame, " ", ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fraction := testTime.Nanosecond()
+		if !nanoseconds {
+			fraction /= int(time.Microsecond)
+		}
+		packetHeader := make([]byte, pcapPacketHeaderSize)
+		order.PutUint32(packetHeader[0:4], uint32(testTime.Unix())+uint32(i))
+		order.PutUint32(packetHeader[4:8], uint32(fraction))
+		order.PutUint32(packetHeader[8:12], uint32(len(packet)))
+		order.PutUint32(packetHeader[12:16], uint32(len(packet)))
+		data = append(append(data, packetHeader...), packet...)
+	}
+	return data
+}
+
+// gzipped
+// compresses the data
+func gzipped(t *testing.T, data []byte) []byte {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// readPcapFrames
+// returns the pcap header and the frames in hex, checks the time stamps of the frames
+func readPcapFrames(t *testing.T, data []byte) (PcapHeader, []string) {
+	t.Helper()
+	pr, err := NewPcapReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := make([]string, 0)
+	for {
+		frame, ci, err := pr.ReadPacket()
+		if errors.Is(err, io.EOF) {
+			return pr.Header(), frames
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := testTime.Add(time.Duration(len(frames)) * time.Second); !ci.Timestamp.Equal(want) {
+			t.Errorf("frame %d time %v, want %v", len(frames), ci.Timestamp, want)
+		}
+		if ci.CaptureLength != len(frame) || ci.Length != len(frame) {
+			t.Errorf("frame %d lengths %d/%d, want %d", len(frames), ci.CaptureLength, ci.Length, len(frame))
+		}
+		frames = append(frames, hex.EncodeToString(frame))
+	}
+}
+
+func TestConvertCapture(t *testing.T) {
+	le, be := binary.LittleEndian, binary.BigEndian
+	tests := []struct {
+		name       string
+		input      []byte
+		target     LinkType
+		wantOrder  binary.ByteOrder
+		wantNanos  bool
+		wantFrames []string
+		wantStats  ConvertStats
+		wantErr    string
+	}{
+		{
+			name:       "SLL to Ethernet",
+			input:      pcapBytes(t, le, false, LinkTypeLinuxSLL, testSllFrame),
+			target:     LinkTypeEthernet,
+			wantOrder:  le,
+			wantFrames: []string{testEthernetFrame},
+			wantStats:  ConvertStats{Packets: 1, Converted: 1},
+		},
+		{
+			name:       "Ethernet to SLL keeps big endian nanosecond file",
+			input:      pcapBytes(t, be, true, LinkTypeEthernet, testEthernetFrame),
+			target:     LinkTypeLinuxSLL,
+			wantOrder:  be,
+			wantNanos:  true,
+			wantFrames: []string{testSllFrame},
+			wantStats:  ConvertStats{Packets: 1, Converted: 1},
+		},
+		{
+			name:       "compressed SLL2 to SLL",
+			input:      gzipped(t, pcapBytes(t, le, false, LinkTypeLinuxSLL2, testSll2Frame)),
+			target:     LinkTypeLinuxSLL,
+			wantOrder:  le,
+			wantFrames: []string{testSllFrame},
+			wantStats:  ConvertStats{Packets: 1, Converted: 1},
+		},
+		{
+			name:       "SLL to SLL2",
+			input:      pcapBytes(t, le, false, LinkTypeLinuxSLL, testSllFrame),
+			target:     LinkTypeLinuxSLL2,
+			wantOrder:  le,
+			wantFrames: []string{testSll2Frame},
+			wantStats:  ConvertStats{Packets: 1, Converted: 1},
+		},
+		{
+			name:       "cooked frames saved as Ethernet",
+			input:      pcapBytes(t, le, false, LinkTypeEthernet, testSllFrame, testEthernetFrame, "0102"),
+			target:     LinkTypeEthernet,
+			wantOrder:  le,
+			wantFrames: []string{testEthernetFrame, testEthernetFrame},
+			wantStats:  ConvertStats{Packets: 3, Converted: 1, Skipped: 1},
+		},
+		{
+			name:    "not supported target",
+			input:   pcapBytes(t, le, false, LinkTypeEthernet),
+			target:  LinkTypeRaw,
+			wantErr: "conversion into link type 101 not supported",
+		},
+		{
+			name:    "not supported source",
+			input:   pcapBytes(t, le, false, LinkTypeRaw, testIpPacket),
+			target:  LinkTypeEthernet,
+			wantErr: "conversion from link type 101 not supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			stats, err := ConvertCapture(bytes.NewReader(tt.input), out, tt.target)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ConvertCapture() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats != tt.wantStats {
+				t.Errorf("ConvertCapture() stats %+v, want %+v", stats, tt.wantStats)
+			}
+			header, frames := readPcapFrames(t, out.Bytes())
+			if header.LinkType != tt.target || header.Order != tt.wantOrder || header.Nanoseconds != tt.wantNanos {
+				t.Errorf("output header %+v, want link type %d, order %v, nanoseconds %v",
+					header, tt.target, tt.wantOrder, tt.wantNanos)
+			}
+			for i := range tt.wantFrames {
+				tt.wantFrames[i] = strings.ReplaceAll(tt.wantFrames[i], " ", "")
+			}
+			if strings.Join(frames, ",") != strings.Join(tt.wantFrames, ",") {
+				t.Errorf("output frames\n%v\nwant\n%v", frames, tt.wantFrames)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"strings"
+
+	"github.com/google/gopacket/layers"
+)
+
+// DnsPort DNS server UDP port
+const DnsPort = 53
+
+// PeerName
+// a host name of the address seen in the capture
+type PeerName struct {
+	Address string
+	Name    string
+}
+
+// DecodeDnsNames
+// decodes A and AAAA records of the DNS response carried in the UDP datagram, the addresses are named with
+// the queried name (the first name of CNAME chain), returns nil for not DNS response or failed query
+func DecodeDnsNames(datagram []byte) []PeerName {
+	udp := layers.UDP{}
+	if udp.DecodeFromBytes(datagram, &DecodeFeedback{}) != nil || udp.SrcPort != DnsPort {
+		return nil
+	}
+	dns := layers.DNS{}
+	if dns.DecodeFromBytes(udp.Payload, &DecodeFeedback{}) != nil || !dns.QR || dns.ResponseCode != layers.DNSResponseCodeNoErr {
+		return nil
+	}
+	var names []PeerName
+	for _, answer := range dns.Answers {
+		if (answer.Type != layers.DNSTypeA && answer.Type != layers.DNSTypeAAAA) || answer.IP == nil {
+			continue
+		}
+		name := string(answer.Name)
+		if len(dns.Questions) > 0 {
+			name = string(dns.Questions[0].Name)
+		}
+		name = strings.TrimSuffix(name, ".")
+		if name == "" {
+			continue
+		}
+		names = append(names, PeerName{Address: answer.IP.String(), Name: name})
+	}
+	return names
+}
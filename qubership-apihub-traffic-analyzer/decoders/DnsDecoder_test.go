@@ -0,0 +1,112 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// dnsDatagram
+// makes UDP datagram of the DNS message sent from the source port
+func dnsDatagram(t *testing.T, srcPort layers.UDPPort, dns *layers.DNS) []byte {
+	buf := gopacket.NewSerializeBuffer()
+	udp := &layers.UDP{SrcPort: srcPort, DstPort: 50000}
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, udp, dns); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeDnsNames(t *testing.T) {
+	question := func(name string) []layers.DNSQuestion {
+		return []layers.DNSQuestion{{Name: []byte(name), Type: layers.DNSTypeA, Class: layers.DNSClassIN}}
+	}
+	address := func(name, ip string) layers.DNSResourceRecord {
+		record := layers.DNSResourceRecord{Name: []byte(name), Class: layers.DNSClassIN, TTL: 30, IP: net.ParseIP(ip)}
+		record.Type = layers.DNSTypeAAAA
+		if record.IP.To4() != nil {
+			record.Type = layers.DNSTypeA
+		}
+		return record
+	}
+	cname := layers.DNSResourceRecord{Name: []byte("orders.shop.svc.cluster.local"), Type: layers.DNSTypeCNAME,
+		Class: layers.DNSClassIN, TTL: 30, CNAME: []byte("orders-v2.shop.svc.cluster.local")}
+	tests := []struct {
+		name    string
+		srcPort layers.UDPPort
+		dns     *layers.DNS
+		want    string
+	}{
+		{
+			name:    "A and AAAA records",
+			srcPort: DnsPort,
+			dns: &layers.DNS{QR: true, Questions: question("orders.shop.svc.cluster.local"), Answers: []layers.DNSResourceRecord{
+				address("orders.shop.svc.cluster.local", "10.0.0.5"),
+				address("orders.shop.svc.cluster.local", "fd00::5"),
+			}},
+			want: "10.0.0.5=orders.shop.svc.cluster.local;fd00::5=orders.shop.svc.cluster.local",
+		},
+		{
+			name:    "CNAME chain is named by the query",
+			srcPort: DnsPort,
+			dns: &layers.DNS{QR: true, Questions: question("orders.shop.svc.cluster.local"), Answers: []layers.DNSResourceRecord{
+				cname, address("orders-v2.shop.svc.cluster.local", "10.0.0.6"),
+			}},
+			want: "10.0.0.6=orders.shop.svc.cluster.local",
+		},
+		{
+			name:    "answer without question",
+			srcPort: DnsPort,
+			dns:     &layers.DNS{QR: true, Answers: []layers.DNSResourceRecord{address("billing", "10.0.0.7")}},
+			want:    "10.0.0.7=billing",
+		},
+		{
+			name:    "query",
+			srcPort: DnsPort,
+			dns:     &layers.DNS{Questions: question("orders")},
+		},
+		{
+			name:    "failed query",
+			srcPort: DnsPort,
+			dns:     &layers.DNS{QR: true, ResponseCode: layers.DNSResponseCodeNXDomain, Questions: question("orders")},
+		},
+		{
+			name:    "not DNS port",
+			srcPort: 5353,
+			dns:     &layers.DNS{QR: true, Questions: question("orders"), Answers: []layers.DNSResourceRecord{address("orders", "10.0.0.5")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := DecodeDnsNames(dnsDatagram(t, tt.srcPort, tt.dns))
+			got := make([]string, 0, len(names))
+			for _, name := range names {
+				got = append(got, fmt.Sprintf("%s=%s", name.Address, name.Name))
+			}
+			if strings.Join(got, ";") != tt.want {
+				t.Errorf("DecodeDnsNames() = %q, want %q", strings.Join(got, ";"), tt.want)
+			}
+		})
+	}
+	if names := DecodeDnsNames([]byte{0, 53, 0xc3, 0x50, 0, 12, 0, 0, 1, 2, 3, 4}); names != nil {
+		t.Errorf("DecodeDnsNames() of not DNS datagram = %v", names)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/gopacket/layers"
+)
+
+const (
+	// maxEncapsulationDepth nested encapsulations removed at most, deeper packets are dropped
+	maxEncapsulationDepth = 8
+	// vlanTagSize 802.1Q tag size (TCI and encapsulated ethernet type)
+	vlanTagSize = 4
+	// udpHeaderSize UDP header size
+	udpHeaderSize = 8
+	// vxlanHeaderSize VXLAN header size
+	vxlanHeaderSize = 8
+	// geneveHeaderSize Geneve header size without options
+	geneveHeaderSize = 8
+	// greHeaderSize GRE header size without optional fields
+	greHeaderSize = 4
+	// VxlanPort IANA VXLAN UDP port (Calico VXLAN)
+	VxlanPort = 4789
+	// VxlanLinuxPort Linux kernel default VXLAN UDP port (Flannel VXLAN)
+	VxlanLinuxPort = 8472
+	// GenevePort Geneve UDP port (OVN)
+	GenevePort = 6081
+	// ethernetTypeQinQ 802.1ad service VLAN tag
+	ethernetTypeQinQ layers.EthernetType = 0x88a8
+	// ethernetTypeQinQLegacy pre-standard double tagging
+	ethernetTypeQinQLegacy layers.EthernetType = 0x9100
+	// ethernetTypeTransparentBridging Ethernet frame in GRE and Geneve
+	ethernetTypeTransparentBridging layers.EthernetType = 0x6558
+	// greFlagChecksum, greFlagKey, greFlagSequence GRE optional field flags
+	greFlagChecksum = 0x8000
+	greFlagKey      = 0x2000
+	greFlagSequence = 0x1000
+	// greVersionMask GRE version bits
+	greVersionMask = 0x7
+	// vxlanFlagVni VXLAN valid VNI flag
+	vxlanFlagVni = 0x08
+)
+
+// Encapsulation
+// tunnel and VLAN layers removed to reach the innermost IP packet
+type Encapsulation struct {
+	// OuterSrcIP, OuterDstIP addresses of the outermost tunnel IP header (node addresses), empty for VLAN only
+	OuterSrcIP string
+	OuterDstIP string
+	// Layers removed layers from the outermost one: vlan:100, vxlan:42, geneve:7, gre, gre:5 (key), ipip
+	Layers []string
+}
+
+// String
+// returns the removed layers separated with '/'
+func (e *Encapsulation) String() string {
+	if e == nil {
+		return ""
+	}
+	return strings.Join(e.Layers, "/")
+}
+
+// Reverse
+// returns encapsulation of the opposite direction
+func (e *Encapsulation) Reverse() *Encapsulation {
+	if e == nil {
+		return nil
+	}
+	return &Encapsulation{
+		OuterSrcIP: e.OuterDstIP,
+		OuterDstIP: e.OuterSrcIP,
+		Layers:     e.Layers,
+	}
+}
+
+// ErrorTooDeepEncapsulation too many nested encapsulations
+var ErrorTooDeepEncapsulation = errors.New("too deep encapsulation")
+
+// unwrapGre
+// removes GRE header, returns the encapsulated protocol, payload and the layer name
+func unwrapGre(data []byte) (layers.EthernetType, []byte, string, error) {
+	if len(data) < greHeaderSize {
+		return 0, nil, "", errors.New("not enough data")
+	}
+	flags := binary.BigEndian.Uint16(data[0:2])
+	if flags&greVersionMask != 0 {
+		return 0, nil, "", fmt.Errorf("GRE version %d not supported", flags&greVersionMask)
+	}
+	etherType := layers.EthernetType(binary.BigEndian.Uint16(data[2:4]))
+	size := greHeaderSize
+	if flags&greFlagChecksum != 0 {
+		size += 4
+	}
+	layer := "gre"
+	if flags&greFlagKey != 0 {
+		if len(data) < size+4 {
+			return 0, nil, "", errors.New("not enough data")
+		}
+		layer = fmt.Sprintf("gre:%d", binary.BigEndian.Uint32(data[size:size+4]))
+		size += 4
+	}
+	if flags&greFlagSequence != 0 {
+		size += 4
+	}
+	if len(data) < size {
+		return 0, nil, "", errors.New("not enough data")
+	}
+	return etherType, data[size:], layer, nil
+}
+
+// unwrapUdpTunnel
+// removes UDP and VXLAN or Geneve headers, payload is nil for not tunnel datagram
+func unwrapUdpTunnel(data []byte) (layers.EthernetType, []byte, string) {
+	if len(data) < udpHeaderSize {
+		return 0, nil, ""
+	}
+	port := binary.BigEndian.Uint16(data[2:4])
+	data = data[udpHeaderSize:]
+	switch port {
+	case VxlanPort, VxlanLinuxPort:
+		if len(data) < vxlanHeaderSize || data[0]&vxlanFlagVni == 0 {
+			return 0, nil, ""
+		}
+		return ethernetTypeTransparentBridging, data[vxlanHeaderSize:], fmt.Sprintf("vxlan:%d", vni(data[4:7]))
+	case GenevePort:
+		if len(data) < geneveHeaderSize || data[0]>>6 != 0 {
+			return 0, nil, ""
+		}
+		size := geneveHeaderSize + int(data[0]&0x3f)*4
+		if len(data) < size {
+			return 0, nil, ""
+		}
+		etherType := layers.EthernetType(binary.BigEndian.Uint16(data[2:4]))
+		return etherType, data[size:], fmt.Sprintf("geneve:%d", vni(data[4:7]))
+	}
+	return 0, nil, ""
+}
+
+// unwrapEthernet
+// removes encapsulated Ethernet header
+func unwrapEthernet(data []byte) (layers.EthernetType, []byte, error) {
+	if len(data) < ETHSize {
+		return 0, nil, errors.New("not enough data")
+	}
+	return layers.EthernetType(binary.BigEndian.Uint16(data[12:14])), data[ETHSize:], nil
+}
+
+// vni
+// decodes 24-bit virtual network identifier
+func vni(data []byte) uint32 {
+	return uint32(data[0])<<16 | uint32(data[1])<<8 | uint32(data[2])
+}
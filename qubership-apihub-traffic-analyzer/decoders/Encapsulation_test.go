@@ -0,0 +1,278 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket/layers"
+)
+
+// testPayload upper layer data of the innermost test packets
+const testPayload = "tcp segment data"
+
+// ipv4Packet
+// makes IPv4 packet of the payload, the fragment sets identification and fragment offset
+func ipv4Packet(src, dst string, protocol layers.IPProtocol, payload []byte, fragment *IpFragment) []byte {
+	header := make([]byte, 20)
+	header[0] = 0x45
+	binary.BigEndian.PutUint16(header[2:4], uint16(len(header)+len(payload)))
+	if fragment != nil {
+		binary.BigEndian.PutUint16(header[4:6], uint16(fragment.Id))
+		flagsOffset := uint16(fragment.Offset / 8)
+		if fragment.More {
+			flagsOffset |= 0x2000
+		}
+		binary.BigEndian.PutUint16(header[6:8], flagsOffset)
+	}
+	header[8] = 64
+	header[9] = byte(protocol)
+	copy(header[12:16], net.ParseIP(src).To4())
+	copy(header[16:20], net.ParseIP(dst).To4())
+	return append(header, payload...)
+}
+
+// ipv6Packet
+// makes IPv6 packet of the payload, the payload starts with extension headers when next is an extension header
+func ipv6Packet(src, dst string, next layers.IPProtocol, payload []byte) []byte {
+	header := make([]byte, 40)
+	header[0] = 0x60
+	binary.BigEndian.PutUint16(header[4:6], uint16(len(payload)))
+	header[6] = byte(next)
+	header[7] = 64
+	copy(header[8:24], net.ParseIP(src).To16())
+	copy(header[24:40], net.ParseIP(dst).To16())
+	return append(header, payload...)
+}
+
+// udpDatagram
+// makes UDP datagram to the destination port
+func udpDatagram(dstPort uint16, payload []byte) []byte {
+	header := make([]byte, udpHeaderSize)
+	binary.BigEndian.PutUint16(header[0:2], 50000)
+	binary.BigEndian.PutUint16(header[2:4], dstPort)
+	binary.BigEndian.PutUint16(header[4:6], uint16(udpHeaderSize+len(payload)))
+	return append(header, payload...)
+}
+
+// ethernetFrame
+// makes Ethernet frame of the IP packet
+func ethernetFrame(etherType layers.EthernetType, packet []byte) []byte {
+	frame := make([]byte, ETHSize)
+	copy(frame[0:6], []byte{0x02, 0, 0, 0, 0, 0x02})
+	copy(frame[6:12], []byte{0x02, 0, 0, 0, 0, 0x01})
+	binary.BigEndian.PutUint16(frame[12:14], uint16(etherType))
+	return append(frame, packet...)
+}
+
+// vlanTag
+// makes 802.1Q tag of the VLAN followed by the packet of the type
+func vlanTag(vlan uint16, etherType layers.EthernetType, packet []byte) []byte {
+	tag := binary.BigEndian.AppendUint16(nil, vlan)
+	tag = binary.BigEndian.AppendUint16(tag, uint16(etherType))
+	return append(tag, packet...)
+}
+
+// concat
+// joins the header parts and the payload
+func concat(parts ...[]byte) []byte {
+	result := make([]byte, 0)
+	for _, part := range parts {
+		result = append(result, part...)
+	}
+	return result
+}
+
+func TestNetworkDecoderEncapsulation(t *testing.T) {
+	inner := ipv4Packet("10.0.0.1", "10.0.0.2", layers.IPProtocolTCP, []byte(testPayload), nil)
+	inner6 := ipv6Packet("fd00::1", "fd00::2", layers.IPProtocolTCP, []byte(testPayload))
+	outer := func(protocol layers.IPProtocol, payload []byte) []byte {
+		return ipv4Packet("192.168.0.1", "192.168.0.2", protocol, payload, nil)
+	}
+	vxlan := func(port uint16) []byte {
+		header := []byte{vxlanFlagVni, 0, 0, 0, 0, 0, 42, 0}
+		return outer(layers.IPProtocolUDP, udpDatagram(port, concat(header, ethernetFrame(layers.EthernetTypeIPv4, inner))))
+	}
+	tooDeep := inner
+	for i := 0; i < maxEncapsulationDepth; i++ {
+		tooDeep = outer(layers.IPProtocolIPv4, tooDeep)
+	}
+	tests := []struct {
+		name       string
+		etherType  layers.EthernetType
+		data       []byte
+		wantFlow   string
+		wantLayers string
+		wantOuter  string
+		wantErr    error
+	}{
+		{
+			name:      "not encapsulated packet",
+			etherType: layers.EthernetTypeIPv4,
+			data:      inner,
+			wantFlow:  "10.0.0.1->10.0.0.2 TCP",
+		},
+		{
+			name:       "VLAN tag",
+			etherType:  layers.EthernetTypeDot1Q,
+			data:       vlanTag(0x2064, layers.EthernetTypeIPv4, inner),
+			wantFlow:   "10.0.0.1->10.0.0.2 TCP",
+			wantLayers: "vlan:100",
+		},
+		{
+			name:       "double tagged VLAN",
+			etherType:  ethernetTypeQinQ,
+			data:       vlanTag(10, layers.EthernetTypeDot1Q, vlanTag(20, layers.EthernetTypeIPv6, inner6)),
+			wantFlow:   "fd00::1->fd00::2 TCP",
+			wantLayers: "vlan:10/vlan:20",
+		},
+		{
+			name:       "VXLAN",
+			etherType:  layers.EthernetTypeIPv4,
+			data:       vxlan(VxlanPort),
+			wantFlow:   "10.0.0.1->10.0.0.2 TCP",
+			wantLayers: "vxlan:42",
+			wantOuter:  "192.168.0.1->192.168.0.2",
+		},
+		{
+			name:       "VXLAN on Linux port",
+			etherType:  layers.EthernetTypeIPv4,
+			data:       vxlan(VxlanLinuxPort),
+			wantFlow:   "10.0.0.1->10.0.0.2 TCP",
+			wantLayers: "vxlan:42",
+			wantOuter:  "192.168.0.1->192.168.0.2",
+		},
+		{
+			name:      "Geneve with options",
+			etherType: layers.EthernetTypeIPv4,
+			data: outer(layers.IPProtocolUDP, udpDatagram(GenevePort, concat(
+				[]byte{1, 0, 0x65, 0x58, 0, 0, 7, 0}, []byte{0, 0x01, 0x80, 0}, ethernetFrame(layers.EthernetTypeIPv4, inner)))),
+			wantFlow:   "10.0.0.1->10.0.0.2 TCP",
+			wantLayers: "geneve:7",
+			wantOuter:  "192.168.0.1->192.168.0.2",
+		},
+		{
+			name:       "Geneve with IP payload",
+			etherType:  layers.EthernetTypeIPv4,
+			data:       outer(layers.IPProtocolUDP, udpDatagram(GenevePort, concat([]byte{0, 0, 0x86, 0xdd, 0, 0, 8, 0}, inner6))),
+			wantFlow:   "fd00::1->fd00::2 TCP",
+			wantLayers: "geneve:8",
+			wantOuter:  "192.168.0.1->192.168.0.2",
+		},
+		{
+			name:       "GRE with key",
+			etherType:  layers.EthernetTypeIPv4,
+			data:       outer(layers.IPProtocolGRE, concat([]byte{0x20, 0, 0x08, 0, 0, 0, 0, 5}, inner)),
+			wantFlow:   "10.0.0.1->10.0.0.2 TCP",
+			wantLayers: "gre:5",
+			wantOuter:  "192.168.0.1->192.168.0.2",
+		},
+		{
+			name:      "GRE with checksum and sequence over IPv6",
+			etherType: layers.EthernetTypeIPv6,
+			data: ipv6Packet("fd00::a", "fd00::b", layers.IPProtocolGRE, concat(
+				[]byte{0x90, 0, 0x65, 0x58, 0, 0, 0, 0, 0, 0, 0, 1}, ethernetFrame(layers.EthernetTypeIPv4, inner))),
+			wantFlow:   "10.0.0.1->10.0.0.2 TCP",
+			wantLayers: "gre",
+			wantOuter:  "fd00::a->fd00::b",
+		},
+		{
+			name:       "IP in IP inside VLAN",
+			etherType:  layers.EthernetTypeDot1Q,
+			data:       vlanTag(5, layers.EthernetTypeIPv4, outer(layers.IPProtocolIPv4, outer(layers.IPProtocolIPv6, inner6))),
+			wantFlow:   "fd00::1->fd00::2 TCP",
+			wantLayers: "vlan:5/ipip/ipip",
+			wantOuter:  "192.168.0.1->192.168.0.2",
+		},
+		{
+			name:      "UDP datagram is not a tunnel",
+			etherType: layers.EthernetTypeIPv4,
+			data:      outer(layers.IPProtocolUDP, udpDatagram(53, []byte("query"))),
+			wantFlow:  "192.168.0.1->192.168.0.2 UDP",
+		},
+		{
+			name:      "VXLAN without VNI flag",
+			etherType: layers.EthernetTypeIPv4,
+			data:      outer(layers.IPProtocolUDP, udpDatagram(VxlanPort, concat(make([]byte, vxlanHeaderSize), inner))),
+			wantFlow:  "192.168.0.1->192.168.0.2 UDP",
+		},
+		{
+			name:      "GRE version 1",
+			etherType: layers.EthernetTypeIPv4,
+			data:      outer(layers.IPProtocolGRE, concat([]byte{0x30, 0x01, 0x88, 0x0b, 0, 0, 0, 0}, inner)),
+			wantErr:   errors.New("unable to decode GRE in packet 1: GRE version 1 not supported"),
+		},
+		{
+			name:      "truncated encapsulated frame",
+			etherType: layers.EthernetTypeIPv4,
+			data:      outer(layers.IPProtocolGRE, []byte{0, 0, 0x65, 0x58, 1, 2, 3}),
+			wantErr:   errors.New("unable to decode encapsulated frame in packet 1: not enough data"),
+		},
+		{
+			name:      "too deep encapsulation",
+			etherType: layers.EthernetTypeIPv4,
+			data:      tooDeep,
+			wantErr:   ErrorTooDeepEncapsulation,
+		},
+		{
+			name:      "not an IP packet",
+			etherType: layers.EthernetTypeARP,
+			data:      inner,
+			wantErr:   ErrorNotAnIpPacket,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nd := NewNetworkDecoder()
+			ip, err := nd.Decode(tt.etherType, tt.data, 1, testTime)
+			if tt.wantErr != nil {
+				if err == nil || !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error() {
+					t.Errorf("Decode() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			protocol := ProtocolName(ip.Protocol)
+			if flow := ip.SrcIP + "->" + ip.DstIP + " " + protocol; flow != tt.wantFlow {
+				t.Errorf("packet %s, want %s", flow, tt.wantFlow)
+			}
+			if nd.Counters().Protocols[protocol] != 1 {
+				t.Errorf("protocol counters %v", nd.Counters().Protocols)
+			}
+			if ip.Encapsulation.String() != tt.wantLayers {
+				t.Errorf("layers %q, want %q", ip.Encapsulation.String(), tt.wantLayers)
+			}
+			outerFlow := ""
+			if ip.Encapsulation != nil && ip.Encapsulation.OuterSrcIP != "" {
+				outerFlow = ip.Encapsulation.OuterSrcIP + "->" + ip.Encapsulation.OuterDstIP
+				reverse := ip.Encapsulation.Reverse()
+				if reverse.OuterSrcIP != ip.Encapsulation.OuterDstIP || reverse.String() != tt.wantLayers {
+					t.Errorf("reversed encapsulation %+v", reverse)
+				}
+			}
+			if outerFlow != tt.wantOuter {
+				t.Errorf("outer addresses %q, want %q", outerFlow, tt.wantOuter)
+			}
+			if protocol == "TCP" && (!ip.TCPExpected || string(ip.TCP) != testPayload) {
+				t.Errorf("TCP data %q, want %q", ip.TCP, testPayload)
+			}
+		})
+	}
+}
@@ -18,6 +18,9 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"net"
+
+	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 )
 
@@ -26,6 +29,8 @@ type IPVersion int
 const (
 	// SLLSize Linux cooked-mode capture (SLL) minimal packet size
 	SLLSize = 16
+	// sllAddrSize SLL link-layer address field size
+	sllAddrSize = 8
 	// ETHSize ethernet header size to drop packet without a proper data inside
 	ETHSize = 14
 	// IPvUnknown IP version is not known
@@ -43,10 +48,33 @@ type IPPacket struct {
 	SrcIP string
 	// DstIP Destination IP address
 	DstIP string
+	// SrcAddr Source IP address in binary form
+	SrcAddr net.IP
+	// DstAddr Destination IP address in binary form
+	DstAddr net.IP
 	// TCP a tcp data (IP payload)
 	TCP []byte
 	// TCPExpected true if IP completely parsed
 	TCPExpected bool
+	// Protocol IP payload protocol (next header for IPv6)
+	Protocol layers.IPProtocol
+	// Payload IP payload of any protocol
+	Payload []byte
+	// Encapsulation tunnel and VLAN layers removed to reach the packet, nil for not encapsulated packet
+	Encapsulation *Encapsulation
+	// Fragment fragment details, nil for not fragmented packet
+	Fragment *IpFragment
+}
+
+// IpFragment
+// IPv4 fragment or IPv6 fragment extension header details
+type IpFragment struct {
+	// Id datagram identification
+	Id uint32
+	// Offset fragment data offset in bytes
+	Offset int
+	// More more fragments follow
+	More bool
 }
 
 // ErrorNotAnIpPacket no TCP/IP data in Linux SLL packet
@@ -72,9 +100,17 @@ func DecodeIp(data []byte, ipv6 bool, i int) (*IPPacket, error) {
 		}
 		ip.SrcIP = ipv6.SrcIP.String()
 		ip.DstIP = ipv6.DstIP.String()
-		ip.TCP = ipv6.Payload
+		ip.SrcAddr = ipv6.SrcIP
+		ip.DstAddr = ipv6.DstIP
 		ip.Version = IPv6
-		ip.TCPExpected = true
+		next := ipv6.NextHeader
+		if ipv6.HopByHop != nil {
+			next = ipv6.HopByHop.NextHeader // hop-by-hop options are removed by the decoder
+		}
+		ip.Protocol, ip.Payload, ip.Fragment, err = walkIpv6Extensions(next, ipv6.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode IPv6 extension headers of packet %d. Error: %v", i, err)
+		}
 	} else {
 		ipv4 := layers.IPv4{}
 		err = ipv4.DecodeFromBytes(data, &df)
@@ -83,15 +119,89 @@ func DecodeIp(data []byte, ipv6 bool, i int) (*IPPacket, error) {
 		}
 		ip.SrcIP = ipv4.SrcIP.String()
 		ip.DstIP = ipv4.DstIP.String()
+		ip.SrcAddr = ipv4.SrcIP
+		ip.DstAddr = ipv4.DstIP
 		ip.Version = IPv4
-		if ipv4.Protocol == layers.IPProtocolTCP {
-			ip.TCP = ipv4.Payload
-			ip.TCPExpected = true
+		ip.Protocol = ipv4.Protocol
+		ip.Payload = ipv4.Payload
+		if ipv4.Flags&layers.IPv4MoreFragments != 0 || ipv4.FragOffset != 0 {
+			ip.Fragment = &IpFragment{
+				Id:     uint32(ipv4.Id),
+				Offset: int(ipv4.FragOffset) * 8,
+				More:   ipv4.Flags&layers.IPv4MoreFragments != 0,
+			}
 		}
 	}
+	ip.expectTcp()
 	return &ip, err
 }
 
+// expectTcp
+// sets TCP data for complete (not fragmented) TCP packet
+func (ip *IPPacket) expectTcp() {
+	if ip.Fragment == nil && ip.Protocol == layers.IPProtocolTCP {
+		ip.TCP = ip.Payload
+		ip.TCPExpected = true
+	} else {
+		ip.TCP = nil
+		ip.TCPExpected = false
+	}
+}
+
+// walkIpv6Extensions
+// skips IPv6 extension headers, returns the upper layer protocol and its data,
+// the walk stops at the fragment header as the rest is a part of the fragmented datagram
+func walkIpv6Extensions(next layers.IPProtocol, data []byte) (layers.IPProtocol, []byte, *IpFragment, error) {
+	for {
+		switch next {
+		case layers.IPProtocolIPv6HopByHop, layers.IPProtocolIPv6Routing, layers.IPProtocolIPv6Destination:
+			if len(data) < 8 {
+				return next, nil, nil, errors.New("truncated extension header")
+			}
+			size := (int(data[1]) + 1) * 8
+			if len(data) < size {
+				return next, nil, nil, errors.New("truncated extension header")
+			}
+			next, data = layers.IPProtocol(data[0]), data[size:]
+		case layers.IPProtocolAH:
+			if len(data) < 8 {
+				return next, nil, nil, errors.New("truncated authentication header")
+			}
+			size := (int(data[1]) + 2) * 4
+			if len(data) < size {
+				return next, nil, nil, errors.New("truncated authentication header")
+			}
+			next, data = layers.IPProtocol(data[0]), data[size:]
+		case layers.IPProtocolIPv6Fragment:
+			if len(data) < 8 {
+				return next, nil, nil, errors.New("truncated fragment header")
+			}
+			fragment := &IpFragment{
+				Id:     binary.BigEndian.Uint32(data[4:8]),
+				Offset: int(binary.BigEndian.Uint16(data[2:4]) & 0xfff8),
+				More:   data[3]&1 != 0,
+			}
+			if fragment.Offset == 0 && !fragment.More {
+				// atomic fragment (RFC 6946) is a complete datagram
+				next, data = layers.IPProtocol(data[0]), data[8:]
+				continue
+			}
+			return layers.IPProtocol(data[0]), data[8:], fragment, nil
+		default:
+			return next, data, nil, nil
+		}
+	}
+}
+
+// NetworkFlow
+// returns the packet addresses as a flow to find out the TCP connection
+func (ip *IPPacket) NetworkFlow() gopacket.Flow {
+	if ip.Version == IPv6 {
+		return gopacket.NewFlow(layers.EndpointIPv6, ip.SrcAddr.To16(), ip.DstAddr.To16())
+	}
+	return gopacket.NewFlow(layers.EndpointIPv4, ip.SrcAddr.To4(), ip.DstAddr.To4())
+}
+
 // DetectAndParseSll
 // an error exposing parser of Linux cooked-mode capture (SLL) packet
 func DetectAndParseSll(data []byte) (*layers.LinuxSLL, error) {
@@ -117,6 +227,9 @@ func DetectAndParseSll(data []byte) (*layers.LinuxSLL, error) {
 		break //log.Tracef("ARPHRD_ type: %x", sll.AddrType)
 	}
 	sll.AddrLen = binary.BigEndian.Uint16(data[4:6])
+	if sll.AddrLen > sllAddrSize {
+		return nil, fmt.Errorf("invalid SLL address length %d", sll.AddrLen)
+	}
 	sll.Addr = data[6 : sll.AddrLen+6]
 	sll.EthernetType = layers.EthernetType(binary.BigEndian.Uint16(data[14:16]))
 	sll.BaseLayer = layers.BaseLayer{Contents: data[:SLLSize], Payload: data[SLLSize:]}
@@ -126,3 +239,82 @@ func DetectAndParseSll(data []byte) (*layers.LinuxSLL, error) {
 	}
 	return nil, ErrorNotAnIpPacket
 }
+
+// LinkType
+// capture link-layer header type (LINKTYPE_* value), gopacket link type is too narrow for some of them
+type LinkType uint16
+
+const (
+	LinkTypeNull     LinkType = 0
+	LinkTypeEthernet LinkType = 1
+	// LinkTypeRawAlt1, LinkTypeRawAlt2 raw IP link types of some platforms
+	LinkTypeRawAlt1   LinkType = 12
+	LinkTypeRawAlt2   LinkType = 14
+	LinkTypeRaw       LinkType = 101
+	LinkTypeLoop      LinkType = 108
+	LinkTypeLinuxSLL  LinkType = 113
+	LinkTypeIPv4      LinkType = 228
+	LinkTypeIPv6      LinkType = 229
+	LinkTypeLinuxSLL2 LinkType = 276
+	// SLL2Size Linux cooked-mode capture v2 (SLL2) header size
+	SLL2Size = 20
+	// loopbackHeaderSize BSD loopback (NULL/LOOP) header size
+	loopbackHeaderSize = 4
+)
+
+// DecodeLinkLayer
+// extracts network layer payload and its type for the capture link type,
+// Ethernet captures may carry Linux cooked-mode (SLL) frames as well
+func DecodeLinkLayer(linkType LinkType, data []byte) ([]byte, layers.EthernetType, error) {
+	switch linkType {
+	case LinkTypeEthernet:
+		sll, err := DetectAndParseSll(data)
+		if err == nil {
+			return sll.Payload, sll.EthernetType, nil
+		}
+		if errors.Is(err, ErrorNotAnIpPacket) {
+			return nil, layers.EthernetTypeLLC, err
+		}
+		df := DecodeFeedback{}
+		etl := layers.Ethernet{}
+		err = etl.DecodeFromBytes(data, &df)
+		if err != nil {
+			return nil, layers.EthernetTypeLLC, err
+		}
+		return etl.Payload, etl.EthernetType, nil
+	case LinkTypeLinuxSLL:
+		if len(data) < SLLSize {
+			return nil, layers.EthernetTypeLLC, errors.New("not enough data to parse SLL")
+		}
+		return data[SLLSize:], layers.EthernetType(binary.BigEndian.Uint16(data[14:16])), nil
+	case LinkTypeLinuxSLL2:
+		if len(data) < SLL2Size {
+			return nil, layers.EthernetTypeLLC, errors.New("not enough data to parse SLL2")
+		}
+		return data[SLL2Size:], layers.EthernetType(binary.BigEndian.Uint16(data[0:2])), nil
+	case LinkTypeRaw, LinkTypeRawAlt1, LinkTypeRawAlt2, LinkTypeIPv4, LinkTypeIPv6:
+		return data, ipEthernetType(data), nil
+	case LinkTypeNull, LinkTypeLoop:
+		if len(data) < loopbackHeaderSize {
+			return nil, layers.EthernetTypeLLC, errors.New("not enough data to parse loopback header")
+		}
+		// address family is in host byte order for NULL and in network byte order for LOOP,
+		// IP version is more reliable than the family value differing between platforms
+		return data[loopbackHeaderSize:], ipEthernetType(data[loopbackHeaderSize:]), nil
+	}
+	return nil, layers.EthernetTypeLLC, fmt.Errorf("link type %d not supported", linkType)
+}
+
+// ipEthernetType
+// detects IP version of raw IP packet
+func ipEthernetType(data []byte) layers.EthernetType {
+	if len(data) > 0 {
+		switch data[0] >> 4 {
+		case IPv4:
+			return layers.EthernetTypeIPv4
+		case IPv6:
+			return layers.EthernetTypeIPv6
+		}
+	}
+	return layers.EthernetTypeLLC
+}
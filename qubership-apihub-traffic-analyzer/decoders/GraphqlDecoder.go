@@ -0,0 +1,423 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// GraphqlQuery query operation type, also used for the shorthand form
+	GraphqlQuery = "query"
+	// GraphqlMutation mutation operation type
+	GraphqlMutation = "mutation"
+	// GraphqlSubscription subscription operation type
+	GraphqlSubscription = "subscription"
+	// graphqlContentType GraphQL document sent as the request body
+	graphqlContentType = "application/graphql"
+	// graphqlIntrospectionPrefix introspection fields are not API operations
+	graphqlIntrospectionPrefix = "__"
+)
+
+// ErrorNotGraphql request body is not a GraphQL request
+var ErrorNotGraphql = errors.New("not a GraphQL request")
+
+// GraphqlOperation
+// an executed GraphQL operation
+type GraphqlOperation struct {
+	// Type query, mutation or subscription
+	Type string
+	// Name operation name, empty for anonymous operation
+	Name string
+	// RootFields names of the root selection set fields (aliases are resolved, fragments are expanded)
+	RootFields []string
+	// Variables operation variables as JSON, nil when not sent
+	Variables json.RawMessage
+}
+
+// graphqlRequest
+// GraphQL over HTTP request body
+type graphqlRequest struct {
+	Query         string          `json:"query"`
+	OperationName string          `json:"operationName"`
+	Variables     json.RawMessage `json:"variables"`
+}
+
+// DecodeGraphqlRequest
+// extracts operations from POST request body, JSON batches give an operation per request
+func DecodeGraphqlRequest(method string, headers map[string]string, body []byte) ([]GraphqlOperation, error) {
+	if method != http.MethodPost || len(body) == 0 {
+		return nil, ErrorNotGraphql
+	}
+	mediaType, _, _ := mime.ParseMediaType(headers["Content-Type"])
+	if mediaType == graphqlContentType {
+		return graphqlOperations(graphqlRequest{Query: string(body)})
+	}
+	requests := make([]graphqlRequest, 0, 1)
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		if err := json.Unmarshal(trimmed, &requests); err != nil {
+			return nil, ErrorNotGraphql
+		}
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		request := graphqlRequest{}
+		if err := json.Unmarshal(trimmed, &request); err != nil {
+			return nil, ErrorNotGraphql
+		}
+		requests = append(requests, request)
+	default:
+		return nil, ErrorNotGraphql
+	}
+	operations := make([]GraphqlOperation, 0, len(requests))
+	for _, request := range requests {
+		if strings.TrimSpace(request.Query) == "" {
+			return nil, ErrorNotGraphql
+		}
+		requestOperations, err := graphqlOperations(request)
+		if err != nil {
+			return operations, err
+		}
+		operations = append(operations, requestOperations...)
+	}
+	return operations, nil
+}
+
+// decodeGraphqlCall
+// fills GraphQL operations of the request, the request stays plain HTTP when the body is not a GraphQL request
+func decodeGraphqlCall(msg *HttpMessage) {
+	if !msg.IsRequest {
+		return
+	}
+	operations, err := DecodeGraphqlRequest(msg.Method, msg.Headers, msg.Body)
+	if err != nil && !errors.Is(err, ErrorNotGraphql) {
+		log.Tracef("unable to decode GraphQL request %s: %v", msg.Path, err)
+	}
+	if len(operations) > 0 {
+		msg.Graphql = operations
+	}
+}
+
+// graphqlOperations
+// parses the document and returns the executed operation (all operations when the name is not given)
+func graphqlOperations(request graphqlRequest) ([]GraphqlOperation, error) {
+	doc, err := parseGraphqlDocument(request.Query)
+	if err != nil {
+		return nil, err
+	}
+	operations := make([]GraphqlOperation, 0, 1)
+	for _, op := range doc.operations {
+		if request.OperationName != "" && op.name != request.OperationName {
+			continue
+		}
+		operations = append(operations, GraphqlOperation{
+			Type:       op.opType,
+			Name:       op.name,
+			RootFields: doc.rootFields(op.selections, make(map[string]bool)),
+			Variables:  request.Variables,
+		})
+	}
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("GraphQL operation '%s' not found in the document", request.OperationName)
+	}
+	return operations, nil
+}
+
+// graphqlSelection
+// a root level selection: a field, a fragment spread or an inline fragment
+type graphqlSelection struct {
+	field string
+	// spread fragment name for the fragment spread
+	spread string
+	// inline selections of the inline fragment
+	inline []graphqlSelection
+}
+
+// graphqlOperationDef
+// an operation definition of the document
+type graphqlOperationDef struct {
+	opType     string
+	name       string
+	selections []graphqlSelection
+}
+
+// graphqlDocument
+// operations and fragments of the document, only the top level selections are kept
+type graphqlDocument struct {
+	operations []graphqlOperationDef
+	fragments  map[string][]graphqlSelection
+}
+
+// rootFields
+// returns field names of the selections expanding fragments, visited prevents fragment cycles
+func (gd *graphqlDocument) rootFields(selections []graphqlSelection, visited map[string]bool) []string {
+	fields := make([]string, 0, len(selections))
+	for _, selection := range selections {
+		switch {
+		case selection.field != "":
+			if !strings.HasPrefix(selection.field, graphqlIntrospectionPrefix) {
+				fields = append(fields, selection.field)
+			}
+		case selection.spread != "":
+			if !visited[selection.spread] {
+				visited[selection.spread] = true
+				fields = append(fields, gd.rootFields(gd.fragments[selection.spread], visited)...)
+			}
+		default:
+			fields = append(fields, gd.rootFields(selection.inline, visited)...)
+		}
+	}
+	return fields
+}
+
+// graphqlParser
+// parses GraphQL executable document tokens
+type graphqlParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseGraphqlDocument
+// parses operation and fragment definitions
+func parseGraphqlDocument(source string) (*graphqlDocument, error) {
+	tokens, err := graphqlTokens(source)
+	if err != nil {
+		return nil, err
+	}
+	gp := &graphqlParser{tokens: tokens}
+	doc := &graphqlDocument{fragments: make(map[string][]graphqlSelection)}
+	for gp.pos < len(gp.tokens) {
+		token := gp.next()
+		switch token {
+		case "{":
+			// query shorthand
+			gp.pos--
+			selections, err := gp.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, graphqlOperationDef{opType: GraphqlQuery, selections: selections})
+		case GraphqlQuery, GraphqlMutation, GraphqlSubscription:
+			op := graphqlOperationDef{opType: token}
+			if isGraphqlName(gp.peek()) {
+				op.name = gp.next()
+			}
+			if gp.peek() == "(" {
+				gp.skipBalanced("(", ")")
+			}
+			gp.directives()
+			op.selections, err = gp.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case "fragment":
+			name := gp.next()
+			if gp.next() != "on" {
+				return nil, fmt.Errorf("type condition expected for fragment %s", name)
+			}
+			gp.next() // type
+			gp.directives()
+			selections, err := gp.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.fragments[name] = selections
+		default:
+			return nil, fmt.Errorf("unexpected token '%s' at document level", token)
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("no operations in GraphQL document")
+	}
+	return doc, nil
+}
+
+// next
+// returns the next token, empty string at the end
+func (gp *graphqlParser) next() string {
+	if gp.pos >= len(gp.tokens) {
+		return ""
+	}
+	gp.pos++
+	return gp.tokens[gp.pos-1]
+}
+
+// peek
+// returns the next token without moving
+func (gp *graphqlParser) peek() string {
+	if gp.pos >= len(gp.tokens) {
+		return ""
+	}
+	return gp.tokens[gp.pos]
+}
+
+// skipBalanced
+// skips tokens from the opening token to the matching closing one
+func (gp *graphqlParser) skipBalanced(open, close string) {
+	depth := 0
+	for gp.pos < len(gp.tokens) {
+		token := gp.next()
+		if token == open {
+			depth++
+		} else if token == close {
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// directives
+// skips directives with their arguments
+func (gp *graphqlParser) directives() {
+	for gp.peek() == "@" {
+		gp.next()
+		gp.next() // directive name
+		if gp.peek() == "(" {
+			gp.skipBalanced("(", ")")
+		}
+	}
+}
+
+// selectionSet
+// parses the top level selections, nested selection sets are skipped
+func (gp *graphqlParser) selectionSet() ([]graphqlSelection, error) {
+	if gp.next() != "{" {
+		return nil, fmt.Errorf("selection set expected")
+	}
+	selections := make([]graphqlSelection, 0)
+	for {
+		token := gp.next()
+		switch {
+		case token == "}":
+			return selections, nil
+		case token == "":
+			return nil, fmt.Errorf("unterminated selection set")
+		case token == "...":
+			if isGraphqlName(gp.peek()) && gp.peek() != "on" {
+				selections = append(selections, graphqlSelection{spread: gp.next()})
+				gp.directives()
+				continue
+			}
+			if gp.peek() == "on" {
+				gp.next()
+				gp.next() // type
+			}
+			gp.directives()
+			inline, err := gp.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			selections = append(selections, graphqlSelection{inline: inline})
+		case isGraphqlName(token):
+			field := token
+			if gp.peek() == ":" {
+				gp.next()
+				field = gp.next() // alias: field
+			}
+			if gp.peek() == "(" {
+				gp.skipBalanced("(", ")")
+			}
+			gp.directives()
+			if gp.peek() == "{" {
+				gp.skipBalanced("{", "}")
+			}
+			selections = append(selections, graphqlSelection{field: field})
+		default:
+			return nil, fmt.Errorf("unexpected token '%s' in selection set", token)
+		}
+	}
+}
+
+// isGraphqlName
+// checks if the token is a name
+func isGraphqlName(token string) bool {
+	if token == "" {
+		return false
+	}
+	c := token[0]
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// graphqlTokens
+// splits GraphQL source into tokens, commas and comments are ignored, string tokens keep their quotes
+func graphqlTokens(source string) ([]string, error) {
+	tokens := make([]string, 0)
+	source = strings.TrimPrefix(source, "\uFEFF")
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(source) && source[i] != '\n' && source[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(source[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		case strings.ContainsRune("!$&():=@[]{|}", rune(c)):
+			tokens = append(tokens, string(c))
+			i++
+		case strings.HasPrefix(source[i:], `"""`):
+			end := strings.Index(strings.ReplaceAll(source[i+3:], `\"""`, "    "), `"""`)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated block string")
+			}
+			tokens = append(tokens, source[i:i+end+6])
+			i += end + 6
+		case c == '"':
+			j := i + 1
+			for j < len(source) && source[j] != '"' {
+				if source[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(source) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, source[i:j+1])
+			i = j + 1
+		default:
+			j := i
+			for j < len(source) && isGraphqlNameChar(source[j]) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected character '%c'", c)
+			}
+			tokens = append(tokens, source[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// isGraphqlNameChar
+// checks if the character belongs to a name or a number
+func isGraphqlNameChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || c == '+' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,134 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDecodeGraphqlRequest(t *testing.T) {
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		body    string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "query shorthand",
+			body:    `{"query":"{ user(id: 1) { name } orders { id } }"}`,
+			headers: jsonHeaders,
+			want:    "query : user,orders",
+		},
+		{
+			name:    "named mutation with variables",
+			body:    `{"query":"mutation AddUser($name: String!) { addUser(name: $name) { id } }","variables":{"name":"a"}}`,
+			headers: jsonHeaders,
+			want:    `mutation AddUser: addUser {"name":"a"}`,
+		},
+		{
+			name: "operation selected by name",
+			body: `{"operationName":"Second","query":"query First { a } subscription Second { b }"}`,
+			want: "subscription Second: b",
+		},
+		{
+			name: "all operations without name",
+			body: `{"query":"query First { a } query Second { b }"}`,
+			want: "query First: a;query Second: b",
+		},
+		{
+			name: "aliases, fragments and introspection",
+			body: `{"query":"query Q @cached { u: user { ...F } ...Root ... on Query { orders } __typename } ` +
+				`fragment Root on Query { items ...Root } fragment F on User { name }"}`,
+			want: "query Q: user,items,orders",
+		},
+		{
+			name: "comments, commas and strings",
+			body: `{"query":"# comment\nquery Q { a(s: \"}{\", b: \"\"\"block \\\"\"\" }\"\"\"), b }"}`,
+			want: "query Q: a,b",
+		},
+		{
+			name: "batch of requests",
+			body: `[{"query":"{ a }"},{"query":"mutation M { b }"}]`,
+			want: "query : a;mutation M: b",
+		},
+		{
+			name:    "GraphQL media type",
+			headers: map[string]string{"Content-Type": "application/graphql; charset=utf-8"},
+			body:    "query Q { a }",
+			want:    "query Q: a",
+		},
+		{
+			name:    "GET request",
+			method:  "GET",
+			body:    `{"query":"{ a }"}`,
+			wantErr: ErrorNotGraphql,
+		},
+		{
+			name:    "JSON without query",
+			body:    `{"name":"value"}`,
+			wantErr: ErrorNotGraphql,
+		},
+		{
+			name:    "not JSON",
+			body:    "name=value",
+			wantErr: ErrorNotGraphql,
+		},
+		{
+			name:    "unknown operation name",
+			body:    `{"operationName":"Other","query":"query Q { a }"}`,
+			wantErr: errors.New("GraphQL operation 'Other' not found in the document"),
+		},
+		{
+			name:    "unterminated selection set",
+			body:    `{"query":"query Q { a "}`,
+			wantErr: errors.New("unterminated selection set"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = "POST"
+			}
+			operations, err := DecodeGraphqlRequest(method, tt.headers, []byte(tt.body))
+			if tt.wantErr != nil {
+				if err == nil || !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error() {
+					t.Errorf("DecodeGraphqlRequest() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(operations))
+			for _, op := range operations {
+				operation := fmt.Sprintf("%s %s: %s", op.Type, op.Name, strings.Join(op.RootFields, ","))
+				if op.Variables != nil {
+					operation += " " + string(op.Variables)
+				}
+				got = append(got, operation)
+			}
+			if strings.Join(got, ";") != tt.want {
+				t.Errorf("DecodeGraphqlRequest() = %q, want %q", strings.Join(got, ";"), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// GrpcContentType content type prefix of gRPC messages (application/grpc, application/grpc+proto, ...)
+	GrpcContentType = "application/grpc"
+	// GrpcStatusHeader gRPC call status (usually sent in trailers)
+	GrpcStatusHeader = "Grpc-Status"
+	// GrpcMessageHeader gRPC call status message
+	GrpcMessageHeader = "Grpc-Message"
+	// GrpcEncodingHeader gRPC message compression
+	GrpcEncodingHeader = "Grpc-Encoding"
+	// grpcPrefixSize compressed flag and message length
+	grpcPrefixSize = 5
+)
+
+// ErrorGrpcTruncated gRPC message length exceeds DATA payload
+var ErrorGrpcTruncated = errors.New("truncated gRPC message")
+
+// GrpcCall
+// gRPC details of HTTP/2 message
+type GrpcCall struct {
+	// Service fully qualified service name from :path (package.Service)
+	Service string
+	// Method RPC method name from :path
+	Method string
+	// Status gRPC status code, nil for requests and for responses without status
+	Status *int
+	// StatusMessage gRPC status message
+	StatusMessage string
+	// Messages unwrapped (and uncompressed) protobuf messages
+	Messages [][]byte
+}
+
+// IsGrpc
+// true for gRPC content type
+func IsGrpc(headers map[string]string) bool {
+	return strings.HasPrefix(strings.ToLower(headers["Content-Type"]), GrpcContentType)
+}
+
+// ParseGrpcPath
+// splits /package.Service/Method path into service and method
+func ParseGrpcPath(path string) (string, string) {
+	service, method, found := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !found {
+		return service, ""
+	}
+	return service, method
+}
+
+// DecodeGrpcMessages
+// unwraps length-prefixed gRPC messages, compressed messages are decoded for gzip encoding only
+func DecodeGrpcMessages(data []byte, encoding string) ([][]byte, error) {
+	messages := make([][]byte, 0)
+	for len(data) > 0 {
+		if len(data) < grpcPrefixSize {
+			return messages, ErrorGrpcTruncated
+		}
+		compressed := data[0] == 1
+		length := int(binary.BigEndian.Uint32(data[1:grpcPrefixSize]))
+		if len(data)-grpcPrefixSize < length {
+			return messages, ErrorGrpcTruncated
+		}
+		message := data[grpcPrefixSize : grpcPrefixSize+length]
+		data = data[grpcPrefixSize+length:]
+		if compressed {
+			if !strings.EqualFold(encoding, "gzip") {
+				return messages, fmt.Errorf("unsupported gRPC message encoding '%s'", encoding)
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(message))
+			if err != nil {
+				return messages, fmt.Errorf("unable to create gzip reader: %w", err)
+			}
+			message, err = io.ReadAll(zr)
+			_ = zr.Close()
+			if err != nil {
+				return messages, fmt.Errorf("unable to uncompress gRPC message: %w", err)
+			}
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// decodeGrpcCall
+// fills gRPC details for HTTP/2 message, status is taken from trailers or from headers (trailers-only response)
+func decodeGrpcCall(msg *HttpMessage) *GrpcCall {
+	call := &GrpcCall{}
+	call.Service, call.Method = ParseGrpcPath(msg.Path)
+	if !msg.IsRequest {
+		for _, headers := range []map[string]string{msg.Trailers, msg.Headers} {
+			value, exists := headers[GrpcStatusHeader]
+			if !exists {
+				continue
+			}
+			if status, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+				call.Status = &status
+				call.StatusMessage = headers[GrpcMessageHeader]
+			}
+			break
+		}
+	}
+	messages, err := DecodeGrpcMessages(msg.Raw, msg.Headers[GrpcEncodingHeader])
+	if err != nil {
+		log.Tracef("unable to unwrap gRPC messages for %s/%s: %v", call.Service, call.Method, err)
+	}
+	call.Messages = messages
+	return call
+}
@@ -0,0 +1,140 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/http2"
+)
+
+func TestDecodeGrpcMessages(t *testing.T) {
+	compressed := &bytes.Buffer{}
+	zw := gzip.NewWriter(compressed)
+	_, _ = zw.Write([]byte{0x08, 0x96, 0x01})
+	_ = zw.Close()
+	gzipped := append([]byte{1, 0, 0, 0, byte(compressed.Len())}, compressed.Bytes()...)
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+		want     string
+		wantErr  error
+	}{
+		{
+			name: "two messages",
+			data: []byte{0, 0, 0, 0, 3, 0x08, 0x96, 0x01, 0, 0, 0, 0, 0},
+			want: "089601,",
+		},
+		{
+			name:     "gzip compressed message",
+			data:     gzipped,
+			encoding: "gzip",
+			want:     "089601",
+		},
+		{
+			name:     "unsupported compression",
+			data:     gzipped,
+			encoding: "snappy",
+			wantErr:  errors.New("unsupported gRPC message encoding 'snappy'"),
+		},
+		{
+			name:    "message longer than data",
+			data:    []byte{0, 0, 0, 0, 4, 0x08, 0x96, 0x01},
+			wantErr: ErrorGrpcTruncated,
+		},
+		{
+			name:    "truncated prefix",
+			data:    []byte{0, 0, 0, 0, 3, 0x08, 0x96, 0x01, 0, 0},
+			want:    "089601",
+			wantErr: ErrorGrpcTruncated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := DecodeGrpcMessages(tt.data, tt.encoding)
+			if (err == nil) != (tt.wantErr == nil) || err != nil && !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error() {
+				t.Errorf("DecodeGrpcMessages() error = %v, want %v", err, tt.wantErr)
+			}
+			got := make([]string, 0, len(messages))
+			for _, message := range messages {
+				got = append(got, hex.EncodeToString(message))
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("DecodeGrpcMessages() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrpcCall(t *testing.T) {
+	message := "\x00\x00\x00\x00\x03\x08\x96\x01"
+	request := []string{":method", "POST", ":scheme", "http", ":path", "/shop.v1.Items/Get", ":authority", "items:9090",
+		"content-type", "application/grpc", "te", "trailers"}
+	tests := []struct {
+		name        string
+		response    func(server *http2Writer) string
+		wantStatus  int
+		wantMessage string
+		wantBody    string
+	}{
+		{
+			name: "status in trailers",
+			response: func(server *http2Writer) string {
+				return server.settings().headers(1, false, ":status", "200", "content-type", "application/grpc").
+					data(1, false, message).headers(1, true, "grpc-status", "0").frames()
+			},
+			wantStatus: 0,
+			wantBody:   "\x08\x96\x01",
+		},
+		{
+			name: "trailers only response",
+			response: func(server *http2Writer) string {
+				return server.settings().headers(1, true, ":status", "200", "content-type", "application/grpc",
+					"grpc-status", "5", "grpc-message", "item not found").frames()
+			},
+			wantStatus:  5,
+			wantMessage: "item not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := newHttp2Writer(t), newHttp2Writer(t)
+			sink := assembleSegments(nil, conversation(
+				testPart{true, http2.ClientPreface + client.settings().headers(1, false, request...).data(1, true, message).frames()},
+				testPart{false, tt.response(server)})...)
+			if len(sink.exchanges) != 1 || sink.exchanges[0].Response == nil {
+				t.Fatalf("got %d exchanges, want the answered call", len(sink.exchanges))
+			}
+			req, rsp := sink.exchanges[0].Request, sink.exchanges[0].Response
+			if req.Grpc == nil || req.Grpc.Service != "shop.v1.Items" || req.Grpc.Method != "Get" || req.Grpc.Status != nil ||
+				string(req.Body) != "\x08\x96\x01" {
+				t.Errorf("request call %+v body %x", req.Grpc, req.Body)
+			}
+			if rsp.Grpc == nil || rsp.Grpc.Status == nil || *rsp.Grpc.Status != tt.wantStatus ||
+				rsp.Grpc.StatusMessage != tt.wantMessage || string(rsp.Body) != tt.wantBody {
+				t.Fatalf("response call %+v body %x", rsp.Grpc, rsp.Body)
+			}
+			if rsp.Grpc.Service != "shop.v1.Items" || rsp.Grpc.Method != "Get" {
+				t.Errorf("response call of %s/%s", rsp.Grpc.Service, rsp.Grpc.Method)
+			}
+		})
+	}
+}
@@ -0,0 +1,480 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+const (
+	// http2FrameHeaderSize HTTP/2 frame header length
+	http2FrameHeaderSize = 9
+	// http2MaxFrameSize the largest frame payload allowed by RFC 9113
+	http2MaxFrameSize = 1<<24 - 1
+	// http2DefaultHeaderTableSize initial HPACK dynamic table size
+	http2DefaultHeaderTableSize = 4096
+	// http2MaxStreams active streams kept per connection
+	http2MaxStreams = 4096
+	// http2UpgradeToken Upgrade header value for cleartext HTTP/2
+	http2UpgradeToken = "h2c"
+	// http2PseudoPath request path pseudo header
+	http2PseudoPath = ":path"
+	// http2PseudoMethod request method pseudo header
+	http2PseudoMethod = ":method"
+	// http2PseudoStatus response status pseudo header
+	http2PseudoStatus = ":status"
+	// http2PseudoAuthority request target host pseudo header
+	http2PseudoAuthority = ":authority"
+	// http2UpgradeStreamId stream of the request sent in HTTP/1.1 before h2c upgrade
+	http2UpgradeStreamId = 1
+)
+
+// http2Preface HTTP/2 client connection preface
+var http2Preface = []byte(http2.ClientPreface)
+
+// http2StreamPart
+// a request or a response part of HTTP/2 stream
+type http2StreamPart struct {
+	headers  map[string]string
+	trailers map[string]string
+	data     []byte
+	// start time stamp of the first HEADERS frame
+	start time.Time
+	// end time stamp of the last frame
+	end time.Time
+	// seqNo, ackNo TCP numbers of the first HEADERS frame
+	seqNo, ackNo uint32
+	// headersDone true after the first header block
+	headersDone bool
+	ended       bool
+	// message is set when the part is sent into the sink
+	message *HttpMessage
+}
+
+// http2Exchange
+// an HTTP/2 stream, a request and a response
+type http2Exchange struct {
+	request  http2StreamPart
+	response http2StreamPart
+}
+
+// http2Direction
+// frame decoding state of one connection direction
+type http2Direction struct {
+	half    *httpHalfStream
+	framer  *http2.Framer
+	src     *bytes.Buffer
+	decoder *hpack.Decoder
+	// block header block being accumulated (HEADERS/PUSH_PROMISE + CONTINUATION)
+	block       []byte
+	blockStream uint32
+	blockEnds   bool
+	blockPush   bool
+	// blockStart, blockSeq, blockAck first frame of the header block
+	blockStart time.Time
+	blockSeq   uint32
+	blockAck   uint32
+	// prefaceDone true when the client preface is consumed (client direction only)
+	prefaceDone bool
+}
+
+// http2Connection
+// HTTP/2 connection state: HPACK tables per direction and active streams
+type http2Connection struct {
+	sink    MessageSink
+	client  *http2Direction
+	server  *http2Direction
+	streams map[uint32]*http2Exchange
+	// broken true when HPACK state is lost, no more decoding for connection
+	broken bool
+}
+
+// newHttp2Direction
+// creates frame decoder for the connection direction, only client sends the preface
+func newHttp2Direction(half *httpHalfStream, prefaceDone bool) *http2Direction {
+	src := new(bytes.Buffer)
+	framer := http2.NewFramer(nil, src)
+	framer.SetMaxReadFrameSize(http2MaxFrameSize)
+	// CONTINUATION order is checked by the direction itself
+	framer.AllowIllegalReads = true
+	return &http2Direction{
+		half:        half,
+		framer:      framer,
+		src:         src,
+		decoder:     hpack.NewDecoder(http2DefaultHeaderTableSize, nil),
+		prefaceDone: prefaceDone,
+	}
+}
+
+// newHttp2Connection
+// creates HTTP/2 connection state, client sends requests
+func newHttp2Connection(sink MessageSink, client, server *httpHalfStream) *http2Connection {
+	return &http2Connection{
+		sink:    sink,
+		client:  newHttp2Direction(client, false),
+		server:  newHttp2Direction(server, true),
+		streams: make(map[uint32]*http2Exchange),
+		broken:  false,
+	}
+}
+
+// startUpgraded
+// registers HTTP/1.1 request sent before h2c upgrade, the response comes in the stream 1
+func (hc *http2Connection) startUpgraded(request *HttpMessage) {
+	hc.streams[http2UpgradeStreamId] = &http2Exchange{
+		request: http2StreamPart{
+			headersDone: true,
+			ended:       true,
+			message:     request,
+		},
+	}
+}
+
+// direction
+// returns decoding state for the stream part
+func (hc *http2Connection) direction(hs *httpHalfStream) *http2Direction {
+	if hs == hc.client.half {
+		return hc.client
+	}
+	return hc.server
+}
+
+// parse
+// decodes all complete frames from the half stream buffer
+func (hc *http2Connection) parse(hs *httpHalfStream, final bool) {
+	dir := hc.direction(hs)
+	if !dir.prefaceDone {
+		if len(hs.buf) < len(http2Preface) && bytes.HasPrefix(http2Preface, hs.buf) && !final {
+			return
+		}
+		if bytes.HasPrefix(hs.buf, http2Preface) {
+			hs.consume(len(http2Preface))
+		}
+		dir.prefaceDone = true
+	}
+	for len(hs.buf) >= http2FrameHeaderSize {
+		if hc.broken {
+			hs.consume(len(hs.buf))
+			return
+		}
+		frameSize := http2FrameHeaderSize + int(uint32(hs.buf[0])<<16|uint32(hs.buf[1])<<8|uint32(hs.buf[2]))
+		if len(hs.buf) < frameSize {
+			break
+		}
+		dir.src.Reset()
+		dir.src.Write(hs.buf[:frameSize])
+		start := hs.timestamp(0)
+		end := hs.timestamp(frameSize - 1)
+		seq := hs.bufSeq
+		frame, err := dir.framer.ReadFrame()
+		if err == nil {
+			hc.onFrame(dir, frame, start, end, seq)
+		} else {
+			log.Tracef("unable to decode HTTP/2 frame in stream %s:%d->%s:%d: %v",
+				hs.flow.SrcIP, hs.flow.SrcPort, hs.flow.DstIP, hs.flow.DstPort, err)
+			hc.sink.OnRejected(hs.flow, RejectHttp2Frame)
+		}
+		hs.consume(frameSize)
+	}
+	if final {
+		hs.consume(len(hs.buf))
+	}
+}
+
+// onFrame
+// applies the frame to the connection state
+func (hc *http2Connection) onFrame(dir *http2Direction, frame http2.Frame, start, end time.Time, seq uint32) {
+	streamId := frame.Header().StreamID
+	switch f := frame.(type) {
+	case *http2.HeadersFrame:
+		dir.startBlock(streamId, f.HeaderBlockFragment(), f.StreamEnded(), false, start, seq)
+		if f.HeadersEnded() {
+			hc.onHeaderBlock(dir, end)
+		}
+	case *http2.PushPromiseFrame:
+		// decoded to keep HPACK table in sync, pushed streams are not tracked
+		dir.startBlock(streamId, f.HeaderBlockFragment(), false, true, start, seq)
+		if f.HeadersEnded() {
+			hc.onHeaderBlock(dir, end)
+		}
+	case *http2.ContinuationFrame:
+		if dir.blockStream != streamId {
+			log.Tracef("unexpected CONTINUATION for HTTP/2 stream %d", streamId)
+			return
+		}
+		if len(dir.block)+len(f.HeaderBlockFragment()) > MaxHttpMessageSize {
+			// HPACK state can not be kept without decoding the block
+			log.Tracef("HTTP/2 header block of stream %d in %s:%d->%s:%d is too large, connection skipped", streamId,
+				dir.half.flow.SrcIP, dir.half.flow.SrcPort, dir.half.flow.DstIP, dir.half.flow.DstPort)
+			hc.sink.OnRejected(dir.half.flow, RejectHttp2TooLarge)
+			dir.block = nil
+			dir.blockStream = 0
+			hc.broken = true
+			hc.flush()
+			return
+		}
+		dir.block = append(dir.block, f.HeaderBlockFragment()...)
+		if f.HeadersEnded() {
+			hc.onHeaderBlock(dir, end)
+		}
+	case *http2.DataFrame:
+		exchange, exists := hc.streams[streamId]
+		if !exists {
+			return
+		}
+		part := hc.part(dir, exchange)
+		if len(part.data)+len(f.Data()) > MaxHttpMessageSize {
+			hc.reject(dir, streamId, exchange)
+			return
+		}
+		part.data = append(part.data, f.Data()...)
+		part.end = end
+		if f.StreamEnded() {
+			hc.onPartEnded(dir, streamId, exchange)
+		}
+	case *http2.SettingsFrame:
+		if value, ok := f.Value(http2.SettingHeaderTableSize); ok && !f.IsAck() {
+			// the peer encoder may use the table up to announced size
+			hc.opposite(dir).decoder.SetAllowedMaxDynamicTableSize(value)
+		}
+	case *http2.RSTStreamFrame:
+		if exchange, exists := hc.streams[streamId]; exists {
+			hc.complete(streamId, exchange)
+		}
+	default:
+		// PING, GOAWAY, WINDOW_UPDATE, PRIORITY do not carry messages
+	}
+}
+
+// opposite
+// returns another direction of the connection
+func (hc *http2Connection) opposite(dir *http2Direction) *http2Direction {
+	if dir == hc.client {
+		return hc.server
+	}
+	return hc.client
+}
+
+// part
+// returns the stream part sent in the direction
+func (hc *http2Connection) part(dir *http2Direction, exchange *http2Exchange) *http2StreamPart {
+	if dir == hc.client {
+		return &exchange.request
+	}
+	return &exchange.response
+}
+
+// startBlock
+// begins a new header block
+func (dir *http2Direction) startBlock(streamId uint32, fragment []byte, streamEnds, push bool, start time.Time, seq uint32) {
+	dir.block = append(dir.block[:0], fragment...)
+	dir.blockStream = streamId
+	dir.blockEnds = streamEnds
+	dir.blockPush = push
+	dir.blockStart = start
+	dir.blockSeq = seq
+	dir.blockAck = dir.half.ackNo
+}
+
+// onHeaderBlock
+// decodes the complete header block and applies it to the stream
+func (hc *http2Connection) onHeaderBlock(dir *http2Direction, end time.Time) {
+	streamId := dir.blockStream
+	dir.blockStream = 0
+	fields, err := dir.decoder.DecodeFull(dir.block)
+	if err != nil {
+		log.Debugf("unable to decode HTTP/2 header block for stream %d in %s:%d->%s:%d: %v, connection skipped", streamId,
+			dir.half.flow.SrcIP, dir.half.flow.SrcPort, dir.half.flow.DstIP, dir.half.flow.DstPort, err)
+		hc.sink.OnRejected(dir.half.flow, RejectHttp2HeaderBlock)
+		hc.broken = true
+		hc.flush()
+		return
+	}
+	if dir.blockPush {
+		return
+	}
+	exchange, exists := hc.streams[streamId]
+	if !exists {
+		if dir != hc.client {
+			return // response for a stream started before the capture
+		}
+		if len(hc.streams) >= http2MaxStreams {
+			log.Tracef("too many active HTTP/2 streams in %s:%d->%s:%d",
+				dir.half.flow.SrcIP, dir.half.flow.SrcPort, dir.half.flow.DstIP, dir.half.flow.DstPort)
+			hc.sink.OnRejected(dir.half.flow, RejectHttp2StreamOverflow)
+			return
+		}
+		exchange = &http2Exchange{}
+		hc.streams[streamId] = exchange
+	}
+	part := hc.part(dir, exchange)
+	headers := make(map[string]string)
+	for _, field := range fields {
+		if value, exists := headers[field.Name]; exists {
+			headers[field.Name] = value + "\n" + field.Value
+		} else {
+			headers[field.Name] = field.Value
+		}
+	}
+	if !part.headersDone {
+		if status, err := strconv.Atoi(headers[http2PseudoStatus]); err == nil &&
+			status >= http.StatusContinue && status < http.StatusOK {
+			return // interim response, the final one follows
+		}
+		part.headers = headers
+		part.headersDone = true
+		part.start = dir.blockStart
+		part.seqNo = dir.blockSeq
+		part.ackNo = dir.blockAck
+	} else {
+		part.trailers = headers
+	}
+	part.end = end
+	if dir.blockEnds {
+		hc.onPartEnded(dir, streamId, exchange)
+	}
+}
+
+// onPartEnded
+// sends the finished request into the sink, completes the stream when the response finished
+func (hc *http2Connection) onPartEnded(dir *http2Direction, streamId uint32, exchange *http2Exchange) {
+	part := hc.part(dir, exchange)
+	part.ended = true
+	if dir == hc.client {
+		hc.emit(&exchange.request, hc.client, exchange)
+		return
+	}
+	hc.complete(streamId, exchange)
+}
+
+// complete
+// sends both parts of the stream into the sink and forgets the stream
+func (hc *http2Connection) complete(streamId uint32, exchange *http2Exchange) {
+	delete(hc.streams, streamId)
+	request := hc.emit(&exchange.request, hc.client, exchange)
+	if request == nil {
+		return
+	}
+	hc.sink.OnHttpExchange(&HttpExchange{
+		Request:  request,
+		Response: hc.emit(&exchange.response, hc.server, exchange),
+	})
+}
+
+// reject
+// forgets the stream exceeding the message size limit, the request already sent into the sink is reported unanswered
+func (hc *http2Connection) reject(dir *http2Direction, streamId uint32, exchange *http2Exchange) {
+	log.Tracef("HTTP/2 message of stream %d in %s:%d->%s:%d is too large, stream dropped", streamId,
+		dir.half.flow.SrcIP, dir.half.flow.SrcPort, dir.half.flow.DstIP, dir.half.flow.DstPort)
+	hc.sink.OnRejected(dir.half.flow, RejectHttp2TooLarge)
+	delete(hc.streams, streamId)
+	if exchange.request.message != nil {
+		hc.sink.OnHttpExchange(&HttpExchange{Request: exchange.request.message})
+	}
+}
+
+// emit
+// converts the stream part into the message and sends it into the sink once
+func (hc *http2Connection) emit(part *http2StreamPart, dir *http2Direction, exchange *http2Exchange) *HttpMessage {
+	if part.message != nil || !part.headersDone {
+		return part.message
+	}
+	msg := &HttpMessage{
+		Flow:         dir.half.flow,
+		Timestamp:    part.start,
+		EndTimestamp: part.end,
+		SeqNo:        int(part.seqNo),
+		AckNo:        int(part.ackNo),
+		IsRequest:    dir == hc.client,
+		Headers:      make(map[string]string),
+		Raw:          part.data,
+	}
+	// header names are canonical as in HTTP/1.x messages, pseudo headers are not kept as the method,
+	// path, query and host have their own fields (the query is redacted by the query rules only)
+	for name, value := range part.headers {
+		if strings.HasPrefix(name, ":") {
+			continue
+		}
+		msg.Headers[canonicalHeaderName(name)] = value
+	}
+	if len(part.trailers) > 0 {
+		msg.Trailers = make(map[string]string)
+		for name, value := range part.trailers {
+			msg.Trailers[canonicalHeaderName(name)] = value
+		}
+	}
+	if msg.IsRequest {
+		msg.Method = part.headers[http2PseudoMethod]
+		msg.Path, msg.Query, _ = strings.Cut(part.headers[http2PseudoPath], "?")
+		msg.Host = part.headers[http2PseudoAuthority]
+	} else {
+		msg.StatusCode, _ = strconv.Atoi(part.headers[http2PseudoStatus])
+		msg.Method = fmt.Sprintf("%d %s", msg.StatusCode, http.StatusText(msg.StatusCode))
+		if exchange.request.message != nil {
+			msg.Path = exchange.request.message.Path
+		}
+	}
+	if IsGrpc(msg.Headers) {
+		msg.Grpc = decodeGrpcCall(msg)
+		msg.Body = bytes.Join(msg.Grpc.Messages, nil)
+	} else {
+		bodyResult := BodyToString(bytes.NewReader(part.data), BodyEncoding{
+			ContentEncoding: part.headers["content-encoding"],
+			ContentType:     part.headers["content-type"],
+		})
+		if bodyResult.Err != nil {
+			log.Tracef("unable to decode HTTP/2 message body: %v", bodyResult.Err)
+		}
+		msg.Body = bodyResult.Body
+		decodeGraphqlCall(msg)
+	}
+	part.message = msg
+	hc.sink.OnHttpMessage(msg)
+	return msg
+}
+
+// flush
+// sends all the known streams into the sink when the connection is over
+func (hc *http2Connection) flush() {
+	for streamId, exchange := range hc.streams {
+		hc.complete(streamId, exchange)
+	}
+}
+
+// isHttp2Upgrade
+// true for h2c upgrade request accepted by the server
+func isHttp2Upgrade(request, response *HttpMessage) bool {
+	return response.StatusCode == http.StatusSwitchingProtocols &&
+		strings.EqualFold(strings.TrimSpace(response.Headers["Upgrade"]), http2UpgradeToken) &&
+		strings.Contains(strings.ToLower(request.Headers["Upgrade"]), http2UpgradeToken)
+}
+
+// canonicalHeaderName
+// converts HTTP/2 lowercase header name into canonical form
+func canonicalHeaderName(name string) string {
+	if strings.HasPrefix(name, ":") {
+		return name
+	}
+	return http.CanonicalHeaderKey(name)
+}
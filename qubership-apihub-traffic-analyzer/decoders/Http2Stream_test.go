@@ -0,0 +1,277 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+// http2Writer
+// writes HTTP/2 frames of one connection direction, header blocks share the HPACK table of the direction
+type http2Writer struct {
+	t       *testing.T
+	out     bytes.Buffer
+	framer  *http2.Framer
+	block   bytes.Buffer
+	encoder *hpack.Encoder
+}
+
+// newHttp2Writer
+// creates the direction writer
+func newHttp2Writer(t *testing.T) *http2Writer {
+	hw := &http2Writer{t: t}
+	hw.framer = http2.NewFramer(&hw.out, nil)
+	hw.encoder = hpack.NewEncoder(&hw.block)
+	return hw
+}
+
+// frames
+// returns the frames written since the previous call
+func (hw *http2Writer) frames() string {
+	data := hw.out.String()
+	hw.out.Reset()
+	return data
+}
+
+// encode
+// encodes the header fields given as name, value pairs
+func (hw *http2Writer) encode(fields ...string) []byte {
+	hw.block.Reset()
+	for i := 0; i+1 < len(fields); i += 2 {
+		if err := hw.encoder.WriteField(hpack.HeaderField{Name: fields[i], Value: fields[i+1]}); err != nil {
+			hw.t.Fatal(err)
+		}
+	}
+	return append([]byte(nil), hw.block.Bytes()...)
+}
+
+// settings
+// writes SETTINGS frame
+func (hw *http2Writer) settings() *http2Writer {
+	if err := hw.framer.WriteSettings(); err != nil {
+		hw.t.Fatal(err)
+	}
+	return hw
+}
+
+// headers
+// writes HEADERS frame of the header fields given as name, value pairs
+func (hw *http2Writer) headers(streamId uint32, endStream bool, fields ...string) *http2Writer {
+	err := hw.framer.WriteHeaders(http2.HeadersFrameParam{StreamID: streamId, BlockFragment: hw.encode(fields...),
+		EndStream: endStream, EndHeaders: true})
+	if err != nil {
+		hw.t.Fatal(err)
+	}
+	return hw
+}
+
+// continued
+// writes the header block split into HEADERS and CONTINUATION frames
+func (hw *http2Writer) continued(streamId uint32, endStream bool, fields ...string) *http2Writer {
+	block := hw.encode(fields...)
+	err := hw.framer.WriteHeaders(http2.HeadersFrameParam{StreamID: streamId, BlockFragment: block[:len(block)/2],
+		EndStream: endStream, EndHeaders: false})
+	if err == nil {
+		err = hw.framer.WriteContinuation(streamId, true, block[len(block)/2:])
+	}
+	if err != nil {
+		hw.t.Fatal(err)
+	}
+	return hw
+}
+
+// data
+// writes DATA frame
+func (hw *http2Writer) data(streamId uint32, endStream bool, data string) *http2Writer {
+	if err := hw.framer.WriteData(streamId, endStream, []byte(data)); err != nil {
+		hw.t.Fatal(err)
+	}
+	return hw
+}
+
+// reset
+// writes RST_STREAM frame
+func (hw *http2Writer) reset(streamId uint32) *http2Writer {
+	if err := hw.framer.WriteRSTStream(streamId, http2.ErrCodeCancel); err != nil {
+		hw.t.Fatal(err)
+	}
+	return hw
+}
+
+// http2Message
+// expected message of HTTP/2 stream
+type http2Message struct {
+	method, path, query, host string
+	status                    int
+	body                      string
+	header, trailer           string
+}
+
+// checkHttp2Message
+// compares the decoded message with expected one, header and trailer are "Name: value" of a single field
+func checkHttp2Message(t *testing.T, msg *HttpMessage, want http2Message) {
+	t.Helper()
+	if msg == nil {
+		t.Fatalf("message is missing, want %+v", want)
+	}
+	if msg.Method != want.method || msg.Path != want.path || msg.Query != want.query || msg.Host != want.host ||
+		msg.StatusCode != want.status || string(msg.Body) != want.body {
+		t.Errorf("message %s %s ? %s host %s status %d body %q, want %+v",
+			msg.Method, msg.Path, msg.Query, msg.Host, msg.StatusCode, msg.Body, want)
+	}
+	for name := range msg.Headers {
+		if strings.HasPrefix(name, ":") {
+			t.Errorf("pseudo header %s is stored", name)
+		}
+	}
+	if name, value, found := strings.Cut(want.header, ": "); found && msg.Headers[name] != value {
+		t.Errorf("header %s = %q, want %q", name, msg.Headers[name], value)
+	}
+	if name, value, found := strings.Cut(want.trailer, ": "); found && msg.Trailers[name] != value {
+		t.Errorf("trailer %s = %q, want %q", name, msg.Trailers[name], value)
+	}
+}
+
+func TestHttp2Stream(t *testing.T) {
+	type exchange struct {
+		request  http2Message
+		response *http2Message
+	}
+	getItems := http2Message{method: "GET", path: "/items", query: "limit=1", host: "example.com", header: "Accept: */*"}
+	okItems := &http2Message{method: "200 OK", path: "/items", status: 200, body: "[]", header: "Content-Type: application/json"}
+	tests := []struct {
+		name  string
+		parts func(client, server *http2Writer) []testPart
+		want  []exchange
+	}{
+		{
+			name: "prior knowledge connection",
+			parts: func(client, server *http2Writer) []testPart {
+				return []testPart{
+					{true, http2.ClientPreface + client.settings().
+						headers(1, true, ":method", "GET", ":scheme", "http", ":path", "/items?limit=1",
+							":authority", "example.com", "accept", "*/*").frames()},
+					{false, server.settings().
+						headers(1, false, ":status", "200", "content-type", "application/json").
+						data(1, true, "[]").frames()},
+				}
+			},
+			want: []exchange{{request: getItems, response: okItems}},
+		},
+		{
+			name: "header tables are kept across streams",
+			parts: func(client, server *http2Writer) []testPart {
+				request := []string{":method", "GET", ":scheme", "http", ":path", "/items?limit=1",
+					":authority", "example.com", "accept", "*/*"}
+				response := []string{":status", "200", "content-type", "application/json"}
+				return []testPart{
+					{true, http2.ClientPreface + client.settings().headers(1, true, request...).headers(3, true, request...).frames()},
+					{false, server.settings().headers(3, false, response...).data(3, true, "[]").
+						headers(1, false, response...).data(1, true, "[]").frames()},
+				}
+			},
+			want: []exchange{{request: getItems, response: okItems}, {request: getItems, response: okItems}},
+		},
+		{
+			name: "header block continued and trailers",
+			parts: func(client, server *http2Writer) []testPart {
+				return []testPart{
+					{true, http2.ClientPreface + client.settings().
+						continued(1, false, ":method", "POST", ":scheme", "http", ":path", "/echo",
+							":authority", "example.com", "content-type", "text/plain").
+						data(1, true, "hi").frames()},
+					{false, server.settings().
+						headers(1, false, ":status", "100").
+						headers(1, false, ":status", "200").
+						data(1, false, "hi").
+						headers(1, true, "x-checksum", "abc").frames()},
+				}
+			},
+			want: []exchange{{
+				request:  http2Message{method: "POST", path: "/echo", host: "example.com", body: "hi", header: "Content-Type: text/plain"},
+				response: &http2Message{method: "200 OK", path: "/echo", status: 200, body: "hi", trailer: "X-Checksum: abc"},
+			}},
+		},
+		{
+			name: "cancelled stream",
+			parts: func(client, server *http2Writer) []testPart {
+				return []testPart{
+					{true, http2.ClientPreface + client.settings().
+						headers(1, true, ":method", "GET", ":scheme", "http", ":path", "/slow", ":authority", "example.com").
+						reset(1).frames()},
+				}
+			},
+			want: []exchange{{request: http2Message{method: "GET", path: "/slow", host: "example.com"}}},
+		},
+		{
+			name: "h2c upgrade",
+			parts: func(client, server *http2Writer) []testPart {
+				return []testPart{
+					{true, "GET /items?limit=1 HTTP/1.1\r\nHost: example.com\r\nAccept: */*\r\n" +
+						"Connection: Upgrade, HTTP2-Settings\r\nUpgrade: h2c\r\nHTTP2-Settings: AAMAAABkAAQAoAAAAAIAAAAA\r\n\r\n"},
+					{false, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: h2c\r\n\r\n" +
+						server.settings().headers(1, false, ":status", "200", "content-type", "application/json").
+							data(1, true, "[]").frames()},
+					{true, http2.ClientPreface + client.settings().frames()},
+				}
+			},
+			want: []exchange{{request: getItems, response: okItems}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := assembleSegments(nil, conversation(tt.parts(newHttp2Writer(t), newHttp2Writer(t))...)...)
+			if len(sink.exchanges) != len(tt.want) {
+				t.Fatalf("got %d exchanges, want %d (rejected %v)", len(sink.exchanges), len(tt.want), sink.rejected)
+			}
+			for i, want := range tt.want {
+				checkHttp2Message(t, sink.exchanges[i].Request, want.request)
+				if want.response == nil {
+					if sink.exchanges[i].Response != nil {
+						t.Errorf("response %d is not expected", sink.exchanges[i].Response.StatusCode)
+					}
+					continue
+				}
+				checkHttp2Message(t, sink.exchanges[i].Response, *want.response)
+			}
+			if len(sink.rejected) != 0 {
+				t.Errorf("rejected %v", sink.rejected)
+			}
+		})
+	}
+}
+
+func TestHttp2StreamLostHeaderTable(t *testing.T) {
+	client, server := newHttp2Writer(t), newHttp2Writer(t)
+	request := []string{":method", "GET", ":scheme", "http", ":path", "/a", ":authority", "example.com", "x-custom", "v"}
+	// the first request is not captured, the second one refers its dynamic table entries
+	client.encode(request...)
+	parts := []testPart{
+		{true, http2.ClientPreface + client.settings().headers(3, true, request...).frames()},
+		{false, server.settings().headers(3, true, ":status", "204").frames()},
+	}
+	sink := assembleSegments(nil, conversation(parts...)...)
+	if len(sink.messages) != 0 {
+		t.Errorf("got %d messages, want none", len(sink.messages))
+	}
+	if strings.Join(sink.rejected, ",") != RejectHttp2HeaderBlock {
+		t.Errorf("rejected %v, want %s", sink.rejected, RejectHttp2HeaderBlock)
+	}
+}
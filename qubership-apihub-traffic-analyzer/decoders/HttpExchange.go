@@ -0,0 +1,95 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxPendingRequests unanswered requests kept per connection
+const maxPendingRequests = 1024
+
+// exchangeTracker
+// pairs requests with responses on the same connection (HTTP/1.x answers in the request order)
+type exchangeTracker struct {
+	sink    MessageSink
+	pending []*HttpMessage
+	// upgrade called for 101 response, returns true when the request is answered using another protocol
+	upgrade func(request, response *HttpMessage) bool
+}
+
+// newExchangeTracker
+// creates a tracker for a single connection
+func newExchangeTracker(sink MessageSink, upgrade func(request, response *HttpMessage) bool) *exchangeTracker {
+	return &exchangeTracker{
+		sink:    sink,
+		pending: nil,
+		upgrade: upgrade,
+	}
+}
+
+// pendingMethod
+// returns method of the oldest unanswered request or empty string
+func (et *exchangeTracker) pendingMethod() string {
+	if len(et.pending) > 0 {
+		return et.pending[0].Method
+	}
+	return ""
+}
+
+// onMessage
+// passes the message into the sink and reports the exchange when the message is a final response
+func (et *exchangeTracker) onMessage(msg *HttpMessage) {
+	if !msg.IsRequest && len(et.pending) > 0 {
+		msg.Path = et.pending[0].Path
+		if msg.StatusCode == http.StatusSwitchingProtocols && et.upgrade(et.pending[0], msg) {
+			// the request is answered using the new protocol
+			et.pending = et.pending[1:]
+			return
+		}
+	}
+	et.sink.OnHttpMessage(msg)
+	if msg.IsRequest {
+		if len(et.pending) >= maxPendingRequests {
+			et.sink.OnHttpExchange(&HttpExchange{Request: et.pending[0]})
+			et.pending = et.pending[1:]
+		}
+		et.pending = append(et.pending, msg)
+		return
+	}
+	if msg.StatusCode >= http.StatusContinue && msg.StatusCode < http.StatusOK && msg.StatusCode != http.StatusSwitchingProtocols {
+		return // interim response, the final one follows
+	}
+	if len(et.pending) == 0 {
+		log.Tracef("response without request in stream %s:%d->%s:%d",
+			msg.Flow.SrcIP, msg.Flow.SrcPort, msg.Flow.DstIP, msg.Flow.DstPort)
+		et.sink.OnRejected(msg.Flow, RejectHttpUnpaired)
+		return
+	}
+	request := et.pending[0]
+	et.pending = et.pending[1:]
+	et.sink.OnHttpExchange(&HttpExchange{Request: request, Response: msg})
+}
+
+// flush
+// reports all the unanswered requests
+func (et *exchangeTracker) flush() {
+	for _, request := range et.pending {
+		et.sink.OnHttpExchange(&HttpExchange{Request: request})
+	}
+	et.pending = nil
+}
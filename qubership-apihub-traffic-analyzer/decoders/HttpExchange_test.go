@@ -0,0 +1,100 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// exchangeString
+// describes the exchange as "request path -> response status", "-" for not answered request
+func exchangeString(exchange *HttpExchange) string {
+	status := "-"
+	if exchange.Response != nil {
+		status = fmt.Sprint(exchange.Response.StatusCode)
+	}
+	return exchange.Request.Method + " " + exchange.Request.Path + " -> " + status
+}
+
+func TestExchangePairing(t *testing.T) {
+	get := func(path string) string {
+		return "GET " + path + " HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	}
+	answer := func(status string) string {
+		return "HTTP/1.1 " + status + "\r\nContent-Length: 0\r\n\r\n"
+	}
+	tests := []struct {
+		name         string
+		parts        []testPart
+		wantExchange string
+		wantRejected string
+	}{
+		{
+			name:         "pipelined requests are answered in order",
+			parts:        []testPart{{true, get("/a") + get("/b")}, {false, answer("200 OK") + answer("404 Not Found")}},
+			wantExchange: "GET /a -> 200;GET /b -> 404",
+		},
+		{
+			name:         "not answered request is reported on close",
+			parts:        []testPart{{true, get("/a")}, {false, answer("204 No Content")}, {true, get("/b")}},
+			wantExchange: "GET /a -> 204;GET /b -> -",
+		},
+		{
+			name: "interim response is not the answer",
+			parts: []testPart{
+				{true, "POST /upload HTTP/1.1\r\nExpect: 100-continue\r\nContent-Length: 2\r\n\r\n"},
+				{false, "HTTP/1.1 100 Continue\r\n\r\n"},
+				{true, "ok"},
+				{false, answer("201 Created")},
+			},
+			wantExchange: "POST /upload -> 201",
+		},
+		{
+			name:         "response without request",
+			parts:        []testPart{{false, answer("200 OK")}},
+			wantRejected: RejectHttpUnpaired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := assembleSegments(nil, conversation(tt.parts...)...)
+			exchanges := make([]string, 0)
+			for _, exchange := range sink.exchanges {
+				exchanges = append(exchanges, exchangeString(exchange))
+			}
+			if got := strings.Join(exchanges, ";"); got != tt.wantExchange {
+				t.Errorf("exchanges %q, want %q", got, tt.wantExchange)
+			}
+			if got := strings.Join(sink.rejected, ";"); got != tt.wantRejected {
+				t.Errorf("rejected %q, want %q", got, tt.wantRejected)
+			}
+		})
+	}
+}
+
+func TestExchangeTimeToFirstByte(t *testing.T) {
+	sink := assembleSegments(nil, conversation(
+		testPart{true, "GET /a HTTP/1.1\r\n\r\n"}, testPart{false, "HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n"})...)
+	if len(sink.exchanges) != 1 {
+		t.Fatalf("got %d exchanges, want 1", len(sink.exchanges))
+	}
+	// the test segments are sent a millisecond apart
+	if got := sink.exchanges[0].TimeToFirstByte(); got != time.Millisecond {
+		t.Errorf("TimeToFirstByte() = %v, want %v", got, time.Millisecond)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"time"
+)
+
+// FlowInfo
+// addresses and ports of a single TCP flow direction
+type FlowInfo struct {
+	SrcIP   string
+	DstIP   string
+	SrcPort int
+	DstPort int
+	// Encapsulation tunnel and VLAN layers of the first connection packet, nil for not encapsulated connection
+	Encapsulation *Encapsulation
+}
+
+// Reverse
+// returns the opposite direction of the flow
+func (fi FlowInfo) Reverse() FlowInfo {
+	return FlowInfo{
+		SrcIP:         fi.DstIP,
+		DstIP:         fi.SrcIP,
+		SrcPort:       fi.DstPort,
+		DstPort:       fi.SrcPort,
+		Encapsulation: fi.Encapsulation.Reverse(),
+	}
+}
+
+// HttpMessage
+// a complete HTTP message reassembled from one or more TCP segments
+type HttpMessage struct {
+	// Flow message direction
+	Flow FlowInfo
+	// Timestamp time stamp of the first message segment
+	Timestamp time.Time
+	// EndTimestamp time stamp of the last message segment
+	EndTimestamp time.Time
+	// SeqNo TCP sequence number of the first message byte
+	SeqNo int
+	// AckNo TCP acknowledge number at the first message segment
+	AckNo int
+	// IsRequest true for requests, false for responses
+	IsRequest bool
+	// Method request method or response status line
+	Method string
+	// StatusCode response status code, zero for requests
+	StatusCode int
+	// Path request path, for responses the path of the answered request
+	Path string
+	// Query raw request query string without the leading '?', empty for responses
+	Query string
+	// Host request target host (HTTP/1.x Host header, HTTP/2 :authority), empty for responses
+	Host string
+	// Headers message headers, multiple values are joined with a new line
+	Headers map[string]string
+	// Trailers headers sent after the body (HTTP/2 trailing header block)
+	Trailers map[string]string
+	// Body decoded message body
+	Body []byte
+	// Raw message bytes as seen on the wire, for HTTP/2 the reassembled DATA payload.
+	// Raw data is used for decoding only and is not stored, the stored body is Body
+	Raw []byte
+	// Grpc gRPC call details, nil for not gRPC messages
+	Grpc *GrpcCall
+	// Graphql GraphQL operations of the request, nil for not GraphQL messages
+	Graphql []GraphqlOperation
+	// StoreRef storage reference, filled by the sink
+	StoreRef interface{}
+}
+
+// HttpExchange
+// a request paired with its response on the same connection
+type HttpExchange struct {
+	Request *HttpMessage
+	// Response nil when the request was not answered within the capture
+	Response *HttpMessage
+}
+
+// TimeToFirstByte
+// returns delay between the request completion and the response start
+func (he *HttpExchange) TimeToFirstByte() time.Duration {
+	if he.Request == nil || he.Response == nil {
+		return 0
+	}
+	return he.Response.Timestamp.Sub(he.Request.EndTimestamp)
+}
+
+// reasons of the stream data rejected by the decoders
+const (
+	RejectHttpMalformed       = "http_malformed"
+	RejectHttpIncomplete      = "http_incomplete"
+	RejectHttpUnpaired        = "http_response_without_request"
+	RejectHttp2Frame          = "http2_frame"
+	RejectHttp2HeaderBlock    = "http2_header_block"
+	RejectHttp2StreamOverflow = "http2_stream_overflow"
+	RejectHttp2TooLarge       = "http2_message_too_large"
+)
+
+// MessageSink
+// receives messages decoded from reassembled TCP streams
+type MessageSink interface {
+	// OnHttpMessage called for each decoded message
+	OnHttpMessage(msg *HttpMessage)
+	// OnHttpExchange called when the request is answered or the connection is closed, after OnHttpMessage for both messages
+	OnHttpExchange(exchange *HttpExchange)
+	// OnWebSocketMessage called for each message decoded after WebSocket handshake
+	OnWebSocketMessage(msg *WebSocketMessage)
+	// OnKafkaMessage called for records of each topic partition in Produce requests and Fetch responses
+	OnKafkaMessage(msg *KafkaMessage)
+	// OnServerName called for TLS ClientHello server name indication, the flow is the client direction
+	OnServerName(flow FlowInfo, serverName string)
+	// OnRejected called when the stream data is dropped as it can not be decoded, reason is one of Reject* constants
+	OnRejected(flow FlowInfo, reason string)
+}
@@ -0,0 +1,384 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxHttpMessageSize buffered data limit for a single HTTP message
+	MaxHttpMessageSize = 64 * 1024 * 1024
+	// maxStartLineSize the longest start line kept while looking for a message start
+	maxStartLineSize = 16 * 1024
+)
+
+var (
+	// errNeedMoreData the message is not complete yet
+	errNeedMoreData = errors.New("need more data")
+	// httpStartRe HTTP/1.x request or response start line at the beginning of any line
+	httpStartRe = regexp.MustCompile(`(?m)^(?:[A-Z]{3,16} \S+ HTTP/1\.[01]\r?\n|HTTP/1\.[01] \d{3}[^\r\n]*\r?\n)`)
+	// httpHeaderEnd an empty line after HTTP headers
+	httpHeaderEnd = []byte("\r\n\r\n")
+	// httpResponsePrefix HTTP response start line prefix
+	httpResponsePrefix = []byte("HTTP/")
+)
+
+// streamMark
+// arrival time of the buffered data at the offset
+type streamMark struct {
+	offset    int
+	timestamp time.Time
+}
+
+// httpHalfStream
+// one direction of a TCP connection, buffers ordered data until HTTP message completion
+type httpHalfStream struct {
+	flow FlowInfo
+	// buf not yet decoded data
+	buf []byte
+	// bufSeq TCP sequence number of the first buffered byte
+	bufSeq uint32
+	// marks arrival times for buffered data
+	marks []streamMark
+	// ackNo last seen acknowledge number
+	ackNo uint32
+	// synced true when buffer begins with a message start
+	synced bool
+	// need buffered length required to complete the pending message, the message is not parsed again before
+	need int
+	// scanned buffered length searched for the end of the pending message headers
+	scanned int
+	// chunkAt offset of the next chunk size line of the pending chunked body, zero until the body is scanned
+	chunkAt int
+}
+
+// newHttpHalfStream
+// creates an empty stream direction
+func newHttpHalfStream(flow FlowInfo) *httpHalfStream {
+	return &httpHalfStream{
+		flow:   flow,
+		buf:    nil,
+		marks:  nil,
+		synced: false,
+	}
+}
+
+// append
+// adds ordered data to the buffer
+func (hs *httpHalfStream) append(data []byte, timestamp time.Time) {
+	hs.marks = append(hs.marks, streamMark{offset: len(hs.buf), timestamp: timestamp})
+	hs.buf = append(hs.buf, data...)
+}
+
+// skip
+// drops buffered data when some stream bytes were lost
+func (hs *httpHalfStream) skip(lost int) {
+	log.Tracef("%d bytes lost in stream %s:%d->%s:%d, %d buffered bytes dropped",
+		lost, hs.flow.SrcIP, hs.flow.SrcPort, hs.flow.DstIP, hs.flow.DstPort, len(hs.buf))
+	hs.bufSeq += uint32(len(hs.buf) + lost)
+	hs.buf = nil
+	hs.marks = nil
+	hs.synced = false
+	hs.resetPending()
+}
+
+// resetPending
+// forgets the scan state of the pending message
+func (hs *httpHalfStream) resetPending() {
+	hs.need = 0
+	hs.scanned = 0
+	hs.chunkAt = 0
+}
+
+// consume
+// removes decoded bytes from the buffer
+func (hs *httpHalfStream) consume(count int) {
+	if count <= 0 {
+		return
+	}
+	hs.resetPending()
+	if count >= len(hs.buf) {
+		hs.bufSeq += uint32(len(hs.buf))
+		hs.buf = nil
+		hs.marks = nil
+		return
+	}
+	hs.bufSeq += uint32(count)
+	hs.buf = hs.buf[count:]
+	marks := make([]streamMark, 0, len(hs.marks))
+	for i, mark := range hs.marks {
+		if mark.offset > count {
+			if len(marks) == 0 && i > 0 {
+				marks = append(marks, streamMark{offset: 0, timestamp: hs.marks[i-1].timestamp})
+			}
+			marks = append(marks, streamMark{offset: mark.offset - count, timestamp: mark.timestamp})
+		} else if mark.offset == count {
+			marks = append(marks, streamMark{offset: 0, timestamp: mark.timestamp})
+		}
+	}
+	if len(marks) == 0 && len(hs.marks) > 0 {
+		marks = append(marks, streamMark{offset: 0, timestamp: hs.marks[len(hs.marks)-1].timestamp})
+	}
+	hs.marks = marks
+}
+
+// timestamp
+// returns arrival time of the buffered byte at the offset
+func (hs *httpHalfStream) timestamp(offset int) time.Time {
+	ts := time.Time{}
+	for _, mark := range hs.marks {
+		if mark.offset > offset {
+			break
+		}
+		ts = mark.timestamp
+	}
+	return ts
+}
+
+// parse
+// decodes all the complete messages from the buffer, final is true when no more data expected
+func (hs *httpHalfStream) parse(final bool, conn *tcpStream) {
+	exchanges := conn.exchanges
+	for len(hs.buf) > 0 {
+		if conn.h2 != nil {
+			conn.h2.parse(hs, final)
+			return
+		}
+		if conn.ws != nil {
+			conn.ws.parse(hs, final)
+			return
+		}
+		if conn.kafka != nil {
+			conn.kafka.parse(hs, final)
+			return
+		}
+		if bytes.HasPrefix(hs.buf, http2Preface) {
+			// prior knowledge HTTP/2
+			conn.startHttp2(hs)
+			continue
+		}
+		if !final && len(hs.buf) < len(http2Preface) && bytes.HasPrefix(http2Preface, hs.buf) {
+			return
+		}
+		if !conn.kafkaChecked {
+			if !final && len(hs.buf) < kafkaRequestHeaderSize {
+				return
+			}
+			// only the first data of the connection may start Kafka
+			conn.kafkaChecked = true
+			if isKafkaRequest(hs.buf) {
+				conn.startKafka(hs)
+				continue
+			}
+		}
+		if !hs.synced {
+			loc := httpStartRe.FindIndex(hs.buf)
+			if loc == nil {
+				// the start line may be split between segments - keep the last line
+				lineStart := bytes.LastIndexByte(hs.buf, '\n') + 1
+				if final || len(hs.buf)-lineStart > maxStartLineSize {
+					lineStart = len(hs.buf)
+				}
+				hs.consume(lineStart)
+				return
+			}
+			hs.consume(loc[0])
+			hs.synced = true
+		}
+		if !final && len(hs.buf) < hs.need && len(hs.buf) <= MaxHttpMessageSize {
+			return // the pending message is not complete yet
+		}
+		msg, consumed, err := hs.parseMessage(final, exchanges.pendingMethod())
+		if err != nil {
+			if errors.Is(err, errNeedMoreData) {
+				if final || len(hs.buf) > MaxHttpMessageSize {
+					log.Tracef("incomplete HTTP message in stream %s:%d->%s:%d, %d bytes dropped",
+						hs.flow.SrcIP, hs.flow.SrcPort, hs.flow.DstIP, hs.flow.DstPort, len(hs.buf))
+					exchanges.sink.OnRejected(hs.flow, RejectHttpIncomplete)
+					hs.consume(len(hs.buf))
+					hs.synced = false
+				}
+				return
+			}
+			log.Tracef("unable to decode HTTP message in stream %s:%d->%s:%d: %v",
+				hs.flow.SrcIP, hs.flow.SrcPort, hs.flow.DstIP, hs.flow.DstPort, err)
+			exchanges.sink.OnRejected(hs.flow, RejectHttpMalformed)
+			// look for the next message start
+			hs.consume(1)
+			hs.synced = false
+			continue
+		}
+		hs.consume(consumed)
+		exchanges.onMessage(msg)
+	}
+}
+
+// parseMessage
+// decodes a single HTTP message from the beginning of the buffer,
+// requestMethod is a method of the request being answered (responses to HEAD have no body)
+func (hs *httpHalfStream) parseMessage(final bool, requestMethod string) (*HttpMessage, int, error) {
+	if bytes.Index(hs.buf[hs.scanned:], httpHeaderEnd) < 0 {
+		// the header end may be split between segments
+		hs.scanned = max(0, len(hs.buf)-len(httpHeaderEnd)+1)
+		hs.need = len(hs.buf) + 1
+		return nil, 0, errNeedMoreData
+	}
+	br := bytes.NewReader(hs.buf)
+	bf := bufio.NewReader(br)
+	msg := &HttpMessage{
+		Flow:      hs.flow,
+		Timestamp: hs.timestamp(0),
+		SeqNo:     int(hs.bufSeq),
+		AckNo:     int(hs.ackNo),
+		Headers:   make(map[string]string),
+	}
+	var (
+		header           http.Header
+		body             io.ReadCloser
+		transferEncoding []string
+	)
+	if bytes.HasPrefix(hs.buf, httpResponsePrefix) {
+		var request *http.Request = nil
+		if requestMethod != "" {
+			request = &http.Request{Method: requestMethod}
+		}
+		resp, err := http.ReadResponse(bf, request)
+		if err != nil {
+			return nil, 0, needMoreData(err)
+		}
+		if resp.ContentLength < 0 && len(resp.TransferEncoding) == 0 && resp.Body != http.NoBody && !final {
+			// body is delimited by the connection close
+			hs.need = math.MaxInt
+			return nil, 0, errNeedMoreData
+		}
+		if !final && !hs.bodyReceived(len(hs.buf)-br.Len()-bf.Buffered(), resp.ContentLength, resp.TransferEncoding) {
+			return nil, 0, errNeedMoreData
+		}
+		msg.Method = resp.Status
+		msg.StatusCode = resp.StatusCode
+		header = resp.Header
+		body = resp.Body
+		transferEncoding = resp.TransferEncoding
+	} else {
+		req, err := http.ReadRequest(bf)
+		if err != nil {
+			return nil, 0, needMoreData(err)
+		}
+		if !final && !hs.bodyReceived(len(hs.buf)-br.Len()-bf.Buffered(), req.ContentLength, req.TransferEncoding) {
+			return nil, 0, errNeedMoreData
+		}
+		msg.IsRequest = true
+		msg.Method = req.Method
+		msg.Path = req.URL.Path
+		msg.Query = req.URL.RawQuery
+		msg.Host = req.Host
+		header = req.Header
+		body = req.Body
+		transferEncoding = req.TransferEncoding
+	}
+	// chunked transfer coding is removed by the reader
+	rawBody, err := io.ReadAll(body)
+	_ = body.Close()
+	consumed := len(hs.buf) - br.Len() - bf.Buffered()
+	if err != nil {
+		err = needMoreData(err)
+		if !final || !errors.Is(err, errNeedMoreData) || !hasCoding(strings.Join(transferEncoding, ","), "chunked") {
+			return nil, 0, err
+		}
+		// the stream ends inside a chunked body, keeping the chunks received
+		consumed = len(hs.buf)
+	}
+	msg.EndTimestamp = hs.timestamp(consumed - 1)
+	for headerName, headerValue := range header {
+		headerString := strings.Join(headerValue, "\n")
+		if headerString != "" {
+			msg.Headers[headerName] = headerString
+		}
+	}
+	bodyResult := BodyToString(bytes.NewReader(rawBody), BodyEncoding{
+		ContentEncoding: header.Get("Content-Encoding"),
+		ContentType:     header.Get("Content-Type"),
+	})
+	if bodyResult.Err != nil {
+		log.Tracef("unable to decode HTTP message body: %v", bodyResult.Err)
+	}
+	msg.Body = bodyResult.Body
+	decodeGraphqlCall(msg)
+	msg.Raw = make([]byte, consumed)
+	copy(msg.Raw, hs.buf[:consumed])
+	return msg, consumed, nil
+}
+
+// bodyReceived
+// checks the message body following the headers is buffered, otherwise sets the buffered length needed
+// to parse the message again. Chunked body is scanned once, from the last complete chunk
+func (hs *httpHalfStream) bodyReceived(bodyStart int, contentLength int64, transferEncoding []string) bool {
+	if !hasCoding(strings.Join(transferEncoding, ","), "chunked") {
+		if contentLength > 0 && int64(len(hs.buf)-bodyStart) < contentLength {
+			hs.need = bodyStart + int(min(contentLength, MaxHttpMessageSize+1))
+			return false
+		}
+		return true
+	}
+	if hs.chunkAt < bodyStart {
+		hs.chunkAt = bodyStart
+	}
+	for {
+		lineEnd := bytes.IndexByte(hs.buf[hs.chunkAt:], '\n')
+		if lineEnd < 0 {
+			hs.need = len(hs.buf) + 1
+			return false
+		}
+		line := string(hs.buf[hs.chunkAt : hs.chunkAt+lineEnd])
+		if i := strings.IndexByte(line, ';'); i >= 0 {
+			line = line[:i] // chunk extensions
+		}
+		size, err := strconv.ParseInt(strings.TrimSpace(line), 16, 64)
+		if err != nil || size == 0 {
+			// the last chunk or a malformed one, the message reader reports it
+			return true
+		}
+		next := int64(hs.chunkAt+lineEnd+1) + size + 2
+		if next > MaxHttpMessageSize {
+			return true
+		}
+		hs.chunkAt = int(next)
+		if hs.chunkAt >= len(hs.buf) {
+			hs.need = hs.chunkAt + 1
+			return false
+		}
+	}
+}
+
+// needMoreData
+// converts an unexpected end of data into errNeedMoreData
+func needMoreData(err error) error {
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return errNeedMoreData
+	}
+	return err
+}
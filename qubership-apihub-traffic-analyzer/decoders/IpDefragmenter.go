@@ -0,0 +1,193 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/google/gopacket/layers"
+)
+
+const (
+	// FragmentTimeout incomplete datagrams older than this period (capture time) are dropped
+	FragmentTimeout = 30 * time.Second
+	// maxDatagramSize maximal reassembled datagram size
+	maxDatagramSize = 65535
+	// maxPendingDatagrams maximal number of incomplete datagrams kept at once
+	maxPendingDatagrams = 4096
+)
+
+// fragmentKey
+// identifies fragments of the same datagram
+type fragmentKey struct {
+	version  IPVersion
+	src      string
+	dst      string
+	id       uint32
+	protocol layers.IPProtocol
+}
+
+// fragmentData
+// a single received fragment
+type fragmentData struct {
+	offset int
+	data   []byte
+}
+
+// fragmentSet
+// received fragments of an incomplete datagram
+type fragmentSet struct {
+	// first the packet of the zero offset fragment, its header is used for the reassembled datagram
+	first     *IPPacket
+	fragments []fragmentData
+	// size datagram size, known when the last fragment is received
+	size int
+	// started capture time of the first received fragment
+	started time.Time
+}
+
+// IpDefragmenter
+// reassembles IPv4 and IPv6 datagrams from fragments
+type IpDefragmenter struct {
+	pending map[fragmentKey]*fragmentSet
+	// cleaned capture time of the last expiration check
+	cleaned time.Time
+	// dropped number of fragments dropped because of timeout or limits
+	dropped int
+}
+
+// NewIpDefragmenter
+// creates an empty defragmenter
+func NewIpDefragmenter() *IpDefragmenter {
+	return &IpDefragmenter{
+		pending: make(map[fragmentKey]*fragmentSet),
+	}
+}
+
+// Add
+// adds the fragment of the packet, returns the reassembled packet when all the datagram fragments are received,
+// otherwise returns nil
+func (d *IpDefragmenter) Add(ip *IPPacket, timestamp time.Time) (*IPPacket, error) {
+	if ip.Fragment == nil {
+		return ip, nil
+	}
+	d.expire(timestamp)
+	end := ip.Fragment.Offset + len(ip.Payload)
+	if end > maxDatagramSize {
+		d.dropped++
+		return nil, fmt.Errorf("fragment at offset %d exceeds maximal datagram size", ip.Fragment.Offset)
+	}
+	key := fragmentKey{version: ip.Version, src: ip.SrcIP, dst: ip.DstIP, id: ip.Fragment.Id, protocol: ip.Protocol}
+	set, exists := d.pending[key]
+	if !exists {
+		if len(d.pending) >= maxPendingDatagrams {
+			d.dropped++
+			return nil, fmt.Errorf("too many incomplete datagrams")
+		}
+		set = &fragmentSet{started: timestamp}
+		d.pending[key] = set
+	}
+	// packet data belongs to the capture reader buffer
+	data := make([]byte, len(ip.Payload))
+	copy(data, ip.Payload)
+	set.fragments = append(set.fragments, fragmentData{offset: ip.Fragment.Offset, data: data})
+	if ip.Fragment.Offset == 0 {
+		first := *ip
+		first.SrcAddr = append(net.IP(nil), ip.SrcAddr...)
+		first.DstAddr = append(net.IP(nil), ip.DstAddr...)
+		set.first = &first
+	}
+	if !ip.Fragment.More {
+		set.size = end
+	}
+	payload := set.reassemble()
+	if payload == nil {
+		return nil, nil
+	}
+	delete(d.pending, key)
+	datagram := *set.first
+	datagram.Fragment = nil
+	datagram.Payload = payload
+	if datagram.Version == IPv6 {
+		var err error
+		// extension headers after the fragment header are a part of the fragmented data
+		datagram.Protocol, datagram.Payload, _, err = walkIpv6Extensions(datagram.Protocol, payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+	datagram.expectTcp()
+	return &datagram, nil
+}
+
+// Dropped
+// returns number of fragments dropped because of timeout or limits
+func (d *IpDefragmenter) Dropped() int {
+	return d.dropped
+}
+
+// Pending
+// returns number of incomplete datagrams
+func (d *IpDefragmenter) Pending() int {
+	return len(d.pending)
+}
+
+// expire
+// drops incomplete datagrams older than the fragment timeout
+func (d *IpDefragmenter) expire(timestamp time.Time) {
+	if timestamp.Sub(d.cleaned) < FragmentTimeout/2 {
+		return
+	}
+	d.cleaned = timestamp
+	for key, set := range d.pending {
+		if timestamp.Sub(set.started) > FragmentTimeout {
+			d.dropped += len(set.fragments)
+			delete(d.pending, key)
+		}
+	}
+}
+
+// reassemble
+// returns the datagram payload when the fragments cover it completely, otherwise returns nil
+func (fs *fragmentSet) reassemble() []byte {
+	if fs.size == 0 || fs.first == nil {
+		return nil
+	}
+	sort.SliceStable(fs.fragments, func(i, j int) bool {
+		return fs.fragments[i].offset < fs.fragments[j].offset
+	})
+	covered := 0
+	for _, fragment := range fs.fragments {
+		if fragment.offset > covered {
+			return nil // gap
+		}
+		if end := fragment.offset + len(fragment.data); end > covered {
+			covered = end
+		}
+	}
+	if covered < fs.size {
+		return nil
+	}
+	payload := make([]byte, fs.size)
+	for _, fragment := range fs.fragments {
+		if fragment.offset < fs.size {
+			copy(payload[fragment.offset:], fragment.data)
+		}
+	}
+	return payload
+}
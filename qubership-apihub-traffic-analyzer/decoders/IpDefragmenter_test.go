@@ -0,0 +1,204 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket/layers"
+)
+
+// ipv6Extension
+// makes IPv6 extension header of 8 bytes followed by the next header
+func ipv6Extension(next layers.IPProtocol) []byte {
+	return []byte{byte(next), 0, 1, 4, 0, 0, 0, 0}
+}
+
+// ipv6FragmentHeader
+// makes IPv6 fragment extension header
+func ipv6FragmentHeader(next layers.IPProtocol, fragment IpFragment) []byte {
+	header := []byte{byte(next), 0}
+	offsetMore := uint16(fragment.Offset)
+	if fragment.More {
+		offsetMore |= 1
+	}
+	header = binary.BigEndian.AppendUint16(header, offsetMore)
+	return binary.BigEndian.AppendUint32(header, fragment.Id)
+}
+
+// testFragment
+// a fragment of the test datagram sent after the delay from the first packet
+type testFragment struct {
+	data  []byte
+	delay time.Duration
+}
+
+func TestNetworkDecoderFragments(t *testing.T) {
+	datagram := []byte("0123456789abcdef" + testPayload)
+	v4 := func(id uint32, offset, end int, more bool) testFragment {
+		return testFragment{data: ipv4Packet("10.0.0.1", "10.0.0.2", layers.IPProtocolTCP, datagram[offset:end],
+			&IpFragment{Id: id, Offset: offset, More: more})}
+	}
+	// extension headers before the fragment header are repeated in each fragment
+	v6 := func(offset, end int, more bool) testFragment {
+		return testFragment{data: ipv6Packet("fd00::1", "fd00::2", layers.IPProtocolIPv6HopByHop, concat(
+			ipv6Extension(layers.IPProtocolIPv6Destination), ipv6Extension(layers.IPProtocolIPv6Fragment),
+			ipv6FragmentHeader(layers.IPProtocolTCP, IpFragment{Id: 77, Offset: offset, More: more}), datagram[offset:end]))}
+	}
+	// the routing header after the fragment header is a part of the fragmented data
+	routed := concat(ipv6Extension(layers.IPProtocolTCP), datagram)
+	v6Routed := func(offset, end int, more bool) testFragment {
+		return testFragment{data: ipv6Packet("fd00::1", "fd00::2", layers.IPProtocolIPv6Fragment, concat(
+			ipv6FragmentHeader(layers.IPProtocolIPv6Routing, IpFragment{Id: 78, Offset: offset, More: more}), routed[offset:end]))}
+	}
+	vxlanPacket := ipv4Packet("192.168.0.1", "192.168.0.2", layers.IPProtocolUDP, udpDatagram(VxlanPort, concat(
+		[]byte{vxlanFlagVni, 0, 0, 0, 0, 0, 42, 0},
+		ethernetFrame(layers.EthernetTypeIPv4, ipv4Packet("10.0.0.1", "10.0.0.2", layers.IPProtocolTCP, datagram, nil)))), nil)
+	vxlanPayload := vxlanPacket[20:]
+	vxlanFragment := func(offset, end int, more bool) testFragment {
+		return testFragment{data: ipv4Packet("192.168.0.1", "192.168.0.2", layers.IPProtocolUDP, vxlanPayload[offset:end],
+			&IpFragment{Id: 9, Offset: offset, More: more})}
+	}
+	late := func(fragment testFragment) testFragment {
+		fragment.delay = FragmentTimeout + time.Second
+		return fragment
+	}
+	tests := []struct {
+		name       string
+		ipv6       bool
+		fragments  []testFragment
+		wantLayers string
+		// notFragmented the packets are complete datagrams
+		notFragmented bool
+		wantPending   int
+		wantDropped   int
+		wantErr       bool
+	}{
+		{
+			name:      "IPv4 fragments in order",
+			fragments: []testFragment{v4(1, 0, 16, true), v4(1, 16, len(datagram), false)},
+		},
+		{
+			name:      "IPv4 fragments out of order",
+			fragments: []testFragment{v4(1, 16, len(datagram), false), v4(1, 8, 16, true), v4(1, 0, 8, true)},
+		},
+		{
+			name:      "duplicated and overlapping fragments",
+			fragments: []testFragment{v4(1, 0, 16, true), v4(1, 0, 16, true), v4(1, 8, 24, true), v4(1, 16, len(datagram), false)},
+		},
+		{
+			name:        "missing fragment",
+			fragments:   []testFragment{v4(1, 0, 8, true), v4(1, 16, len(datagram), false)},
+			wantPending: 1,
+		},
+		{
+			name:        "fragments of different datagrams",
+			fragments:   []testFragment{v4(1, 0, 16, true), v4(2, 16, len(datagram), false)},
+			wantPending: 2,
+		},
+		{
+			name:        "expired fragment",
+			fragments:   []testFragment{v4(1, 0, 16, true), late(v4(2, 16, len(datagram), false))},
+			wantPending: 1,
+			wantDropped: 1,
+		},
+		{
+			name:      "IPv6 fragments after extension headers",
+			ipv6:      true,
+			fragments: []testFragment{v6(16, len(datagram), false), v6(0, 16, true)},
+		},
+		{
+			name:      "IPv6 extension header in fragmented data",
+			ipv6:      true,
+			fragments: []testFragment{v6Routed(0, 16, true), v6Routed(16, len(routed), false)},
+		},
+		{
+			name: "IPv6 atomic fragment",
+			ipv6: true,
+			fragments: []testFragment{{data: ipv6Packet("fd00::1", "fd00::2", layers.IPProtocolIPv6Fragment,
+				concat(ipv6FragmentHeader(layers.IPProtocolTCP, IpFragment{Id: 79}), datagram))}},
+			notFragmented: true,
+		},
+		{
+			name: "IPv6 authentication header",
+			ipv6: true,
+			fragments: []testFragment{{data: ipv6Packet("fd00::1", "fd00::2", layers.IPProtocolAH,
+				concat([]byte{byte(layers.IPProtocolTCP), 1, 0, 0}, make([]byte, 8), datagram))}},
+			notFragmented: true,
+		},
+		{
+			name:       "fragmented tunnel packet",
+			fragments:  []testFragment{vxlanFragment(0, 48, true), vxlanFragment(48, len(vxlanPayload), false)},
+			wantLayers: "vxlan:42",
+		},
+		{
+			name: "fragment beyond maximal datagram size",
+			fragments: []testFragment{v4(1, 0, 16, true), {data: ipv4Packet("10.0.0.1", "10.0.0.2", layers.IPProtocolTCP,
+				datagram, &IpFragment{Id: 1, Offset: maxDatagramSize - 8})}},
+			wantPending: 1,
+			wantDropped: 1,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nd := NewNetworkDecoder()
+			etherType := layers.EthernetTypeIPv4
+			if tt.ipv6 {
+				etherType = layers.EthernetTypeIPv6
+			}
+			var ip *IPPacket
+			for i, fragment := range tt.fragments {
+				if ip != nil {
+					t.Fatalf("datagram reassembled before fragment %d", i)
+				}
+				var err error
+				ip, err = nd.Decode(etherType, fragment.data, i, testTime.Add(fragment.delay))
+				if (err != nil) != (tt.wantErr && i == len(tt.fragments)-1) {
+					t.Fatalf("Decode() of fragment %d error = %v, want error %v", i, err, tt.wantErr)
+				}
+			}
+			counters := nd.Counters()
+			if counters.FragmentsPending != tt.wantPending || counters.FragmentsDropped != tt.wantDropped {
+				t.Errorf("pending %d, dropped %d, want %d and %d",
+					counters.FragmentsPending, counters.FragmentsDropped, tt.wantPending, tt.wantDropped)
+			}
+			if tt.wantPending != 0 || tt.wantErr {
+				if ip != nil {
+					t.Errorf("incomplete datagram is returned")
+				}
+				return
+			}
+			if ip == nil {
+				t.Fatal("datagram is not reassembled")
+			}
+			if !ip.TCPExpected || string(ip.TCP) != string(datagram) {
+				t.Errorf("TCP data %q, want %q", ip.TCP, datagram)
+			}
+			if ip.Encapsulation.String() != tt.wantLayers {
+				t.Errorf("layers %q, want %q", ip.Encapsulation.String(), tt.wantLayers)
+			}
+			wantFragments, wantReassembled := len(tt.fragments), 1
+			if tt.notFragmented {
+				wantFragments, wantReassembled = 0, 0
+			}
+			if counters.Protocols["TCP"] != 1 || counters.Fragments != wantFragments || counters.Reassembled != wantReassembled {
+				t.Errorf("counters %s, want %d fragments reassembled %d times", counters, wantFragments, wantReassembled)
+			}
+		})
+	}
+}
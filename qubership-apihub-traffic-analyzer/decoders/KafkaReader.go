@@ -0,0 +1,189 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// errKafkaTruncated the Kafka structure is longer than the data
+var errKafkaTruncated = errors.New("truncated Kafka data")
+
+// kafkaReader
+// reads Kafka protocol primitives, the first error stops reading and is kept in err
+type kafkaReader struct {
+	buf []byte
+	pos int
+	// flexible true for flexible versions using compact strings, arrays and tagged fields
+	flexible bool
+	err      error
+}
+
+// newKafkaReader
+// creates a reader of the data
+func newKafkaReader(buf []byte, flexible bool) *kafkaReader {
+	return &kafkaReader{buf: buf, pos: 0, flexible: flexible}
+}
+
+// remaining
+// returns number of not read bytes
+func (kr *kafkaReader) remaining() int {
+	return len(kr.buf) - kr.pos
+}
+
+// next
+// returns the next n bytes, nil after an error
+func (kr *kafkaReader) next(n int) []byte {
+	if kr.err != nil {
+		return nil
+	}
+	if n < 0 || n > kr.remaining() {
+		kr.err = errKafkaTruncated
+		return nil
+	}
+	data := kr.buf[kr.pos : kr.pos+n]
+	kr.pos += n
+	return data
+}
+
+func (kr *kafkaReader) int8() int8 {
+	data := kr.next(1)
+	if data == nil {
+		return 0
+	}
+	return int8(data[0])
+}
+
+func (kr *kafkaReader) int16() int16 {
+	data := kr.next(2)
+	if data == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(data))
+}
+
+func (kr *kafkaReader) int32() int32 {
+	data := kr.next(4)
+	if data == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(data))
+}
+
+func (kr *kafkaReader) int64() int64 {
+	data := kr.next(8)
+	if data == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(data))
+}
+
+// uvarint
+// reads unsigned variable length integer
+func (kr *kafkaReader) uvarint() uint64 {
+	if kr.err != nil {
+		return 0
+	}
+	value, n := binary.Uvarint(kr.buf[kr.pos:])
+	if n <= 0 {
+		kr.err = fmt.Errorf("invalid varint: %w", errKafkaTruncated)
+		return 0
+	}
+	kr.pos += n
+	return value
+}
+
+// varint
+// reads zigzag encoded variable length integer (varint and varlong of record batches)
+func (kr *kafkaReader) varint() int64 {
+	if kr.err != nil {
+		return 0
+	}
+	value, n := binary.Varint(kr.buf[kr.pos:])
+	if n <= 0 {
+		kr.err = fmt.Errorf("invalid varint: %w", errKafkaTruncated)
+		return 0
+	}
+	kr.pos += n
+	return value
+}
+
+// length
+// reads length of string, bytes or array, -1 for null
+func (kr *kafkaReader) length(classic func() int) int {
+	if kr.flexible {
+		return int(kr.uvarint()) - 1
+	}
+	return classic()
+}
+
+// string
+// reads a string or a nullable string, null is read as an empty string
+func (kr *kafkaReader) string() string {
+	n := kr.length(func() int { return int(kr.int16()) })
+	if n <= 0 {
+		return ""
+	}
+	return string(kr.next(n))
+}
+
+// bytes
+// reads bytes or nullable bytes, null is read as nil
+func (kr *kafkaReader) bytes() []byte {
+	n := kr.length(func() int { return int(kr.int32()) })
+	if n < 0 {
+		return nil
+	}
+	return kr.next(n)
+}
+
+// arrayLength
+// reads array length, null array has no elements
+func (kr *kafkaReader) arrayLength() int {
+	n := kr.length(func() int { return int(kr.int32()) })
+	if n < 0 || n > kr.remaining() {
+		// each element takes at least one byte
+		if n > kr.remaining() {
+			kr.err = errKafkaTruncated
+		}
+		return 0
+	}
+	return n
+}
+
+// uuid
+// reads UUID (topic id)
+func (kr *kafkaReader) uuid() uuid.UUID {
+	var id uuid.UUID
+	copy(id[:], kr.next(len(id)))
+	return id
+}
+
+// taggedFields
+// skips tagged fields of the flexible versions
+func (kr *kafkaReader) taggedFields() {
+	if !kr.flexible {
+		return
+	}
+	count := kr.uvarint()
+	for i := uint64(0); i < count && kr.err == nil; i++ {
+		kr.uvarint() // tag
+		kr.next(int(kr.uvarint()))
+	}
+}
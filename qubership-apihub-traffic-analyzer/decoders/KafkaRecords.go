@@ -0,0 +1,274 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+const (
+	// kafkaBatchOverhead offset and length fields before each record batch or legacy message
+	kafkaBatchOverhead = 12
+	// kafkaMagicOffset position of the magic byte after the batch length field
+	kafkaMagicOffset = 4
+	// kafkaRecordBatchMagic current record batch format
+	kafkaRecordBatchMagic = 2
+	// kafkaCompressionMask compression codec bits of the batch attributes
+	kafkaCompressionMask = 0x07
+	// kafkaControlBatchBit the batch holds transaction markers instead of records
+	kafkaControlBatchBit = 0x20
+	// kafkaMaxNesting legacy compressed messages wrap one more message set
+	kafkaMaxNesting = 2
+)
+
+// Kafka compression codecs
+const (
+	kafkaCompressionNone   = 0
+	kafkaCompressionGzip   = 1
+	kafkaCompressionSnappy = 2
+	kafkaCompressionLz4    = 3
+	kafkaCompressionZstd   = 4
+)
+
+// snappyJavaMagic xerial snappy-java stream header used by Kafka clients
+var snappyJavaMagic = []byte{0x82, 'S', 'N', 'A', 'P', 'P', 'Y', 0}
+
+// KafkaRecord
+// a single record of a topic partition
+type KafkaRecord struct {
+	// Offset record offset, for Produce requests relative to the batch
+	Offset int64
+	// Timestamp record time stamp set by the producer or the broker
+	Timestamp time.Time
+	// Key record key, nil for null key
+	Key []byte
+	// Value record value, nil for tombstone
+	Value []byte
+	// Headers record headers, multiple values are joined with a new line
+	Headers map[string]string
+}
+
+// decodeKafkaRecords
+// decodes record batches (magic 2) and legacy message sets (magic 0 and 1),
+// the last batch may be truncated by the fetch size limit and is skipped
+func decodeKafkaRecords(data []byte) ([]KafkaRecord, error) {
+	return decodeKafkaRecordSet(data, 0)
+}
+
+// decodeKafkaRecordSet
+// decodes batches from the data, nesting counts legacy compressed wrappers
+func decodeKafkaRecordSet(data []byte, nesting int) ([]KafkaRecord, error) {
+	records := make([]KafkaRecord, 0)
+	for len(data) > kafkaBatchOverhead+kafkaMagicOffset {
+		baseOffset := int64(binary.BigEndian.Uint64(data))
+		batchLength := int(int32(binary.BigEndian.Uint32(data[8:])))
+		if batchLength < kafkaMagicOffset+1 || batchLength > len(data)-kafkaBatchOverhead {
+			break // partial batch at the end of the fetched data
+		}
+		batch := data[kafkaBatchOverhead : kafkaBatchOverhead+batchLength]
+		data = data[kafkaBatchOverhead+batchLength:]
+		var (
+			batchRecords []KafkaRecord
+			err          error
+		)
+		if batch[kafkaMagicOffset] == kafkaRecordBatchMagic {
+			batchRecords, err = decodeKafkaRecordBatch(baseOffset, batch)
+		} else {
+			batchRecords, err = decodeKafkaLegacyMessage(baseOffset, batch, nesting)
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, batchRecords...)
+	}
+	return records, nil
+}
+
+// decodeKafkaRecordBatch
+// decodes record batch v2 following the batch length field
+func decodeKafkaRecordBatch(baseOffset int64, batch []byte) ([]KafkaRecord, error) {
+	kr := newKafkaReader(batch, false)
+	kr.int32() // partition leader epoch
+	kr.int8()  // magic
+	kr.int32() // crc
+	attributes := kr.int16()
+	kr.int32() // last offset delta
+	baseTimestamp := kr.int64()
+	kr.int64() // max timestamp
+	kr.int64() // producer id
+	kr.int16() // producer epoch
+	kr.int32() // base sequence
+	count := int(kr.int32())
+	if kr.err != nil {
+		return nil, fmt.Errorf("unable to read record batch header: %w", kr.err)
+	}
+	if attributes&kafkaControlBatchBit != 0 {
+		return nil, nil
+	}
+	payload, err := decompressKafka(int(attributes&kafkaCompressionMask), batch[kr.pos:])
+	if err != nil {
+		return nil, err
+	}
+	kr = newKafkaReader(payload, false)
+	records := make([]KafkaRecord, 0, min(count, kr.remaining()))
+	for i := 0; i < count && kr.remaining() > 0; i++ {
+		length := int(kr.varint())
+		rr := newKafkaReader(kr.next(length), false)
+		if kr.err != nil {
+			return records, fmt.Errorf("unable to read record %d: %w", i, kr.err)
+		}
+		rr.int8() // attributes
+		timestampDelta := rr.varint()
+		offsetDelta := rr.varint()
+		record := KafkaRecord{
+			Offset:    baseOffset + offsetDelta,
+			Timestamp: time.UnixMilli(baseTimestamp + timestampDelta),
+			Key:       rr.varBytes(),
+			Value:     rr.varBytes(),
+			Headers:   make(map[string]string),
+		}
+		headerCount := int(rr.varint())
+		for h := 0; h < headerCount && rr.err == nil; h++ {
+			name := string(rr.varBytes())
+			value := string(rr.varBytes())
+			if previous, exists := record.Headers[name]; exists {
+				value = previous + "\n" + value
+			}
+			record.Headers[name] = value
+		}
+		if rr.err != nil {
+			return records, fmt.Errorf("unable to read record %d: %w", i, rr.err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// decodeKafkaLegacyMessage
+// decodes message format v0 or v1, compressed message value is a nested message set
+func decodeKafkaLegacyMessage(offset int64, message []byte, nesting int) ([]KafkaRecord, error) {
+	kr := newKafkaReader(message, false)
+	kr.int32() // crc
+	magic := kr.int8()
+	attributes := kr.int8()
+	record := KafkaRecord{Offset: offset}
+	if magic == 1 {
+		record.Timestamp = time.UnixMilli(kr.int64())
+	}
+	record.Key = kr.bytes()
+	record.Value = kr.bytes()
+	if kr.err != nil {
+		return nil, fmt.Errorf("unable to read legacy message: %w", kr.err)
+	}
+	codec := int(attributes & kafkaCompressionMask)
+	if codec == kafkaCompressionNone {
+		return []KafkaRecord{record}, nil
+	}
+	if nesting >= kafkaMaxNesting {
+		return nil, fmt.Errorf("too deep compressed message nesting")
+	}
+	inner, err := decompressKafka(codec, record.Value)
+	if err != nil {
+		return nil, err
+	}
+	return decodeKafkaRecordSet(inner, nesting+1)
+}
+
+// varBytes
+// reads record key, value or header part prefixed by varint length, -1 for null
+func (kr *kafkaReader) varBytes() []byte {
+	n := int(kr.varint())
+	if n < 0 {
+		return nil
+	}
+	return kr.next(n)
+}
+
+// decompressKafka
+// decompresses records by the batch codec
+func decompressKafka(codec int, data []byte) ([]byte, error) {
+	var rdr io.Reader
+	switch codec {
+	case kafkaCompressionNone:
+		return data, nil
+	case kafkaCompressionGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("unable to create gzip reader: %w", err)
+		}
+		defer zr.Close()
+		rdr = zr
+	case kafkaCompressionSnappy:
+		return decodeKafkaSnappy(data)
+	case kafkaCompressionLz4:
+		rdr = lz4.NewReader(bytes.NewReader(data))
+	case kafkaCompressionZstd:
+		zr, err := zstd.NewReader(bytes.NewReader(data), zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxMemory(MaxHttpMessageSize))
+		if err != nil {
+			return nil, fmt.Errorf("unable to create zstd reader: %w", err)
+		}
+		defer zr.Close()
+		rdr = zr
+	default:
+		return nil, fmt.Errorf("unsupported Kafka compression codec %d", codec)
+	}
+	decoded, err := io.ReadAll(io.LimitReader(rdr, MaxHttpMessageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress records: %w", err)
+	}
+	if len(decoded) > MaxHttpMessageSize {
+		return nil, errBodyTooLarge
+	}
+	return decoded, nil
+}
+
+// decodeKafkaSnappy
+// decodes snappy-java framed data (header, version, compatible version, then length prefixed blocks) or a raw snappy block
+func decodeKafkaSnappy(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, snappyJavaMagic) {
+		return snappy.Decode(nil, data)
+	}
+	kr := newKafkaReader(data[len(snappyJavaMagic):], false)
+	kr.int32() // version
+	kr.int32() // compatible version
+	decoded := make([]byte, 0)
+	for kr.remaining() > 0 && kr.err == nil {
+		block, err := snappy.Decode(nil, kr.next(int(kr.int32())))
+		if kr.err != nil {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode snappy block: %w", err)
+		}
+		decoded = append(decoded, block...)
+		if len(decoded) > MaxHttpMessageSize {
+			return nil, errBodyTooLarge
+		}
+	}
+	if kr.err != nil {
+		return nil, fmt.Errorf("unable to read snappy block: %w", kr.err)
+	}
+	return decoded, nil
+}
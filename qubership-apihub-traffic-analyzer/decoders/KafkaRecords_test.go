@@ -0,0 +1,200 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// kafkaTestTimestamp base time stamp of the test batches in milliseconds
+const kafkaTestTimestamp = 1700000000000
+
+// kafkaRecordData
+// encodes a record of the batch v2, the offset delta is the time stamp delta as well, headers are name, value pairs
+func kafkaRecordData(offsetDelta int64, key, value string, headers ...string) []byte {
+	record := &kafkaWriter{}
+	record.int8(0).varint(offsetDelta).varint(offsetDelta)
+	for _, part := range []string{key, value} {
+		if part == "" {
+			record.varint(-1)
+			continue
+		}
+		record.varint(int64(len(part)))
+		record.buf = append(record.buf, part...)
+	}
+	record.varint(int64(len(headers) / 2))
+	for _, part := range headers {
+		record.varint(int64(len(part)))
+		record.buf = append(record.buf, part...)
+	}
+	return append((&kafkaWriter{}).varint(int64(len(record.buf))).buf, record.buf...)
+}
+
+// kafkaRecordBatch
+// encodes record batch v2 of the records compressed by the codec
+func kafkaRecordBatch(baseOffset int64, attributes int16, count int32, records ...[]byte) []byte {
+	payload := bytes.Join(records, nil)
+	if count == 0 {
+		count = int32(len(records))
+	}
+	batch := &kafkaWriter{}
+	batch.int32(0).int8(kafkaRecordBatchMagic).int32(0).int16(attributes).int32(count - 1).
+		int64(kafkaTestTimestamp).int64(kafkaTestTimestamp).int64(-1).int16(-1).int32(-1).int32(count)
+	batch.buf = append(batch.buf, payload...)
+	return append((&kafkaWriter{}).int64(baseOffset).int32(int32(len(batch.buf))).buf, batch.buf...)
+}
+
+// kafkaLegacyMessage
+// encodes message v1 at the offset, the time stamp is offset milliseconds of the test batch time stamp
+func kafkaLegacyMessage(offset int64, attributes int8, key, value []byte) []byte {
+	message := &kafkaWriter{}
+	message.int32(0).int8(1).int8(attributes).int64(kafkaTestTimestamp + offset%10)
+	if key == nil {
+		message.int32(-1)
+	} else {
+		message.bytes(key)
+	}
+	message.bytes(value)
+	return append((&kafkaWriter{}).int64(offset).int32(int32(len(message.buf))).buf, message.buf...)
+}
+
+// compressedWith
+// compresses the data with the writer
+func compressedWith(t *testing.T, data []byte, writer func(w io.Writer) io.WriteCloser) []byte {
+	buf := &bytes.Buffer{}
+	w := writer(buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeKafkaRecords(t *testing.T) {
+	records := [][]byte{kafkaRecordData(0, "k1", "v1", "h", "a", "h", "b"), kafkaRecordData(1, "", "v2")}
+	plain := bytes.Join(records, nil)
+	lz4Writer := func(w io.Writer) io.WriteCloser { return lz4.NewWriter(w) }
+	zstdWriter := func(w io.Writer) io.WriteCloser {
+		zw, _ := zstd.NewWriter(w)
+		return zw
+	}
+	snappyJava := append(append([]byte(nil), snappyJavaMagic...), 0, 0, 0, 1, 0, 0, 0, 1)
+	block := snappy.Encode(nil, plain)
+	snappyJava = append((&kafkaWriter{buf: snappyJava}).int32(int32(len(block))).buf, block...)
+	twoRecords := "10 k1=v1 h=a\nb;11 <nil>=v2 h="
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "uncompressed batch",
+			data: kafkaRecordBatch(10, kafkaCompressionNone, 0, records...),
+			want: twoRecords,
+		},
+		{
+			name: "gzip batch",
+			data: kafkaRecordBatch(10, kafkaCompressionGzip, 2, gzipped(t, plain)),
+			want: twoRecords,
+		},
+		{
+			name: "snappy batch",
+			data: kafkaRecordBatch(10, kafkaCompressionSnappy, 2, snappy.Encode(nil, plain)),
+			want: twoRecords,
+		},
+		{
+			name: "snappy-java framed batch",
+			data: kafkaRecordBatch(10, kafkaCompressionSnappy, 2, snappyJava),
+			want: twoRecords,
+		},
+		{
+			name: "lz4 batch",
+			data: kafkaRecordBatch(10, kafkaCompressionLz4, 2, compressedWith(t, plain, lz4Writer)),
+			want: twoRecords,
+		},
+		{
+			name: "zstd batch",
+			data: kafkaRecordBatch(10, kafkaCompressionZstd, 2, compressedWith(t, plain, zstdWriter)),
+			want: twoRecords,
+		},
+		{
+			name: "control batch is skipped",
+			data: append(kafkaRecordBatch(8, kafkaControlBatchBit, 0, kafkaRecordData(0, "", "")),
+				kafkaRecordBatch(10, kafkaCompressionNone, 0, records...)...),
+			want: twoRecords,
+		},
+		{
+			name: "partial batch at the end",
+			data: append(kafkaRecordBatch(10, kafkaCompressionNone, 0, records...),
+				kafkaRecordBatch(12, kafkaCompressionNone, 0, records...)[:40]...),
+			want: twoRecords,
+		},
+		{
+			name: "legacy messages",
+			data: append(kafkaLegacyMessage(3, 0, []byte("k"), []byte("v")), kafkaLegacyMessage(4, 0, nil, []byte("w"))...),
+			want: "3 k=v h=;4 <nil>=w h=",
+		},
+		{
+			name: "legacy compressed message set",
+			data: kafkaLegacyMessage(4, kafkaCompressionGzip, nil, gzipped(t,
+				append(kafkaLegacyMessage(3, 0, []byte("k"), []byte("v")), kafkaLegacyMessage(4, 0, nil, []byte("w"))...))),
+			want: "3 k=v h=;4 <nil>=w h=",
+		},
+		{
+			name:    "unsupported codec",
+			data:    kafkaRecordBatch(10, 5, 2, plain),
+			wantErr: true,
+		},
+		{
+			name:    "corrupted compressed records",
+			data:    kafkaRecordBatch(10, kafkaCompressionGzip, 2, plain),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := decodeKafkaRecords(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeKafkaRecords() error = %v, want error %v", err, tt.wantErr)
+			}
+			got := make([]string, 0, len(records))
+			for _, record := range records {
+				key := string(record.Key)
+				if record.Key == nil {
+					key = "<nil>"
+				}
+				got = append(got, fmt.Sprintf("%d %s=%s h=%s", record.Offset, key, record.Value, record.Headers["h"]))
+				if !record.Timestamp.Equal(time.UnixMilli(kafkaTestTimestamp + record.Offset%10)) {
+					t.Errorf("record %d time stamp %v", record.Offset, record.Timestamp)
+				}
+			}
+			if strings.Join(got, ";") != tt.want {
+				t.Errorf("decodeKafkaRecords() = %q, want %q", strings.Join(got, ";"), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// kafkaSizeLength request and response size field length
+	kafkaSizeLength = 4
+	// kafkaRequestHeaderSize request size, api key, api version, correlation id and client id length
+	kafkaRequestHeaderSize = kafkaSizeLength + 2 + 2 + 4 + 2
+	// kafkaMaxApiKey the largest API key accepted as a Kafka request start
+	kafkaMaxApiKey = 80
+	// kafkaMaxApiVersion the largest API version accepted as a Kafka request start
+	kafkaMaxApiVersion = 20
+)
+
+// Kafka API keys of the decoded requests
+const (
+	KafkaApiProduce     = 0
+	KafkaApiFetch       = 1
+	KafkaApiMetadata    = 3
+	KafkaApiApiVersions = 18
+)
+
+// kafkaFlexibleVersions the first flexible version of the decoded APIs,
+// flexible requests and responses use compact strings, arrays and tagged fields
+var kafkaFlexibleVersions = map[int16]int16{
+	KafkaApiProduce:     9,
+	KafkaApiFetch:       12,
+	KafkaApiMetadata:    9,
+	KafkaApiApiVersions: 3,
+}
+
+// KafkaMessage
+// records of a single topic partition carried by Produce request or Fetch response
+type KafkaMessage struct {
+	// Flow message direction, from client for Produce and from broker for Fetch
+	Flow FlowInfo
+	// Timestamp time stamp of the first request or response segment
+	Timestamp time.Time
+	// SeqNo TCP sequence number of the first request or response byte
+	SeqNo int
+	// ApiKey KafkaApiProduce or KafkaApiFetch
+	ApiKey int16
+	// ApiVersion request version
+	ApiVersion int16
+	// CorrelationId request correlation id
+	CorrelationId int32
+	// ClientId client id from the request header
+	ClientId string
+	// Topic topic name, topic id when the name is not known
+	Topic string
+	// TopicId topic id for the versions which address topics by id
+	TopicId string
+	// Partition partition index
+	Partition int32
+	// Records decoded records
+	Records []KafkaRecord
+}
+
+// KafkaTopics
+// topic names by id learned from Metadata responses, shared by the connections of the capture
+type KafkaTopics struct {
+	lock  sync.Mutex
+	names map[uuid.UUID]string
+}
+
+// NewKafkaTopics
+// creates an empty topic name registry
+func NewKafkaTopics() *KafkaTopics {
+	return &KafkaTopics{names: make(map[uuid.UUID]string)}
+}
+
+// add
+// remembers the topic name
+func (kt *KafkaTopics) add(id uuid.UUID, name string) {
+	if id == uuid.Nil || name == "" {
+		return
+	}
+	kt.lock.Lock()
+	defer kt.lock.Unlock()
+	kt.names[id] = name
+}
+
+// name
+// returns the topic name or empty string
+func (kt *KafkaTopics) name(id uuid.UUID) string {
+	kt.lock.Lock()
+	defer kt.lock.Unlock()
+	return kt.names[id]
+}
+
+// kafkaRequest
+// header of the request waiting for the response
+type kafkaRequest struct {
+	apiKey     int16
+	apiVersion int16
+	clientId   string
+}
+
+// kafkaConnection
+// Kafka connection state: requests waiting for responses by correlation id
+type kafkaConnection struct {
+	sink     MessageSink
+	client   *httpHalfStream
+	topics   *KafkaTopics
+	requests map[int32]kafkaRequest
+}
+
+// newKafkaConnection
+// creates Kafka connection state, client sends requests
+func newKafkaConnection(sink MessageSink, client *httpHalfStream, topics *KafkaTopics) *kafkaConnection {
+	return &kafkaConnection{
+		sink:     sink,
+		client:   client,
+		topics:   topics,
+		requests: make(map[int32]kafkaRequest),
+	}
+}
+
+// isKafkaRequest
+// checks if the data starts with a plausible Kafka request header
+func isKafkaRequest(buf []byte) bool {
+	if len(buf) < kafkaRequestHeaderSize {
+		return false
+	}
+	size := int32(binary.BigEndian.Uint32(buf))
+	apiKey := int16(binary.BigEndian.Uint16(buf[4:]))
+	apiVersion := int16(binary.BigEndian.Uint16(buf[6:]))
+	clientIdLength := int(int16(binary.BigEndian.Uint16(buf[12:])))
+	if size < kafkaRequestHeaderSize-kafkaSizeLength || size > MaxHttpMessageSize ||
+		apiKey < 0 || apiKey > kafkaMaxApiKey || apiVersion < 0 || apiVersion > kafkaMaxApiVersion ||
+		clientIdLength < -1 || clientIdLength > int(size)-(kafkaRequestHeaderSize-kafkaSizeLength) {
+		return false
+	}
+	clientId := buf[kafkaRequestHeaderSize:min(len(buf), kafkaRequestHeaderSize+max(clientIdLength, 0))]
+	for _, c := range clientId {
+		if c >= unicode.MaxASCII || !unicode.IsPrint(rune(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// kafkaFlexible
+// checks if the API version uses the flexible encoding
+func kafkaFlexible(apiKey, apiVersion int16) bool {
+	first, known := kafkaFlexibleVersions[apiKey]
+	return known && apiVersion >= first
+}
+
+// parse
+// decodes all complete requests or responses from the half stream buffer
+func (kc *kafkaConnection) parse(hs *httpHalfStream, final bool) {
+	for len(hs.buf) >= kafkaSizeLength {
+		size := int(int32(binary.BigEndian.Uint32(hs.buf)))
+		if size < 0 || size > MaxHttpMessageSize {
+			log.Tracef("invalid Kafka message size %d in stream %s:%d->%s:%d, %d buffered bytes dropped",
+				size, hs.flow.SrcIP, hs.flow.SrcPort, hs.flow.DstIP, hs.flow.DstPort, len(hs.buf))
+			hs.consume(len(hs.buf))
+			return
+		}
+		if len(hs.buf) < kafkaSizeLength+size {
+			break
+		}
+		data := hs.buf[kafkaSizeLength : kafkaSizeLength+size]
+		if hs == kc.client {
+			kc.onRequest(hs, data)
+		} else {
+			kc.onResponse(hs, data)
+		}
+		hs.consume(kafkaSizeLength + size)
+	}
+	if final {
+		hs.consume(len(hs.buf))
+	}
+}
+
+// message
+// makes a message of the stream data at the buffer start
+func (kc *kafkaConnection) message(hs *httpHalfStream, apiKey, apiVersion int16, correlationId int32, clientId string) KafkaMessage {
+	return KafkaMessage{
+		Flow:          hs.flow,
+		Timestamp:     hs.timestamp(0),
+		SeqNo:         int(hs.bufSeq),
+		ApiKey:        apiKey,
+		ApiVersion:    apiVersion,
+		CorrelationId: correlationId,
+		ClientId:      clientId,
+	}
+}
+
+// onRequest
+// remembers the request header and decodes Produce request records
+func (kc *kafkaConnection) onRequest(hs *httpHalfStream, data []byte) {
+	kr := newKafkaReader(data, false)
+	apiKey := kr.int16()
+	apiVersion := kr.int16()
+	correlationId := kr.int32()
+	clientId := kr.string() // not compact even in the flexible header
+	flexible := kafkaFlexible(apiKey, apiVersion)
+	kr.flexible = flexible
+	kr.taggedFields()
+	if kr.err != nil {
+		log.Tracef("unable to decode Kafka request header in stream %s:%d->%s:%d: %v",
+			hs.flow.SrcIP, hs.flow.SrcPort, hs.flow.DstIP, hs.flow.DstPort, kr.err)
+		return
+	}
+	if len(kc.requests) < maxPendingRequests {
+		kc.requests[correlationId] = kafkaRequest{apiKey: apiKey, apiVersion: apiVersion, clientId: clientId}
+	}
+	if apiKey != KafkaApiProduce {
+		return
+	}
+	template := kc.message(hs, apiKey, apiVersion, correlationId, clientId)
+	if apiVersion >= 3 {
+		kr.string() // transactional id
+	}
+	kr.int16() // acks
+	kr.int32() // timeout
+	topicCount := kr.arrayLength()
+	for t := 0; t < topicCount && kr.err == nil; t++ {
+		topic, topicId := kc.readTopic(kr, apiVersion >= 13)
+		partitionCount := kr.arrayLength()
+		for p := 0; p < partitionCount && kr.err == nil; p++ {
+			partition := kr.int32()
+			records := kr.bytes()
+			kr.taggedFields()
+			kc.onRecords(template, topic, topicId, partition, records)
+		}
+		kr.taggedFields()
+	}
+	if kr.err != nil {
+		log.Tracef("unable to decode Kafka Produce request in stream %s:%d->%s:%d: %v",
+			hs.flow.SrcIP, hs.flow.SrcPort, hs.flow.DstIP, hs.flow.DstPort, kr.err)
+	}
+}
+
+// onResponse
+// decodes Fetch response records and Metadata response topic ids
+func (kc *kafkaConnection) onResponse(hs *httpHalfStream, data []byte) {
+	kr := newKafkaReader(data, false)
+	correlationId := kr.int32()
+	request, found := kc.requests[correlationId]
+	if !found || kr.err != nil {
+		return
+	}
+	delete(kc.requests, correlationId)
+	// ApiVersions response header is never flexible to let clients read it
+	kr.flexible = kafkaFlexible(request.apiKey, request.apiVersion) && request.apiKey != KafkaApiApiVersions
+	kr.taggedFields()
+	switch request.apiKey {
+	case KafkaApiFetch:
+		kc.onFetchResponse(hs, kr, request, correlationId)
+	case KafkaApiMetadata:
+		kc.onMetadataResponse(kr, request.apiVersion)
+	default:
+		return
+	}
+	if kr.err != nil {
+		log.Tracef("unable to decode Kafka response (api key %d, version %d) in stream %s:%d->%s:%d: %v",
+			request.apiKey, request.apiVersion, hs.flow.SrcIP, hs.flow.SrcPort, hs.flow.DstIP, hs.flow.DstPort, kr.err)
+	}
+}
+
+// onFetchResponse
+// decodes records of the fetched partitions
+func (kc *kafkaConnection) onFetchResponse(hs *httpHalfStream, kr *kafkaReader, request kafkaRequest, correlationId int32) {
+	version := request.apiVersion
+	template := kc.message(hs, KafkaApiFetch, version, correlationId, request.clientId)
+	if version >= 1 {
+		kr.int32() // throttle time
+	}
+	if version >= 7 {
+		kr.int16() // error code
+		kr.int32() // session id
+	}
+	topicCount := kr.arrayLength()
+	for t := 0; t < topicCount && kr.err == nil; t++ {
+		topic, topicId := kc.readTopic(kr, version >= 13)
+		partitionCount := kr.arrayLength()
+		for p := 0; p < partitionCount && kr.err == nil; p++ {
+			partition := kr.int32()
+			kr.int16() // error code
+			kr.int64() // high watermark
+			if version >= 4 {
+				kr.int64() // last stable offset
+			}
+			if version >= 5 {
+				kr.int64() // log start offset
+			}
+			if version >= 4 {
+				abortedCount := kr.arrayLength()
+				for a := 0; a < abortedCount && kr.err == nil; a++ {
+					kr.int64() // producer id
+					kr.int64() // first offset
+					kr.taggedFields()
+				}
+			}
+			if version >= 11 {
+				kr.int32() // preferred read replica
+			}
+			records := kr.bytes()
+			kr.taggedFields()
+			kc.onRecords(template, topic, topicId, partition, records)
+		}
+		kr.taggedFields()
+	}
+}
+
+// onMetadataResponse
+// remembers topic names by id
+func (kc *kafkaConnection) onMetadataResponse(kr *kafkaReader, version int16) {
+	if version < 10 {
+		return // topic ids are not sent
+	}
+	kr.int32() // throttle time
+	brokerCount := kr.arrayLength()
+	for b := 0; b < brokerCount && kr.err == nil; b++ {
+		kr.int32()  // node id
+		kr.string() // host
+		kr.int32()  // port
+		kr.string() // rack
+		kr.taggedFields()
+	}
+	kr.string() // cluster id
+	kr.int32()  // controller id
+	topicCount := kr.arrayLength()
+	for t := 0; t < topicCount && kr.err == nil; t++ {
+		kr.int16() // error code
+		name := kr.string()
+		id := kr.uuid()
+		kr.int8() // is internal
+		partitionCount := kr.arrayLength()
+		for p := 0; p < partitionCount && kr.err == nil; p++ {
+			kr.int16() // error code
+			kr.int32() // partition index
+			kr.int32() // leader id
+			kr.int32() // leader epoch
+			for n := 0; n < 3; n++ {
+				// replica, in-sync and offline node ids
+				kr.next(4 * kr.arrayLength())
+			}
+			kr.taggedFields()
+		}
+		kr.int32() // topic authorized operations
+		kr.taggedFields()
+		if kr.err == nil {
+			kc.topics.add(id, name)
+		}
+	}
+}
+
+// readTopic
+// reads the topic name or id, the name of topic id is taken from Metadata responses
+func (kc *kafkaConnection) readTopic(kr *kafkaReader, byId bool) (string, string) {
+	if !byId {
+		return kr.string(), ""
+	}
+	id := kr.uuid()
+	if name := kc.topics.name(id); name != "" {
+		return name, id.String()
+	}
+	return id.String(), id.String()
+}
+
+// onRecords
+// sends decoded records of the partition into the sink
+func (kc *kafkaConnection) onRecords(template KafkaMessage, topic, topicId string, partition int32, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	records, err := decodeKafkaRecords(data)
+	if err != nil {
+		log.Tracef("unable to decode Kafka records of %s-%d: %v", topic, partition, err)
+	}
+	if len(records) == 0 {
+		return
+	}
+	msg := template
+	msg.Topic = topic
+	msg.TopicId = topicId
+	msg.Partition = partition
+	msg.Records = records
+	kc.sink.OnKafkaMessage(&msg)
+}
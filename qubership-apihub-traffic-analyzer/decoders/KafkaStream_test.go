@@ -0,0 +1,264 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// kafkaWriter
+// encodes Kafka protocol fields, flexible versions use compact lengths and tagged fields
+type kafkaWriter struct {
+	buf      []byte
+	flexible bool
+}
+
+func (kw *kafkaWriter) int8(v int8) *kafkaWriter {
+	kw.buf = append(kw.buf, byte(v))
+	return kw
+}
+
+func (kw *kafkaWriter) int16(v int16) *kafkaWriter {
+	kw.buf = binary.BigEndian.AppendUint16(kw.buf, uint16(v))
+	return kw
+}
+
+func (kw *kafkaWriter) int32(v int32) *kafkaWriter {
+	kw.buf = binary.BigEndian.AppendUint32(kw.buf, uint32(v))
+	return kw
+}
+
+func (kw *kafkaWriter) int64(v int64) *kafkaWriter {
+	kw.buf = binary.BigEndian.AppendUint64(kw.buf, uint64(v))
+	return kw
+}
+
+func (kw *kafkaWriter) varint(v int64) *kafkaWriter {
+	kw.buf = binary.AppendVarint(kw.buf, v)
+	return kw
+}
+
+// length
+// writes length of string, bytes or array, classic strings have int16 length
+func (kw *kafkaWriter) length(n int, classicInt16 bool) *kafkaWriter {
+	switch {
+	case kw.flexible:
+		kw.buf = binary.AppendUvarint(kw.buf, uint64(n+1))
+	case classicInt16:
+		kw.int16(int16(n))
+	default:
+		kw.int32(int32(n))
+	}
+	return kw
+}
+
+func (kw *kafkaWriter) string(s string) *kafkaWriter {
+	kw.length(len(s), true)
+	kw.buf = append(kw.buf, s...)
+	return kw
+}
+
+func (kw *kafkaWriter) bytes(b []byte) *kafkaWriter {
+	kw.length(len(b), false)
+	kw.buf = append(kw.buf, b...)
+	return kw
+}
+
+func (kw *kafkaWriter) array(n int) *kafkaWriter {
+	return kw.length(n, false)
+}
+
+func (kw *kafkaWriter) uuid(id uuid.UUID) *kafkaWriter {
+	kw.buf = append(kw.buf, id[:]...)
+	return kw
+}
+
+// tags
+// writes empty tagged fields of the flexible versions
+func (kw *kafkaWriter) tags() *kafkaWriter {
+	if kw.flexible {
+		kw.buf = append(kw.buf, 0)
+	}
+	return kw
+}
+
+// sized
+// returns the data prefixed by its size
+func (kw *kafkaWriter) sized() string {
+	return string(binary.BigEndian.AppendUint32(nil, uint32(len(kw.buf)))) + string(kw.buf)
+}
+
+// kafkaRequestHeader
+// starts the request of the API version, the client id is never compact
+func kafkaRequestHeader(apiKey, apiVersion int16, correlationId int32) *kafkaWriter {
+	kw := &kafkaWriter{}
+	kw.int16(apiKey).int16(apiVersion).int32(correlationId).string("svc-client")
+	kw.flexible = kafkaFlexible(apiKey, apiVersion)
+	return kw.tags()
+}
+
+// kafkaResponseHeader
+// starts the response to the request of the API version
+func kafkaResponseHeader(apiKey, apiVersion int16, correlationId int32) *kafkaWriter {
+	kw := &kafkaWriter{flexible: kafkaFlexible(apiKey, apiVersion)}
+	return kw.int32(correlationId).tags()
+}
+
+// kafkaTestBatch
+// record batch of the record "k" = "v" with header "h" = "1", offset 5
+var kafkaTestBatch = kafkaRecordBatch(5, 0, 0, kafkaRecordData(0, "k", "v", "h", "1"))
+
+func TestKafkaStream(t *testing.T) {
+	topicId := uuid.MustParse("8d7f1c1e-3b8a-4f43-9a8e-6c1b7a0a6f01")
+	produce := func(version int16) string {
+		kw := kafkaRequestHeader(KafkaApiProduce, version, 7)
+		if version >= 3 {
+			kw.string("") // transactional id
+		}
+		kw.int16(1).int32(30000).array(1)
+		if version >= 13 {
+			kw.uuid(topicId)
+		} else {
+			kw.string("orders")
+		}
+		return kw.array(1).int32(2).bytes(kafkaTestBatch).tags().tags().tags().sized()
+	}
+	fetchRequest := func(version int16, correlationId int32) string {
+		// the request body is not decoded
+		return kafkaRequestHeader(KafkaApiFetch, version, correlationId).int32(-1).sized()
+	}
+	fetchResponse := func(version int16, correlationId int32) string {
+		kw := kafkaResponseHeader(KafkaApiFetch, version, correlationId).int32(0)
+		if version >= 7 {
+			kw.int16(0).int32(0)
+		}
+		kw.array(1)
+		if version >= 13 {
+			kw.uuid(topicId)
+		} else {
+			kw.string("orders")
+		}
+		kw.array(1).int32(3).int16(0).int64(100).int64(100)
+		if version >= 5 {
+			kw.int64(0)
+		}
+		kw.array(0)
+		if version >= 11 {
+			kw.int32(-1)
+		}
+		return kw.bytes(kafkaTestBatch).tags().tags().tags().sized()
+	}
+	metadataRequest := kafkaRequestHeader(KafkaApiMetadata, 12, 1).array(0).int8(0).int8(0).tags().sized()
+	metadataResponse := kafkaResponseHeader(KafkaApiMetadata, 12, 1).
+		int32(0).array(1).int32(1).string("broker-1").int32(9092).string("").tags().
+		string("cluster").int32(1).
+		array(1).int16(0).string("orders").uuid(topicId).int8(0).array(0).int32(0).tags().
+		tags().sized()
+	tests := []struct {
+		name  string
+		parts []testPart
+		want  string
+	}{
+		{
+			name:  "produce request",
+			parts: []testPart{{true, produce(3)}},
+			want:  "client produce v3 #7 svc-client orders-2: 5 k=v h=1",
+		},
+		{
+			name:  "flexible produce request",
+			parts: []testPart{{true, produce(9)}},
+			want:  "client produce v9 #7 svc-client orders-2: 5 k=v h=1",
+		},
+		{
+			name:  "fetch response",
+			parts: []testPart{{true, fetchRequest(4, 8)}, {false, fetchResponse(4, 8)}},
+			want:  "server fetch v4 #8 svc-client orders-3: 5 k=v h=1",
+		},
+		{
+			name: "topic name of fetch response by topic id",
+			parts: []testPart{
+				{true, metadataRequest + fetchRequest(13, 9)},
+				{false, metadataResponse + fetchResponse(13, 9)},
+			},
+			want: "server fetch v13 #9 svc-client orders-3: 5 k=v h=1",
+		},
+		{
+			name:  "unknown topic id",
+			parts: []testPart{{true, fetchRequest(13, 9)}, {false, fetchResponse(13, 9)}},
+			want:  "server fetch v13 #9 svc-client " + topicId.String() + "-3: 5 k=v h=1",
+		},
+		{
+			name:  "response without request",
+			parts: []testPart{{true, fetchRequest(4, 8)}, {false, fetchResponse(4, 10)}},
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := assembleSegments(nil, conversation(tt.parts...)...)
+			messages := make([]string, 0, len(sink.kafka))
+			for _, msg := range sink.kafka {
+				from := "server"
+				if msg.Flow.SrcPort != testServerPort {
+					from = "client"
+				}
+				api := "produce"
+				if msg.ApiKey == KafkaApiFetch {
+					api = "fetch"
+				}
+				records := make([]string, 0, len(msg.Records))
+				for _, record := range msg.Records {
+					records = append(records, fmt.Sprintf("%d %s=%s h=%s", record.Offset, record.Key, record.Value, record.Headers["h"]))
+				}
+				messages = append(messages, fmt.Sprintf("%s %s v%d #%d %s %s-%d: %s", from, api, msg.ApiVersion,
+					msg.CorrelationId, msg.ClientId, msg.Topic, msg.Partition, strings.Join(records, ",")))
+			}
+			if got := strings.Join(messages, ";"); got != tt.want {
+				t.Errorf("messages %q, want %q", got, tt.want)
+			}
+			if len(sink.messages) != 0 || len(sink.rejected) != 0 {
+				t.Errorf("Kafka connection decoded as HTTP: %d messages, rejected %v", len(sink.messages), sink.rejected)
+			}
+		})
+	}
+}
+
+func TestIsKafkaRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"metadata request", kafkaRequestHeader(KafkaApiMetadata, 12, 1).array(0).sized(), true},
+		{"null client id", "\x00\x00\x00\x0a\x00\x03\x00\x01\x00\x00\x00\x01\xff\xff", true},
+		{"HTTP request", "GET / HTTP/1.1\r\nHost: a\r\n\r\n", false},
+		{"unknown api key", "\x00\x00\x00\x0a\x01\x03\x00\x01\x00\x00\x00\x01\xff\xff", false},
+		{"client id longer than request", "\x00\x00\x00\x0a\x00\x03\x00\x01\x00\x00\x00\x01\x00\x10", false},
+		{"not printable client id", "\x00\x00\x00\x0b\x00\x03\x00\x01\x00\x00\x00\x01\x00\x01\x07", false},
+		{"short data", "\x00\x00\x00\x0a\x00\x03", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isKafkaRequest([]byte(tt.data)); got != tt.want {
+				t.Errorf("isKafkaRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/gopacket/layers"
+)
+
+// NetworkCounters
+// network layer statistics of the decoded packets
+type NetworkCounters struct {
+	// Protocols number of packets (reassembled datagrams) by the innermost IP protocol name
+	Protocols map[string]int
+	// Fragments number of received IP fragments
+	Fragments int
+	// Reassembled number of datagrams reassembled from fragments
+	Reassembled int
+	// FragmentsDropped number of fragments dropped because of timeout or limits
+	FragmentsDropped int
+	// FragmentsPending number of incomplete datagrams
+	FragmentsPending int
+}
+
+// String
+// returns counters in a human-readable form
+func (nc NetworkCounters) String() string {
+	protocols := make([]string, 0, len(nc.Protocols))
+	for name, count := range nc.Protocols {
+		protocols = append(protocols, fmt.Sprintf("%s: %d", name, count))
+	}
+	return fmt.Sprintf("protocols: [%s], fragments: %d, reassembled: %d, dropped fragments: %d, incomplete datagrams: %d",
+		strings.Join(protocols, ", "), nc.Fragments, nc.Reassembled, nc.FragmentsDropped, nc.FragmentsPending)
+}
+
+// NetworkDecoder
+// decodes network layer of the capture packets, removes encapsulations and reassembles IP fragments
+type NetworkDecoder struct {
+	defragmenter *IpDefragmenter
+	counters     NetworkCounters
+}
+
+// NewNetworkDecoder
+// creates a decoder for packets of a single capture
+func NewNetworkDecoder() *NetworkDecoder {
+	return &NetworkDecoder{
+		defragmenter: NewIpDefragmenter(),
+		counters:     NetworkCounters{Protocols: make(map[string]int)},
+	}
+}
+
+// Counters
+// returns statistics of the decoded packets
+func (nd *NetworkDecoder) Counters() NetworkCounters {
+	counters := nd.counters
+	counters.Protocols = make(map[string]int, len(nd.counters.Protocols))
+	for name, count := range nd.counters.Protocols {
+		counters.Protocols[name] = count
+	}
+	counters.FragmentsDropped += nd.defragmenter.Dropped()
+	counters.FragmentsPending = nd.defragmenter.Pending()
+	return counters
+}
+
+// Decode
+// decodes the network layer payload of the given type, VLAN tags, IP-in-IP, GRE, VXLAN and Geneve encapsulations
+// are removed recursively down to the innermost IP packet, the removed layers are kept in the packet encapsulation.
+// IP fragments are reassembled at each layer, nil packet without error is returned while the datagram is incomplete
+func (nd *NetworkDecoder) Decode(etherType layers.EthernetType, data []byte, i int, timestamp time.Time) (*IPPacket, error) {
+	var encapsulation *Encapsulation
+	addLayer := func(layer string, outer *IPPacket) {
+		if encapsulation == nil {
+			encapsulation = &Encapsulation{}
+		}
+		if outer != nil && encapsulation.OuterSrcIP == "" {
+			encapsulation.OuterSrcIP = outer.SrcIP
+			encapsulation.OuterDstIP = outer.DstIP
+		}
+		encapsulation.Layers = append(encapsulation.Layers, layer)
+	}
+	for depth := 0; depth < maxEncapsulationDepth; depth++ {
+		switch etherType {
+		case layers.EthernetTypeDot1Q, ethernetTypeQinQ, ethernetTypeQinQLegacy:
+			if len(data) < vlanTagSize {
+				return nil, fmt.Errorf("not enough data to parse VLAN tag in packet %d", i)
+			}
+			addLayer(fmt.Sprintf("vlan:%d", binary.BigEndian.Uint16(data[0:2])&0x0fff), nil)
+			etherType = layers.EthernetType(binary.BigEndian.Uint16(data[2:4]))
+			data = data[vlanTagSize:]
+			continue
+		case layers.EthernetTypeIPv4, layers.EthernetTypeIPv6:
+		default:
+			return nil, ErrorNotAnIpPacket
+		}
+		ip, err := DecodeIp(data, etherType == layers.EthernetTypeIPv6, i)
+		if err != nil {
+			return nil, err
+		}
+		if ip.Fragment != nil {
+			nd.counters.Fragments++
+			ip, err = nd.defragmenter.Add(ip, timestamp)
+			if err != nil {
+				return nil, fmt.Errorf("unable to reassemble fragment of packet %d: %v", i, err)
+			}
+			if ip == nil {
+				return nil, nil // incomplete datagram
+			}
+			nd.counters.Reassembled++
+		}
+		var inner []byte
+		switch ip.Protocol {
+		case layers.IPProtocolIPv4:
+			addLayer("ipip", ip)
+			etherType, inner = layers.EthernetTypeIPv4, ip.Payload
+		case layers.IPProtocolIPv6:
+			addLayer("ipip", ip)
+			etherType, inner = layers.EthernetTypeIPv6, ip.Payload
+		case layers.IPProtocolGRE:
+			var layer string
+			etherType, inner, layer, err = unwrapGre(ip.Payload)
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode GRE in packet %d: %v", i, err)
+			}
+			addLayer(layer, ip)
+		case layers.IPProtocolUDP:
+			var layer string
+			etherType, inner, layer = unwrapUdpTunnel(ip.Payload)
+			if inner == nil {
+				return nd.innermost(ip, encapsulation), nil // not a tunnel
+			}
+			addLayer(layer, ip)
+		default:
+			return nd.innermost(ip, encapsulation), nil
+		}
+		if etherType == ethernetTypeTransparentBridging {
+			etherType, inner, err = unwrapEthernet(inner)
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode encapsulated frame in packet %d: %v", i, err)
+			}
+		}
+		data = inner
+	}
+	return nil, ErrorTooDeepEncapsulation
+}
+
+// innermost
+// counts protocol of the innermost packet and sets its encapsulation
+func (nd *NetworkDecoder) innermost(ip *IPPacket, encapsulation *Encapsulation) *IPPacket {
+	ip.Encapsulation = encapsulation
+	nd.counters.Protocols[ProtocolName(ip.Protocol)]++
+	return ip
+}
+
+// ProtocolName
+// returns IP protocol name, the protocol number for unknown protocols
+func ProtocolName(protocol layers.IPProtocol) string {
+	name := protocol.String()
+	if strings.HasPrefix(name, "Unknown") {
+		return fmt.Sprintf("IP-%d", protocol)
+	}
+	return name
+}
@@ -16,6 +16,7 @@ package decoders
 
 import (
 	"regexp"
+	"strings"
 )
 
 type PayloadType int
@@ -34,6 +35,7 @@ const (
 	byteT              = byte('T')
 	RequestMethod      = "Method"
 	RequestPath        = "Path"
+	RequestQuery       = "Query"
 	RRProtocol         = "Proto"
 	ResponseStatus     = "Status"
 	ResponseStatusText = "StatusText"
@@ -78,7 +80,10 @@ func DetectHttp(payLoad []byte) (PayloadType, map[string]interface{}) {
 				case 1:
 					fields[RequestMethod] = string(m)
 				case 2:
-					fields[RequestPath] = string(m)
+					// the query is kept apart to match operations by path only
+					path, query, _ := strings.Cut(string(m), "?")
+					fields[RequestPath] = path
+					fields[RequestQuery] = query
 				case 3:
 					fields[RRProtocol] = string(m)
 				default:
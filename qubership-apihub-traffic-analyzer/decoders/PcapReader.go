@@ -0,0 +1,158 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+const (
+	// pcapMagicMicroseconds pcap file magic for microsecond time stamps
+	pcapMagicMicroseconds uint32 = 0xA1B2C3D4
+	// pcapMagicNanoseconds pcap file magic for nanosecond time stamps
+	pcapMagicNanoseconds uint32 = 0xA1B23C4D
+	pcapFileHeaderSize          = 24
+	pcapPacketHeaderSize        = 16
+	pcapVersionMajor            = 2
+	pcapVersionMinor            = 4
+	// pcapDefaultSnapLen maximal packet size accepted regardless of the file snapshot length
+	pcapDefaultSnapLen = 262144
+	// streamBufferSize read buffer size for capture streams
+	streamBufferSize = 256 * 1024
+)
+
+// gzipMagic compressed stream signature
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ErrorNotPcap the data is not a pcap capture
+var ErrorNotPcap = errors.New("not a pcap file")
+
+// PcapHeader
+// pcap file header values
+type PcapHeader struct {
+	Order binary.ByteOrder
+	// Nanoseconds time stamps resolution is nanoseconds (microseconds otherwise)
+	Nanoseconds bool
+	SnapLen     uint32
+	LinkType    LinkType
+}
+
+// PcapReader
+// pure Go reader of pcap capture stream
+type PcapReader struct {
+	r            io.Reader
+	header       PcapHeader
+	packetHeader []byte
+	maxLen       uint32
+}
+
+// Decompressed
+// returns buffered reader of the stream, gzip compressed stream is uncompressed on the fly
+func Decompressed(r io.Reader) (*bufio.Reader, error) {
+	br := bufio.NewReaderSize(r, streamBufferSize)
+	signature, err := br.Peek(len(gzipMagic))
+	if err != nil || !bytes.Equal(signature, gzipMagic) {
+		return br, nil
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("unable to uncompress capture: %w", err)
+	}
+	return bufio.NewReaderSize(zr, streamBufferSize), nil
+}
+
+// NewPcapReader
+// reads pcap file header of either byte order and time stamps resolution
+func NewPcapReader(r io.Reader) (*PcapReader, error) {
+	header := make([]byte, pcapFileHeaderSize)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, fmt.Errorf("header was not read: %w", err)
+	}
+	pr := &PcapReader{
+		r:            r,
+		packetHeader: make([]byte, pcapPacketHeaderSize),
+	}
+	switch {
+	case isPcapMagic(binary.LittleEndian.Uint32(header[0:4])):
+		pr.header.Order = binary.LittleEndian
+	case isPcapMagic(binary.BigEndian.Uint32(header[0:4])):
+		pr.header.Order = binary.BigEndian
+	default:
+		return nil, ErrorNotPcap
+	}
+	order := pr.header.Order
+	if order.Uint16(header[4:6]) != pcapVersionMajor || order.Uint16(header[6:8]) != pcapVersionMinor {
+		return nil, fmt.Errorf("pcap version %d.%d not supported", order.Uint16(header[4:6]), order.Uint16(header[6:8]))
+	}
+	pr.header.Nanoseconds = order.Uint32(header[0:4]) == pcapMagicNanoseconds
+	pr.header.SnapLen = order.Uint32(header[16:20])
+	// upper bits of the link type field carry FCS information
+	pr.header.LinkType = LinkType(order.Uint32(header[20:24]) & 0xFFFF)
+	pr.maxLen = max(pr.header.SnapLen, pcapDefaultSnapLen)
+	return pr, nil
+}
+
+// Header
+// returns the file header values
+func (pr *PcapReader) Header() PcapHeader {
+	return pr.header
+}
+
+// ReadPacket
+// returns the next packet data in a new buffer, io.EOF at the end of the capture
+func (pr *PcapReader) ReadPacket() ([]byte, gopacket.CaptureInfo, error) {
+	ci := gopacket.CaptureInfo{}
+	_, err := io.ReadFull(pr.r, pr.packetHeader)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ci, err
+		}
+		return nil, ci, fmt.Errorf("unable to read packet header: %w", err)
+	}
+	order := pr.header.Order
+	capLen := order.Uint32(pr.packetHeader[8:12])
+	if capLen > pr.maxLen {
+		return nil, ci, fmt.Errorf("improper capture length %d", capLen)
+	}
+	fraction := int64(order.Uint32(pr.packetHeader[4:8]))
+	if !pr.header.Nanoseconds {
+		fraction *= int64(time.Microsecond)
+	}
+	ci.Timestamp = time.Unix(int64(order.Uint32(pr.packetHeader[0:4])), fraction).UTC()
+	ci.CaptureLength = int(capLen)
+	ci.Length = int(order.Uint32(pr.packetHeader[12:16]))
+	data := make([]byte, capLen)
+	_, err = io.ReadFull(pr.r, data)
+	if err != nil {
+		return nil, ci, fmt.Errorf("unable to read packet body: %w", err)
+	}
+	return data, ci, nil
+}
+
+// isPcapMagic
+// true for pcap file magic of any time stamp resolution
+func isPcapMagic(magic uint32) bool {
+	return magic == pcapMagicMicroseconds || magic == pcapMagicNanoseconds
+}
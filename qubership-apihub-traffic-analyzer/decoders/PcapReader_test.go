@@ -0,0 +1,126 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPcapReader(t *testing.T) {
+	le, be := binary.LittleEndian, binary.BigEndian
+	twoFrames := pcapBytes(t, be, true, LinkTypeEthernet, testEthernetFrame, testEthernetFrame)
+	oversized := pcapBytes(t, le, false, LinkTypeEthernet, testEthernetFrame)
+	le.PutUint32(oversized[pcapFileHeaderSize+8:], pcapDefaultSnapLen+1)
+	wrongVersion := pcapBytes(t, le, false, LinkTypeEthernet)
+	le.PutUint16(wrongVersion[4:6], 3)
+	fcsLinkType := pcapBytes(t, le, false, LinkTypeEthernet, testEthernetFrame)
+	// FCS length is present in the upper bits of the link type
+	le.PutUint32(fcsLinkType[20:24], 0x14000000|uint32(LinkTypeEthernet))
+	tests := []struct {
+		name          string
+		input         []byte
+		wantFrames    int
+		wantLinkType  LinkType
+		wantHeaderErr error
+		wantReadErr   string
+	}{
+		{
+			name:         "big endian nanosecond capture",
+			input:        twoFrames,
+			wantFrames:   2,
+			wantLinkType: LinkTypeEthernet,
+		},
+		{
+			name:         "compressed capture",
+			input:        gzipped(t, twoFrames),
+			wantFrames:   2,
+			wantLinkType: LinkTypeEthernet,
+		},
+		{
+			name:         "link type with FCS bits",
+			input:        fcsLinkType,
+			wantFrames:   1,
+			wantLinkType: LinkTypeEthernet,
+		},
+		{
+			name:          "not a capture",
+			input:         []byte(strings.Repeat("not a pcap", 3)),
+			wantHeaderErr: ErrorNotPcap,
+		},
+		{
+			name:          "short header",
+			input:         twoFrames[:10],
+			wantHeaderErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:          "not supported version",
+			input:         wrongVersion,
+			wantHeaderErr: errors.New("pcap version 3.4 not supported"),
+		},
+		{
+			name:         "truncated packet",
+			input:        twoFrames[:len(twoFrames)-3],
+			wantFrames:   1,
+			wantLinkType: LinkTypeEthernet,
+			wantReadErr:  "unable to read packet body: unexpected EOF",
+		},
+		{
+			name:         "improper capture length",
+			input:        oversized,
+			wantLinkType: LinkTypeEthernet,
+			wantReadErr:  "improper capture length 262145",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			br, err := Decompressed(bytes.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pr, err := NewPcapReader(br)
+			if tt.wantHeaderErr != nil {
+				if err == nil || !errors.Is(err, tt.wantHeaderErr) && err.Error() != tt.wantHeaderErr.Error() {
+					t.Errorf("NewPcapReader() error = %v, want %v", err, tt.wantHeaderErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pr.Header().LinkType != tt.wantLinkType {
+				t.Errorf("link type %d, want %d", pr.Header().LinkType, tt.wantLinkType)
+			}
+			frames := 0
+			for {
+				_, _, err = pr.ReadPacket()
+				if err != nil {
+					break
+				}
+				frames++
+			}
+			if frames != tt.wantFrames {
+				t.Errorf("read %d frames, want %d", frames, tt.wantFrames)
+			}
+			if tt.wantReadErr == "" && !errors.Is(err, io.EOF) || tt.wantReadErr != "" && err.Error() != tt.wantReadErr {
+				t.Errorf("ReadPacket() error = %v, want %q", err, tt.wantReadErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// maxRawProtobufDepth nesting limit for the raw wire decoder
+const maxRawProtobufDepth = 32
+
+// ErrorNotProtobuf data is not a valid protobuf wire format message
+var ErrorNotProtobuf = errors.New("not a protobuf message")
+
+// protobufDescriptorSet
+// services and messages from a single uploaded FileDescriptorSet
+type protobufDescriptorSet struct {
+	name string
+	// serviceName service the set is bound to, empty for the sets applicable to any service
+	serviceName string
+	files       *protoregistry.Files
+	types       *dynamicpb.Types
+}
+
+// ProtobufRegistry
+// descriptor sets used to decode gRPC messages into JSON
+type ProtobufRegistry struct {
+	sets []protobufDescriptorSet
+}
+
+// NewProtobufRegistry
+// creates an empty registry, messages are decoded by the raw wire decoder until descriptor sets are added
+func NewProtobufRegistry() *ProtobufRegistry {
+	return &ProtobufRegistry{sets: make([]protobufDescriptorSet, 0)}
+}
+
+// ParseDescriptorSet
+// parses serialized FileDescriptorSet, all the imported files should be included (protoc --include_imports)
+func ParseDescriptorSet(data []byte) (*protoregistry.Files, error) {
+	fds := new(descriptorpb.FileDescriptorSet)
+	err := proto.Unmarshal(data, fds)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal file descriptor set: %w", err)
+	}
+	if len(fds.GetFile()) == 0 {
+		return nil, fmt.Errorf("file descriptor set is empty")
+	}
+	files, err := protodesc.NewFiles(fds)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve file descriptor set: %w", err)
+	}
+	return files, nil
+}
+
+// Add
+// registers the descriptor set, serviceName binds the set to a particular service (empty for any service)
+func (pr *ProtobufRegistry) Add(name, serviceName string, data []byte) error {
+	files, err := ParseDescriptorSet(data)
+	if err != nil {
+		return err
+	}
+	pr.sets = append(pr.sets, protobufDescriptorSet{
+		name:        name,
+		serviceName: serviceName,
+		files:       files,
+		types:       dynamicpb.NewTypes(files),
+	})
+	return nil
+}
+
+// findMethod
+// looks for the gRPC method in the sets bound to the service first, then in the unbound sets
+func (pr *ProtobufRegistry) findMethod(serviceName, grpcService, grpcMethod string) (protoreflect.MethodDescriptor, *protobufDescriptorSet) {
+	if pr == nil || grpcService == "" || grpcMethod == "" {
+		return nil, nil
+	}
+	for _, bound := range []bool{true, false} {
+		for i := range pr.sets {
+			set := &pr.sets[i]
+			if bound && (serviceName == "" || set.serviceName != serviceName) {
+				continue
+			}
+			if !bound && set.serviceName != "" {
+				continue
+			}
+			desc, err := set.files.FindDescriptorByName(protoreflect.FullName(grpcService))
+			if err != nil {
+				continue
+			}
+			service, ok := desc.(protoreflect.ServiceDescriptor)
+			if !ok {
+				continue
+			}
+			method := service.Methods().ByName(protoreflect.Name(grpcMethod))
+			if method != nil {
+				return method, set
+			}
+		}
+	}
+	return nil, nil
+}
+
+// GrpcJson
+// decodes gRPC messages into JSON, serviceName is a name of the service serving the call,
+// stream of several messages is rendered as JSON array
+func (pr *ProtobufRegistry) GrpcJson(call *GrpcCall, isRequest bool, serviceName string) string {
+	if call == nil || len(call.Messages) == 0 {
+		return ""
+	}
+	var messageDesc protoreflect.MessageDescriptor = nil
+	method, set := pr.findMethod(serviceName, call.Service, call.Method)
+	if method != nil {
+		if isRequest {
+			messageDesc = method.Input()
+		} else {
+			messageDesc = method.Output()
+		}
+	}
+	values := make([]json.RawMessage, 0, len(call.Messages))
+	for _, message := range call.Messages {
+		var (
+			value []byte
+			err   error
+		)
+		if messageDesc != nil {
+			value, err = decodeProtobufJson(message, messageDesc, set.types)
+			if err != nil {
+				log.Tracef("unable to decode %s message with descriptor set '%s': %v", messageDesc.FullName(), set.name, err)
+			}
+		}
+		if value == nil {
+			value, err = DecodeRawProtobuf(message)
+			if err != nil {
+				log.Tracef("unable to decode raw protobuf message for %s/%s: %v", call.Service, call.Method, err)
+				value, _ = json.Marshal(base64.StdEncoding.EncodeToString(message))
+			}
+		}
+		values = append(values, value)
+	}
+	if len(values) == 1 {
+		return string(values[0])
+	}
+	result, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return string(result)
+}
+
+// decodeProtobufJson
+// decodes the message of known type into JSON
+func decodeProtobufJson(data []byte, messageDesc protoreflect.MessageDescriptor, types *dynamicpb.Types) ([]byte, error) {
+	message := dynamicpb.NewMessage(messageDesc)
+	err := proto.UnmarshalOptions{Resolver: types}.Unmarshal(data, message)
+	if err != nil {
+		return nil, err
+	}
+	value, err := protojson.MarshalOptions{UseProtoNames: true, Resolver: types}.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	// protojson output is deliberately unstable in white spaces
+	compact := new(bytes.Buffer)
+	err = json.Compact(compact, value)
+	if err != nil {
+		return nil, err
+	}
+	return compact.Bytes(), nil
+}
+
+// DecodeRawProtobuf
+// decodes the message without descriptor into JSON object keyed by field numbers,
+// repeated fields become arrays, length-delimited fields are rendered as nested messages,
+// printable strings or base64 encoded bytes
+func DecodeRawProtobuf(data []byte) ([]byte, error) {
+	fields, err := decodeRawFields(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// decodeRawFields
+// decodes the wire format message into field number to value map
+func decodeRawFields(data []byte, depth int) (map[string]interface{}, error) {
+	if depth > maxRawProtobufDepth {
+		return nil, ErrorNotProtobuf
+	}
+	fields := make(map[string]interface{})
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, ErrorNotProtobuf
+		}
+		data = data[n:]
+		var value interface{}
+		switch wireType {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return nil, ErrorNotProtobuf
+			}
+			value = v
+			data = data[n:]
+		case protowire.Fixed32Type:
+			v, n := protowire.ConsumeFixed32(data)
+			if n < 0 {
+				return nil, ErrorNotProtobuf
+			}
+			value = v
+			data = data[n:]
+		case protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(data)
+			if n < 0 {
+				return nil, ErrorNotProtobuf
+			}
+			value = v
+			data = data[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return nil, ErrorNotProtobuf
+			}
+			value = rawBytesValue(v, depth)
+			data = data[n:]
+		case protowire.StartGroupType:
+			v, n := protowire.ConsumeGroup(number, data)
+			if n < 0 {
+				return nil, ErrorNotProtobuf
+			}
+			group, err := decodeRawFields(v, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			value = group
+			data = data[n:]
+		default:
+			return nil, ErrorNotProtobuf
+		}
+		key := strconv.Itoa(int(number))
+		existing, found := fields[key]
+		if !found {
+			fields[key] = value
+		} else if repeated, ok := existing.([]interface{}); ok {
+			fields[key] = append(repeated, value)
+		} else {
+			fields[key] = []interface{}{existing, value}
+		}
+	}
+	return fields, nil
+}
+
+// rawBytesValue
+// guesses the length-delimited field type: printable string, nested message or bytes
+func rawBytesValue(data []byte, depth int) interface{} {
+	if isPrintable(data) {
+		return string(data)
+	}
+	if nested, err := decodeRawFields(data, depth+1); err == nil && len(nested) > 0 {
+		return nested
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// isPrintable
+// true for valid UTF-8 text without control characters except white spaces
+func isPrintable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// itemsDescriptorSet
+// serialized set of shop.v1.Items service with Get(GetItem) returns (Item) method,
+// GetItem has int64 id = 1, Item has string name = 1 and int64 price = 2
+func itemsDescriptorSet(t *testing.T) []byte {
+	field := func(name string, number int32, fieldType descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     fieldType.Enum(),
+			JsonName: proto.String(name),
+		}
+	}
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("shop/v1/items.proto"),
+		Package: proto.String("shop.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("GetItem"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64)}},
+			{Name: proto.String("Item"), Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("price", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64)}},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Items"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Get"),
+				InputType:  proto.String(".shop.v1.GetItem"),
+				OutputType: proto.String(".shop.v1.Item"),
+			}},
+		}},
+	}}}
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestGrpcJson(t *testing.T) {
+	// Item{name: "pen", price: 150}
+	item := []byte{0x0a, 0x03, 'p', 'e', 'n', 0x10, 0x96, 0x01}
+	call := func(service string, messages ...[]byte) *GrpcCall {
+		return &GrpcCall{Service: service, Method: "Get", Messages: messages}
+	}
+	tests := []struct {
+		name      string
+		boundTo   string
+		call      *GrpcCall
+		isRequest bool
+		peerName  string
+		want      string
+	}{
+		{
+			name:      "request decoded with the unbound set",
+			call:      call("shop.v1.Items", []byte{0x08, 0x07}),
+			isRequest: true,
+			want:      `{"id":"7"}`,
+		},
+		{
+			name: "response decoded with the unbound set",
+			call: call("shop.v1.Items", item),
+			want: `{"name":"pen","price":"150"}`,
+		},
+		{
+			name:     "set bound to the serving service",
+			boundTo:  "shop",
+			call:     call("shop.v1.Items", item),
+			peerName: "shop",
+			want:     `{"name":"pen","price":"150"}`,
+		},
+		{
+			name:     "set bound to another service is not used",
+			boundTo:  "shop",
+			call:     call("shop.v1.Items", item),
+			peerName: "billing",
+			want:     `{"1":"pen","2":150}`,
+		},
+		{
+			name: "unknown method is decoded without descriptor",
+			call: call("shop.v1.Orders", item),
+			want: `{"1":"pen","2":150}`,
+		},
+		{
+			name: "stream of messages",
+			call: call("shop.v1.Items", item, item),
+			want: `[{"name":"pen","price":"150"},{"name":"pen","price":"150"}]`,
+		},
+		{
+			name: "not protobuf message is base64 encoded",
+			call: call("shop.v1.Orders", []byte{0xff, 0xff}),
+			want: `"//8="`,
+		},
+		{
+			name: "no messages",
+			call: call("shop.v1.Items"),
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewProtobufRegistry()
+			if err := registry.Add("items.pb", tt.boundTo, itemsDescriptorSet(t)); err != nil {
+				t.Fatal(err)
+			}
+			if got := registry.GrpcJson(tt.call, tt.isRequest, tt.peerName); got != tt.want {
+				t.Errorf("GrpcJson() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRawProtobuf(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "repeated varint and nested message",
+			data: []byte{0x08, 0x01, 0x08, 0x02, 0x12, 0x02, 0x08, 0x05},
+			want: `{"1":[1,2],"2":{"1":5}}`,
+		},
+		{
+			name: "fixed width fields",
+			data: []byte{0x0d, 0x01, 0x00, 0x00, 0x00, 0x11, 0x02, 0, 0, 0, 0, 0, 0, 0},
+			want: `{"1":1,"2":2}`,
+		},
+		{
+			name:    "truncated length delimited field",
+			data:    []byte{0x0a, 0x05, 'a'},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeRawProtobuf(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeRawProtobuf() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("DecodeRawProtobuf() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDescriptorSetErrors(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"not a descriptor set": {0xff},
+		"empty set":            {},
+	} {
+		if _, err := ParseDescriptorSet(data); err == nil {
+			t.Errorf("%s: ParseDescriptorSet() error is expected", name)
+		}
+	}
+}
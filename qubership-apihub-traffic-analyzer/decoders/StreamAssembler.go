@@ -0,0 +1,285 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/reassembly"
+)
+
+const (
+	// StreamIdleTimeout connections without packets for this period are flushed and closed
+	StreamIdleTimeout = 2 * time.Minute
+	// StreamFlushInterval number of assembled packets between idle connection checks
+	StreamFlushInterval = 10000
+)
+
+// assemblerContext
+// passes capture info of the current packet through the assembler
+type assemblerContext struct {
+	ci gopacket.CaptureInfo
+	// encapsulation tunnel and VLAN layers of the packet
+	encapsulation *Encapsulation
+}
+
+// GetCaptureInfo
+// to make assembler context interface implementation valid
+func (ac *assemblerContext) GetCaptureInfo() gopacket.CaptureInfo {
+	return ac.ci
+}
+
+// StreamAssembler
+// reassembles TCP segments into ordered per-connection streams (keyed on addresses and ports)
+// and passes the decoded messages into the sink
+type StreamAssembler struct {
+	assembler *reassembly.Assembler
+	packets   int
+}
+
+// NewStreamAssembler
+// creates an assembler which sends decoded messages into the sink,
+// TLS connections are decrypted when the key log has their secrets (keys may be nil)
+func NewStreamAssembler(sink MessageSink, keys *TlsKeyLog) *StreamAssembler {
+	pool := reassembly.NewStreamPool(&tcpStreamFactory{sink: sink, keys: keys, topics: NewKafkaTopics()})
+	return &StreamAssembler{
+		assembler: reassembly.NewAssembler(pool),
+		packets:   0,
+	}
+}
+
+// Assemble
+// passes the TCP segment into its connection stream, retransmissions and out-of-order segments are handled by the assembler
+func (sa *StreamAssembler) Assemble(ip *IPPacket, tcp *layers.TCP, ci gopacket.CaptureInfo) {
+	sa.assembler.AssembleWithContext(ip.NetworkFlow(), tcp, &assemblerContext{ci: ci, encapsulation: ip.Encapsulation})
+	sa.packets++
+	if sa.packets%StreamFlushInterval == 0 {
+		sa.assembler.FlushCloseOlderThan(ci.Timestamp.Add(-StreamIdleTimeout))
+	}
+}
+
+// Flush
+// closes all the streams, incomplete data is decoded as far as possible
+func (sa *StreamAssembler) Flush() int {
+	return sa.assembler.FlushAll()
+}
+
+// tcpStreamFactory
+// creates a stream for each new TCP connection
+type tcpStreamFactory struct {
+	sink MessageSink
+	keys *TlsKeyLog
+	// topics Kafka topic names known from Metadata responses of any connection
+	topics *KafkaTopics
+}
+
+// New
+// to make stream factory interface implementation valid
+func (f *tcpStreamFactory) New(netFlow, _ gopacket.Flow, tcp *layers.TCP, ac reassembly.AssemblerContext) reassembly.Stream {
+	src, dst := netFlow.Endpoints()
+	flow := FlowInfo{
+		SrcIP:   src.String(),
+		DstIP:   dst.String(),
+		SrcPort: int(tcp.SrcPort),
+		DstPort: int(tcp.DstPort),
+	}
+	if ctx, ok := ac.(*assemblerContext); ok {
+		flow.Encapsulation = ctx.encapsulation
+	}
+	stream := &tcpStream{
+		sink:   f.sink,
+		halves: [2]*httpHalfStream{newHttpHalfStream(flow), newHttpHalfStream(flow.Reverse())},
+		h2:     nil,
+		keys:   f.keys,
+		topics: f.topics,
+		tls:    nil,
+	}
+	stream.exchanges = newExchangeTracker(f.sink, stream.upgrade)
+	return stream
+}
+
+// tcpStream
+// both directions of a TCP connection
+type tcpStream struct {
+	sink      MessageSink
+	exchanges *exchangeTracker
+	halves    [2]*httpHalfStream
+	// h2 HTTP/2 state, nil for HTTP/1.x connection
+	h2   *http2Connection
+	keys *TlsKeyLog
+	// ws WebSocket state, nil before the handshake
+	ws *webSocketConnection
+	// kafka Kafka state, nil for not Kafka connection
+	kafka  *kafkaConnection
+	topics *KafkaTopics
+	// tls TLS state, nil for plain text connection
+	tls *tlsConnection
+	// tlsChecked connection start was checked for TLS ClientHello
+	tlsChecked bool
+	// kafkaChecked connection start was checked for Kafka request, the check is not repeated after data loss
+	kafkaChecked bool
+}
+
+// index
+// returns stream part index for the direction
+func (s *tcpStream) index(dir reassembly.TCPFlowDirection) int {
+	if dir == reassembly.TCPDirClientToServer {
+		return 0
+	}
+	return 1
+}
+
+// half
+// returns stream part for the direction
+func (s *tcpStream) half(dir reassembly.TCPFlowDirection) *httpHalfStream {
+	return s.halves[s.index(dir)]
+}
+
+// other
+// returns opposite direction of the stream part
+func (s *tcpStream) other(half *httpHalfStream) *httpHalfStream {
+	if half == s.halves[0] {
+		return s.halves[1]
+	}
+	return s.halves[0]
+}
+
+// startHttp2
+// switches the connection to HTTP/2, client sends requests
+func (s *tcpStream) startHttp2(client *httpHalfStream) {
+	server := s.other(client)
+	s.h2 = newHttp2Connection(s.sink, client, server)
+	// the server may send SETTINGS before the client preface is seen
+	server.parse(false, s)
+}
+
+// startKafka
+// switches the connection to Kafka protocol, client sends requests
+func (s *tcpStream) startKafka(client *httpHalfStream) {
+	s.kafka = newKafkaConnection(s.sink, client, s.topics)
+	// the broker may answer before the request is seen as a whole
+	s.other(client).parse(false, s)
+}
+
+// upgrade
+// switches the connection to HTTP/2 after h2c upgrade or to WebSocket after the handshake,
+// returns true when the request is answered using the new protocol
+func (s *tcpStream) upgrade(request, response *HttpMessage) bool {
+	client := s.other(s.halves[0])
+	if s.halves[0].flow == request.Flow {
+		client = s.halves[0]
+	}
+	if isWebSocketUpgrade(request, response) {
+		// the handshake is answered by 101 response, frames follow it in both directions
+		s.ws = newWebSocketConnection(s.sink, request, response, client, s.other(client))
+		client.parse(false, s)
+		return false
+	}
+	if !isHttp2Upgrade(request, response) {
+		return false
+	}
+	s.h2 = newHttp2Connection(s.sink, client, s.other(client))
+	s.h2.startUpgraded(request)
+	client.parse(false, s)
+	return true
+}
+
+// Accept
+// accepts all the segments, connections without SYN (started before the capture) are accepted too
+func (s *tcpStream) Accept(tcp *layers.TCP, _ gopacket.CaptureInfo, dir reassembly.TCPFlowDirection, nextSeq reassembly.Sequence, start *bool, _ reassembly.AssemblerContext) bool {
+	half := s.half(dir)
+	if nextSeq < 0 {
+		// the first segment in this direction, assembler starts from it
+		seq := tcp.Seq
+		if tcp.SYN {
+			seq++
+		}
+		half.bufSeq = seq
+	}
+	if tcp.ACK {
+		half.ackNo = tcp.Ack
+	}
+	*start = true
+	return true
+}
+
+// ReassembledSG
+// receives ordered stream data
+func (s *tcpStream) ReassembledSG(sg reassembly.ScatterGather, _ reassembly.AssemblerContext) {
+	dir, _, _, skip := sg.Info()
+	idx := s.index(dir)
+	half := s.halves[idx]
+	if skip > 0 {
+		s.kafkaChecked = true
+		if s.tls != nil {
+			s.tls.skip(idx)
+		} else {
+			half.skip(skip)
+		}
+	}
+	length, _ := sg.Lengths()
+	if length > 0 {
+		data := sg.Fetch(length)
+		timestamp := sg.CaptureInfo(0).Timestamp
+		if !s.tlsChecked && len(data) > tlsRecordHeaderSize {
+			s.tlsChecked = true
+			if isTlsClientHello(data) {
+				if serverName := tlsServerName(data); serverName != "" {
+					s.sink.OnServerName(half.flow, serverName)
+				}
+				if s.keys.Len() > 0 {
+					s.tls = newTlsConnection(s.keys, half.flow)
+				}
+			}
+		}
+		if s.tls != nil {
+			s.readTls(idx, data, timestamp)
+			return
+		}
+		half.append(data, timestamp)
+		half.parse(false, s)
+	}
+}
+
+// readTls
+// decrypts the direction data, the opposite direction may wait for the keys known from this data
+func (s *tcpStream) readTls(idx int, data []byte, timestamp time.Time) {
+	for _, i := range []int{idx, 1 - idx} {
+		var chunk []byte = nil
+		if i == idx {
+			chunk = data
+		}
+		plain := s.tls.read(i, chunk)
+		if len(plain) > 0 {
+			s.halves[i].append(plain, timestamp)
+			s.halves[i].parse(false, s)
+		}
+	}
+}
+
+// ReassemblyComplete
+// decodes the rest of data when the connection is closed or timed out
+func (s *tcpStream) ReassemblyComplete(_ reassembly.AssemblerContext) bool {
+	for _, half := range s.halves {
+		half.parse(true, s)
+	}
+	if s.h2 != nil {
+		s.h2.flush()
+	}
+	s.exchanges.flush()
+	return true
+}
@@ -0,0 +1,189 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+const (
+	// testClientSeq, testServerSeq initial sequence numbers of the test connection directions
+	testClientSeq = 1000
+	testServerSeq = 5000
+	// testServerPort server port of the test connection 10.0.0.1:40000 - 10.0.0.2:testServerPort
+	testServerPort = 8080
+)
+
+// testSegment
+// a TCP segment of the test connection, offset is relative to the initial sequence number of the direction
+type testSegment struct {
+	fromClient bool
+	offset     uint32
+	data       string
+}
+
+// testPart
+// data sent in one direction of the test connection
+type testPart struct {
+	fromClient bool
+	data       string
+}
+
+// conversation
+// makes segments of the parts sent one after another, each part is a single segment
+func conversation(parts ...testPart) []testSegment {
+	segments := make([]testSegment, 0, len(parts))
+	var clientOffset, serverOffset uint32
+	for _, part := range parts {
+		offset := &serverOffset
+		if part.fromClient {
+			offset = &clientOffset
+		}
+		segments = append(segments, testSegment{fromClient: part.fromClient, offset: *offset, data: part.data})
+		*offset += uint32(len(part.data))
+	}
+	return segments
+}
+
+// testSink
+// keeps everything sent into the sink
+type testSink struct {
+	messages    []*HttpMessage
+	exchanges   []*HttpExchange
+	webSocket   []*WebSocketMessage
+	kafka       []*KafkaMessage
+	serverNames []string
+	rejected    []string
+}
+
+func (ts *testSink) OnHttpMessage(msg *HttpMessage) {
+	ts.messages = append(ts.messages, msg)
+}
+
+func (ts *testSink) OnHttpExchange(exchange *HttpExchange) {
+	ts.exchanges = append(ts.exchanges, exchange)
+}
+
+func (ts *testSink) OnWebSocketMessage(msg *WebSocketMessage) {
+	ts.webSocket = append(ts.webSocket, msg)
+}
+
+func (ts *testSink) OnKafkaMessage(msg *KafkaMessage) {
+	ts.kafka = append(ts.kafka, msg)
+}
+
+func (ts *testSink) OnServerName(_ FlowInfo, serverName string) {
+	ts.serverNames = append(ts.serverNames, serverName)
+}
+
+func (ts *testSink) OnRejected(_ FlowInfo, reason string) {
+	ts.rejected = append(ts.rejected, reason)
+}
+
+// assembleSegments
+// passes the segments of the test connection into a new assembler and flushes it
+func assembleSegments(keys *TlsKeyLog, segments ...testSegment) *testSink {
+	sink := &testSink{}
+	assembler := NewStreamAssembler(sink, keys)
+	client, server := net.ParseIP("10.0.0.1").To4(), net.ParseIP("10.0.0.2").To4()
+	timestamp := time.Unix(1700000000, 0)
+	for _, segment := range segments {
+		ip := &IPPacket{Version: IPv4, SrcIP: client.String(), DstIP: server.String(), SrcAddr: client, DstAddr: server}
+		tcp := &layers.TCP{SrcPort: 40000, DstPort: testServerPort, Seq: testClientSeq + segment.offset, Ack: testServerSeq,
+			ACK: true, PSH: true}
+		if !segment.fromClient {
+			ip.SrcIP, ip.DstIP, ip.SrcAddr, ip.DstAddr = ip.DstIP, ip.SrcIP, ip.DstAddr, ip.SrcAddr
+			tcp.SrcPort, tcp.DstPort = tcp.DstPort, tcp.SrcPort
+			tcp.Seq, tcp.Ack = testServerSeq+segment.offset, testClientSeq
+		}
+		tcp.Payload = []byte(segment.data)
+		timestamp = timestamp.Add(time.Millisecond)
+		assembler.Assemble(ip, tcp, gopacket.CaptureInfo{Timestamp: timestamp, CaptureLength: len(segment.data), Length: len(segment.data)})
+	}
+	assembler.Flush()
+	return sink
+}
+
+func TestStreamAssemblerReassemblesHttpMessages(t *testing.T) {
+	const request = "POST /items HTTP/1.1\r\nHost: example.com\r\nContent-Length: 11\r\n\r\n{\"id\":\"a1\"}"
+	const response = "HTTP/1.1 201 Created\r\nContent-Length: 2\r\n\r\nok"
+	tests := []struct {
+		name     string
+		segments []testSegment
+	}{
+		{
+			name:     "single segments",
+			segments: conversation(testPart{true, request}, testPart{false, response}),
+		},
+		{
+			name: "message split across segments",
+			segments: []testSegment{
+				{fromClient: true, offset: 0, data: request[:7]},
+				{fromClient: true, offset: 7, data: request[7:40]},
+				{fromClient: true, offset: 40, data: request[40:]},
+				{fromClient: false, offset: 0, data: response},
+			},
+		},
+		{
+			name: "out of order segments",
+			segments: []testSegment{
+				// the assembler starts the direction from its first segment
+				{fromClient: true, offset: 0, data: request[:10]},
+				{fromClient: true, offset: 30, data: request[30:]},
+				{fromClient: true, offset: 10, data: request[10:30]},
+				{fromClient: false, offset: 0, data: response[:10]},
+				{fromClient: false, offset: 20, data: response[20:]},
+				{fromClient: false, offset: 10, data: response[10:20]},
+			},
+		},
+		{
+			name: "retransmitted segment",
+			segments: []testSegment{
+				{fromClient: true, offset: 0, data: request[:30]},
+				{fromClient: true, offset: 0, data: request[:30]},
+				{fromClient: true, offset: 30, data: request[30:]},
+				{fromClient: false, offset: 0, data: response},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := assembleSegments(nil, tt.segments...)
+			if len(sink.messages) != 2 {
+				t.Fatalf("got %d messages, want 2", len(sink.messages))
+			}
+			req, rsp := sink.messages[0], sink.messages[1]
+			if !req.IsRequest || req.Method != "POST" || req.Path != "/items" || req.Host != "example.com" ||
+				string(req.Body) != `{"id":"a1"}` || req.SeqNo != testClientSeq {
+				t.Errorf("request %s %s host %s body %q seq %d", req.Method, req.Path, req.Host, req.Body, req.SeqNo)
+			}
+			if rsp.IsRequest || rsp.StatusCode != 201 || rsp.Path != "/items" || string(rsp.Body) != "ok" {
+				t.Errorf("response %d path %s body %q", rsp.StatusCode, rsp.Path, rsp.Body)
+			}
+			if !strings.HasPrefix(string(req.Raw), "POST /items") {
+				t.Errorf("request raw data %q", req.Raw)
+			}
+			if len(sink.rejected) != 0 {
+				t.Errorf("rejected %v", sink.rejected)
+			}
+		})
+	}
+}
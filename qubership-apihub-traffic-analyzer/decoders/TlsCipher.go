@@ -0,0 +1,308 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// tlsCipherKind record protection algorithm
+type tlsCipherKind int
+
+const (
+	tlsCipherGcm tlsCipherKind = iota
+	tlsCipherChaCha
+	tlsCipherCbc
+)
+
+// ErrorTlsDecrypt record can not be decrypted with the current keys
+var ErrorTlsDecrypt = errors.New("unable to decrypt TLS record")
+
+// tlsCipherSuite
+// key material layout of the supported cipher suite
+type tlsCipherSuite struct {
+	kind tlsCipherKind
+	// keyLen encryption key length
+	keyLen int
+	// ivLen implicit (fixed) IV length
+	ivLen int
+	// macLen HMAC length for CBC suites
+	macLen int
+	// hash PRF (TLS 1.2) or HKDF (TLS 1.3) hash
+	hash func() hash.Hash
+}
+
+var (
+	tlsAes128Gcm       = &tlsCipherSuite{kind: tlsCipherGcm, keyLen: 16, ivLen: 4, hash: sha256.New}
+	tlsAes256Gcm       = &tlsCipherSuite{kind: tlsCipherGcm, keyLen: 32, ivLen: 4, hash: sha512.New384}
+	tlsChaCha          = &tlsCipherSuite{kind: tlsCipherChaCha, keyLen: 32, ivLen: 12, hash: sha256.New}
+	tlsAes128CbcSha    = &tlsCipherSuite{kind: tlsCipherCbc, keyLen: 16, macLen: 20, hash: sha256.New}
+	tlsAes256CbcSha    = &tlsCipherSuite{kind: tlsCipherCbc, keyLen: 32, macLen: 20, hash: sha256.New}
+	tlsAes128CbcSha256 = &tlsCipherSuite{kind: tlsCipherCbc, keyLen: 16, macLen: 32, hash: sha256.New}
+	tlsAes256CbcSha256 = &tlsCipherSuite{kind: tlsCipherCbc, keyLen: 32, macLen: 32, hash: sha256.New}
+	tlsAes256CbcSha384 = &tlsCipherSuite{kind: tlsCipherCbc, keyLen: 32, macLen: 48, hash: sha512.New384}
+)
+
+// tls12CipherSuites supported TLS 1.2 cipher suites
+var tls12CipherSuites = map[uint16]*tlsCipherSuite{
+	0x009C: tlsAes128Gcm,       // TLS_RSA_WITH_AES_128_GCM_SHA256
+	0x009D: tlsAes256Gcm,       // TLS_RSA_WITH_AES_256_GCM_SHA384
+	0x009E: tlsAes128Gcm,       // TLS_DHE_RSA_WITH_AES_128_GCM_SHA256
+	0x009F: tlsAes256Gcm,       // TLS_DHE_RSA_WITH_AES_256_GCM_SHA384
+	0xC02B: tlsAes128Gcm,       // TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256
+	0xC02C: tlsAes256Gcm,       // TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384
+	0xC02F: tlsAes128Gcm,       // TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+	0xC030: tlsAes256Gcm,       // TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384
+	0xCCA8: tlsChaCha,          // TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256
+	0xCCA9: tlsChaCha,          // TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256
+	0xCCAA: tlsChaCha,          // TLS_DHE_RSA_WITH_CHACHA20_POLY1305_SHA256
+	0x002F: tlsAes128CbcSha,    // TLS_RSA_WITH_AES_128_CBC_SHA
+	0x0033: tlsAes128CbcSha,    // TLS_DHE_RSA_WITH_AES_128_CBC_SHA
+	0x0035: tlsAes256CbcSha,    // TLS_RSA_WITH_AES_256_CBC_SHA
+	0x0039: tlsAes256CbcSha,    // TLS_DHE_RSA_WITH_AES_256_CBC_SHA
+	0xC009: tlsAes128CbcSha,    // TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA
+	0xC00A: tlsAes256CbcSha,    // TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA
+	0xC013: tlsAes128CbcSha,    // TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA
+	0xC014: tlsAes256CbcSha,    // TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA
+	0x003C: tlsAes128CbcSha256, // TLS_RSA_WITH_AES_128_CBC_SHA256
+	0x003D: tlsAes256CbcSha256, // TLS_RSA_WITH_AES_256_CBC_SHA256
+	0x0067: tlsAes128CbcSha256, // TLS_DHE_RSA_WITH_AES_128_CBC_SHA256
+	0x006B: tlsAes256CbcSha256, // TLS_DHE_RSA_WITH_AES_256_CBC_SHA256
+	0xC023: tlsAes128CbcSha256, // TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256
+	0xC024: tlsAes256CbcSha384, // TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA384
+	0xC027: tlsAes128CbcSha256, // TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256
+	0xC028: tlsAes256CbcSha384, // TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA384
+}
+
+// tls13CipherSuites supported TLS 1.3 cipher suites (IV length is always 12 bytes)
+var tls13CipherSuites = map[uint16]*tlsCipherSuite{
+	0x1301: {kind: tlsCipherGcm, keyLen: 16, ivLen: 12, hash: sha256.New},    // TLS_AES_128_GCM_SHA256
+	0x1302: {kind: tlsCipherGcm, keyLen: 32, ivLen: 12, hash: sha512.New384}, // TLS_AES_256_GCM_SHA384
+	0x1303: {kind: tlsCipherChaCha, keyLen: 32, ivLen: 12, hash: sha256.New}, // TLS_CHACHA20_POLY1305_SHA256
+}
+
+// tlsRecordCipher
+// decrypts records of one connection direction, the sequence number advances on successful decryption only
+type tlsRecordCipher interface {
+	// decrypt returns record content type (inner type for TLS 1.3) and plain text
+	decrypt(recordType uint8, version uint16, payload []byte) (uint8, []byte, error)
+}
+
+// tlsAeadCipher
+// AES-GCM and ChaCha20-Poly1305 record protection
+type tlsAeadCipher struct {
+	aead cipher.AEAD
+	iv   []byte
+	// explicitNonce TLS 1.2 AES-GCM records start with 8 bytes of the nonce
+	explicitNonce bool
+	tls13         bool
+	seq           uint64
+}
+
+// tlsCbcCipher
+// TLS 1.2 AES-CBC record protection, MAC is stripped but not verified
+type tlsCbcCipher struct {
+	block  cipher.Block
+	macLen int
+	// encryptThenMac MAC follows the cipher text (RFC 7366)
+	encryptThenMac bool
+	seq            uint64
+}
+
+// newTls12Cipher
+// derives direction keys from the master secret
+func newTls12Cipher(suite *tlsCipherSuite, masterSecret, clientRandom, serverRandom []byte, client, encryptThenMac bool) (tlsRecordCipher, error) {
+	seed := make([]byte, 0, 2*tlsRandomSize)
+	seed = append(seed, serverRandom...)
+	seed = append(seed, clientRandom...)
+	keyBlock := tlsPrf(suite.hash, masterSecret, "key expansion", seed, 2*(suite.macLen+suite.keyLen+suite.ivLen))
+	offset := 2 * suite.macLen
+	if !client {
+		offset += suite.keyLen
+	}
+	key := keyBlock[offset : offset+suite.keyLen]
+	offset = 2 * (suite.macLen + suite.keyLen)
+	if !client {
+		offset += suite.ivLen
+	}
+	iv := keyBlock[offset : offset+suite.ivLen]
+	switch suite.kind {
+	case tlsCipherCbc:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return &tlsCbcCipher{block: block, macLen: suite.macLen, encryptThenMac: encryptThenMac}, nil
+	default:
+		aead, err := newTlsAead(suite, key)
+		if err != nil {
+			return nil, err
+		}
+		return &tlsAeadCipher{aead: aead, iv: iv, explicitNonce: suite.kind == tlsCipherGcm}, nil
+	}
+}
+
+// newTls13Cipher
+// derives record keys from the traffic secret
+func newTls13Cipher(suite *tlsCipherSuite, secret []byte) (tlsRecordCipher, error) {
+	key := hkdfExpandLabel(suite.hash, secret, "key", suite.keyLen)
+	iv := hkdfExpandLabel(suite.hash, secret, "iv", suite.ivLen)
+	aead, err := newTlsAead(suite, key)
+	if err != nil {
+		return nil, err
+	}
+	return &tlsAeadCipher{aead: aead, iv: iv, tls13: true}, nil
+}
+
+// nextTls13Secret
+// computes the traffic secret after KeyUpdate
+func nextTls13Secret(suite *tlsCipherSuite, secret []byte) []byte {
+	return hkdfExpandLabel(suite.hash, secret, "traffic upd", len(secret))
+}
+
+// newTlsAead
+// creates AEAD for the suite
+func newTlsAead(suite *tlsCipherSuite, key []byte) (cipher.AEAD, error) {
+	if suite.kind == tlsCipherChaCha {
+		return chacha20poly1305.New(key)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decrypt
+// to make record cipher interface implementation valid
+func (c *tlsAeadCipher) decrypt(recordType uint8, version uint16, payload []byte) (uint8, []byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if c.explicitNonce {
+		if len(payload) < 8+c.aead.Overhead() {
+			return 0, nil, ErrorTlsDecrypt
+		}
+		copy(nonce, c.iv)
+		copy(nonce[len(c.iv):], payload[:8])
+		payload = payload[8:]
+	} else {
+		if len(payload) < c.aead.Overhead() {
+			return 0, nil, ErrorTlsDecrypt
+		}
+		copy(nonce, c.iv)
+		for i := 0; i < 8; i++ {
+			nonce[len(nonce)-1-i] ^= byte(c.seq >> (8 * i))
+		}
+	}
+	var additional []byte
+	if c.tls13 {
+		additional = []byte{recordType, byte(version >> 8), byte(version), 0, 0}
+		binary.BigEndian.PutUint16(additional[3:], uint16(len(payload)))
+	} else {
+		additional = make([]byte, 13)
+		binary.BigEndian.PutUint64(additional, c.seq)
+		additional[8] = recordType
+		binary.BigEndian.PutUint16(additional[9:], version)
+		binary.BigEndian.PutUint16(additional[11:], uint16(len(payload)-c.aead.Overhead()))
+	}
+	plain, err := c.aead.Open(nil, nonce, payload, additional)
+	if err != nil {
+		return 0, nil, ErrorTlsDecrypt
+	}
+	c.seq++
+	if !c.tls13 {
+		return recordType, plain, nil
+	}
+	// inner plain text: content, content type, zero padding
+	for i := len(plain) - 1; i >= 0; i-- {
+		if plain[i] != 0 {
+			return plain[i], plain[:i], nil
+		}
+	}
+	return 0, nil, ErrorTlsDecrypt
+}
+
+// decrypt
+// to make record cipher interface implementation valid
+func (c *tlsCbcCipher) decrypt(recordType uint8, _ uint16, payload []byte) (uint8, []byte, error) {
+	blockSize := c.block.BlockSize()
+	if c.encryptThenMac {
+		if len(payload) < c.macLen {
+			return 0, nil, ErrorTlsDecrypt
+		}
+		payload = payload[:len(payload)-c.macLen]
+	}
+	if len(payload) < 2*blockSize || len(payload)%blockSize != 0 {
+		return 0, nil, ErrorTlsDecrypt
+	}
+	plain := make([]byte, len(payload)-blockSize)
+	cipher.NewCBCDecrypter(c.block, payload[:blockSize]).CryptBlocks(plain, payload[blockSize:])
+	padding := int(plain[len(plain)-1]) + 1
+	if padding > len(plain) {
+		return 0, nil, ErrorTlsDecrypt
+	}
+	plain = plain[:len(plain)-padding]
+	if !c.encryptThenMac {
+		if len(plain) < c.macLen {
+			return 0, nil, ErrorTlsDecrypt
+		}
+		plain = plain[:len(plain)-c.macLen]
+	}
+	c.seq++
+	return recordType, plain, nil
+}
+
+// tlsPrf
+// TLS 1.2 pseudo random function (RFC 5246 section 5)
+func tlsPrf(hash func() hash.Hash, secret []byte, label string, seed []byte, length int) []byte {
+	labelSeed := append([]byte(label), seed...)
+	result := make([]byte, 0, length)
+	mac := hmac.New(hash, secret)
+	mac.Write(labelSeed)
+	a := mac.Sum(nil)
+	for len(result) < length {
+		mac.Reset()
+		mac.Write(a)
+		mac.Write(labelSeed)
+		result = append(result, mac.Sum(nil)...)
+		mac.Reset()
+		mac.Write(a)
+		a = mac.Sum(nil)
+	}
+	return result[:length]
+}
+
+// hkdfExpandLabel
+// TLS 1.3 key derivation (RFC 8446 section 7.1) with empty context
+func hkdfExpandLabel(hash func() hash.Hash, secret []byte, label string, length int) []byte {
+	fullLabel := "tls13 " + label
+	info := make([]byte, 0, 4+len(fullLabel))
+	info = binary.BigEndian.AppendUint16(info, uint16(length))
+	info = append(info, byte(len(fullLabel)))
+	info = append(info, fullLabel...)
+	info = append(info, 0)
+	result := make([]byte, length)
+	_, _ = hkdf.Expand(hash, secret, info).Read(result)
+	return result
+}
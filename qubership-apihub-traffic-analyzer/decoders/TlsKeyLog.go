@@ -0,0 +1,127 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// keyLogClientRandom TLS 1.2 master secret
+	keyLogClientRandom = "CLIENT_RANDOM"
+	// keyLogClientHandshake TLS 1.3 client handshake traffic secret
+	keyLogClientHandshake = "CLIENT_HANDSHAKE_TRAFFIC_SECRET"
+	// keyLogServerHandshake TLS 1.3 server handshake traffic secret
+	keyLogServerHandshake = "SERVER_HANDSHAKE_TRAFFIC_SECRET"
+	// keyLogClientTraffic TLS 1.3 first client application traffic secret
+	keyLogClientTraffic = "CLIENT_TRAFFIC_SECRET_0"
+	// keyLogServerTraffic TLS 1.3 first server application traffic secret
+	keyLogServerTraffic = "SERVER_TRAFFIC_SECRET_0"
+	// tlsRandomSize client random length
+	tlsRandomSize = 32
+)
+
+// TlsKeyLog
+// TLS secrets from NSS key log files (SSLKEYLOGFILE format), keyed by label and client random
+type TlsKeyLog struct {
+	lock    sync.RWMutex
+	secrets map[string][]byte
+}
+
+// NewTlsKeyLog
+// creates an empty key log
+func NewTlsKeyLog() *TlsKeyLog {
+	return &TlsKeyLog{
+		lock:    sync.RWMutex{},
+		secrets: make(map[string][]byte),
+	}
+}
+
+// Read
+// adds secrets from the key log file contents, returns number of the secrets read and of the malformed lines skipped,
+// comments and unsupported labels are skipped silently
+func (kl *TlsKeyLog) Read(r io.Reader) (int, int, error) {
+	count := 0
+	skipped := 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), 64*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			log.Tracef("malformed key log line %d skipped", lineNo)
+			skipped++
+			continue
+		}
+		switch fields[0] {
+		case keyLogClientRandom, keyLogClientHandshake, keyLogServerHandshake, keyLogClientTraffic, keyLogServerTraffic:
+		default:
+			continue
+		}
+		random, err := hex.DecodeString(fields[1])
+		if err != nil || len(random) != tlsRandomSize {
+			log.Tracef("invalid client random at key log line %d skipped", lineNo)
+			skipped++
+			continue
+		}
+		secret, err := hex.DecodeString(fields[2])
+		if err != nil || len(secret) == 0 {
+			log.Tracef("invalid secret at key log line %d skipped", lineNo)
+			skipped++
+			continue
+		}
+		kl.lock.Lock()
+		kl.secrets[keyLogKey(fields[0], random)] = secret
+		kl.lock.Unlock()
+		count++
+	}
+	return count, skipped, scanner.Err()
+}
+
+// Len
+// returns number of the known secrets
+func (kl *TlsKeyLog) Len() int {
+	if kl == nil {
+		return 0
+	}
+	kl.lock.RLock()
+	defer kl.lock.RUnlock()
+	return len(kl.secrets)
+}
+
+// lookup
+// returns the secret for the label and client random, nil when not found
+func (kl *TlsKeyLog) lookup(label string, clientRandom []byte) []byte {
+	kl.lock.RLock()
+	defer kl.lock.RUnlock()
+	return kl.secrets[keyLogKey(label, clientRandom)]
+}
+
+// keyLogKey
+// makes secrets map key
+func keyLogKey(label string, clientRandom []byte) string {
+	return label + " " + hex.EncodeToString(clientRandom)
+}
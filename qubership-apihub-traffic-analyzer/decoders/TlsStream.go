@@ -0,0 +1,453 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// tlsRecordHeaderSize content type, legacy version and length
+	tlsRecordHeaderSize = 5
+	// tlsMaxRecordSize the longest protected record payload
+	tlsMaxRecordSize = 16384 + 2048
+	// tlsHandshakeHeaderSize handshake message type and length
+	tlsHandshakeHeaderSize = 4
+
+	tlsRecordChangeCipherSpec uint8 = 20
+	tlsRecordAlert            uint8 = 21
+	tlsRecordHandshake        uint8 = 22
+	tlsRecordApplicationData  uint8 = 23
+
+	tlsHandshakeClientHello uint8 = 1
+	tlsHandshakeServerHello uint8 = 2
+	tlsHandshakeFinished    uint8 = 20
+	tlsHandshakeKeyUpdate   uint8 = 24
+
+	tlsVersion12 uint16 = 0x0303
+	tlsVersion13 uint16 = 0x0304
+
+	tlsExtensionServerName        uint16 = 0
+	tlsExtensionEncryptThenMac    uint16 = 22
+	tlsExtensionSupportedVersions uint16 = 43
+
+	// tlsServerNameHost host name type of server name indication
+	tlsServerNameHost uint8 = 0
+)
+
+// tlsHelloRetryRandom server random of TLS 1.3 HelloRetryRequest
+var tlsHelloRetryRandom = []byte{
+	0xCF, 0x21, 0xAD, 0x74, 0xE5, 0x9A, 0x61, 0x11, 0xBE, 0x1D, 0x8C, 0x02, 0x1E, 0x65, 0xB8, 0x91,
+	0xC2, 0xA2, 0x11, 0x16, 0x7A, 0xBB, 0x8C, 0x5E, 0x07, 0x9E, 0x09, 0xE2, 0xC8, 0xA8, 0x33, 0x9C,
+}
+
+// tlsDirection
+// records sent in one direction of TLS connection
+type tlsDirection struct {
+	// buf incomplete record
+	buf []byte
+	// handshake incomplete handshake message
+	handshake []byte
+	// changeCipher TLS 1.2 ChangeCipherSpec seen, the following records are protected
+	changeCipher bool
+	cipher       tlsRecordCipher
+	// secret current TLS 1.3 traffic secret
+	secret []byte
+	// application TLS 1.3 application traffic keys are in use
+	application bool
+	// broken records can not be followed anymore
+	broken bool
+}
+
+// tlsConnection
+// decrypts TLS 1.2/1.3 records with the secrets from the key log
+type tlsConnection struct {
+	keys *TlsKeyLog
+	flow FlowInfo
+	dirs [2]*tlsDirection
+	// client index of the direction which sent ClientHello, -1 while unknown
+	client       int
+	clientRandom []byte
+	serverRandom []byte
+	// serverHello negotiated parameters are known
+	serverHello    bool
+	version        uint16
+	suite          *tlsCipherSuite
+	encryptThenMac bool
+	// undecryptable no keys or unsupported parameters, all the data is dropped
+	undecryptable bool
+}
+
+// isTlsClientHello
+// true when data starts with TLS handshake record containing ClientHello
+func isTlsClientHello(data []byte) bool {
+	return len(data) > tlsRecordHeaderSize &&
+		data[0] == tlsRecordHandshake && data[1] == 3 && data[2] <= 4 &&
+		data[tlsRecordHeaderSize] == tlsHandshakeClientHello
+}
+
+// tlsServerName
+// returns the host name of server name indication extension from the first ClientHello record,
+// empty string when the extension is not present or the record is incomplete
+func tlsServerName(data []byte) string {
+	if !isTlsClientHello(data) {
+		return ""
+	}
+	length := int(binary.BigEndian.Uint16(data[3:tlsRecordHeaderSize]))
+	if len(data) > tlsRecordHeaderSize+length {
+		data = data[:tlsRecordHeaderSize+length]
+	}
+	body := data[tlsRecordHeaderSize+tlsHandshakeHeaderSize:]
+	pos := 2 + tlsRandomSize // legacy version and random
+	// session id, cipher suites and compression methods
+	for _, sizeLen := range []int{1, 2, 1} {
+		if len(body) < pos+sizeLen {
+			return ""
+		}
+		size := int(body[pos])
+		if sizeLen == 2 {
+			size = int(binary.BigEndian.Uint16(body[pos : pos+2]))
+		}
+		pos += sizeLen + size
+	}
+	if len(body) < pos+2 {
+		return ""
+	}
+	extensions := body[pos+2:]
+	for len(extensions) >= 4 {
+		extType := binary.BigEndian.Uint16(extensions[0:2])
+		extLen := int(binary.BigEndian.Uint16(extensions[2:4]))
+		if len(extensions) < 4+extLen {
+			return ""
+		}
+		if extType == tlsExtensionServerName {
+			// server name list: list length, then name type, name length and name
+			names := extensions[4 : 4+extLen]
+			if len(names) < 2 {
+				return ""
+			}
+			names = names[2:]
+			for len(names) >= 3 {
+				nameLen := int(binary.BigEndian.Uint16(names[1:3]))
+				if len(names) < 3+nameLen {
+					return ""
+				}
+				if names[0] == tlsServerNameHost {
+					return string(names[3 : 3+nameLen])
+				}
+				names = names[3+nameLen:]
+			}
+			return ""
+		}
+		extensions = extensions[4+extLen:]
+	}
+	return ""
+}
+
+// newTlsConnection
+// creates TLS state, flow is the direction of the first segment
+func newTlsConnection(keys *TlsKeyLog, flow FlowInfo) *tlsConnection {
+	return &tlsConnection{
+		keys:   keys,
+		flow:   flow,
+		dirs:   [2]*tlsDirection{{}, {}},
+		client: -1,
+	}
+}
+
+// read
+// adds the direction data and returns decrypted application data of the complete records
+func (tc *tlsConnection) read(idx int, data []byte) []byte {
+	dir := tc.dirs[idx]
+	if dir.broken || tc.undecryptable {
+		return nil
+	}
+	dir.buf = append(dir.buf, data...)
+	var plain []byte = nil
+	for len(dir.buf) >= tlsRecordHeaderSize {
+		recordType := dir.buf[0]
+		version := binary.BigEndian.Uint16(dir.buf[1:3])
+		length := int(binary.BigEndian.Uint16(dir.buf[3:tlsRecordHeaderSize]))
+		if recordType < tlsRecordChangeCipherSpec || recordType > tlsRecordApplicationData || dir.buf[1] != 3 || length > tlsMaxRecordSize {
+			tc.fail(dir, "not a TLS record")
+			return plain
+		}
+		if len(dir.buf) < tlsRecordHeaderSize+length {
+			break
+		}
+		payload := dir.buf[tlsRecordHeaderSize : tlsRecordHeaderSize+length]
+		if tc.protected(dir, recordType) {
+			if !tc.serverHello {
+				// keys depend on ServerHello which is not seen yet
+				if len(dir.buf) > MaxHttpMessageSize {
+					tc.fail(dir, "no ServerHello")
+				}
+				break
+			}
+			if !tc.ensureCipher(idx) {
+				return plain
+			}
+			innerType, content, err := dir.cipher.decrypt(recordType, version, payload)
+			if err != nil {
+				log.Tracef("TLS record of type %d skipped in stream %s:%d->%s:%d: %v",
+					recordType, tc.flow.SrcIP, tc.flow.SrcPort, tc.flow.DstIP, tc.flow.DstPort, err)
+			} else {
+				switch innerType {
+				case tlsRecordApplicationData:
+					plain = append(plain, content...)
+				case tlsRecordHandshake:
+					if tc.version == tlsVersion13 {
+						tc.onHandshake(idx, content, true)
+					}
+				}
+			}
+		} else {
+			switch recordType {
+			case tlsRecordChangeCipherSpec:
+				// TLS 1.3 sends it for middlebox compatibility only
+				if tc.version != tlsVersion13 {
+					dir.changeCipher = true
+					dir.handshake = nil
+				}
+			case tlsRecordHandshake:
+				tc.onHandshake(idx, payload, false)
+			}
+		}
+		if dir.broken || tc.undecryptable {
+			return plain
+		}
+		dir.buf = dir.buf[tlsRecordHeaderSize+length:]
+	}
+	if len(dir.buf) == 0 {
+		dir.buf = nil
+	}
+	return plain
+}
+
+// skip
+// stops decryption of the direction when stream bytes were lost
+func (tc *tlsConnection) skip(idx int) {
+	tc.fail(tc.dirs[idx], "stream bytes lost")
+}
+
+// fail
+// stops decryption of the direction
+func (tc *tlsConnection) fail(dir *tlsDirection, reason string) {
+	if !dir.broken {
+		log.Tracef("TLS decryption stopped in stream %s:%d->%s:%d: %s",
+			tc.flow.SrcIP, tc.flow.SrcPort, tc.flow.DstIP, tc.flow.DstPort, reason)
+	}
+	dir.broken = true
+	dir.buf = nil
+	dir.handshake = nil
+}
+
+// unsupported
+// stops decryption of the connection
+func (tc *tlsConnection) unsupported(reason string) {
+	log.Debugf("unable to decrypt TLS stream %s:%d->%s:%d: %s",
+		tc.flow.SrcIP, tc.flow.SrcPort, tc.flow.DstIP, tc.flow.DstPort, reason)
+	tc.undecryptable = true
+	tc.dirs[0].buf = nil
+	tc.dirs[1].buf = nil
+}
+
+// protected
+// true when the record is encrypted
+func (tc *tlsConnection) protected(dir *tlsDirection, recordType uint8) bool {
+	if recordType == tlsRecordApplicationData {
+		return true
+	}
+	if tc.version == tlsVersion13 {
+		return false
+	}
+	return dir.changeCipher && (recordType == tlsRecordHandshake || recordType == tlsRecordAlert)
+}
+
+// ensureCipher
+// derives the direction keys, false when the connection can not be decrypted
+func (tc *tlsConnection) ensureCipher(idx int) bool {
+	dir := tc.dirs[idx]
+	if dir.cipher != nil {
+		return true
+	}
+	if tc.client < 0 {
+		tc.unsupported("ClientHello not seen")
+		return false
+	}
+	if tc.suite == nil {
+		tc.unsupported("unsupported version or cipher suite")
+		return false
+	}
+	client := idx == tc.client
+	var err error = nil
+	if tc.version == tlsVersion13 {
+		label := keyLogServerHandshake
+		if client {
+			label = keyLogClientHandshake
+		}
+		dir.secret = tc.keys.lookup(label, tc.clientRandom)
+		if dir.secret == nil {
+			tc.unsupported("no " + label + " in key log")
+			return false
+		}
+		dir.cipher, err = newTls13Cipher(tc.suite, dir.secret)
+	} else {
+		masterSecret := tc.keys.lookup(keyLogClientRandom, tc.clientRandom)
+		if masterSecret == nil {
+			tc.unsupported("no " + keyLogClientRandom + " in key log")
+			return false
+		}
+		dir.cipher, err = newTls12Cipher(tc.suite, masterSecret, tc.clientRandom, tc.serverRandom, client, tc.encryptThenMac)
+	}
+	if err != nil {
+		tc.unsupported(err.Error())
+		return false
+	}
+	return true
+}
+
+// onHandshake
+// follows handshake messages, protected is true for TLS 1.3 encrypted handshake
+func (tc *tlsConnection) onHandshake(idx int, data []byte, protected bool) {
+	dir := tc.dirs[idx]
+	dir.handshake = append(dir.handshake, data...)
+	for len(dir.handshake) >= tlsHandshakeHeaderSize {
+		messageType := dir.handshake[0]
+		length := int(dir.handshake[1])<<16 | int(dir.handshake[2])<<8 | int(dir.handshake[3])
+		if len(dir.handshake) < tlsHandshakeHeaderSize+length {
+			if length > MaxHttpMessageSize {
+				tc.fail(dir, "handshake message is too long")
+			}
+			return
+		}
+		body := dir.handshake[tlsHandshakeHeaderSize : tlsHandshakeHeaderSize+length]
+		switch {
+		case messageType == tlsHandshakeClientHello && !protected:
+			tc.onClientHello(idx, body)
+		case messageType == tlsHandshakeServerHello && !protected:
+			tc.onServerHello(body)
+		case messageType == tlsHandshakeFinished && protected && !dir.application:
+			// Finished ends the record, the following records use application traffic keys
+			tc.startApplicationTraffic(idx)
+		case messageType == tlsHandshakeKeyUpdate && protected && dir.application:
+			dir.secret = nextTls13Secret(tc.suite, dir.secret)
+			cipher, err := newTls13Cipher(tc.suite, dir.secret)
+			if err != nil {
+				tc.fail(dir, err.Error())
+				return
+			}
+			dir.cipher = cipher
+		}
+		dir.handshake = dir.handshake[tlsHandshakeHeaderSize+length:]
+	}
+	if len(dir.handshake) == 0 {
+		dir.handshake = nil
+	}
+}
+
+// onClientHello
+// remembers client random
+func (tc *tlsConnection) onClientHello(idx int, body []byte) {
+	if len(body) < 2+tlsRandomSize {
+		return
+	}
+	tc.client = idx
+	tc.clientRandom = bytes.Clone(body[2 : 2+tlsRandomSize])
+}
+
+// onServerHello
+// remembers negotiated version, cipher suite and server random
+func (tc *tlsConnection) onServerHello(body []byte) {
+	if len(body) < 2+tlsRandomSize+1 {
+		return
+	}
+	random := body[2 : 2+tlsRandomSize]
+	if bytes.Equal(random, tlsHelloRetryRandom) {
+		// HelloRetryRequest - the real ServerHello follows
+		return
+	}
+	version := binary.BigEndian.Uint16(body[0:2])
+	pos := 2 + tlsRandomSize
+	pos += 1 + int(body[pos]) // session id
+	if len(body) < pos+3 {
+		return
+	}
+	suiteId := binary.BigEndian.Uint16(body[pos : pos+2])
+	pos += 3 // cipher suite and compression method
+	encryptThenMac := false
+	if len(body) >= pos+2 {
+		extensions := body[pos+2:]
+		for len(extensions) >= 4 {
+			extType := binary.BigEndian.Uint16(extensions[0:2])
+			extLen := int(binary.BigEndian.Uint16(extensions[2:4]))
+			if len(extensions) < 4+extLen {
+				break
+			}
+			extData := extensions[4 : 4+extLen]
+			switch extType {
+			case tlsExtensionSupportedVersions:
+				if extLen == 2 {
+					version = binary.BigEndian.Uint16(extData)
+				}
+			case tlsExtensionEncryptThenMac:
+				encryptThenMac = true
+			}
+			extensions = extensions[4+extLen:]
+		}
+	}
+	tc.serverRandom = bytes.Clone(random)
+	tc.version = version
+	tc.encryptThenMac = encryptThenMac
+	switch version {
+	case tlsVersion13:
+		tc.suite = tls13CipherSuites[suiteId]
+	case tlsVersion12:
+		tc.suite = tls12CipherSuites[suiteId]
+	default:
+		tc.suite = nil
+	}
+	tc.serverHello = true
+	if tc.suite == nil {
+		tc.unsupported(fmt.Sprintf("unsupported TLS version %04x or cipher suite %04x", version, suiteId))
+	}
+}
+
+// startApplicationTraffic
+// switches TLS 1.3 direction to the application traffic keys
+func (tc *tlsConnection) startApplicationTraffic(idx int) {
+	dir := tc.dirs[idx]
+	label := keyLogServerTraffic
+	if idx == tc.client {
+		label = keyLogClientTraffic
+	}
+	secret := tc.keys.lookup(label, tc.clientRandom)
+	if secret == nil {
+		tc.unsupported("no " + label + " in key log")
+		return
+	}
+	cipher, err := newTls13Cipher(tc.suite, secret)
+	if err != nil {
+		tc.unsupported(err.Error())
+		return
+	}
+	dir.secret = secret
+	dir.cipher = cipher
+	dir.application = true
+}
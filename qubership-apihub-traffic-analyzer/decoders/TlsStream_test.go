@@ -0,0 +1,222 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTlsKeyLogRead(t *testing.T) {
+	random := strings.Repeat("ab", tlsRandomSize)
+	keyLog := "# SSL/TLS secrets log file\n" +
+		"\n" +
+		"CLIENT_RANDOM " + random + " 0102\n" +
+		"CLIENT_HANDSHAKE_TRAFFIC_SECRET " + random + " 03\n" +
+		"EXPORTER_SECRET " + random + " 04\n" +
+		"CLIENT_RANDOM " + random + "\n" +
+		"CLIENT_RANDOM abcd 05\n" +
+		"CLIENT_RANDOM " + random + " zz\n"
+	keys := NewTlsKeyLog()
+	count, skipped, err := keys.Read(strings.NewReader(keyLog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || skipped != 3 || keys.Len() != 2 {
+		t.Errorf("Read() = %d secrets, %d skipped, %d known, want 2, 3, 2", count, skipped, keys.Len())
+	}
+	clientRandom := bytes.Repeat([]byte{0xab}, tlsRandomSize)
+	if secret := keys.lookup(keyLogClientRandom, clientRandom); !bytes.Equal(secret, []byte{1, 2}) {
+		t.Errorf("master secret %x, want 0102", secret)
+	}
+	if secret := keys.lookup(keyLogServerHandshake, clientRandom); secret != nil {
+		t.Errorf("server handshake secret %x is not expected", secret)
+	}
+	var none *TlsKeyLog
+	if none.Len() != 0 {
+		t.Errorf("nil key log has secrets")
+	}
+}
+
+// recordingConn
+// a connection end which keeps the written data in the order of writes
+type recordingConn struct {
+	net.Conn
+	client bool
+	lock   *sync.Mutex
+	parts  *[]testPart
+}
+
+func (rc *recordingConn) Write(data []byte) (int, error) {
+	rc.lock.Lock()
+	*rc.parts = append(*rc.parts, testPart{rc.client, string(data)})
+	rc.lock.Unlock()
+	return rc.Conn.Write(data)
+}
+
+// testCertificate
+// makes self-signed ECDSA certificate of example.com
+func testCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// tlsConversation
+// runs HTTP/1.1 exchange over TLS connection, returns the wire data and the key log written by the client
+func tlsConversation(t *testing.T, config *tls.Config, request, response string) ([]testPart, string) {
+	lock := &sync.Mutex{}
+	parts := make([]testPart, 0)
+	clientEnd, serverEnd := net.Pipe()
+	keyLog := &bytes.Buffer{}
+	clientConfig := config.Clone()
+	clientConfig.ServerName = "example.com"
+	clientConfig.InsecureSkipVerify = true
+	clientConfig.KeyLogWriter = keyLog
+	serverConfig := config.Clone()
+	serverConfig.Certificates = []tls.Certificate{testCertificate(t)}
+	// the synchronous pipe does not let the server write tickets while the client writes the request
+	serverConfig.SessionTicketsDisabled = true
+	client := tls.Client(&recordingConn{Conn: clientEnd, client: true, lock: lock, parts: &parts}, clientConfig)
+	server := tls.Server(&recordingConn{Conn: serverEnd, client: false, lock: lock, parts: &parts}, serverConfig)
+	served := make(chan error, 1)
+	go func() {
+		// close_notify is not sent, nobody reads the pipe anymore
+		defer serverEnd.Close()
+		req, err := http.ReadRequest(bufio.NewReader(server))
+		if err == nil {
+			_, _ = io.Copy(io.Discard, req.Body)
+			_, err = server.Write([]byte(response))
+		}
+		served <- err
+	}()
+	_, err := client.Write([]byte(request))
+	if err == nil {
+		var rsp *http.Response
+		rsp, err = http.ReadResponse(bufio.NewReader(client), nil)
+		if err == nil {
+			_, err = io.Copy(io.Discard, rsp.Body)
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = <-served; err != nil {
+		t.Fatal(err)
+	}
+	_ = clientEnd.Close()
+	return parts, keyLog.String()
+}
+
+func TestTlsDecryption(t *testing.T) {
+	const request = "POST /orders HTTP/1.1\r\nHost: example.com\r\nContent-Length: 9\r\n\r\n{\"n\":\"1\"}"
+	const response = "HTTP/1.1 201 Created\r\nContent-Length: 2\r\n\r\nok"
+	tls12 := func(suite uint16) *tls.Config {
+		return &tls.Config{MinVersion: tls.VersionTLS12, MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{suite}}
+	}
+	tests := []struct {
+		name         string
+		config       *tls.Config
+		noKeys       bool
+		wantMessages int
+	}{
+		{
+			name:         "TLS 1.3",
+			config:       &tls.Config{MinVersion: tls.VersionTLS13},
+			wantMessages: 2,
+		},
+		{
+			name:         "TLS 1.2 AES GCM",
+			config:       tls12(tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256),
+			wantMessages: 2,
+		},
+		{
+			name:         "TLS 1.2 AES 256 GCM",
+			config:       tls12(tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384),
+			wantMessages: 2,
+		},
+		{
+			name:         "TLS 1.2 ChaCha20-Poly1305",
+			config:       tls12(tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256),
+			wantMessages: 2,
+		},
+		{
+			name:         "TLS 1.2 AES CBC",
+			config:       tls12(tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA),
+			wantMessages: 2,
+		},
+		{
+			name:         "no secrets in key log",
+			config:       &tls.Config{MinVersion: tls.VersionTLS13},
+			noKeys:       true,
+			wantMessages: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, keyLog := tlsConversation(t, tt.config, request, response)
+			keys := NewTlsKeyLog()
+			if !tt.noKeys {
+				if _, _, err := keys.Read(strings.NewReader(keyLog)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			sink := assembleSegments(keys, conversation(parts...)...)
+			if strings.Join(sink.serverNames, ",") != "example.com" {
+				t.Errorf("server names %v, want example.com", sink.serverNames)
+			}
+			if len(sink.messages) != tt.wantMessages {
+				t.Fatalf("got %d messages, want %d", len(sink.messages), tt.wantMessages)
+			}
+			if tt.wantMessages == 0 {
+				return
+			}
+			req, rsp := sink.messages[0], sink.messages[1]
+			if req.Method != "POST" || req.Path != "/orders" || string(req.Body) != `{"n":"1"}` {
+				t.Errorf("request %s %s body %q", req.Method, req.Path, req.Body)
+			}
+			if rsp.StatusCode != 201 || string(rsp.Body) != "ok" {
+				t.Errorf("response %d body %q", rsp.StatusCode, rsp.Body)
+			}
+		})
+	}
+}
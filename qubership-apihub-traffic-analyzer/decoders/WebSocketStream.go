@@ -0,0 +1,314 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// webSocketUpgradeToken Upgrade header value for WebSocket
+	webSocketUpgradeToken = "websocket"
+	// webSocketExtensionsHeader negotiated extensions header in canonical form
+	webSocketExtensionsHeader = "Sec-Websocket-Extensions"
+	// webSocketDeflateExtension permessage-deflate extension name (RFC 7692)
+	webSocketDeflateExtension = "permessage-deflate"
+	// webSocketDeflateWindow the largest LZ77 window used by permessage-deflate
+	webSocketDeflateWindow = 32 * 1024
+	// webSocketMinFrameSize frame header length without extended length and mask
+	webSocketMinFrameSize = 2
+	// webSocketMaskSize masking key length
+	webSocketMaskSize = 4
+)
+
+// WebSocket frame bits and opcodes (RFC 6455)
+const (
+	webSocketFinBit  = 0x80
+	webSocketRsv1Bit = 0x40
+	webSocketMaskBit = 0x80
+
+	WebSocketOpContinuation = 0x0
+	WebSocketOpText         = 0x1
+	WebSocketOpBinary       = 0x2
+	WebSocketOpClose        = 0x8
+	WebSocketOpPing         = 0x9
+	WebSocketOpPong         = 0xA
+)
+
+// webSocketDeflateTail the empty block removed from each compressed message
+var webSocketDeflateTail = []byte{0x00, 0x00, 0xff, 0xff}
+
+// WebSocketMessage
+// a complete WebSocket message, fragments are joined and the payload is unmasked and decompressed
+type WebSocketMessage struct {
+	// Flow message direction
+	Flow FlowInfo
+	// Timestamp time stamp of the first frame
+	Timestamp time.Time
+	// EndTimestamp time stamp of the last frame
+	EndTimestamp time.Time
+	// SeqNo TCP sequence number of the first frame byte
+	SeqNo int
+	// FromClient true for messages sent by the client which made the handshake
+	FromClient bool
+	// Opcode message opcode, continuation frames take the opcode of the first frame
+	Opcode int
+	// Compressed true when the message was sent compressed by permessage-deflate
+	Compressed bool
+	// Payload decoded message payload
+	Payload []byte
+	// Handshake the upgrade request of the connection
+	Handshake *HttpMessage
+}
+
+// OpcodeName
+// returns readable name of the message opcode
+func (wm *WebSocketMessage) OpcodeName() string {
+	switch wm.Opcode {
+	case WebSocketOpText:
+		return "text"
+	case WebSocketOpBinary:
+		return "binary"
+	case WebSocketOpClose:
+		return "close"
+	case WebSocketOpPing:
+		return "ping"
+	case WebSocketOpPong:
+		return "pong"
+	}
+	return "unknown"
+}
+
+// webSocketDirection
+// frame decoding state of one connection direction
+type webSocketDirection struct {
+	half       *httpHalfStream
+	fromClient bool
+	// noContextTakeover true when each compressed message starts with an empty window
+	noContextTakeover bool
+	// window recent decompressed data used as dictionary by the next compressed message
+	window []byte
+	// message data message being assembled from fragments, nil when no fragmented message
+	message *WebSocketMessage
+}
+
+// webSocketConnection
+// WebSocket connection state after the handshake
+type webSocketConnection struct {
+	sink      MessageSink
+	handshake *HttpMessage
+	client    *webSocketDirection
+	server    *webSocketDirection
+	// deflate true when permessage-deflate is negotiated
+	deflate bool
+}
+
+// isWebSocketUpgrade
+// checks if the request is answered by switching to WebSocket
+func isWebSocketUpgrade(request, response *HttpMessage) bool {
+	return response.StatusCode == http.StatusSwitchingProtocols &&
+		strings.EqualFold(strings.TrimSpace(response.Headers["Upgrade"]), webSocketUpgradeToken) &&
+		strings.Contains(strings.ToLower(request.Headers["Upgrade"]), webSocketUpgradeToken)
+}
+
+// newWebSocketConnection
+// creates WebSocket connection state using the extensions accepted by the server, client sends the handshake request
+func newWebSocketConnection(sink MessageSink, request, response *HttpMessage, client, server *httpHalfStream) *webSocketConnection {
+	wc := &webSocketConnection{
+		sink:      sink,
+		handshake: request,
+		client:    &webSocketDirection{half: client, fromClient: true},
+		server:    &webSocketDirection{half: server, fromClient: false},
+	}
+	for _, extension := range strings.Split(response.Headers[webSocketExtensionsHeader], ",") {
+		params := strings.Split(extension, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), webSocketDeflateExtension) {
+			continue
+		}
+		wc.deflate = true
+		for _, param := range params[1:] {
+			name, _, _ := strings.Cut(strings.TrimSpace(param), "=")
+			switch strings.ToLower(name) {
+			case "client_no_context_takeover":
+				wc.client.noContextTakeover = true
+			case "server_no_context_takeover":
+				wc.server.noContextTakeover = true
+			}
+		}
+		break
+	}
+	return wc
+}
+
+// direction
+// returns decoding state for the stream part
+func (wc *webSocketConnection) direction(hs *httpHalfStream) *webSocketDirection {
+	if hs == wc.client.half {
+		return wc.client
+	}
+	return wc.server
+}
+
+// parse
+// decodes all complete frames from the half stream buffer
+func (wc *webSocketConnection) parse(hs *httpHalfStream, final bool) {
+	dir := wc.direction(hs)
+	for len(hs.buf) >= webSocketMinFrameSize {
+		headerSize, payloadSize, ok := webSocketFrameSize(hs.buf)
+		if !ok {
+			break
+		}
+		if payloadSize > MaxHttpMessageSize {
+			log.Tracef("WebSocket frame of %d bytes in stream %s:%d->%s:%d, %d buffered bytes dropped",
+				payloadSize, hs.flow.SrcIP, hs.flow.SrcPort, hs.flow.DstIP, hs.flow.DstPort, len(hs.buf))
+			hs.consume(len(hs.buf))
+			return
+		}
+		frameSize := headerSize + int(payloadSize)
+		if len(hs.buf) < frameSize {
+			break
+		}
+		wc.onFrame(dir, hs.buf[:frameSize], headerSize, hs.timestamp(0), hs.timestamp(frameSize-1), hs.bufSeq)
+		hs.consume(frameSize)
+	}
+	if final {
+		hs.consume(len(hs.buf))
+		dir.message = nil
+	}
+}
+
+// webSocketFrameSize
+// returns frame header and payload lengths, ok is false when the header is not complete
+func webSocketFrameSize(buf []byte) (int, uint64, bool) {
+	headerSize := webSocketMinFrameSize
+	payloadSize := uint64(buf[1] & 0x7f)
+	switch payloadSize {
+	case 126:
+		headerSize += 2
+		if len(buf) < headerSize {
+			return 0, 0, false
+		}
+		payloadSize = uint64(binary.BigEndian.Uint16(buf[2:4]))
+	case 127:
+		headerSize += 8
+		if len(buf) < headerSize {
+			return 0, 0, false
+		}
+		payloadSize = binary.BigEndian.Uint64(buf[2:10])
+	}
+	if buf[1]&webSocketMaskBit != 0 {
+		headerSize += webSocketMaskSize
+	}
+	return headerSize, payloadSize, len(buf) >= headerSize
+}
+
+// onFrame
+// unmasks the frame payload, joins fragments and sends complete messages into the sink
+func (wc *webSocketConnection) onFrame(dir *webSocketDirection, frame []byte, headerSize int, start, end time.Time, seq uint32) {
+	fin := frame[0]&webSocketFinBit != 0
+	opcode := int(frame[0] & 0x0f)
+	payload := make([]byte, len(frame)-headerSize)
+	copy(payload, frame[headerSize:])
+	if frame[1]&webSocketMaskBit != 0 {
+		mask := frame[headerSize-webSocketMaskSize : headerSize]
+		for i := range payload {
+			payload[i] ^= mask[i%webSocketMaskSize]
+		}
+	}
+	msg := &WebSocketMessage{
+		Flow:         dir.half.flow,
+		Timestamp:    start,
+		EndTimestamp: end,
+		SeqNo:        int(seq),
+		FromClient:   dir.fromClient,
+		Opcode:       opcode,
+		Compressed:   wc.deflate && frame[0]&webSocketRsv1Bit != 0,
+		Payload:      payload,
+		Handshake:    wc.handshake,
+	}
+	switch {
+	case opcode >= WebSocketOpClose:
+		// control frames are not fragmented and may come between fragments of a data message
+		wc.sink.OnWebSocketMessage(msg)
+		return
+	case opcode == WebSocketOpContinuation:
+		if dir.message == nil {
+			log.Tracef("WebSocket continuation frame without message start in stream %s:%d->%s:%d",
+				msg.Flow.SrcIP, msg.Flow.SrcPort, msg.Flow.DstIP, msg.Flow.DstPort)
+			return
+		}
+		dir.message.Payload = append(dir.message.Payload, payload...)
+		dir.message.EndTimestamp = end
+		if len(dir.message.Payload) > MaxHttpMessageSize {
+			log.Tracef("WebSocket message in stream %s:%d->%s:%d is too large, dropped",
+				msg.Flow.SrcIP, msg.Flow.SrcPort, msg.Flow.DstIP, msg.Flow.DstPort)
+			dir.message = nil
+			return
+		}
+	default:
+		dir.message = msg
+	}
+	if !fin {
+		return
+	}
+	msg = dir.message
+	dir.message = nil
+	if msg.Compressed {
+		payload, err := dir.inflate(msg.Payload)
+		if err != nil {
+			log.Tracef("unable to decompress WebSocket message in stream %s:%d->%s:%d: %v",
+				msg.Flow.SrcIP, msg.Flow.SrcPort, msg.Flow.DstIP, msg.Flow.DstPort, err)
+		} else {
+			msg.Payload = payload
+		}
+	}
+	wc.sink.OnWebSocketMessage(msg)
+}
+
+// inflate
+// decompresses permessage-deflate message, previous messages are the dictionary unless context takeover is disabled
+func (wd *webSocketDirection) inflate(payload []byte) ([]byte, error) {
+	var dict []byte = nil
+	if !wd.noContextTakeover {
+		dict = wd.window
+	}
+	fr := flate.NewReaderDict(io.MultiReader(bytes.NewReader(payload), bytes.NewReader(webSocketDeflateTail)), dict)
+	defer fr.Close()
+	decoded, err := io.ReadAll(io.LimitReader(fr, MaxHttpMessageSize+1))
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		// the message ends by a sync flush, there is no final block
+		wd.window = nil
+		return nil, err
+	}
+	if len(decoded) > MaxHttpMessageSize {
+		return nil, errBodyTooLarge
+	}
+	if !wd.noContextTakeover {
+		wd.window = append(wd.window, decoded...)
+		if len(wd.window) > webSocketDeflateWindow {
+			wd.window = bytes.Clone(wd.window[len(wd.window)-webSocketDeflateWindow:])
+		}
+	}
+	return decoded, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decoders
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// WebSocket frames written in hex, the examples are taken from RFC 6455 section 5.7 and RFC 7692 section 7.2.3
+const (
+	// wsMaskedHello masked text frame "Hello" sent by the client
+	wsMaskedHello = "8185 37fa213d 7f9f4d5158"
+	// wsHello unmasked text frame "Hello"
+	wsHello = "8105 48656c6c6f"
+	// wsFragmentedHello "Hello" sent in two fragments with ping between them
+	wsFragmentedHello = "0103 48656c" + "8900" + "8002 6c6f"
+	// wsDeflatedHello compressed "Hello"
+	wsDeflatedHello = "c107 f248cdc9c90700"
+	// wsDeflatedHelloAgain compressed "Hello" referring the previous message
+	wsDeflatedHelloAgain = "c105 f200110000"
+	// wsClose close frame with status 1000
+	wsClose = "8802 03e8"
+)
+
+// webSocketParts
+// makes the handshake of the connection followed by the frames written in hex
+func webSocketParts(t *testing.T, extensions string, clientFrames, serverFrames string) []testPart {
+	frames := func(hexFrames string) string {
+		data, err := hex.DecodeString(strings.ReplaceAll(hexFrames, " ", ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: s3pPLMBiTxaQ9kYGzzhZRbK+xOo=\r\n"
+	if extensions != "" {
+		response += "Sec-WebSocket-Extensions: " + extensions + "\r\n"
+	}
+	parts := []testPart{
+		{true, "GET /chat HTTP/1.1\r\nHost: example.com\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"},
+		{false, response + "\r\n" + frames(serverFrames)},
+	}
+	if clientFrames != "" {
+		parts = append(parts, testPart{true, frames(clientFrames)})
+	}
+	return parts
+}
+
+func TestWebSocketStream(t *testing.T) {
+	tests := []struct {
+		name         string
+		extensions   string
+		clientFrames string
+		serverFrames string
+		want         string
+	}{
+		{
+			name:         "masked and unmasked text frames",
+			clientFrames: wsMaskedHello + wsClose,
+			serverFrames: wsHello,
+			want:         "server text Hello;client text Hello;client close \x03\xe8",
+		},
+		{
+			name:         "fragmented message with control frame between fragments",
+			serverFrames: wsFragmentedHello,
+			want:         "server ping ;server text Hello",
+		},
+		{
+			name:         "binary frame with 16 bit length",
+			serverFrames: "827e 0100" + strings.Repeat("ab", 256),
+			want:         "server binary " + strings.Repeat("\xab", 256),
+		},
+		{
+			name:         "compressed messages share the window",
+			extensions:   "permessage-deflate",
+			serverFrames: wsDeflatedHello + wsDeflatedHelloAgain,
+			want:         "server text Hello (compressed);server text Hello (compressed)",
+		},
+		{
+			name:         "compressed messages without context takeover",
+			extensions:   "permessage-deflate; server_no_context_takeover",
+			serverFrames: wsDeflatedHello + wsDeflatedHello,
+			want:         "server text Hello (compressed);server text Hello (compressed)",
+		},
+		{
+			name:         "reserved bit without negotiated compression",
+			serverFrames: wsDeflatedHello,
+			want:         "server text \xf2\x48\xcd\xc9\xc9\x07\x00",
+		},
+		{
+			name:         "continuation without message start",
+			serverFrames: "8002 6c6f" + wsHello,
+			want:         "server text Hello",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := assembleSegments(nil, conversation(webSocketParts(t, tt.extensions, tt.clientFrames, tt.serverFrames)...)...)
+			messages := make([]string, 0, len(sink.webSocket))
+			for _, msg := range sink.webSocket {
+				from := "server"
+				if msg.FromClient {
+					from = "client"
+				}
+				message := fmt.Sprintf("%s %s %s", from, msg.OpcodeName(), msg.Payload)
+				if msg.Compressed {
+					message += " (compressed)"
+				}
+				if msg.Handshake == nil || msg.Handshake.Path != "/chat" {
+					t.Errorf("message %q handshake %v", message, msg.Handshake)
+				}
+				messages = append(messages, message)
+			}
+			if got := strings.Join(messages, ";"); got != tt.want {
+				t.Errorf("messages %q, want %q", got, tt.want)
+			}
+			if len(sink.exchanges) != 1 || sink.exchanges[0].Response == nil || sink.exchanges[0].Response.StatusCode != 101 {
+				t.Errorf("the handshake is expected to be the only exchange, got %d", len(sink.exchanges))
+			}
+		})
+	}
+}
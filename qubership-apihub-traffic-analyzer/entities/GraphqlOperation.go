@@ -0,0 +1,28 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+type ServiceGraphqlOperation struct {
+	tableName struct{} `pg:"service_graphql_operations, alias:service_graphql_operations"`
+
+	OperationId    int    `pg:"operation_id,pk,type:bigint"`
+	CaptureId      string `pg:"capture_id,type:varchar"`
+	PacketId       int    `pg:"packet_id,type:bigint"`
+	OperationIndex int    `pg:"operation_index,use_zero,type:int"`
+	OperationType  string `pg:"operation_type,type:varchar"`
+	OperationName  string `pg:"operation_name,type:varchar"`
+	RootField      string `pg:"root_field,type:varchar"`
+	Variables      string `pg:"variables,type:json"`
+}
@@ -34,7 +34,8 @@ func computeHeaderId(key, value string) string {
 }
 
 // NewHttpHeader
-// prepares new record to interact with DB
+// prepares new record to interact with DB, the name and value are cleaned for the varchar columns
 func NewHttpHeader(key, value string) HttpHeaderItem {
+	key, value = textValue(key), textValue(value)
 	return HttpHeaderItem{Key: key, Value: value, Id: computeHeaderId(key, value)}
 }
@@ -0,0 +1,63 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"bytes"
+	"encoding/base64"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// KafkaOperationProduce records sent by a producer in Produce request
+	KafkaOperationProduce = "PRODUCE"
+	// KafkaOperationConsume records received by a consumer in Fetch response
+	KafkaOperationConsume = "CONSUME"
+)
+
+type KafkaMessageEvent struct {
+	tableName struct{} `pg:"kafka_message_events, alias:kafka_message_events"`
+
+	EventId        int               `pg:"event_id,pk,type:bigint"`
+	CaptureId      string            `pg:"capture_id,type:varchar"`
+	SourceId       int               `pg:"source_id,use_zero,type:bigint"`
+	SourcePort     int               `pg:"source_port,use_zero,type:int"`
+	DestId         int               `pg:"dest_id,use_zero,type:bigint"`
+	DestPort       int               `pg:"dest_port,use_zero,type:int"`
+	Operation      string            `pg:"operation,type:varchar"`
+	ApiVersion     int               `pg:"api_version,use_zero,type:int"`
+	ClientId       string            `pg:"client_id,type:varchar"`
+	Topic          string            `pg:"topic,type:varchar"`
+	TopicId        string            `pg:"topic_id,type:varchar"`
+	PartitionIndex int               `pg:"partition_index,use_zero,type:int"`
+	RecordIndex    int               `pg:"record_index,use_zero,type:int"`
+	RecordOffset   int64             `pg:"record_offset,use_zero,type:bigint"`
+	RecordKey      string            `pg:"record_key,type:text"`
+	RecordValue    string            `pg:"record_value,type:text"`
+	RecordHeaders  map[string]string `pg:"record_headers,type:json"`
+	RecordTime     time.Time         `pg:"record_time,type:timestamptz"`
+	TimeStamp      time.Time         `pg:"time_stamp,type:timestamptz"`
+	SeqNo          int               `pg:"seq_no,use_zero,type:bigint"`
+}
+
+// RecordText
+// converts record key or value into a string acceptable by the text column, binary data is base64 encoded
+func RecordText(data []byte) string {
+	if utf8.Valid(data) && bytes.IndexByte(data, 0) < 0 {
+		return string(data)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
@@ -0,0 +1,53 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+)
+
+// LoadPacketJob
+// a capture load job, the job is held by a worker until the TTL expires
+type LoadPacketJob struct {
+	tableName struct{} `pg:"load_packet_jobs, alias:load_packet_jobs"`
+
+	JobId      int        `pg:"job_id,pk,type:integer"`
+	CaptureId  string     `pg:"capture_id,type:varchar"`
+	InstanceId string     `pg:"instance_id,type:varchar"`
+	WorkerId   string     `pg:"worker_id,type:varchar"`
+	Created    time.Time  `pg:"created,type:timestamp,default:now()"`
+	Started    *time.Time `pg:"started,type:timestamp"`
+	Finished   *time.Time `pg:"finished,type:timestamp"`
+	JobTtl     *time.Time `pg:"job_ttl,type:timestamp"`
+	Attempts   int        `pg:"attempts,type:integer,use_zero"`
+	Error      *string    `pg:"error,type:text"`
+	Files      int        `pg:"files,type:integer,use_zero"`
+}
+
+// LoadPacketJobFile
+// a capture file read by the load job
+type LoadPacketJobFile struct {
+	tableName struct{} `pg:"load_packet_job_files, alias:load_packet_job_files"`
+
+	JobId    int                  `pg:"job_id,pk,type:integer"`
+	FileName string               `pg:"file_name,pk,type:varchar"`
+	Started  time.Time            `pg:"started,type:timestamp,default:now()"`
+	Finished *time.Time           `pg:"finished,type:timestamp"`
+	Packets  int                  `pg:"packets,type:integer,use_zero"`
+	Error    *string              `pg:"error,type:text"`
+	Counters *view.IngestCounters `pg:"counters,type:json"`
+}
@@ -17,6 +17,7 @@ package entities
 type PacketHeader struct {
 	tableName struct{} `pg:"service_packet_headers, alias:service_packet_headers"`
 
-	HeaderId string `pg:"header_id, pk, type:varchar"`
-	PacketId int    `pg:"packet_id, pk, type:bigint"`
+	HeaderId  string `pg:"header_id, pk, type:varchar"`
+	PacketId  int    `pg:"packet_id, pk, type:bigint"`
+	CaptureId string `pg:"capture_id, type:varchar"`
 }
@@ -34,7 +34,11 @@ type ServicePacket struct {
 	Body          string    `pg:"body,type:text"`
 	CaptureId     string    `pg:"capture_id,type:varchar"`
 	RequestPath   string    `pg:"request_path,type:varchar"`
+	RequestQuery  string    `pg:"request_query,type:varchar"`
 	RequestMethod string    `pg:"request_method,type:varchar"`
+	OuterSourceIp string    `pg:"outer_source_ip,type:varchar"`
+	OuterDestIp   string    `pg:"outer_dest_ip,type:varchar"`
+	Encapsulation string    `pg:"encapsulation,type:varchar"`
 }
 
 type ParsedPacket struct {
@@ -48,7 +52,12 @@ type ParsedPacket struct {
 	ServiceName   string
 	Headers       map[string]string
 	RequestPath   string
+	RequestQuery  string
 	RequestMethod string
+	// OuterSourceIp, OuterDestIp, Encapsulation tunnel metadata, empty for not tunneled packet
+	OuterSourceIp string
+	OuterDestIp   string
+	Encapsulation string
 }
 
 func MakeDbPacket(p ParsedPacket, captureId string) ServicePacket {
@@ -60,9 +69,13 @@ func MakeDbPacket(p ParsedPacket, captureId string) ServicePacket {
 		TimeStamp:     p.Timestamp,
 		SeqNo:         p.SeqNo,
 		AckNo:         p.AckNo,
-		Body:          p.StrPayload,
+		Body:          textValue(p.StrPayload),
 		CaptureId:     captureId,
-		RequestPath:   p.RequestPath,
-		RequestMethod: p.RequestMethod,
+		RequestPath:   textValue(p.RequestPath),
+		RequestQuery:  textValue(p.RequestQuery),
+		RequestMethod: textValue(p.RequestMethod),
+		OuterSourceIp: p.OuterSourceIp,
+		OuterDestIp:   p.OuterDestIp,
+		Encapsulation: p.Encapsulation,
 	}
 }
@@ -0,0 +1,56 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"net/url"
+	"strings"
+)
+
+type ServiceQueryParam struct {
+	tableName struct{} `pg:"service_query_params, alias:service_query_params"`
+
+	ParamId    int    `pg:"param_id,pk,type:bigint"`
+	CaptureId  string `pg:"capture_id,type:varchar"`
+	PacketId   int    `pg:"packet_id,type:bigint"`
+	ParamIndex int    `pg:"param_index,use_zero,type:int"`
+	ParamName  string `pg:"param_name,type:varchar"`
+	ParamValue string `pg:"param_value,type:varchar"`
+}
+
+// ParseQueryParams
+// splits the raw query string into parameters keeping their order, undecodable names and values are kept as is
+func ParseQueryParams(query string, captureId string) []ServiceQueryParam {
+	params := make([]ServiceQueryParam, 0)
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		params = append(params, ServiceQueryParam{
+			CaptureId:  captureId,
+			ParamIndex: len(params),
+			ParamName:  textValue(name),
+			ParamValue: textValue(value),
+		})
+	}
+	return params
+}
@@ -15,8 +15,10 @@
 package entities
 
 const (
-	ReportAffectedPacket    = 1
-	ReportAffectedOperation = 2
+	ReportAffectedPacket           = 1
+	ReportAffectedOperation        = 2
+	ReportAffectedKafkaEvent       = 3
+	ReportAffectedGraphqlOperation = 4
 )
 
 type ReportAffectedRef struct {
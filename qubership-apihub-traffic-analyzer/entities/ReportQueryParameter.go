@@ -0,0 +1,27 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+type ReportQueryParameter struct {
+	tableName  struct{} `pg:"report_query_parameters, alias:report_query_parameters"`
+	ReportId   int      `pg:"report_id,pk,type:bigint"`
+	Title      string   `pg:"operation_title,type:varchar"`
+	Path       string   `pg:"operation_path,pk,type:varchar"`
+	Method     string   `pg:"operation_method,pk,type:varchar"`
+	Name       string   `pg:"param_name,pk,type:varchar"`
+	Documented bool     `pg:"documented,use_zero,type:bool"`
+	Required   bool     `pg:"required,use_zero,type:bool"`
+	HitCount   int      `pg:"hit_count,use_zero,type:bigint"`
+}
@@ -46,15 +46,16 @@ type ServiceOperationUpdate struct {
 }
 
 type ReportServiceOperationWithPeers struct {
-	tableName   struct{} `pg:"report_service_operations2, alias:report_service_operations2"`
-	ReportId    int      `pg:"report_id,type:bigint"`
-	Sender      string   `pg:"src_peer, type:varchar"`
-	Receiver    string   `pg:"dst_peer, type:varchar"`
-	Path        string   `pg:"operation_path,type:varchar"`
-	Method      string   `pg:"operation_method,type:varchar"`
-	OperationId string   `pg:"operation_title, type:varchar"`
-	Occurrences int      `pg:"hit_count,type:int"`
-	Comment     string   `pg:"operation_status,type:varchar"`
+	tableName     struct{} `pg:"report_service_operations2, alias:report_service_operations2"`
+	ReportId      int      `pg:"report_id,type:bigint"`
+	Sender        string   `pg:"src_peer, type:varchar"`
+	Receiver      string   `pg:"dst_peer, type:varchar"`
+	Path          string   `pg:"operation_path,type:varchar"`
+	Method        string   `pg:"operation_method,type:varchar"`
+	OperationId   string   `pg:"operation_title, type:varchar"`
+	Occurrences   int      `pg:"hit_count,type:int"`
+	Comment       string   `pg:"operation_status,type:varchar"`
+	ResponseCodes string   `pg:"response_codes,type:varchar"`
 }
 
 func NewReportServiceOperation(reportId int, title, path, method, status string) ReportServiceOperation {
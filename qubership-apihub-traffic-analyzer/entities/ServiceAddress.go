@@ -16,6 +16,7 @@ package entities
 
 import (
 	"encoding/json"
+	"time"
 )
 
 type ServiceAddress struct {
@@ -26,6 +27,62 @@ type ServiceAddress struct {
 	Name      string `pg:"service_name, type:varchar"`
 	Version   string `pg:"service_version, type:varchar"`
 	CaptureId string `pg:"capture_id, type:varchar"`
+	// ValidFrom, ValidTo address validity window, nil for unbounded
+	ValidFrom *time.Time `pg:"valid_from, type:timestamptz"`
+	ValidTo   *time.Time `pg:"valid_to, type:timestamptz"`
+	// InferredName host name seen in the capture (DNS, TLS SNI, Host header), InferredSource its source
+	InferredName   string `pg:"inferred_name, type:varchar"`
+	InferredSource string `pg:"inferred_source, type:varchar"`
+}
+
+// Covers
+// checks the address is valid at the given time
+func (sa *ServiceAddress) Covers(timestamp time.Time) bool {
+	if sa.ValidFrom != nil && timestamp.Before(*sa.ValidFrom) {
+		return false
+	}
+	return sa.ValidTo == nil || timestamp.Before(*sa.ValidTo)
+}
+
+// SameWindow
+// checks both addresses have the same validity window
+func (sa *ServiceAddress) SameWindow(other *ServiceAddress) bool {
+	return sameTime(sa.ValidFrom, other.ValidFrom) && sameTime(sa.ValidTo, other.ValidTo)
+}
+
+// ResolveServiceAddress
+// selects the address entry valid at the given time, a later window start (the latest snapshot) takes precedence
+// and named entries are preferred to unnamed ones. Returns nil when no entry covers the time
+func ResolveServiceAddress(entries []ServiceAddress, timestamp time.Time) *ServiceAddress {
+	var best *ServiceAddress
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Covers(timestamp) && (best == nil || betterEntry(entry, best)) {
+			best = entry
+		}
+	}
+	return best
+}
+
+// betterEntry
+// checks the entry takes precedence over the current best entry covering the same time
+func betterEntry(entry, best *ServiceAddress) bool {
+	if (entry.Name != "") != (best.Name != "") {
+		return entry.Name != ""
+	}
+	if entry.ValidFrom == nil {
+		return false
+	}
+	return best.ValidFrom == nil || entry.ValidFrom.After(*best.ValidFrom)
+}
+
+// sameTime
+// compares optional time values
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
 }
 
 func UnmarshalServiceAddress(bytes []byte) (*ServiceAddress, error) {
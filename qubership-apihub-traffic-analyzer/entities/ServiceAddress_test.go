@@ -0,0 +1,108 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveServiceAddress(t *testing.T) {
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) *time.Time {
+		moment := base.Add(time.Duration(minutes) * time.Minute)
+		return &moment
+	}
+	entry := func(id int, name string, from, to *time.Time) ServiceAddress {
+		return ServiceAddress{Id: id, Address: "10.0.0.5", Name: name, ValidFrom: from, ValidTo: to}
+	}
+	// the pod IP belongs to orders until minute 10 and to payments after a gap
+	churn := []ServiceAddress{
+		entry(1, "orders", at(0), at(10)),
+		entry(2, "payments", at(15), nil),
+	}
+	tests := []struct {
+		name    string
+		entries []ServiceAddress
+		time    *time.Time
+		wantId  int
+	}{
+		{"window start is included", churn, at(0), 1},
+		{"window end is excluded", churn, at(10), 0},
+		{"time in the gap", churn, at(12), 0},
+		{"open window", churn, at(600), 2},
+		{"time before all windows", churn, at(-1), 0},
+		{
+			name:    "later snapshot takes precedence",
+			entries: []ServiceAddress{entry(1, "orders", nil, nil), entry(2, "payments", at(5), nil), entry(3, "billing", at(2), nil)},
+			time:    at(7),
+			wantId:  2,
+		},
+		{
+			name:    "unbounded entry before the snapshot",
+			entries: []ServiceAddress{entry(1, "orders", nil, nil), entry(2, "payments", at(5), nil)},
+			time:    at(3),
+			wantId:  1,
+		},
+		{
+			name:    "named entry is preferred",
+			entries: []ServiceAddress{entry(1, "orders", at(0), nil), entry(2, "", at(5), nil)},
+			time:    at(7),
+			wantId:  1,
+		},
+		{
+			name:    "unnamed entry is used when nothing else covers the time",
+			entries: []ServiceAddress{entry(1, "orders", at(0), at(5)), entry(2, "", at(5), nil)},
+			time:    at(7),
+			wantId:  2,
+		},
+		{"no entries", nil, at(0), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveServiceAddress(tt.entries, *tt.time)
+			gotId := 0
+			if got != nil {
+				gotId = got.Id
+			}
+			if gotId != tt.wantId {
+				t.Errorf("ResolveServiceAddress() = entry %d, want %d", gotId, tt.wantId)
+			}
+		})
+	}
+}
+
+func TestServiceAddressSameWindow(t *testing.T) {
+	from := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	sameFrom := from.In(time.FixedZone("UTC+3", 3*60*60))
+	to := from.Add(time.Hour)
+	tests := []struct {
+		name string
+		a, b ServiceAddress
+		want bool
+	}{
+		{"unbounded windows", ServiceAddress{}, ServiceAddress{}, true},
+		{"same moment in other zone", ServiceAddress{ValidFrom: &from}, ServiceAddress{ValidFrom: &sameFrom}, true},
+		{"bounded and unbounded start", ServiceAddress{ValidFrom: &from}, ServiceAddress{}, false},
+		{"different end", ServiceAddress{ValidFrom: &from, ValidTo: &to}, ServiceAddress{ValidFrom: &from}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.SameWindow(&tt.b); got != tt.want {
+				t.Errorf("SameWindow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
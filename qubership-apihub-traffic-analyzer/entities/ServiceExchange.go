@@ -0,0 +1,102 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+type ServiceExchange struct {
+	tableName struct{} `pg:"service_exchanges, alias:service_exchanges"`
+
+	ExchangeId       int               `pg:"exchange_id,pk,type:bigint"`
+	CaptureId        string            `pg:"capture_id,type:varchar"`
+	RequestPacketId  int               `pg:"request_packet_id,type:bigint"`
+	ResponsePacketId int               `pg:"response_packet_id,type:bigint"`
+	RequestMethod    string            `pg:"request_method,type:varchar"`
+	RequestPath      string            `pg:"request_path,type:varchar"`
+	StatusCode       int               `pg:"status_code,type:int"`
+	StatusText       string            `pg:"status_text,type:varchar"`
+	RequestHeaders   map[string]string `pg:"request_headers,type:json"`
+	ResponseHeaders  map[string]string `pg:"response_headers,type:json"`
+	RequestBody      string            `pg:"request_body,type:text"`
+	ResponseBody     string            `pg:"response_body,type:text"`
+	RequestTime      time.Time         `pg:"request_time,type:timestamptz"`
+	ResponseTime     time.Time         `pg:"response_time,type:timestamptz"`
+	TimeToFirstByte  int64             `pg:"time_to_first_byte,type:bigint"`
+	GrpcService      string            `pg:"grpc_service,type:varchar"`
+	GrpcMethod       string            `pg:"grpc_method,type:varchar"`
+	GrpcStatus       *int              `pg:"grpc_status,type:int"`
+	GrpcMessage      string            `pg:"grpc_message,type:varchar"`
+}
+
+// ExchangeMessage
+// a request or response part of an exchange
+type ExchangeMessage struct {
+	PacketId   int
+	Method     string
+	Path       string
+	StatusCode int
+	Headers    map[string]string
+	Body       []byte
+	Timestamp  time.Time
+	// GrpcService, GrpcMethod gRPC call, empty for not gRPC requests
+	GrpcService string
+	GrpcMethod  string
+	// GrpcStatus, GrpcMessage gRPC call status, nil for not gRPC responses
+	GrpcStatus  *int
+	GrpcMessage string
+}
+
+// MakeDbExchange
+// makes a table row for the request and optional response, time to first byte is in microseconds
+func MakeDbExchange(request ExchangeMessage, response *ExchangeMessage, timeToFirstByte time.Duration, captureId string) ServiceExchange {
+	exchange := ServiceExchange{
+		CaptureId:       captureId,
+		RequestPacketId: request.PacketId,
+		RequestMethod:   request.Method,
+		RequestPath:     request.Path,
+		RequestHeaders:  request.Headers,
+		RequestBody:     textBody(request.Body),
+		RequestTime:     request.Timestamp,
+		GrpcService:     request.GrpcService,
+		GrpcMethod:      request.GrpcMethod,
+	}
+	if response != nil {
+		exchange.ResponsePacketId = response.PacketId
+		exchange.StatusCode = response.StatusCode
+		exchange.StatusText = response.Method
+		exchange.ResponseHeaders = response.Headers
+		exchange.ResponseBody = textBody(response.Body)
+		exchange.ResponseTime = response.Timestamp
+		exchange.TimeToFirstByte = timeToFirstByte.Microseconds()
+		exchange.GrpcStatus = response.GrpcStatus
+		exchange.GrpcMessage = response.GrpcMessage
+	}
+	return exchange
+}
+
+// textBody
+// converts body into a string acceptable by the text column
+func textBody(body []byte) string {
+	return textValue(string(body))
+}
+
+// textValue
+// removes NUL bytes and invalid UTF-8 sequences rejected by the text columns
+func textValue(text string) string {
+	return strings.ToValidUTF8(strings.ReplaceAll(text, "\x00", ""), "\uFFFD")
+}
@@ -0,0 +1,30 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"time"
+)
+
+// StoredCapture
+// a capture loaded into DB
+type StoredCapture struct {
+	tableName struct{} `pg:"stored_captures, alias:stored_captures"`
+
+	CaptureId     string     `pg:"capture_id,pk,type:varchar"`
+	CreatedAt     time.Time  `pg:"created_at,type:timestamptz,default:now()"`
+	LoadStartedAt time.Time  `pg:"load_started_at,type:timestamptz,default:now()"`
+	LoadedAt      *time.Time `pg:"loaded_at,type:timestamptz"`
+}
@@ -0,0 +1,49 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entities
+
+import (
+	"encoding/base64"
+	"time"
+)
+
+// webSocketOpText WebSocket text message opcode
+const webSocketOpText = 0x1
+
+type ServiceWebSocketMessage struct {
+	tableName struct{} `pg:"service_websocket_messages, alias:service_websocket_messages"`
+
+	MessageId         int       `pg:"message_id,pk,type:bigint"`
+	CaptureId         string    `pg:"capture_id,type:varchar"`
+	HandshakePacketId int       `pg:"handshake_packet_id,type:bigint"`
+	RequestPath       string    `pg:"request_path,type:varchar"`
+	FromClient        bool      `pg:"from_client,use_zero,type:boolean"`
+	Opcode            int       `pg:"opcode,use_zero,type:int"`
+	MessageType       string    `pg:"message_type,type:varchar"`
+	Compressed        bool      `pg:"compressed,use_zero,type:boolean"`
+	Payload           string    `pg:"payload,type:text"`
+	PayloadSize       int       `pg:"payload_size,use_zero,type:int"`
+	TimeStamp         time.Time `pg:"time_stamp,type:timestamptz"`
+	SeqNo             int       `pg:"seq_no,use_zero,type:bigint"`
+}
+
+// WebSocketPayload
+// converts message payload into a string acceptable by the text column, not text messages are base64 encoded
+func WebSocketPayload(opcode int, payload []byte) string {
+	if opcode == webSocketOpText {
+		return textBody(payload)
+	}
+	return base64.StdEncoding.EncodeToString(payload)
+}
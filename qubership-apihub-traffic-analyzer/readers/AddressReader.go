@@ -17,12 +17,14 @@ package readers
 import (
 	"bufio"
 	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
@@ -33,7 +35,9 @@ import (
 
 type HostsReader interface {
 	Read(hostsFile string) error
-	GetServiceByIp(ip string) (*entities.ServiceAddress, error)
+	GetServiceByIp(ip string, timestamp time.Time) (*entities.ServiceAddress, error)
+	AddNameRecord(ip, name string) error
+	AddInferredName(ip, name, source string) error
 	Close() error
 }
 
@@ -84,33 +88,66 @@ func (hr *hostsReaderImpl) Read(hostsFile string) error {
 	return hr.readCaptureServiceMap(fh)
 }
 
-func (hr *hostsReaderImpl) GetServiceByIp(ip string) (*entities.ServiceAddress, error) {
-	ise, err := hr.hostsCache.GetServiceAddressByIp(ip)
-	if err == nil {
-		return ise, nil // found in cache
-	}
-	// not in cache - ask db for an IP address
-	svcAddr, err := hr.hostsCache.GetServiceAddress(ip, view.EmptyString, view.EmptyString, hr.captureId)
-	if err != nil {
-		return nil, err // unable to find in DB
-	}
-	return &svcAddr, nil // found in DB
+// GetServiceByIp
+// returns the address entry valid at the packet time, the entries are loaded from DB on the first access
+func (hr *hostsReaderImpl) GetServiceByIp(ip string, timestamp time.Time) (*entities.ServiceAddress, error) {
+	return hr.hostsCache.GetServiceAddressAt(ip, hr.captureId, timestamp)
+}
+
+// AddNameRecord
+// names the address from the capture name resolution records, the address list names take precedence
+func (hr *hostsReaderImpl) AddNameRecord(ip, name string) error {
+	_, err := hr.hostsCache.NameServiceAddress(ip, name, hr.captureId)
+	return err
+}
+
+// AddInferredName
+// names the address with the host name seen in the capture, shown when the address list has no name for the address
+func (hr *hostsReaderImpl) AddInferredName(ip, name, source string) error {
+	return hr.hostsCache.InferServiceAddressName(ip, name, source, hr.captureId)
 }
 
+// addressEntry
+// an address list entry, the optional validity window overrides the snapshot time
+type addressEntry struct {
+	view.ServiceView
+	ValidFrom *time.Time `json:"valid_from,omitempty"`
+	ValidTo   *time.Time `json:"valid_to,omitempty"`
+}
+
+// readCaptureServiceMap
+// reads address list lines '<ip> {"service_name":...,"service_version":...}', a line '# snapshot: <RFC3339 time>'
+// starts an address list snapshot, the following entries are valid from the snapshot time until the next snapshot
+// of the same address. Entries before the first snapshot are valid for the whole capture
 func (hr *hostsReaderImpl) readCaptureServiceMap(fh io.Reader) error {
 	scanner := bufio.NewScanner(fh)
 	if scanner == nil {
 		return fmt.Errorf("unable to create file scanner")
 	}
 	var reqRe = regexp.MustCompile(`^([a-f\d\.:]+)\s+({.+})\s*$`)
+	var snapshotRe = regexp.MustCompile(`^#\s*snapshot:\s*(\S+)\s*$`)
+	var snapshot *time.Time
 	for scanner.Scan() {
+		if ms := snapshotRe.FindSubmatch(scanner.Bytes()); ms != nil {
+			snapshotTime, err := time.Parse(time.RFC3339Nano, string(ms[1]))
+			if err != nil {
+				return fmt.Errorf("invalid address list snapshot time: %w", err)
+			}
+			snapshot = &snapshotTime
+			continue
+		}
 		ms := reqRe.FindSubmatch(scanner.Bytes())
 		if ms != nil {
-			svc, err := view.UnmarshalServiceView(ms[2])
+			var entry addressEntry
+			err := json.Unmarshal(ms[2], &entry)
 			if err != nil {
 				return err
 			}
-			_, err = hr.hostsCache.GetServiceAddress(string(ms[1]), svc.Name, svc.Version, hr.captureId)
+			validFrom, validTo := entry.ValidFrom, entry.ValidTo
+			if validFrom == nil && validTo == nil {
+				validFrom = snapshot
+			}
+			_, err = hr.hostsCache.GetServiceAddress(string(ms[1]), entry.Name, entry.Version, hr.captureId, validFrom, validTo)
 			if err != nil {
 				log.Debugf("unable to get service by ip %s: %s", string(ms[1]), err)
 			}
@@ -0,0 +1,523 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package readers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/decoders"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/repository"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	log "github.com/sirupsen/logrus"
+)
+
+// captureChain
+// decoding state shared by the capture files read in sequence: connections, IP fragments and the inferred
+// names continue from a file into the next one, the decoded messages are stored by the file being read
+type captureChain struct {
+	assembler *decoders.StreamAssembler
+	// protobuf descriptor sets to decode gRPC messages
+	protobuf *decoders.ProtobufRegistry
+	// network removes encapsulations and reassembles IP fragments
+	network *decoders.NetworkDecoder
+	// inferred addresses named from the capture by source, each address is named once per chain and source
+	inferred map[string]bool
+	// file the file being read, the last read file when the chain is over
+	file *captureFile
+}
+
+// captureFile
+// decoding state of a single capture file, chains of files are decoded concurrently sharing the capture reader
+type captureFile struct {
+	// ctx stops reading when cancelled
+	ctx       context.Context
+	reader    *captureReaderImpl
+	captureId string
+	// batch stores decoded messages
+	batch repository.PacketBatch
+	// httpMessages number of HTTP messages decoded from the file
+	httpMessages int
+	// webSocketMessages number of WebSocket messages decoded from the file
+	webSocketMessages int
+	// kafkaRecords number of Kafka records decoded from the file
+	kafkaRecords int
+	// counters packets and messages of the file, losses by reason
+	counters *view.IngestCounters
+	// chain decoding state continued from the previous file
+	chain *captureChain
+	// last the connections left open are flushed when the last file of the chain is read
+	last bool
+}
+
+// readPcap
+// reads pcap capture, packets are decoded according to the file link type
+func (cf *captureFile) readPcap(rdr io.Reader) (int, error) {
+	pr, err := decoders.NewPcapReader(rdr)
+	if err != nil {
+		return 0, err
+	}
+	assembler := cf.start()
+	linkType := pr.Header().LinkType
+	i := -1
+	for {
+		if err := cf.ctx.Err(); err != nil {
+			return cf.batch.Stored(), fmt.Errorf("capture file reading is cancelled after %d packets: %w", i+1, err)
+		}
+		data, ci, err := pr.ReadPacket()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				log.Errorf("unable to read pcap packet after %d: %v", i, err)
+				cf.counters.AddError(view.IngestErrorRead, 1)
+			}
+			break
+		}
+		i++
+		cf.processPacket(assembler, i, linkType, data, ci)
+	}
+	return cf.finish(assembler, i+1)
+}
+
+// readPcapng
+// reads pcapng capture, each packet is decoded according to its interface link type,
+// name resolution records name the addresses and embedded TLS secrets are added to the key log
+func (cf *captureFile) readPcapng(rdr io.Reader) (int, error) {
+	pr, err := newPcapngReader(rdr, cf.reader.onNameRecord, cf.reader.onKeyLog)
+	if err != nil {
+		return 0, err
+	}
+	assembler := cf.start()
+	i := -1
+	for {
+		if err := cf.ctx.Err(); err != nil {
+			return cf.batch.Stored(), fmt.Errorf("capture file reading is cancelled after %d packets: %w", i+1, err)
+		}
+		packet, err := pr.next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				log.Errorf("unable to read pcapng packet after %d: %v", i, err)
+				cf.counters.AddError(view.IngestErrorRead, 1)
+			}
+			break
+		}
+		i++
+		for _, comment := range packet.comments {
+			log.Debugf("packet %d comment: %s", i, comment)
+		}
+		cf.processPacket(assembler, i, packet.linkType, packet.data, packet.ci)
+	}
+	return cf.finish(assembler, i+1)
+}
+
+// start
+// prepares decoding of the capture file, the decoding state is created by the first file of the chain
+func (cf *captureFile) start() *decoders.StreamAssembler {
+	chain := cf.chain
+	chain.file = cf
+	if chain.assembler == nil {
+		var err error
+		chain.protobuf, err = repository.LoadProtobufRegistry(cf.reader.db)
+		if err != nil {
+			log.Warnf("gRPC messages will be decoded without descriptors: %v", err)
+		}
+		chain.network = decoders.NewNetworkDecoder()
+		chain.inferred = make(map[string]bool)
+		chain.assembler = decoders.NewStreamAssembler(chain, cf.reader.keyLog)
+	}
+	return chain.assembler
+}
+
+// finish
+// flushes the stored data and the decoded connections of the last chain file, returns number of the stored messages
+func (cf *captureFile) finish(assembler *decoders.StreamAssembler, packetCount int) (int, error) {
+	if cf.last {
+		assembler.Flush()
+	}
+	err := cf.batch.Flush()
+	if err == nil {
+		// a batch dropped while reading fails the file, so the resumed load reads it again
+		err = cf.batch.Err()
+	}
+	cf.counters.Stored += cf.batch.Stored() - cf.batch.Duplicates()
+	cf.counters.Duplicates += cf.batch.Duplicates()
+	cf.counters.AddError(view.IngestErrorStore, cf.batch.Dropped())
+	log.Debugf("total packets: %d, HTTP messages: %d, WebSocket messages: %d, Kafka records: %d, processed messages: %d for capture %s",
+		packetCount, cf.httpMessages, cf.webSocketMessages, cf.kafkaRecords, cf.batch.Stored(), cf.captureId)
+	log.Debugf("network layer of capture %s: %s", cf.captureId, cf.chain.network.Counters())
+	if err != nil {
+		return cf.batch.Stored(), fmt.Errorf("unable to store packets: %w", err)
+	}
+	return cf.batch.Stored(), nil
+}
+
+// processPacket
+// decodes link, IP and TCP layers of the packet and passes the segment into the assembler
+func (cf *captureFile) processPacket(assembler *decoders.StreamAssembler, i int, linkType decoders.LinkType, packetData []byte, ci gopacket.CaptureInfo) {
+	var ipPacket *decoders.IPPacket = nil
+	cf.counters.Packets++
+	if len(packetData) < decoders.ETHSize && linkType == decoders.LinkTypeEthernet {
+		cf.counters.AddError(view.IngestErrorTruncated, 1)
+		return // packet too small - skip
+	}
+	ipPayLoad, pktType, err := decoders.DecodeLinkLayer(linkType, packetData)
+	if err != nil {
+		if errors.Is(err, decoders.ErrorNotAnIpPacket) {
+			cf.counters.NonIp++
+		} else {
+			log.Errorf("unable to decode link layer of packet %d. Error: %v", i, err)
+			cf.counters.AddError(view.IngestErrorLinkLayer, 1)
+		}
+		return
+	}
+	if ipPayLoad == nil {
+		log.Errorf("NIL packet with type %d at %d", pktType, i)
+		cf.counters.AddError(view.IngestErrorLinkLayer, 1)
+		return
+	}
+	// VLAN tags and tunnels (IP-in-IP, GRE, VXLAN, Geneve) are removed down to the innermost IP packet,
+	// fragments are kept until the datagram is complete
+	ipPacket, err = cf.chain.network.Decode(pktType, ipPayLoad, i, ci.Timestamp)
+	if errors.Is(err, decoders.ErrorNotAnIpPacket) {
+		log.Tracef("unsupported packet type %d at %d", pktType, i)
+		cf.counters.NonIp++
+		return
+	}
+	if err != nil {
+		log.Errorf("unable to decode IP packet %d. Error: %v", i, err)
+		cf.counters.AddError(view.IngestErrorIpLayer, 1)
+		return
+	}
+	if ipPacket == nil {
+		return // fragment of incomplete datagram
+	}
+	if ipPacket.SrcIP == view.EmptyString && ipPacket.DstIP == view.EmptyString {
+		log.Errorf("empty IP addresses in packet %d (IP layer length:%d, TCP layer length:%d)", i, len(ipPayLoad), len(ipPacket.TCP))
+		cf.counters.AddError(view.IngestErrorNoAddress, 1)
+		return
+	}
+	if ipPacket.Protocol == layers.IPProtocolUDP {
+		cf.counters.NonTcp++
+		// DNS responses name the resolved addresses
+		for _, peerName := range decoders.DecodeDnsNames(ipPacket.Payload) {
+			cf.inferName(peerName.Address, peerName.Name, view.PeerNameSourceDns)
+		}
+		return
+	}
+	if ipPacket.TCP == nil {
+		cf.counters.NonTcp++
+		return
+	}
+	df := decoders.DecodeFeedback{}
+	tcp := layers.TCP{}
+	err = tcp.DecodeFromBytes(ipPacket.TCP, &df)
+	if err != nil {
+		log.Tracef("unable to decode TCP packet %d. Error: %v\n", i, err)
+		cf.counters.AddError(view.IngestErrorTcpLayer, 1)
+		return // not a TCP packet
+	}
+	assembler.Assemble(ipPacket, &tcp, ci)
+}
+
+// OnHttpMessage
+// stores a complete HTTP message reassembled from the capture
+func (cf *captureFile) OnHttpMessage(msg *decoders.HttpMessage) {
+	cf.httpMessages++
+	if msg.IsRequest {
+		cf.counters.HttpRequests++
+	} else {
+		cf.counters.HttpResponses++
+	}
+	if msg.IsRequest && msg.Host != view.EmptyString {
+		host := msg.Host
+		if name, _, err := net.SplitHostPort(host); err == nil {
+			host = name
+		}
+		cf.inferName(msg.Flow.DstIP, host, view.PeerNameSourceHost)
+	}
+	peers := cf.flowPeers(msg.Flow, msg.Timestamp)
+	if msg.Grpc != nil {
+		// gRPC messages are stored as JSON
+		grpcServer := peers[view.DestPeer].Name
+		if !msg.IsRequest {
+			grpcServer = peers[view.SourcePeer].Name
+		}
+		if body := cf.chain.protobuf.GrpcJson(msg.Grpc, msg.IsRequest, grpcServer); body != view.EmptyString {
+			msg.Body = []byte(body)
+		}
+	}
+	ref := cf.batch.AddPacket(httpPacket(msg, peers))
+	msg.StoreRef = ref
+	if len(msg.Graphql) > 0 {
+		cf.batch.AddGraphqlOperations(graphqlOperations(msg.Graphql, cf.captureId), ref)
+	}
+}
+
+// httpPacket
+// makes a packet of the decoded message, the stored payload is the decoded body and never the raw message
+func httpPacket(msg *decoders.HttpMessage, peers []entities.ServiceAddress) entities.ParsedPacket {
+	p2s := entities.ParsedPacket{
+		Peers:         peers,
+		Ports:         make([]int, 2),
+		Timestamp:     msg.Timestamp,
+		SeqNo:         msg.SeqNo,
+		AckNo:         msg.AckNo,
+		Payload:       msg.Body,
+		StrPayload:    string(msg.Body),
+		RequestPath:   msg.Path,
+		RequestQuery:  msg.Query,
+		RequestMethod: msg.Method,
+		Headers:       msg.Headers,
+	}
+	for name, value := range msg.Trailers {
+		p2s.Headers[name] = value
+	}
+	p2s.Ports[view.SourcePeer] = msg.Flow.SrcPort
+	p2s.Ports[view.DestPeer] = msg.Flow.DstPort
+	if msg.Flow.Encapsulation != nil {
+		p2s.OuterSourceIp = msg.Flow.Encapsulation.OuterSrcIP
+		p2s.OuterDestIp = msg.Flow.Encapsulation.OuterDstIP
+		p2s.Encapsulation = msg.Flow.Encapsulation.String()
+	}
+	return p2s
+}
+
+// graphqlOperations
+// makes a table row for each root field of the request operations
+func graphqlOperations(operations []decoders.GraphqlOperation, captureId string) []entities.ServiceGraphqlOperation {
+	rows := make([]entities.ServiceGraphqlOperation, 0, len(operations))
+	for i, operation := range operations {
+		variables := view.EmptyString
+		if len(operation.Variables) > 0 && string(operation.Variables) != "null" {
+			variables = string(operation.Variables)
+		}
+		for _, field := range operation.RootFields {
+			rows = append(rows, entities.ServiceGraphqlOperation{
+				CaptureId:      captureId,
+				OperationIndex: i,
+				OperationType:  operation.Type,
+				OperationName:  operation.Name,
+				RootField:      field,
+				Variables:      variables,
+			})
+		}
+	}
+	return rows
+}
+
+// OnServerName
+// names the server address with TLS server name indication
+func (cf *captureFile) OnServerName(flow decoders.FlowInfo, serverName string) {
+	cf.inferName(flow.DstIP, serverName, view.PeerNameSourceSni)
+}
+
+// OnRejected
+// counts the stream data dropped by the decoders
+func (cf *captureFile) OnRejected(flow decoders.FlowInfo, reason string) {
+	cf.counters.AddError(reason, 1)
+}
+
+// inferName
+// names the address with the host name seen in the capture, address literals are skipped
+func (cf *captureFile) inferName(ip, name, source string) {
+	name = strings.ToLower(strings.TrimSuffix(strings.Trim(name, "[]"), "."))
+	if name == view.EmptyString || net.ParseIP(name) != nil {
+		return
+	}
+	key := source + "/" + ip
+	if cf.chain.inferred[key] {
+		return
+	}
+	cf.chain.inferred[key] = true
+	err := cf.reader.hosts.AddInferredName(ip, name, source)
+	if err != nil {
+		log.Debugf("unable to add %s name %s for %s: %v", source, name, ip, err)
+	}
+}
+
+// flowPeers
+// returns service addresses of the flow source and destination valid at the message time
+func (cf *captureFile) flowPeers(flow decoders.FlowInfo, timestamp time.Time) []entities.ServiceAddress {
+	peers := make([]entities.ServiceAddress, 2)
+	sourceService, err := cf.reader.hosts.GetServiceByIp(flow.SrcIP, timestamp)
+	if err == nil {
+		peers[view.SourcePeer] = *sourceService
+	} else {
+		log.Debugf("source ip address %s not found: %v", flow.SrcIP, err)
+		cf.counters.AddError(view.IngestErrorUnknownPeer, 1)
+	}
+	destService, err := cf.reader.hosts.GetServiceByIp(flow.DstIP, timestamp)
+	if err == nil {
+		peers[view.DestPeer] = *destService
+	} else {
+		log.Debugf("dest ip address %s not found: %v", flow.DstIP, err)
+		cf.counters.AddError(view.IngestErrorUnknownPeer, 1)
+	}
+	return peers
+}
+
+// OnHttpExchange
+// queues the request paired with its response, packet ids are set when the batch is stored
+func (cf *captureFile) OnHttpExchange(exchange *decoders.HttpExchange) {
+	requestRef, stored := exchange.Request.StoreRef.(*repository.PacketRef)
+	if !stored {
+		return // request not stored
+	}
+	var (
+		response    *entities.ExchangeMessage = nil
+		responseRef *repository.PacketRef     = nil
+	)
+	if exchange.Response != nil {
+		responseRef, stored = exchange.Response.StoreRef.(*repository.PacketRef)
+		if stored {
+			response = exchangeMessage(exchange.Response)
+		}
+	}
+	cf.batch.AddExchange(entities.MakeDbExchange(*exchangeMessage(exchange.Request), response,
+		exchange.TimeToFirstByte(), cf.captureId), requestRef, responseRef)
+}
+
+// OnWebSocketMessage
+// queues the message sent after WebSocket handshake, handshake packet id is set when the batch is stored
+func (cf *captureFile) OnWebSocketMessage(msg *decoders.WebSocketMessage) {
+	handshakeRef, stored := msg.Handshake.StoreRef.(*repository.PacketRef)
+	if !stored {
+		return // handshake not stored
+	}
+	cf.webSocketMessages++
+	cf.batch.AddWebSocketMessage(entities.ServiceWebSocketMessage{
+		CaptureId:   cf.captureId,
+		RequestPath: msg.Handshake.Path,
+		FromClient:  msg.FromClient,
+		Opcode:      msg.Opcode,
+		MessageType: msg.OpcodeName(),
+		Compressed:  msg.Compressed,
+		Payload:     entities.WebSocketPayload(msg.Opcode, msg.Payload),
+		PayloadSize: len(msg.Payload),
+		TimeStamp:   msg.Timestamp,
+		SeqNo:       msg.SeqNo,
+	}, handshakeRef)
+}
+
+// OnKafkaMessage
+// queues records of the topic partition produced or consumed over Kafka connection
+func (cf *captureFile) OnKafkaMessage(msg *decoders.KafkaMessage) {
+	cf.kafkaRecords += len(msg.Records)
+	peers := cf.flowPeers(msg.Flow, msg.Timestamp)
+	operation := entities.KafkaOperationConsume
+	if msg.ApiKey == decoders.KafkaApiProduce {
+		operation = entities.KafkaOperationProduce
+	}
+	events := make([]entities.KafkaMessageEvent, 0, len(msg.Records))
+	for i, record := range msg.Records {
+		events = append(events, entities.KafkaMessageEvent{
+			CaptureId:      cf.captureId,
+			SourceId:       peers[view.SourcePeer].Id,
+			SourcePort:     msg.Flow.SrcPort,
+			DestId:         peers[view.DestPeer].Id,
+			DestPort:       msg.Flow.DstPort,
+			Operation:      operation,
+			ApiVersion:     int(msg.ApiVersion),
+			ClientId:       msg.ClientId,
+			Topic:          msg.Topic,
+			TopicId:        msg.TopicId,
+			PartitionIndex: int(msg.Partition),
+			RecordIndex:    i,
+			RecordOffset:   record.Offset,
+			RecordKey:      entities.RecordText(record.Key),
+			RecordValue:    entities.RecordText(record.Value),
+			RecordHeaders:  record.Headers,
+			RecordTime:     record.Timestamp,
+			TimeStamp:      msg.Timestamp,
+			SeqNo:          msg.SeqNo,
+		})
+	}
+	cf.batch.AddKafkaEvents(events)
+}
+
+// exchangeMessage
+// converts decoded message into an exchange part
+func exchangeMessage(msg *decoders.HttpMessage) *entities.ExchangeMessage {
+	result := &entities.ExchangeMessage{
+		Method:     msg.Method,
+		Path:       msg.Path,
+		StatusCode: msg.StatusCode,
+		Headers:    msg.Headers,
+		Body:       msg.Body,
+		Timestamp:  msg.Timestamp,
+	}
+	if msg.Grpc != nil {
+		result.GrpcService = msg.Grpc.Service
+		result.GrpcMethod = msg.Grpc.Method
+		result.GrpcStatus = msg.Grpc.Status
+		result.GrpcMessage = msg.Grpc.StatusMessage
+	}
+	return result
+}
+
+// OnHttpMessage
+// passes the message into the file being read
+func (chain *captureChain) OnHttpMessage(msg *decoders.HttpMessage) {
+	chain.file.OnHttpMessage(msg)
+}
+
+// OnHttpExchange
+// passes the exchange into the file being read, the request may be stored by a previous file
+func (chain *captureChain) OnHttpExchange(exchange *decoders.HttpExchange) {
+	chain.file.OnHttpExchange(exchange)
+}
+
+// OnWebSocketMessage
+// passes the message into the file being read
+func (chain *captureChain) OnWebSocketMessage(msg *decoders.WebSocketMessage) {
+	chain.file.OnWebSocketMessage(msg)
+}
+
+// OnKafkaMessage
+// passes the message into the file being read
+func (chain *captureChain) OnKafkaMessage(msg *decoders.KafkaMessage) {
+	chain.file.OnKafkaMessage(msg)
+}
+
+// OnServerName
+// passes the server name into the file being read
+func (chain *captureChain) OnServerName(flow decoders.FlowInfo, serverName string) {
+	chain.file.OnServerName(flow, serverName)
+}
+
+// OnRejected
+// counts the dropped stream data into the file being read
+func (chain *captureChain) OnRejected(flow decoders.FlowInfo, reason string) {
+	chain.file.OnRejected(flow, reason)
+}
+
+// flush
+// decodes the connections left open when the last file of the chain was not read (cancelled or failed to open),
+// the messages are stored by the last read file
+func (chain *captureChain) flush() error {
+	if chain.file == nil || chain.file.last {
+		return nil
+	}
+	chain.file.last = true
+	chain.assembler.Flush()
+	return chain.file.batch.Flush()
+}
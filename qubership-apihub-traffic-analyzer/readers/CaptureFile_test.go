@@ -0,0 +1,170 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package readers
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/decoders"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/redaction"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// messageCollector
+// keeps the decoded HTTP messages and exchanges
+type messageCollector struct {
+	messages  []*decoders.HttpMessage
+	exchanges []*decoders.HttpExchange
+}
+
+func (mc *messageCollector) OnHttpMessage(msg *decoders.HttpMessage) {
+	mc.messages = append(mc.messages, msg)
+}
+
+func (mc *messageCollector) OnHttpExchange(exchange *decoders.HttpExchange) {
+	mc.exchanges = append(mc.exchanges, exchange)
+}
+
+func (mc *messageCollector) OnWebSocketMessage(*decoders.WebSocketMessage) {}
+
+func (mc *messageCollector) OnKafkaMessage(*decoders.KafkaMessage) {}
+
+func (mc *messageCollector) OnServerName(decoders.FlowInfo, string) {}
+
+func (mc *messageCollector) OnRejected(decoders.FlowInfo, string) {}
+
+// decodeConnection
+// decodes the client data and the server answer, each sent as a single TCP segment
+func decodeConnection(request, response string) *messageCollector {
+	collector := &messageCollector{}
+	assembler := decoders.NewStreamAssembler(collector, nil)
+	client, server := net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")
+	timestamp := time.Now()
+	send := func(src, dst net.IP, srcPort, dstPort layers.TCPPort, seq, ack uint32, data string) {
+		ip := &decoders.IPPacket{Version: decoders.IPv4, SrcIP: src.String(), DstIP: dst.String(), SrcAddr: src, DstAddr: dst}
+		tcp := &layers.TCP{SrcPort: srcPort, DstPort: dstPort, Seq: seq, Ack: ack, ACK: true, PSH: true}
+		tcp.Payload = []byte(data)
+		timestamp = timestamp.Add(time.Millisecond)
+		assembler.Assemble(ip, tcp, gopacket.CaptureInfo{Timestamp: timestamp, CaptureLength: len(data), Length: len(data)})
+	}
+	send(client, server, 40000, 8080, 1000, 5000, request)
+	if response != "" {
+		send(server, client, 8080, 40000, 5000, 1000+uint32(len(request)), response)
+	}
+	assembler.Flush()
+	return collector
+}
+
+// decodeRequest
+// decodes the request sent as a single TCP segment
+func decodeRequest(t *testing.T, wire string) *decoders.HttpMessage {
+	collector := decodeConnection(wire, "")
+	if len(collector.messages) != 1 {
+		t.Fatalf("expected 1 decoded message, got %d", len(collector.messages))
+	}
+	return collector.messages[0]
+}
+
+func TestStoredRequestHasNoAuthorizationValue(t *testing.T) {
+	const secret = "x0secret0token"
+	body := `{"name":"a"}`
+	wire := "POST /api/items HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"Authorization: Bearer " + secret + "\r\n" +
+		"Content-Type: application/json\r\n" +
+		"Content-Length: 12\r\n" +
+		"\r\n" + body
+	msg := decodeRequest(t, wire)
+	if !strings.Contains(string(msg.Raw), secret) {
+		t.Fatalf("raw message is expected to keep the wire text")
+	}
+
+	redactor, err := redaction.NewRedactor(view.DefaultRedactionRules(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	peers := []entities.ServiceAddress{{Id: 1}, {Id: 2}}
+	packet := httpPacket(msg, peers)
+	redactor.Packet(&packet)
+	stored := entities.MakeDbPacket(packet, "capture")
+
+	if stored.Body != body {
+		t.Errorf("stored body is expected to be the decoded body %q, got %q", body, stored.Body)
+	}
+	if strings.Contains(stored.Body, secret) {
+		t.Errorf("stored body contains the Authorization value")
+	}
+	found := false
+	for name, value := range packet.Headers {
+		header := entities.NewHttpHeader(name, value)
+		if strings.Contains(header.Value, secret) {
+			t.Errorf("stored header %s contains the Authorization value", header.Key)
+		}
+		if strings.EqualFold(name, "Authorization") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("masked Authorization header is expected to be stored")
+	}
+}
+
+func TestStoredFormBodyIsRedactedByQueryRules(t *testing.T) {
+	const secret = "x0secret0password"
+	body := "user=a&password=" + secret
+	request := "POST /login HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"Content-Type: application/x-www-form-urlencoded; charset=utf-8\r\n" +
+		"Content-Length: " + strconv.Itoa(len(body)) + "\r\n" +
+		"\r\n" + body
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 2\r\n\r\nok"
+	collector := decodeConnection(request, response)
+	if len(collector.exchanges) != 1 || collector.exchanges[0].Response == nil {
+		t.Fatalf("expected 1 answered exchange, got %d", len(collector.exchanges))
+	}
+	rules := view.RedactionRules{QueryParams: []view.RedactionRule{{Name: "password", Action: view.RedactMask}}}
+	redactor, err := redaction.NewRedactor(rules, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet := httpPacket(collector.exchanges[0].Request, []entities.ServiceAddress{{Id: 1}, {Id: 2}})
+	redactor.Packet(&packet)
+	exchange := entities.MakeDbExchange(*exchangeMessage(collector.exchanges[0].Request),
+		exchangeMessage(collector.exchanges[0].Response), collector.exchanges[0].TimeToFirstByte(), "capture")
+	redactor.Exchange(&exchange)
+
+	for name, stored := range map[string]string{
+		"packet body":           packet.StrPayload,
+		"exchange request body": exchange.RequestBody,
+	} {
+		if strings.Contains(stored, secret) {
+			t.Errorf("%s contains the form password: %s", name, stored)
+		}
+		if !strings.Contains(stored, "user=a") {
+			t.Errorf("%s lost the not redacted parameter: %s", name, stored)
+		}
+	}
+	if exchange.ResponseBody != "ok" {
+		t.Errorf("response body is expected unchanged, got %q", exchange.ResponseBody)
+	}
+}
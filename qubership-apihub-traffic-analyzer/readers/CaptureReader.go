@@ -15,34 +15,59 @@
 package readers
 
 import (
-	"bufio"
 	"bytes"
-	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"net"
 	"os"
 	"path"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/decoders"
-	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/repository"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
-	"github.com/google/gopacket"
-	"github.com/google/gopacket/layers"
-	"github.com/google/gopacket/pcap"
 	log "github.com/sirupsen/logrus"
 )
 
+// DefLoadConcurrency capture files decoded in parallel by default
+const DefLoadConcurrency = 2
+
+// CaptureSource
+// a capture file or object, Open is called by the worker reading the capture
+type CaptureSource struct {
+	Name string
+	Open func() (io.ReadCloser, error)
+}
+
+// LoadProgress
+// tracks capture files of a resumable load
+type LoadProgress interface {
+	// FileRead true when the file was read by an interrupted attempt of the load
+	FileRead(name string) bool
+	// FilesFound called with the number of the capture files before reading
+	FilesFound(count int)
+	FileStarted(name string)
+	FileFinished(name string, packets int, counters *view.IngestCounters, err error)
+}
+
 type CaptureReader interface {
 	ReadCaptureDir(captureId string, workDir string) error
 	ReadCaptureFile(captureId, inputFile string) (int, error)
+	ReadCaptureStream(captureId string, rdr io.Reader) (int, error)
+	ReadCaptures(ctx context.Context, captureId string, sources []CaptureSource) (int, error)
 	GetMetadataReader(captureId string) MetadataReader
 	ReadHostsFile2(fileName, captureId string) error
 	ReadHostsFile(fileName string) error
+	ReadKeyLogFile(fileName string) error
+	ReadKeyLogStream(name string, rdr io.Reader) error
+	SetProgress(progress LoadProgress)
 	Close() error
 }
 
@@ -51,15 +76,21 @@ func NewCaptureReader(
 	packets repository.PacketCache,
 	peers repository.ServiceAddressRepository,
 	pdb db.ConnectionProvider,
-	workDir string) CaptureReader {
+	workDir string,
+	concurrency int) CaptureReader {
+	if concurrency < 1 {
+		concurrency = DefLoadConcurrency
+	}
 	return &captureReaderImpl{
-		headers:   headers,
-		packets:   packets,
-		peers:     peers,
-		db:        pdb,
-		hosts:     nil,
-		workDir:   workDir,
-		captureId: view.EmptyString,
+		headers:     headers,
+		packets:     packets,
+		peers:       peers,
+		db:          pdb,
+		hosts:       nil,
+		workDir:     workDir,
+		captureId:   view.EmptyString,
+		keyLog:      decoders.NewTlsKeyLog(),
+		concurrency: concurrency,
 	}
 }
 
@@ -71,55 +102,234 @@ type captureReaderImpl struct {
 	db        db.ConnectionProvider
 	workDir   string
 	captureId string
+	// keyLog TLS secrets to decrypt TLS connections
+	keyLog *decoders.TlsKeyLog
+	// concurrency number of capture files decoded in parallel
+	concurrency int
+	// progress files of the resumable load, nil when the load is not tracked
+	progress LoadProgress
 }
 
 func (cr *captureReaderImpl) ReadCaptureFile(captureId, fileName string) (int, error) {
-	cr.captureId = captureId
-	if strings.HasSuffix(fileName, view.CompressedSuffix) {
-		cs, err := os.Open(fileName)
+	fh, err := os.Open(fileName)
+	if err != nil {
+		return 0, err
+	}
+	defer func(fh *os.File) {
+		err := fh.Close()
 		if err != nil {
-			return 0, err
+			log.Errorf("unable to close capture file %s. Error: %v", path.Base(fileName), err)
 		}
-		defer func(cs *os.File) {
-			err := cs.Close()
-			if err != nil {
-				log.Errorf("unable to close compressed file %s. Error: %v", path.Base(fileName), err)
-			}
-		}(cs)
-		zr, err := gzip.NewReader(cs)
-		if zr == nil || err != nil {
-			if err != nil {
-				log.Errorf("unable to uncompress file %s. Error: %v", path.Base(fileName), err)
-			} else {
-				return 0, fmt.Errorf("unable to uncompress file %s", path.Base(fileName))
-			}
+	}(fh)
+	return cr.ReadCaptureStream(captureId, fh)
+}
+
+// ReadCaptureStream
+// reads pcap or pcapng capture from the stream, compressed stream is uncompressed on the fly
+func (cr *captureReaderImpl) ReadCaptureStream(captureId string, rdr io.Reader) (int, error) {
+	return cr.readCaptureStream(context.Background(), captureId, rdr, &view.IngestCounters{}, &captureChain{}, true)
+}
+
+// readCaptureStream
+// reads the capture stream until the context is cancelled, packets and messages are counted into the counters.
+// Decoding continues the chain state, the connections left open are flushed after the last file of the chain
+func (cr *captureReaderImpl) readCaptureStream(ctx context.Context, captureId string, rdr io.Reader,
+	counters *view.IngestCounters, chain *captureChain, last bool) (int, error) {
+	if cr.hosts == nil {
+		return -1, fmt.Errorf("no hosts file for capture: %s", captureId)
+	}
+	br, err := decoders.Decompressed(rdr)
+	if err != nil {
+		return 0, err
+	}
+	cf := &captureFile{
+		ctx:       ctx,
+		reader:    cr,
+		captureId: captureId,
+		batch:     cr.packets.NewPacketBatch(captureId),
+		counters:  counters,
+		chain:     chain,
+		last:      last,
+	}
+	signature, err := br.Peek(len(pcapngSignature))
+	if err == nil && bytes.Equal(signature, pcapngSignature) {
+		return cf.readPcapng(br)
+	}
+	return cf.readPcap(br)
+}
+
+// ReadCaptures
+// reads the capture files by a bounded pool of workers, returns number of the stored messages. Files are grouped
+// into chains by time, each chain is read by one worker. Chains are not started and the files being read are
+// abandoned once the context is cancelled
+func (cr *captureReaderImpl) ReadCaptures(ctx context.Context, captureId string, sources []CaptureSource) (int, error) {
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		total    = 0
+		failures = make([]error, 0)
+		counters = view.IngestCounters{}
+	)
+	if cr.progress != nil {
+		cr.progress.FilesFound(len(sources))
+	}
+	pending := make([]CaptureSource, 0, len(sources))
+	for _, source := range sources {
+		if cr.progress != nil && cr.progress.FileRead(source.Name) {
+			log.Debugf("capture file '%s' was read before, skipped", source.Name)
+			continue
 		}
-		defer func(zr *gzip.Reader) {
-			err := zr.Close()
-			if err != nil {
-				log.Errorf("unable to close decompressor for file %s. Error: %v", path.Base(fileName), err)
-			}
-		}(zr)
-		tmpFileName := fileName + ".tmp"
-		fth, err := os.Create(tmpFileName)
+		pending = append(pending, source)
+	}
+	readFile := func(source CaptureSource, chain *captureChain, last bool) {
+		tStart := time.Now()
+		if cr.progress != nil {
+			cr.progress.FileStarted(source.Name)
+		}
+		fileCounters := &view.IngestCounters{}
+		count, err := cr.readCaptureSource(ctx, captureId, source, fileCounters, chain, last)
+		if cr.progress != nil {
+			cr.progress.FileFinished(source.Name, count, fileCounters, err)
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		total += count
+		counters.Add(*fileCounters)
 		if err != nil {
-			log.Errorf("unable to create intermediate file '%s'. Error: %v", tmpFileName, err)
+			log.Errorf("unable to process capture file '%s'. Error: %v", source.Name, err)
+			failures = append(failures, fmt.Errorf("%s: %w", source.Name, err))
 		} else {
-			defer func(fth *os.File) {
-				err := fth.Close()
-				if err != nil {
-					log.Errorf("unable to close intermedate file '%s'. Error: %v", tmpFileName, err)
+			log.Debugf("capture file '%s' read in %v, packets processed %d", source.Name, time.Since(tStart), count)
+		}
+	}
+	workers := make(chan struct{}, cr.concurrency)
+	for _, files := range captureChains(pending) {
+		select {
+		case workers <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(files []CaptureSource) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			chain := &captureChain{}
+			for i, source := range files {
+				if ctx.Err() != nil {
+					break
 				}
-			}(fth)
-			_, err = io.Copy(fth, zr)
+				readFile(source, chain, i == len(files)-1)
+			}
+			err := chain.flush()
 			if err != nil {
-				log.Errorf("unable to write uncompressed data. error: %v", err)
-				return 0, err
+				log.Errorf("unable to store connections left open by capture file '%s'. Error: %v", files[len(files)-1].Name, err)
 			}
-			return cr.readPackets(captureId, tmpFileName)
+		}(files)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		failures = append(failures, fmt.Errorf("capture reading is cancelled: %w", ctx.Err()))
+	}
+	log.Printf("capture '%s' ingest summary: %s", captureId, counters)
+	return total, errors.Join(failures...)
+}
+
+// readCaptureSource
+// opens and reads the capture
+func (cr *captureReaderImpl) readCaptureSource(ctx context.Context, captureId string, source CaptureSource,
+	counters *view.IngestCounters, chain *captureChain, last bool) (int, error) {
+	rc, err := source.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer func(rc io.ReadCloser) {
+		err := rc.Close()
+		if err != nil {
+			log.Errorf("unable to close capture file %s. Error: %v", source.Name, err)
 		}
+	}(rc)
+	return cr.readCaptureStream(ctx, captureId, rc, counters, chain, last)
+}
+
+// rotatedFileDigits numbers and time stamps of rotated capture file names
+var rotatedFileDigits = regexp.MustCompile(`[0-9]+`)
+
+// captureChains
+// groups the capture files by time: files of a capture point (rotated files, their names differ by digits
+// only) are ordered by the first packet time and make a chain, so the connections continue from a file
+// into the next one. Chains are ordered by their first file names
+func captureChains(sources []CaptureSource) [][]CaptureSource {
+	type timedSource struct {
+		source CaptureSource
+		start  time.Time
 	}
-	return cr.readPackets(captureId, fileName)
+	keys := make([]string, 0)
+	groups := make(map[string][]timedSource)
+	for _, source := range sources {
+		key := rotatedFileDigits.ReplaceAllString(strings.TrimSuffix(path.Base(source.Name), view.CompressedSuffix), "#")
+		if _, exists := groups[key]; !exists {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], timedSource{source: source, start: firstPacketTime(source)})
+	}
+	chains := make([][]CaptureSource, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		sort.SliceStable(group, func(i, j int) bool {
+			if group[i].start.Equal(group[j].start) {
+				return group[i].source.Name < group[j].source.Name
+			}
+			return group[i].start.Before(group[j].start)
+		})
+		chain := make([]CaptureSource, 0, len(group))
+		for _, item := range group {
+			chain = append(chain, item.source)
+		}
+		chains = append(chains, chain)
+	}
+	return chains
+}
+
+// firstPacketTime
+// reads time stamp of the first packet, zero time when the capture is not readable (the error is reported
+// when the file is read)
+func firstPacketTime(source CaptureSource) time.Time {
+	rc, err := source.Open()
+	if err != nil {
+		return time.Time{}
+	}
+	defer func(rc io.ReadCloser) {
+		_ = rc.Close()
+	}(rc)
+	br, err := decoders.Decompressed(rc)
+	if err != nil {
+		return time.Time{}
+	}
+	signature, err := br.Peek(len(pcapngSignature))
+	if err == nil && bytes.Equal(signature, pcapngSignature) {
+		pr, err := newPcapngReader(br, nil, nil)
+		if err != nil {
+			return time.Time{}
+		}
+		packet, err := pr.next()
+		if err != nil {
+			return time.Time{}
+		}
+		return packet.ci.Timestamp
+	}
+	pr, err := decoders.NewPcapReader(br)
+	if err != nil {
+		return time.Time{}
+	}
+	_, ci, err := pr.ReadPacket()
+	if err != nil {
+		return time.Time{}
+	}
+	return ci.Timestamp
 }
 
 func (cr *captureReaderImpl) ReadCaptureDir(captureId string, workDir string) error {
@@ -174,6 +384,14 @@ func (cr *captureReaderImpl) ReadCaptureDir(captureId string, workDir string) er
 		if strings.HasSuffix(name, view.CompressedSuffix) {
 			name = strings.TrimSuffix(name, view.CompressedSuffix)
 		}
+		if strings.HasSuffix(name, view.KeyLogSuffix) {
+			err = cr.ReadKeyLogFile(inputFilename)
+			if err != nil {
+				log.Errorf("unable to read TLS key log file '%s'. Error: %v", item.Name(), err)
+			} else {
+				log.Printf("read TLS key log file '%s' successfully", item.Name())
+			}
+		}
 		if strings.HasSuffix(name, ".txt") {
 			// service name list
 			err = cr.ReadHostsFile2(inputFilename, captureId)
@@ -198,6 +416,7 @@ func (cr *captureReaderImpl) ReadCaptureDir(captureId string, workDir string) er
 		}
 	}
 	// read captures
+	sources := make([]CaptureSource, 0)
 	for _, item := range items {
 		if item.IsDir() {
 			continue
@@ -210,18 +429,29 @@ func (cr *captureReaderImpl) ReadCaptureDir(captureId string, workDir string) er
 			name = strings.TrimSuffix(name, view.CompressedSuffix)
 		}
 		inputFilename := path.Join(workDir, item.Name())
-		if strings.HasSuffix(name, ".pcap") {
-			packetCount, err := cr.ReadCaptureFile(captureId, inputFilename)
-			if err != nil {
-				log.Errorf("unable to process capture file '%s'. Error: %v", item.Name(), err)
-			} else {
-				log.Debugf("capture file '%s' read successfully, packets processed %d", item.Name(), packetCount)
-			}
+		if strings.HasSuffix(name, view.CaptureSuffix) || strings.HasSuffix(name, view.CaptureNgSuffix) {
+			sources = append(sources, CaptureSource{
+				Name: item.Name(),
+				Open: func() (io.ReadCloser, error) {
+					return os.Open(inputFilename)
+				},
+			})
 		}
 	}
+	packetCount, err := cr.ReadCaptures(context.Background(), captureId, sources)
+	log.Debugf("%d capture file(s) read, packets processed %d", len(sources), packetCount)
+	if err != nil {
+		log.Errorf("unable to process capture files: %v", err)
+	}
 	return nil
 }
 
+// SetProgress
+// sets the load progress tracking, the files read before are skipped
+func (cr *captureReaderImpl) SetProgress(progress LoadProgress) {
+	cr.progress = progress
+}
+
 func (cr *captureReaderImpl) GetMetadataReader(captureId string) MetadataReader {
 	return NewMetadataReader(cr.db, captureId)
 }
@@ -249,249 +479,61 @@ func (cr *captureReaderImpl) ReadHostsFile(fileName string) error {
 	return cr.hosts.Read(fileName)
 }
 
-func (cr *captureReaderImpl) readPackets(captureId, fileName string) (int, error) {
-	if cr.hosts == nil {
-		return -1, fmt.Errorf("no hosts file for capture: %s", captureId)
-	}
-	handle, err := pcap.OpenOffline(fileName)
+// ReadKeyLogFile
+// adds TLS secrets from SSLKEYLOGFILE format file (optionally compressed)
+func (cr *captureReaderImpl) ReadKeyLogFile(fileName string) error {
+	fh, err := os.Open(fileName)
 	if err != nil {
-		log.Printf("unable to open offline capture %s. Error: %v", fileName, err)
-		return 0, err
+		return err
 	}
-	defer handle.Close()
-	packetSource := gopacket.NewPacketSource(handle, handle.LinkType())
-	i := -1
-	df := decoders.DecodeFeedback{}
-	processedPackets := 0
-	httpPackets := 0
-	for packet := range packetSource.Packets() {
-		i++
-		var (
-			ls1                           = view.EmptyString
-			ipPacket   *decoders.IPPacket = nil
-			pktType                       = layers.EthernetTypeLLC
-			ipPayLoad  []byte             = nil
-			packetData []byte             = nil
-		)
-		packetData = packet.Data()
-		if len(packetData) < decoders.ETHSize {
-			continue // packet too small - skip
-		}
-		sll, err := decoders.DetectAndParseSll(packet.Data())
+	defer func(fh *os.File) {
+		err := fh.Close()
 		if err != nil {
-			if errors.Is(err, decoders.ErrorNotAnIpPacket) {
-				continue // skip
-			}
-			etl := layers.Ethernet{}
-			err = etl.DecodeFromBytes(packet.Data(), &df)
-			if err != nil {
-				log.Errorf("unable to decode ETH packet %d. Error: %v", i, err)
-				continue
-			} else {
-				ipPayLoad = etl.Payload
-				pktType = etl.EthernetType
-
-			}
-		} else {
-			ipPayLoad = sll.Payload
-			pktType = sll.EthernetType
-		}
-		if ipPayLoad != nil {
-			switch pktType {
-			case layers.EthernetTypeIPv4:
-				ipPacket, err = decoders.DecodeIp(ipPayLoad, false, i)
-			case layers.EthernetTypeIPv6:
-				ipPacket, err = decoders.DecodeIp(ipPayLoad, true, i)
-			default:
-				{
-					log.Tracef("unsupported packet type %d at %d", pktType, i)
-					continue
-				}
-			}
-		} else {
-			log.Errorf("NIL packet with type %d at %d", pktType, i)
-			continue
-		}
-		if err != nil {
-			log.Errorf("unable to decode IP packet %d. Error: %v", i, err)
-			continue
-		}
-		if ipPacket == nil {
-			log.Errorf("no error, but IP packet is nil at %d", i)
-			continue
-		}
-		if ipPacket.SrcIP == view.EmptyString && ipPacket.DstIP == view.EmptyString {
-			log.Errorf("empty IP addresses in packet %d (IP layer length:%d, TCP layer length:%d)", i, len(ipPayLoad), len(ipPacket.TCP))
-			continue
-		}
-		peers := make([]entities.ServiceAddress, 2)
-		sourceService, err := cr.hosts.GetServiceByIp(ipPacket.SrcIP)
-		if err == nil {
-			peers[view.SourcePeer] = *sourceService
-		} else {
-			log.Debugf("source ip address %s not found: %v", ipPacket.SrcIP, err)
-		}
-		destService, err := cr.hosts.GetServiceByIp(ipPacket.DstIP)
-		if err == nil {
-			peers[view.DestPeer] = *destService
-		} else {
-			log.Debugf("dest ip address %s not found: %v", ipPacket.DstIP, err)
-		}
-		if ipPacket.TCP == nil {
-			continue
-		}
-		tcp := layers.TCP{}
-		err = tcp.DecodeFromBytes(ipPacket.TCP, &df)
-		if err != nil {
-			log.Tracef("unable to decode TCP packet %d. Error: %v\n", i, err)
-			continue // not a TCP packet
-		}
-		headers := make(map[string]string)
-		tcpPayLoad := tcp.Payload
-		reqPath := view.EmptyString
-		reqMethod := view.EmptyString
-		if tcpPayLoad != nil {
-			pt, parsedFields := decoders.DetectHttp(tcpPayLoad)
-			bHttp := false
-			switch pt {
-			case decoders.PTHttpReq:
-				{
-					reqPath = fmt.Sprint(parsedFields[decoders.RequestPath])
-					reqMethod = fmt.Sprint(parsedFields[decoders.RequestMethod])
-					httpPackets++
-					bHttp = true
-					ls1 = string(tcpPayLoad)
-					br := bytes.NewReader(tcpPayLoad)
-					if br != nil {
-						req, err := http.ReadRequest(bufio.NewReader(br))
-						if err == nil {
-							if req != nil {
-								// headers
-								for headerName, headerValue := range req.Header {
-									headerString := strings.Join(headerValue, "\n")
-									if headerString != view.EmptyString {
-										headers[headerName] = headerString
-									}
-								}
-								// request!
-								reqMethod = req.Method
-								reqPath = req.URL.Path
-								bodyResult := decoders.BodyToString(req.Body, true)
-								if bodyResult.Err == nil {
-									if bodyResult.Body != nil {
-										if ls1 == view.EmptyString {
-											ls1 = string(bodyResult.Body)
-										}
-									}
-								} else {
-									if !strings.HasSuffix(bodyResult.Err.Error(), "unexpected EOF") {
-										log.Tracef("unable to decode request %d body: %v", i, bodyResult.Err)
-									}
-								}
-							} else {
-								log.Tracef("request is nil")
-							}
-						} else {
-							log.Tracef("unable to detect http request at %d:%v", i, err)
-						}
-					} else {
-						log.Tracef("request bufreade is nil '%s' at %d:%v", ls1, i, err)
-					}
-				}
-			case decoders.PTHttpResp:
-				{
-					httpPackets++
-					reqMethod = fmt.Sprintf("%s %s", parsedFields[decoders.ResponseStatus], parsedFields[decoders.ResponseStatusText])
-					bHttp = true
-					ls1 = string(tcpPayLoad)
-					br := bytes.NewReader(tcpPayLoad)
-					resp, err := http.ReadResponse(bufio.NewReader(br), nil)
-					if err == nil {
-						if resp != nil {
-							reqMethod = resp.Status
-							// headers
-							for headerName, headerValue := range resp.Header {
-								headerString := strings.Join(headerValue, "\n")
-								if len(headerString) > 0 {
-									headers[headerName] = headerString
-								}
-							}
-							// response!
-							if resp.Request != nil {
-								reqPath = resp.Request.URL.Path
-							}
-							bodyResult := decoders.BodyToString(resp.Body, resp.Uncompressed)
-							if bodyResult.Err == nil {
-								if bodyResult.Body != nil {
-									if ls1 == view.EmptyString {
-										ls1 = string(bodyResult.Body)
-									}
-								}
-							} else {
-								if !strings.HasSuffix(bodyResult.Err.Error(), "unexpected EOF") {
-									log.Tracef("unable to decode response %d body: %v", i, bodyResult.Err)
-								}
-							}
-						} else {
-							log.Tracef("response is nil")
-						}
-					} else {
-						if !errors.Is(err, io.ErrUnexpectedEOF) {
-							log.Tracef("unable to detect http response at %d:%v", i, err)
-						}
-					}
-				}
-			case decoders.PTHttp:
-				{
-					pos := 0
-					if iPos, bff := parsedFields[decoders.RHOffset]; bff {
-						if pos, bff = iPos.(int); !bff {
-							pos = 0
-						} else {
-							if pos < 0 {
-								pos = 0
-							}
-						}
-					}
-					ls1 = string(tcpPayLoad[pos:])
-				}
-			case decoders.PTNotHttp:
-				break // log.Debugf("not a HTTP packet %d\n", i)
-			default:
-				log.Debug("unhandled default case")
-			}
-			if ls1 == view.EmptyString && reqMethod == view.EmptyString && reqPath == view.EmptyString {
-				if bHttp {
-					log.Debugf("http packet %d rejected (%s,%s,%s)", i, reqMethod, reqPath, ls1)
-				}
-				continue
-			}
-			p2s := entities.ParsedPacket{
-				Peers:         nil,
-				Ports:         make([]int, 2),
-				Timestamp:     packet.Metadata().Timestamp,
-				SeqNo:         int(tcp.Seq),
-				AckNo:         int(tcp.Ack),
-				Payload:       tcpPayLoad,
-				StrPayload:    ls1,
-				RequestPath:   reqPath,
-				RequestMethod: reqMethod,
-				Headers:       headers,
-			}
-			p2s.Headers = headers
-			p2s.Peers = peers
-			p2s.Ports[view.SourcePeer] = int(tcp.SrcPort)
-			p2s.Ports[view.DestPeer] = int(tcp.DstPort)
-			err = cr.packets.StorePacket(p2s, cr.headers, captureId)
-			if err == nil {
-				processedPackets++
-			} else {
-				log.Debugf("unable to store packet %d:%v", i, err)
-			}
+			log.Errorf("unable to close key log file %s. Error: %v", path.Base(fileName), err)
 		}
+	}(fh)
+	return cr.ReadKeyLogStream(path.Base(fileName), fh)
+}
+
+// ReadKeyLogStream
+// adds TLS secrets from SSLKEYLOGFILE format stream (optionally compressed), the secrets are kept in memory only
+func (cr *captureReaderImpl) ReadKeyLogStream(name string, rdr io.Reader) error {
+	br, err := decoders.Decompressed(rdr)
+	if err != nil {
+		return fmt.Errorf("unable to uncompress key log file %s: %w", name, err)
+	}
+	count, skipped, err := cr.keyLog.Read(br)
+	if skipped > 0 {
+		log.Warnf("%d malformed line(s) skipped in key log file %s", skipped, name)
+	}
+	log.Debugf("%d TLS secrets read from %s", count, name)
+	return err
+}
+
+// onNameRecord
+// names the address from pcapng name resolution block
+func (cr *captureReaderImpl) onNameRecord(ip net.IP, names []string) {
+	if len(names) == 0 {
+		// a record without names (malformed block)
+		return
+	}
+	err := cr.hosts.AddNameRecord(ip.String(), names[0])
+	if err != nil {
+		log.Debugf("unable to add name record %s for %s: %v", names[0], ip, err)
+	}
+}
+
+// onKeyLog
+// adds TLS secrets from pcapng decryption secrets block
+func (cr *captureReaderImpl) onKeyLog(keyLog []byte) {
+	count, skipped, err := cr.keyLog.Read(bytes.NewReader(keyLog))
+	if err != nil {
+		log.Warnf("unable to read TLS secrets embedded into capture: %v", err)
+	}
+	if skipped > 0 {
+		log.Warnf("%d malformed line(s) skipped in TLS secrets embedded into capture", skipped)
 	}
-	log.Debugf("total packets: %d, HTTP packets: %d,  processed packets: %d for capture %s", i, httpPackets, processedPackets, captureId)
-	return processedPackets, nil
+	log.Debugf("%d TLS secrets read from capture", count)
 }
 
 // Close
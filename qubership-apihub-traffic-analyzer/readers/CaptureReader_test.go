@@ -0,0 +1,268 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package readers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/repository"
+	"github.com/go-pg/pg/v10"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// tcpSegment
+// a TCP segment of the test connection 10.0.0.1:40000 - 10.0.0.2:8080
+type tcpSegment struct {
+	fromClient bool
+	seq, ack   uint32
+	data       string
+	at         time.Time
+}
+
+// pcapFile
+// writes Ethernet pcap file of the segments
+func pcapFile(t *testing.T, segments ...tcpSegment) []byte {
+	buf := &bytes.Buffer{}
+	w := pcapgo.NewWriter(buf)
+	err := w.WriteFileHeader(65535, layers.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, server := net.ParseIP("10.0.0.1").To4(), net.ParseIP("10.0.0.2").To4()
+	for _, segment := range segments {
+		ip := &layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolTCP, SrcIP: client, DstIP: server}
+		tcp := &layers.TCP{SrcPort: 40000, DstPort: 8080, Seq: segment.seq, Ack: segment.ack, ACK: true, PSH: true, Window: 65535}
+		if !segment.fromClient {
+			ip.SrcIP, ip.DstIP = server, client
+			tcp.SrcPort, tcp.DstPort = 8080, 40000
+		}
+		err = tcp.SetNetworkLayerForChecksum(ip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eth := &layers.Ethernet{
+			SrcMAC:       net.HardwareAddr{0x02, 0x42, 0xac, 0x11, 0x00, 0x01},
+			DstMAC:       net.HardwareAddr{0x02, 0x42, 0xac, 0x11, 0x00, 0x02},
+			EthernetType: layers.EthernetTypeIPv4,
+		}
+		packet := gopacket.NewSerializeBuffer()
+		err = gopacket.SerializeLayers(packet, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true},
+			eth, ip, tcp, gopacket.Payload(segment.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := packet.Bytes()
+		err = w.WritePacket(gopacket.CaptureInfo{Timestamp: segment.at, CaptureLength: len(data), Length: len(data)}, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// memorySource
+// capture source of the data, nil data fails to open
+func memorySource(name string, data []byte) CaptureSource {
+	return CaptureSource{
+		Name: name,
+		Open: func() (io.ReadCloser, error) {
+			if data == nil {
+				return nil, errors.New("no such file")
+			}
+			return io.NopCloser(bytes.NewReader(data)), nil
+		},
+	}
+}
+
+func TestCaptureChains(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	file := func(offset time.Duration) []byte {
+		return pcapFile(t, tcpSegment{fromClient: true, seq: 1, data: "x", at: start.Add(offset)})
+	}
+	tests := []struct {
+		name    string
+		sources []CaptureSource
+		want    string
+	}{
+		{
+			name: "rotated files are ordered by the first packet time",
+			sources: []CaptureSource{
+				memorySource("c1_1.pcap", file(30*time.Second)),
+				memorySource("c1_10.pcap", file(10*time.Second)),
+				memorySource("c1_2.pcap", file(20*time.Second)),
+			},
+			want: "c1_10.pcap,c1_2.pcap,c1_1.pcap",
+		},
+		{
+			name: "capture points make separate chains",
+			sources: []CaptureSource{
+				memorySource("c1_node-a_00002.pcap", file(20*time.Second)),
+				memorySource("c1_pod-b_00001.pcap", file(10*time.Second)),
+				memorySource("c1_node-a_00001.pcap", file(10*time.Second)),
+			},
+			want: "c1_node-a_00001.pcap,c1_node-a_00002.pcap;c1_pod-b_00001.pcap",
+		},
+		{
+			name: "not readable file starts the chain",
+			sources: []CaptureSource{
+				memorySource("c1_1.pcap", file(0)),
+				memorySource("c1_2.pcap", nil),
+			},
+			want: "c1_2.pcap,c1_1.pcap",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chains := make([]string, 0)
+			for _, chain := range captureChains(tt.sources) {
+				names := make([]string, 0)
+				for _, source := range chain {
+					names = append(names, source.Name)
+				}
+				chains = append(chains, strings.Join(names, ","))
+			}
+			if got := strings.Join(chains, ";"); got != tt.want {
+				t.Errorf("captureChains() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeHosts
+// names every address as the unknown service
+type fakeHosts struct{}
+
+func (fakeHosts) Read(string) error { return nil }
+
+func (fakeHosts) GetServiceByIp(string, time.Time) (*entities.ServiceAddress, error) {
+	return &entities.ServiceAddress{Id: 1}, nil
+}
+
+func (fakeHosts) AddNameRecord(string, string) error { return nil }
+
+func (fakeHosts) AddInferredName(string, string, string) error { return nil }
+
+func (fakeHosts) Close() error { return nil }
+
+// storedExchange
+// an exchange queued into a file batch
+type storedExchange struct {
+	path     string
+	answered bool
+}
+
+// fakeBatch
+// keeps the queued packets and exchanges in memory
+type fakeBatch struct {
+	packets   int
+	exchanges []storedExchange
+}
+
+func (fb *fakeBatch) AddPacket(entities.ParsedPacket) *repository.PacketRef {
+	fb.packets++
+	return &repository.PacketRef{PacketId: fb.packets}
+}
+
+func (fb *fakeBatch) AddExchange(exchange entities.ServiceExchange, _, response *repository.PacketRef) {
+	fb.exchanges = append(fb.exchanges, storedExchange{path: exchange.RequestPath, answered: response != nil})
+}
+
+func (fb *fakeBatch) AddWebSocketMessage(entities.ServiceWebSocketMessage, *repository.PacketRef) {}
+
+func (fb *fakeBatch) AddGraphqlOperations([]entities.ServiceGraphqlOperation, *repository.PacketRef) {
+}
+
+func (fb *fakeBatch) AddKafkaEvents([]entities.KafkaMessageEvent) {}
+
+func (fb *fakeBatch) Flush() error { return nil }
+
+func (fb *fakeBatch) Stored() int { return fb.packets }
+
+func (fb *fakeBatch) Duplicates() int { return 0 }
+
+func (fb *fakeBatch) Dropped() int { return 0 }
+
+func (fb *fakeBatch) Err() error { return nil }
+
+// fakePackets
+// creates a memory batch for each file
+type fakePackets struct {
+	lock    sync.Mutex
+	batches []*fakeBatch
+}
+
+func (fp *fakePackets) GetPacketCount(string) (int, error) { return 0, nil }
+
+func (fp *fakePackets) NewPacketBatch(string) repository.PacketBatch {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+	batch := &fakeBatch{}
+	fp.batches = append(fp.batches, batch)
+	return batch
+}
+
+func (fp *fakePackets) Close() {}
+
+// noDb
+// DB which is not available, protobuf descriptors are not loaded
+type noDb struct {
+	db *pg.DB
+}
+
+func (nd noDb) GetConnection() *pg.DB { return nd.db }
+
+func TestExchangeSpanningRotatedFiles(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	request := "GET /items HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	response := "HTTP/1.1 200 OK\r\nContent-Length: 2\r\n\r\nok"
+	sources := []CaptureSource{
+		// the response is written into the next rotated file
+		memorySource("c1_00002.pcap", pcapFile(t,
+			tcpSegment{fromClient: false, seq: 5000, ack: 1000 + uint32(len(request)), data: response, at: start.Add(time.Second)})),
+		memorySource("c1_00001.pcap", pcapFile(t,
+			tcpSegment{fromClient: true, seq: 1000, ack: 5000, data: request, at: start})),
+	}
+	conn := pg.Connect(&pg.Options{Addr: "127.0.0.1:1", DialTimeout: time.Second})
+	defer func() {
+		_ = conn.Close()
+	}()
+	packets := &fakePackets{}
+	reader := &captureReaderImpl{hosts: fakeHosts{}, packets: packets, db: noDb{db: conn}, concurrency: 2}
+	count, err := reader.ReadCaptures(context.Background(), "c1", sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("stored %d messages, want 2", count)
+	}
+	exchanges := make([]storedExchange, 0)
+	for _, batch := range packets.batches {
+		exchanges = append(exchanges, batch.exchanges...)
+	}
+	if len(exchanges) != 1 || !exchanges[0].answered || exchanges[0].path != "/items" {
+		t.Errorf("exchanges %v, want the answered request of /items", exchanges)
+	}
+}
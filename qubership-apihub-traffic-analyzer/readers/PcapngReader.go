@@ -0,0 +1,399 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package readers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"net"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/decoders"
+	"github.com/google/gopacket"
+)
+
+const (
+	pcapngBlockSectionHeader     uint32 = 0x0A0D0D0A
+	pcapngBlockInterface         uint32 = 0x00000001
+	pcapngBlockPacket            uint32 = 0x00000002 // obsolete packet block
+	pcapngBlockSimplePacket      uint32 = 0x00000003
+	pcapngBlockNameResolution    uint32 = 0x00000004
+	pcapngBlockEnhancedPacket    uint32 = 0x00000006
+	pcapngBlockDecryptionSecrets uint32 = 0x0000000A
+
+	pcapngByteOrderMagic uint32 = 0x1A2B3C4D
+	// pcapngMaxBlockSize sanity limit for a block size
+	pcapngMaxBlockSize = 256 * 1024 * 1024
+
+	pcapngOptionEnd        uint16 = 0
+	pcapngOptionComment    uint16 = 1
+	pcapngOptionTsResol    uint16 = 9
+	pcapngOptionTsOffset   uint16 = 14
+	pcapngRecordEnd        uint16 = 0
+	pcapngRecordIPv4       uint16 = 1
+	pcapngRecordIPv6       uint16 = 2
+	pcapngSecretsTlsKeyLog uint32 = 0x544c534b
+)
+
+// pcapngSignature section header block type bytes starting pcapng stream of any byte order
+var pcapngSignature = []byte{0x0A, 0x0D, 0x0D, 0x0A}
+
+// ErrorNotPcapng the data is not pcapng
+var ErrorNotPcapng = errors.New("not a pcapng file")
+
+// pcapngInterface
+// capture interface described in the section
+type pcapngInterface struct {
+	linkType decoders.LinkType
+	snapLen  uint32
+	// tsUnits time stamp units per second
+	tsUnits uint64
+	// tsOffset seconds to add to time stamps
+	tsOffset int64
+}
+
+// pcapngPacket
+// a packet with its interface link type and comments
+type pcapngPacket struct {
+	data     []byte
+	ci       gopacket.CaptureInfo
+	linkType decoders.LinkType
+	comments []string
+}
+
+// pcapngReader
+// pure Go pcapng reader, name resolution records and TLS secrets are passed into the callbacks
+type pcapngReader struct {
+	r          *bufio.Reader
+	order      binary.ByteOrder
+	interfaces []pcapngInterface
+	// onName is called for each name resolution record
+	onName func(ip net.IP, names []string)
+	// onSecrets is called for TLS key log from the decryption secrets block
+	onSecrets func(keyLog []byte)
+}
+
+// newPcapngReader
+// creates a reader and reads the first section header
+func newPcapngReader(r io.Reader, onName func(net.IP, []string), onSecrets func([]byte)) (*pcapngReader, error) {
+	pr := &pcapngReader{
+		r:          bufio.NewReaderSize(r, 64*1024),
+		order:      binary.LittleEndian,
+		interfaces: nil,
+		onName:     onName,
+		onSecrets:  onSecrets,
+	}
+	blockType, _, err := pr.readBlock()
+	if err != nil {
+		return nil, err
+	}
+	if blockType != pcapngBlockSectionHeader {
+		return nil, ErrorNotPcapng
+	}
+	return pr, nil
+}
+
+// next
+// returns the next packet, io.EOF at the end of data
+func (pr *pcapngReader) next() (*pcapngPacket, error) {
+	for {
+		blockType, body, err := pr.readBlock()
+		if err != nil {
+			return nil, err
+		}
+		switch blockType {
+		case pcapngBlockInterface:
+			err = pr.readInterface(body)
+		case pcapngBlockEnhancedPacket:
+			return pr.readEnhancedPacket(body)
+		case pcapngBlockSimplePacket:
+			return pr.readSimplePacket(body)
+		case pcapngBlockPacket:
+			return pr.readObsoletePacket(body)
+		case pcapngBlockNameResolution:
+			pr.readNameResolution(body)
+		case pcapngBlockDecryptionSecrets:
+			pr.readDecryptionSecrets(body)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readBlock
+// reads the next block, a new section resets byte order and interfaces
+func (pr *pcapngReader) readBlock() (uint32, []byte, error) {
+	header := make([]byte, 12)
+	_, err := io.ReadFull(pr.r, header[:8])
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, nil, fmt.Errorf("truncated pcapng block header: %w", err)
+		}
+		return 0, nil, err
+	}
+	blockType := pr.order.Uint32(header[0:4])
+	if blockType == pcapngBlockSectionHeader {
+		// byte order is defined by the section header magic
+		_, err = io.ReadFull(pr.r, header[8:12])
+		if err != nil {
+			return 0, nil, fmt.Errorf("truncated pcapng section header: %w", err)
+		}
+		switch pcapngByteOrderMagic {
+		case binary.LittleEndian.Uint32(header[8:12]):
+			pr.order = binary.LittleEndian
+		case binary.BigEndian.Uint32(header[8:12]):
+			pr.order = binary.BigEndian
+		default:
+			return 0, nil, ErrorNotPcapng
+		}
+		pr.interfaces = nil
+	}
+	length := pr.order.Uint32(header[4:8])
+	if length < 12 || length%4 != 0 || length > pcapngMaxBlockSize {
+		return 0, nil, fmt.Errorf("invalid pcapng block length %d", length)
+	}
+	read := 8
+	if blockType == pcapngBlockSectionHeader {
+		read = 12
+	}
+	block := make([]byte, length)
+	copy(block, header[:read])
+	_, err = io.ReadFull(pr.r, block[read:])
+	if err != nil {
+		return 0, nil, fmt.Errorf("truncated pcapng block: %w", err)
+	}
+	if pr.order.Uint32(block[length-4:]) != length {
+		return 0, nil, fmt.Errorf("pcapng block length mismatch")
+	}
+	return blockType, block[8 : length-4], nil
+}
+
+// readOptions
+// calls the handler for each option
+func (pr *pcapngReader) readOptions(data []byte, handler func(code uint16, value []byte)) {
+	for len(data) >= 4 {
+		code := pr.order.Uint16(data[0:2])
+		length := int(pr.order.Uint16(data[2:4]))
+		if code == pcapngOptionEnd || len(data) < 4+length {
+			return
+		}
+		handler(code, data[4:4+length])
+		data = data[4+pcapngPadded(length):]
+	}
+}
+
+// readInterface
+// adds interface description
+func (pr *pcapngReader) readInterface(body []byte) error {
+	if len(body) < 8 {
+		return fmt.Errorf("invalid pcapng interface description block")
+	}
+	intf := pcapngInterface{
+		linkType: decoders.LinkType(pr.order.Uint16(body[0:2])),
+		snapLen:  pr.order.Uint32(body[4:8]),
+		tsUnits:  1000000,
+		tsOffset: 0,
+	}
+	pr.readOptions(body[8:], func(code uint16, value []byte) {
+		switch code {
+		case pcapngOptionTsResol:
+			if len(value) == 1 {
+				exp := uint64(value[0] & 0x7F)
+				base := uint64(10)
+				if value[0]&0x80 != 0 {
+					base = 2
+				}
+				units := uint64(1)
+				for i := uint64(0); i < exp && units <= (1<<63)/base; i++ {
+					units *= base
+				}
+				intf.tsUnits = units
+			}
+		case pcapngOptionTsOffset:
+			if len(value) == 8 {
+				intf.tsOffset = int64(pr.order.Uint64(value))
+			}
+		}
+	})
+	pr.interfaces = append(pr.interfaces, intf)
+	return nil
+}
+
+// packetInterface
+// returns interface by its id
+func (pr *pcapngReader) packetInterface(id uint32) (*pcapngInterface, error) {
+	if int(id) >= len(pr.interfaces) {
+		return nil, fmt.Errorf("pcapng packet refers unknown interface %d", id)
+	}
+	return &pr.interfaces[id], nil
+}
+
+// timestamp
+// converts interface time stamp units into time
+func (intf *pcapngInterface) timestamp(high, low uint32) time.Time {
+	ts := uint64(high)<<32 | uint64(low)
+	seconds := ts / intf.tsUnits
+	hi, lo := bits.Mul64(ts%intf.tsUnits, uint64(time.Second))
+	nanos, _ := bits.Div64(hi, lo, intf.tsUnits)
+	return time.Unix(int64(seconds)+intf.tsOffset, int64(nanos)).UTC()
+}
+
+// readEnhancedPacket
+// decodes enhanced packet block
+func (pr *pcapngReader) readEnhancedPacket(body []byte) (*pcapngPacket, error) {
+	if len(body) < 20 {
+		return nil, fmt.Errorf("invalid pcapng enhanced packet block")
+	}
+	intf, err := pr.packetInterface(pr.order.Uint32(body[0:4]))
+	if err != nil {
+		return nil, err
+	}
+	capLen := int(pr.order.Uint32(body[12:16]))
+	if 20+capLen > len(body) {
+		return nil, fmt.Errorf("pcapng packet data exceeds block")
+	}
+	packet := &pcapngPacket{
+		data: body[20 : 20+capLen],
+		ci: gopacket.CaptureInfo{
+			Timestamp:      intf.timestamp(pr.order.Uint32(body[4:8]), pr.order.Uint32(body[8:12])),
+			CaptureLength:  capLen,
+			Length:         int(pr.order.Uint32(body[16:20])),
+			InterfaceIndex: int(pr.order.Uint32(body[0:4])),
+		},
+		linkType: intf.linkType,
+	}
+	pr.readOptions(body[20+pcapngPadded(capLen):], packet.addComment)
+	return packet, nil
+}
+
+// readSimplePacket
+// decodes simple packet block, it is captured on the first interface without time stamp
+func (pr *pcapngReader) readSimplePacket(body []byte) (*pcapngPacket, error) {
+	if len(body) < 4 {
+		return nil, fmt.Errorf("invalid pcapng simple packet block")
+	}
+	intf, err := pr.packetInterface(0)
+	if err != nil {
+		return nil, err
+	}
+	length := int(pr.order.Uint32(body[0:4]))
+	capLen := min(length, len(body)-4)
+	if intf.snapLen > 0 {
+		capLen = min(capLen, int(intf.snapLen))
+	}
+	return &pcapngPacket{
+		data: body[4 : 4+capLen],
+		ci: gopacket.CaptureInfo{
+			CaptureLength: capLen,
+			Length:        length,
+		},
+		linkType: intf.linkType,
+	}, nil
+}
+
+// readObsoletePacket
+// decodes obsolete packet block
+func (pr *pcapngReader) readObsoletePacket(body []byte) (*pcapngPacket, error) {
+	if len(body) < 20 {
+		return nil, fmt.Errorf("invalid pcapng packet block")
+	}
+	intf, err := pr.packetInterface(uint32(pr.order.Uint16(body[0:2])))
+	if err != nil {
+		return nil, err
+	}
+	capLen := int(pr.order.Uint32(body[12:16]))
+	if 20+capLen > len(body) {
+		return nil, fmt.Errorf("pcapng packet data exceeds block")
+	}
+	packet := &pcapngPacket{
+		data: body[20 : 20+capLen],
+		ci: gopacket.CaptureInfo{
+			Timestamp:      intf.timestamp(pr.order.Uint32(body[4:8]), pr.order.Uint32(body[8:12])),
+			CaptureLength:  capLen,
+			Length:         int(pr.order.Uint32(body[16:20])),
+			InterfaceIndex: int(pr.order.Uint16(body[0:2])),
+		},
+		linkType: intf.linkType,
+	}
+	pr.readOptions(body[20+pcapngPadded(capLen):], packet.addComment)
+	return packet, nil
+}
+
+// addComment
+// collects packet comments from options
+func (p *pcapngPacket) addComment(code uint16, value []byte) {
+	if code == pcapngOptionComment && len(value) > 0 {
+		p.comments = append(p.comments, string(value))
+	}
+}
+
+// readNameResolution
+// passes IP address to host names records into the callback
+func (pr *pcapngReader) readNameResolution(body []byte) {
+	for len(body) >= 4 {
+		recordType := pr.order.Uint16(body[0:2])
+		length := int(pr.order.Uint16(body[2:4]))
+		if recordType == pcapngRecordEnd || len(body) < 4+length {
+			return
+		}
+		value := body[4 : 4+length]
+		body = body[4+pcapngPadded(length):]
+		var ipLen int
+		switch recordType {
+		case pcapngRecordIPv4:
+			ipLen = net.IPv4len
+		case pcapngRecordIPv6:
+			ipLen = net.IPv6len
+		default:
+			continue
+		}
+		if len(value) <= ipLen || pr.onName == nil {
+			continue
+		}
+		names := make([]string, 0, 1)
+		for _, name := range bytes.Split(value[ipLen:], []byte{0}) {
+			if len(name) > 0 {
+				names = append(names, string(name))
+			}
+		}
+		if len(names) > 0 {
+			pr.onName(net.IP(bytes.Clone(value[:ipLen])), names)
+		}
+	}
+}
+
+// readDecryptionSecrets
+// passes TLS key log embedded into the capture into the callback
+func (pr *pcapngReader) readDecryptionSecrets(body []byte) {
+	if len(body) < 8 || pr.onSecrets == nil {
+		return
+	}
+	length := int(pr.order.Uint32(body[4:8]))
+	if pr.order.Uint32(body[0:4]) == pcapngSecretsTlsKeyLog && 8+length <= len(body) {
+		pr.onSecrets(body[8 : 8+length])
+	}
+}
+
+// pcapngPadded
+// rounds length up to 32 bits boundary
+func pcapngPadded(length int) int {
+	return (length + 3) &^ 3
+}
@@ -0,0 +1,198 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package readers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/decoders"
+)
+
+// pcapng block bodies written in hex, the blocks are completed with the type and lengths by pcapngBlock
+const (
+	// section header: byte order magic, version 1.0, section length not specified
+	pcapngLeSection = "4d3c2b1a 0100 0000 ffffffffffffffff"
+	// Ethernet interface, snap length 65535, nanosecond time stamps
+	pcapngLeInterface = "0100 0000 ffff0000 0900 0100 09000000 0000 0000"
+	// enhanced packet of 4 bytes at 1700000000.5 seconds with comment "hello"
+	pcapngLePacket = "00000000 fe9c9717 0065f753 04000000 04000000 deadbeef 0100 0500 68656c6c6f000000 0000 0000"
+	// name resolution: 10.0.0.1 named "svc" and "svc.local", 10.0.0.2 without names
+	pcapngLeNames = "0100 1200 0a000001 73766300 7376632e6c6f63616c00 0000 0100 0500 0a000002 00000000 0000 0000"
+	// decryption secrets: TLS key log "CLIENT_RANDOM a b\n"
+	pcapngLeSecrets = "4b534c54 12000000 434c49454e545f52414e444f4d206120620a 0000"
+	// section header of big endian section
+	pcapngBeSection = "1a2b3c4d 0001 0000 ffffffffffffffff"
+	// Ethernet interface with microsecond time stamps
+	pcapngBeInterface = "0001 0000 0000ffff"
+	// simple packet of 2 bytes
+	pcapngBeSimplePacket = "00000002 abcd0000"
+)
+
+// pcapngBlock
+// makes the block of the type with the body written in hex with spaces
+func pcapngBlock(t *testing.T, order binary.AppendByteOrder, blockType uint32, body string) []byte {
+	data, err := hex.DecodeString(strings.ReplaceAll(body, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := order.AppendUint32(nil, blockType)
+	block = order.AppendUint32(block, uint32(12+len(data)))
+	block = append(block, data...)
+	return order.AppendUint32(block, uint32(12+len(data)))
+}
+
+// nameRecord
+// a name resolution record passed into the callback
+type nameRecord struct {
+	ip    string
+	names string
+}
+
+func TestPcapngReader(t *testing.T) {
+	var le, be binary.AppendByteOrder = binary.LittleEndian, binary.BigEndian
+	leSection := pcapngBlock(t, le, pcapngBlockSectionHeader, pcapngLeSection)
+	leInterface := pcapngBlock(t, le, pcapngBlockInterface, pcapngLeInterface)
+	lePacket := pcapngBlock(t, le, pcapngBlockEnhancedPacket, pcapngLePacket)
+	packetTime := time.Unix(1700000000, 500000000).UTC()
+	tests := []struct {
+		name        string
+		blocks      [][]byte
+		wantErr     error
+		wantPackets []string
+		wantTime    time.Time
+		wantComment string
+		wantNames   []nameRecord
+		wantSecrets string
+	}{
+		{
+			name:        "little endian enhanced packet with nanosecond time stamp",
+			blocks:      [][]byte{leSection, leInterface, lePacket},
+			wantPackets: []string{"deadbeef"},
+			wantTime:    packetTime,
+			wantComment: "hello",
+		},
+		{
+			name: "big endian simple packet",
+			blocks: [][]byte{
+				pcapngBlock(t, be, pcapngBlockSectionHeader, pcapngBeSection),
+				pcapngBlock(t, be, pcapngBlockInterface, pcapngBeInterface),
+				pcapngBlock(t, be, pcapngBlockSimplePacket, pcapngBeSimplePacket),
+			},
+			wantPackets: []string{"abcd"},
+		},
+		{
+			name:        "name records without names are not passed",
+			blocks:      [][]byte{leSection, leInterface, pcapngBlock(t, le, pcapngBlockNameResolution, pcapngLeNames), lePacket},
+			wantPackets: []string{"deadbeef"},
+			wantTime:    packetTime,
+			wantComment: "hello",
+			wantNames:   []nameRecord{{ip: "10.0.0.1", names: "svc,svc.local"}},
+		},
+		{
+			name:        "embedded TLS key log",
+			blocks:      [][]byte{leSection, leInterface, pcapngBlock(t, le, pcapngBlockDecryptionSecrets, pcapngLeSecrets)},
+			wantSecrets: "CLIENT_RANDOM a b\n",
+		},
+		{
+			name:    "packet of unknown interface",
+			blocks:  [][]byte{leSection, lePacket},
+			wantErr: errors.New("pcapng packet refers unknown interface 0"),
+		},
+		{
+			name:    "truncated block",
+			blocks:  [][]byte{leSection, leInterface, lePacket[:len(lePacket)-8]},
+			wantErr: io.ErrUnexpectedEOF,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := make([]nameRecord, 0)
+			secrets := ""
+			pr, err := newPcapngReader(bytes.NewReader(bytes.Join(tt.blocks, nil)),
+				func(ip net.IP, n []string) {
+					names = append(names, nameRecord{ip: ip.String(), names: strings.Join(n, ",")})
+				},
+				func(keyLog []byte) { secrets += string(keyLog) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			packets := make([]*pcapngPacket, 0)
+			for {
+				packet, err := pr.next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if tt.wantErr == nil || !(errors.Is(err, tt.wantErr) || err.Error() == tt.wantErr.Error()) {
+						t.Fatalf("next() error = %v, want %v", err, tt.wantErr)
+					}
+					break
+				}
+				packets = append(packets, packet)
+			}
+			if len(packets) != len(tt.wantPackets) {
+				t.Fatalf("got %d packets, want %d", len(packets), len(tt.wantPackets))
+			}
+			for i, packet := range packets {
+				if hex.EncodeToString(packet.data) != tt.wantPackets[i] {
+					t.Errorf("packet data %x, want %s", packet.data, tt.wantPackets[i])
+				}
+				if packet.linkType != decoders.LinkType(1) {
+					t.Errorf("packet link type %d, want Ethernet", packet.linkType)
+				}
+				if !packet.ci.Timestamp.Equal(tt.wantTime) {
+					t.Errorf("packet time %v, want %v", packet.ci.Timestamp, tt.wantTime)
+				}
+				if strings.Join(packet.comments, ",") != tt.wantComment {
+					t.Errorf("packet comments %v, want %q", packet.comments, tt.wantComment)
+				}
+			}
+			if len(names) != len(tt.wantNames) {
+				t.Fatalf("name records %v, want %v", names, tt.wantNames)
+			}
+			for i := range names {
+				if names[i] != tt.wantNames[i] {
+					t.Errorf("name record %v, want %v", names[i], tt.wantNames[i])
+				}
+			}
+			if secrets != tt.wantSecrets {
+				t.Errorf("TLS key log %q, want %q", secrets, tt.wantSecrets)
+			}
+		})
+	}
+}
+
+func TestPcapngReaderRejectsOtherFormats(t *testing.T) {
+	// section header block type with unknown byte order magic
+	data := pcapngBlock(t, binary.LittleEndian, pcapngBlockSectionHeader, "d4c3b2a1 0100 0000 ffffffffffffffff")
+	_, err := newPcapngReader(bytes.NewReader(data), nil, nil)
+	if !errors.Is(err, ErrorNotPcapng) {
+		t.Errorf("newPcapngReader() error = %v, want %v", err, ErrorNotPcapng)
+	}
+}
+
+func TestNameRecordWithoutNames(t *testing.T) {
+	// the reader has no hosts to name, a record without names is skipped before they are used
+	reader := &captureReaderImpl{}
+	reader.onNameRecord(net.ParseIP("10.0.0.1"), nil)
+}
@@ -0,0 +1,164 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redaction
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonStep
+// a JSON path step: a field name, an array index or a wildcard, recursive steps match at any depth
+type jsonStep struct {
+	name      string
+	index     int
+	wildcard  bool
+	recursive bool
+}
+
+// jsonReplacer
+// makes a replacement for the matched value, false to remove the value
+type jsonReplacer func(value interface{}) (interface{}, bool)
+
+// parseJsonPath
+// parses a JSON path subset: $.a.b, $['a'], $.items[*].c, $.items[0], $..name
+func parseJsonPath(path string) ([]jsonStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("JSON path %s must start with $", path)
+	}
+	steps := make([]jsonStep, 0)
+	rest := path[1:]
+	for rest != "" {
+		step := jsonStep{index: -1}
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			step.recursive = true
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "["):
+		default:
+			return nil, fmt.Errorf("unexpected '%s' in JSON path %s", rest, path)
+		}
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket in JSON path %s", path)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case selector == "*":
+				step.wildcard = true
+			case len(selector) > 1 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				step.name = selector[1 : len(selector)-1]
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid selector [%s] in JSON path %s", selector, path)
+				}
+				step.index = index
+			}
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			step.name = rest[:end]
+			rest = rest[end:]
+			if step.name == "*" {
+				step.name = ""
+				step.wildcard = true
+			} else if step.name == "" {
+				return nil, fmt.Errorf("empty field name in JSON path %s", path)
+			}
+		}
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("JSON path %s selects the whole document", path)
+	}
+	return steps, nil
+}
+
+// matchesKey
+// checks the step against the object field
+func (s jsonStep) matchesKey(key string) bool {
+	return s.wildcard || (s.index < 0 && s.name == key)
+}
+
+// matchesIndex
+// checks the step against the array element
+func (s jsonStep) matchesIndex(index int) bool {
+	return s.wildcard || (s.name == "" && s.index == index)
+}
+
+// applyJsonPath
+// replaces values selected by the path, returns the updated node and true when anything is replaced
+func applyJsonPath(node interface{}, steps []jsonStep, replace jsonReplacer) (interface{}, bool) {
+	step := steps[0]
+	last := len(steps) == 1
+	changed := false
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, value := range n {
+			if step.matchesKey(key) {
+				if last {
+					if replacement, keep := replace(value); keep {
+						n[key] = replacement
+					} else {
+						delete(n, key)
+					}
+					changed = true
+					continue
+				}
+				var c bool
+				value, c = applyJsonPath(value, steps[1:], replace)
+				n[key] = value
+				changed = changed || c
+			}
+			if step.recursive {
+				var c bool
+				n[key], c = applyJsonPath(value, steps, replace)
+				changed = changed || c
+			}
+		}
+	case []interface{}:
+		kept := n[:0]
+		for i, value := range n {
+			if step.matchesIndex(i) {
+				if last {
+					changed = true
+					if replacement, keep := replace(value); keep {
+						kept = append(kept, replacement)
+					}
+					continue
+				}
+				var c bool
+				value, c = applyJsonPath(value, steps[1:], replace)
+				changed = changed || c
+			}
+			if step.recursive {
+				var c bool
+				value, c = applyJsonPath(value, steps, replace)
+				changed = changed || c
+			}
+			kept = append(kept, value)
+		}
+		return kept, changed
+	}
+	return node, changed
+}
@@ -0,0 +1,383 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redaction
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/decoders"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+)
+
+const (
+	// hashPrefix marks hashed values
+	hashPrefix = "hash:"
+	// hashLength hex digits of the hash kept in the value
+	hashLength = 32
+	// formContentType form body content type, the body is redacted by the query parameter rules
+	formContentType = "application/x-www-form-urlencoded"
+)
+
+// Redactor
+// removes sensitive data from the decoded traffic before it is stored
+type Redactor interface {
+	// Packet redacts the path, query, headers and body of the HTTP message
+	Packet(packet *entities.ParsedPacket)
+	// Exchange redacts headers and bodies of the request/response pair
+	Exchange(exchange *entities.ServiceExchange)
+	// WebSocketMessage redacts the text message payload
+	WebSocketMessage(message *entities.ServiceWebSocketMessage)
+	// KafkaEvent redacts the record key, value and headers
+	KafkaEvent(event *entities.KafkaMessageEvent)
+	// GraphqlOperation redacts the operation variables
+	GraphqlOperation(operation *entities.ServiceGraphqlOperation)
+}
+
+// namedRule
+// a header or query parameter rule
+type namedRule struct {
+	name   string
+	action string
+}
+
+// jsonRule
+// a JSON field rule
+type jsonRule struct {
+	path   []jsonStep
+	action string
+}
+
+// patternRule
+// a regular expression rule
+type patternRule struct {
+	re     *regexp.Regexp
+	action string
+}
+
+type redactorImpl struct {
+	hashKey     []byte
+	headers     []namedRule
+	queryParams []namedRule
+	jsonPaths   []jsonRule
+	patterns    []patternRule
+}
+
+// NewRedactor
+// compiles the rules, the hash key makes hashes of the same value equal only within the installation
+func NewRedactor(rules view.RedactionRules, hashKey string) (Redactor, error) {
+	r := &redactorImpl{hashKey: []byte(hashKey)}
+	for _, rule := range rules.Headers {
+		action, err := ruleAction(rule)
+		if err != nil {
+			return nil, err
+		}
+		r.headers = append(r.headers, namedRule{name: rule.Name, action: action})
+	}
+	for _, rule := range rules.QueryParams {
+		action, err := ruleAction(rule)
+		if err != nil {
+			return nil, err
+		}
+		r.queryParams = append(r.queryParams, namedRule{name: rule.Name, action: action})
+	}
+	for _, rule := range rules.JsonPaths {
+		action, err := ruleAction(rule)
+		if err != nil {
+			return nil, err
+		}
+		path, err := parseJsonPath(rule.Path)
+		if err != nil {
+			return nil, err
+		}
+		r.jsonPaths = append(r.jsonPaths, jsonRule{path: path, action: action})
+	}
+	for _, rule := range rules.Patterns {
+		action, err := ruleAction(rule)
+		if err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %s: %v", rule.Regex, err)
+		}
+		r.patterns = append(r.patterns, patternRule{re: re, action: action})
+	}
+	return r, nil
+}
+
+// LoadRules
+// reads rules from the JSON text or, when the text is empty, from the JSON file, default rules are used when both are empty
+func LoadRules(rulesText, rulesFile string) (view.RedactionRules, error) {
+	rules := view.RedactionRules{}
+	data := []byte(rulesText)
+	if rulesText == view.EmptyString {
+		if rulesFile == view.EmptyString {
+			return view.DefaultRedactionRules(), nil
+		}
+		var err error
+		data, err = os.ReadFile(rulesFile)
+		if err != nil {
+			return rules, fmt.Errorf("unable to read redaction rules: %v", err)
+		}
+	}
+	err := json.Unmarshal(data, &rules)
+	if err != nil {
+		return rules, fmt.Errorf("unable to parse redaction rules: %v", err)
+	}
+	return rules, nil
+}
+
+// ruleAction
+// validates the rule action, mask is the default
+func ruleAction(rule view.RedactionRule) (string, error) {
+	switch strings.ToLower(rule.Action) {
+	case view.EmptyString, view.RedactMask:
+		return view.RedactMask, nil
+	case view.RedactHash:
+		return view.RedactHash, nil
+	case view.RedactRemove:
+		return view.RedactRemove, nil
+	}
+	return view.EmptyString, fmt.Errorf("unsupported redaction action '%s'", rule.Action)
+}
+
+func (r *redactorImpl) Packet(packet *entities.ParsedPacket) {
+	packet.RequestPath = r.text(packet.RequestPath)
+	packet.RequestQuery = r.query(packet.RequestQuery)
+	packet.StrPayload = r.payload(packet.StrPayload, packet.Headers)
+	packet.Headers = r.redactHeaders(packet.Headers)
+}
+
+func (r *redactorImpl) Exchange(exchange *entities.ServiceExchange) {
+	exchange.RequestPath = r.text(exchange.RequestPath)
+	exchange.RequestBody = r.payload(exchange.RequestBody, exchange.RequestHeaders)
+	exchange.ResponseBody = r.payload(exchange.ResponseBody, exchange.ResponseHeaders)
+	exchange.RequestHeaders = r.redactHeaders(exchange.RequestHeaders)
+	exchange.ResponseHeaders = r.redactHeaders(exchange.ResponseHeaders)
+}
+
+func (r *redactorImpl) WebSocketMessage(message *entities.ServiceWebSocketMessage) {
+	message.RequestPath = r.text(message.RequestPath)
+	if message.Opcode == decoders.WebSocketOpText {
+		message.Payload = r.body(message.Payload)
+	}
+}
+
+func (r *redactorImpl) KafkaEvent(event *entities.KafkaMessageEvent) {
+	event.RecordKey = r.body(event.RecordKey)
+	event.RecordValue = r.body(event.RecordValue)
+	event.RecordHeaders = r.redactHeaders(event.RecordHeaders)
+}
+
+func (r *redactorImpl) GraphqlOperation(operation *entities.ServiceGraphqlOperation) {
+	operation.Variables = r.body(operation.Variables)
+}
+
+// payload
+// applies query parameter rules to a form body and body rules to other bodies, the content type is taken
+// from the message headers before they are redacted
+func (r *redactorImpl) payload(body string, headers map[string]string) string {
+	for name, value := range headers {
+		if strings.EqualFold(name, "Content-Type") && strings.HasPrefix(strings.ToLower(value), formContentType) {
+			return r.query(body)
+		}
+	}
+	return r.body(body)
+}
+
+// redactHeaders
+// applies header rules and then patterns to the header values, the source map is shared with the decoder and is not changed
+func (r *redactorImpl) redactHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+	result := make(map[string]string, len(headers))
+	for name, value := range headers {
+		rule := findRule(r.headers, name)
+		switch {
+		case rule == nil:
+			result[name] = r.text(value)
+		case rule.action == view.RedactRemove:
+			continue
+		default:
+			result[name] = r.replace(value, rule.action)
+		}
+	}
+	return result
+}
+
+// query
+// applies query parameter rules and patterns to the parameter values,
+// the query is rebuilt only when a value is changed
+func (r *redactorImpl) query(query string) string {
+	if query == view.EmptyString {
+		return query
+	}
+	pairs := strings.Split(query, "&")
+	changed := false
+	result := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		rawName, rawValue, hasValue := strings.Cut(pair, "=")
+		name, err := url.QueryUnescape(rawName)
+		if err != nil {
+			name = rawName
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			value = rawValue
+		}
+		redacted := value
+		rule := findRule(r.queryParams, name)
+		switch {
+		case rule == nil:
+			redacted = r.text(value)
+		case rule.action == view.RedactRemove:
+			changed = true
+			continue
+		default:
+			redacted = r.replace(value, rule.action)
+		}
+		if redacted == value {
+			result = append(result, pair)
+			continue
+		}
+		changed = true
+		if hasValue || redacted != view.EmptyString {
+			result = append(result, rawName+"="+url.QueryEscape(redacted))
+		} else {
+			result = append(result, rawName)
+		}
+	}
+	if !changed {
+		return query
+	}
+	return strings.Join(result, "&")
+}
+
+// body
+// applies JSON rules when the body is a JSON document and then patterns
+func (r *redactorImpl) body(body string) string {
+	if body == view.EmptyString {
+		return body
+	}
+	if len(r.jsonPaths) > 0 {
+		body = r.json(body)
+	}
+	return r.text(body)
+}
+
+// json
+// applies JSON rules, the document is rewritten only when a field is redacted
+func (r *redactorImpl) json(body string) string {
+	trimmed := strings.TrimSpace(body)
+	if trimmed == view.EmptyString || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return body
+	}
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	dec.UseNumber()
+	var doc interface{}
+	if dec.Decode(&doc) != nil {
+		return body
+	}
+	changed := false
+	for _, rule := range r.jsonPaths {
+		var ruleChanged bool
+		doc, ruleChanged = applyJsonPath(doc, rule.path, func(value interface{}) (interface{}, bool) {
+			if rule.action == view.RedactRemove {
+				return nil, false
+			}
+			return r.replace(jsonText(value), rule.action), true
+		})
+		changed = changed || ruleChanged
+	}
+	if !changed {
+		return body
+	}
+	buf := bytes.Buffer{}
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if enc.Encode(doc) != nil {
+		return body
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// text
+// applies patterns to the text
+func (r *redactorImpl) text(text string) string {
+	if text == view.EmptyString {
+		return text
+	}
+	for _, rule := range r.patterns {
+		text = rule.re.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.action == view.RedactHash {
+				return r.hash(match)
+			}
+			return view.RedactedValue
+		})
+	}
+	return text
+}
+
+// replace
+// makes a replacement of the value according to the action
+func (r *redactorImpl) replace(value, action string) string {
+	if action == view.RedactHash {
+		return r.hash(value)
+	}
+	return view.RedactedValue
+}
+
+// hash
+// makes a keyed hash of the value, equal values get equal hashes
+func (r *redactorImpl) hash(value string) string {
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write([]byte(value))
+	return hashPrefix + hex.EncodeToString(mac.Sum(nil))[:hashLength]
+}
+
+// findRule
+// returns the first rule for the name (case-insensitive), nil if not found
+func findRule(rules []namedRule, name string) *namedRule {
+	for i := range rules {
+		if strings.EqualFold(rules[i].name, name) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// jsonText
+// returns a string value as is and a JSON text for other values to hash them the same way as in headers and queries
+func jsonText(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return view.EmptyString
+	}
+	return string(data)
+}
@@ -0,0 +1,210 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redaction
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+)
+
+// testHashKey installation key of the hashed test values
+const testHashKey = "test-key"
+
+func TestRedactJsonPaths(t *testing.T) {
+	hasher := &redactorImpl{hashKey: []byte(testHashKey)}
+	tests := []struct {
+		name   string
+		path   string
+		action string
+		body   string
+		want   string
+	}{
+		{
+			name: "field is masked",
+			path: "$.password",
+			body: `{"user":"a","password":"p"}`,
+			want: `{"password":"***","user":"a"}`,
+		},
+		{
+			name:   "nested field is removed",
+			path:   "$.card.number",
+			action: view.RedactRemove,
+			body:   `{"card":{"number":"4111","exp":"12/30"}}`,
+			want:   `{"card":{"exp":"12/30"}}`,
+		},
+		{
+			name: "fields of all array elements",
+			path: "$.items[*].token",
+			body: `{"items":[{"token":"a","id":1},{"token":"b","id":2.50}]}`,
+			want: `{"items":[{"id":1,"token":"***"},{"id":2.50,"token":"***"}]}`,
+		},
+		{
+			name:   "array element is removed",
+			path:   "$.items[1]",
+			action: view.RedactRemove,
+			body:   `{"items":[1,2,3]}`,
+			want:   `{"items":[1,3]}`,
+		},
+		{
+			name: "quoted field name",
+			path: "$['x-key']",
+			body: `{"x-key":"k","x-id":"i"}`,
+			want: `{"x-id":"i","x-key":"***"}`,
+		},
+		{
+			name: "fields at any depth",
+			path: "$..secret",
+			body: `{"secret":"a","nested":[{"secret":"b"},{"other":{"secret":{"deep":1}}}]}`,
+			want: `{"nested":[{"secret":"***"},{"other":{"secret":"***"}}],"secret":"***"}`,
+		},
+		{
+			name: "top level array",
+			path: "$[0].a",
+			body: `[{"a":1},{"a":2}]`,
+			want: `[{"a":"***"},{"a":2}]`,
+		},
+		{
+			name:   "hash of a string",
+			path:   "$.email",
+			action: view.RedactHash,
+			body:   `{"email":"a@example.com"}`,
+			want:   `{"email":"` + hasher.hash("a@example.com") + `"}`,
+		},
+		{
+			name:   "hash of a number",
+			path:   "$.id",
+			action: view.RedactHash,
+			body:   `{"id":42}`,
+			want:   `{"id":"` + hasher.hash("42") + `"}`,
+		},
+		{
+			name: "body without the field is not rewritten",
+			path: "$.password",
+			body: `{ "user" : "<a>" }`,
+			want: `{ "user" : "<a>" }`,
+		},
+		{
+			name: "not a JSON body",
+			path: "$.password",
+			body: "password=p",
+			want: "password=p",
+		},
+		{
+			name: "broken JSON body",
+			path: "$.password",
+			body: `{"password":"p"`,
+			want: `{"password":"p"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := view.RedactionRules{JsonPaths: []view.RedactionRule{{Path: tt.path, Action: tt.action}}}
+			r, err := NewRedactor(rules, testHashKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exchange := &entities.ServiceExchange{RequestBody: tt.body}
+			r.Exchange(exchange)
+			if exchange.RequestBody != tt.want {
+				t.Errorf("redacted body %s, want %s", exchange.RequestBody, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJsonPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []jsonStep
+		wantErr bool
+	}{
+		{path: "$.a.b", want: []jsonStep{{name: "a", index: -1}, {name: "b", index: -1}}},
+		{path: "$.a[2]", want: []jsonStep{{name: "a", index: -1}, {index: 2}}},
+		{path: "$..a.*", want: []jsonStep{{name: "a", index: -1, recursive: true}, {index: -1, wildcard: true}}},
+		{path: `$["a.b"][*]`, want: []jsonStep{{name: "a.b", index: -1}, {index: -1, wildcard: true}}},
+		{path: "a.b", wantErr: true},
+		{path: "$", wantErr: true},
+		{path: "$.a[1", wantErr: true},
+		{path: "$.a[-1]", wantErr: true},
+		{path: "$.a..", wantErr: true},
+		{path: "$a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := parseJsonPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJsonPath() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseJsonPath() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactPacket(t *testing.T) {
+	rules := view.RedactionRules{
+		Headers:     []view.RedactionRule{{Name: "authorization"}, {Name: "Cookie", Action: view.RedactRemove}},
+		QueryParams: []view.RedactionRule{{Name: "token", Action: view.RedactHash}, {Name: "debug", Action: view.RedactRemove}},
+		Patterns:    []view.RedactionRule{{Regex: `\d{4}-\d{4}`}},
+	}
+	r, err := NewRedactor(rules, testHashKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher := &redactorImpl{hashKey: []byte(testHashKey)}
+	headers := map[string]string{"Authorization": "Bearer t", "Cookie": "c=1", "X-Card": "1234-5678"}
+	packet := &entities.ParsedPacket{
+		RequestPath:  "/cards/1234-5678",
+		RequestQuery: "token=a%20b&debug&page=2",
+		Headers:      headers,
+		StrPayload:   "card 1234-5678",
+	}
+	r.Packet(packet)
+	want := &entities.ParsedPacket{
+		RequestPath:  "/cards/***",
+		RequestQuery: "token=" + url.QueryEscape(hasher.hash("a b")) + "&page=2",
+		Headers:      map[string]string{"Authorization": "***", "X-Card": "***"},
+		StrPayload:   "card ***",
+	}
+	if !reflect.DeepEqual(packet, want) {
+		t.Errorf("redacted packet %+v, want %+v", packet, want)
+	}
+	if headers["Cookie"] != "c=1" {
+		t.Errorf("decoder headers are changed: %v", headers)
+	}
+}
+
+func TestNewRedactorErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules view.RedactionRules
+	}{
+		{"unknown action", view.RedactionRules{Headers: []view.RedactionRule{{Name: "a", Action: "drop"}}}},
+		{"invalid JSON path", view.RedactionRules{JsonPaths: []view.RedactionRule{{Path: "a"}}}},
+		{"invalid pattern", view.RedactionRules{Patterns: []view.RedactionRule{{Regex: "("}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRedactor(tt.rules, testHashKey); err == nil {
+				t.Error("NewRedactor() error expected")
+			}
+		})
+	}
+}
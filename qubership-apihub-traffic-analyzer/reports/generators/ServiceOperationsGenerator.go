@@ -15,13 +15,18 @@
 package generators
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/client"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/openapi/orderedmap"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/repository"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
 	"github.com/go-pg/pg/v10"
@@ -64,8 +69,30 @@ const (
 	ServiceVersionRecent = "requested"
 	// ServiceOperationPageSize limit service operations per page (used in APIHUB backend)
 	ServiceOperationPageSize = 100
+	// asyncKafkaProtocol AsyncAPI server protocol of Kafka channels (kafka, kafka-secure)
+	asyncKafkaProtocol = "kafka"
 )
 
+// peersSql
+// makes src_peer and dst_peer columns of the message table alias
+func peersSql(alias string) string {
+	return peerSql("sas", alias+".source_port") + ` as src_peer,
+		` + peerSql("sad", alias+".dest_port") + ` as dst_peer`
+}
+
+// peerSql
+// makes peer name of the service address alias: the address list service name, the name inferred from
+// the capture (DNS, TLS SNI, Host header) or address and port when the service is not known
+func peerSql(address, port string) string {
+	return `case
+			when length(coalesce(` + address + `.service_name,''))>0 then ` + address + `.service_name
+			when length(coalesce(` + address + `.inferred_name,''))>0 then ` + address + `.inferred_name
+			else concat(coalesce(` + address + `.ip_address,''),':',to_char(` + port + `,'FM99999')) end`
+}
+
+// asyncChannelParamRe AsyncAPI channel parameter
+var asyncChannelParamRe = regexp.MustCompile(`\{[^}]*\}`)
+
 // Generate
 // generates report according to the request
 func (rep *ServiceOperationsImpl) Generate(rqi interface{}) error {
@@ -140,7 +167,7 @@ func (rep *ServiceOperationsImpl) Generate(rqi interface{}) error {
 // with parameters:
 // serviceName A.K.A. packageId
 // serviceVersion A.K.A. version
-// "rest" A.K.A. apiType
+// "rest" and "protobuf" A.K.A. apiType
 func (rep *ServiceOperationsImpl) queryServiceOperations(rq view.ServiceReportRequest, serviceId string, reportId int) error {
 	var err error
 	// it is impossible to get all the operations at once - use paging
@@ -164,33 +191,40 @@ func (rep *ServiceOperationsImpl) queryServiceOperations(rq view.ServiceReportRe
 			break // no operations on page - break the loop
 		}
 		// dump service operation into DB, count occurrences, fill operation status
-		tmpOptUpd := new(entities.ServicePacket)
 		for _, op := range contents.Operations {
 			opCount++
 			tmpOpStat := entities.NewReportServiceOperation(reportId, op.OperationId, op.Path, op.Method, view.OperationNotFound)
-			whereClause := "capture_id=? and request_method=? and "
-			pathParam := tmpOpStat.Path
-			if tmpOpStat.Regexp != view.EmptyString {
-				whereClause += "not regexp_match(request_path, ?) is null "
-				pathParam = tmpOpStat.Regexp
-			} else {
-				whereClause += "request_path = ? "
-			}
-			tmpOpStat.HitCount, err = rep.db.GetConnection().Model(tmpOptUpd).
-				Where(whereClause, rq.CaptureId, tmpOpStat.Method, pathParam).Count()
+			err = rep.cacheServiceOperation(rq, &tmpOpStat)
 			if err == nil {
-				if tmpOpStat.HitCount > 0 {
-					tmpOpStat.Status = view.OperationFound
-				}
-			} else {
-				if !errors.Is(err, pg.ErrNoRows) {
-					log.Debugf("unable to get hit count %s for service operation %s to report id %d: %v", whereClause, tmpOpStat.Path, reportId, err)
-				}
+				cachedOpCount++
+				rep.cacheQueryParameters(tmpOpStat, documentedQueryParameters(op.Data, op.Method))
 			}
-			err = entities.InsertReportServiceOperation(rep.db, &tmpOpStat)
-			if err != nil {
-				log.Debugf("unable to store service operation %s in Db: %v", op.Path, err)
-			} else {
+		}
+	}
+	// gRPC operations are requested with POST to /{package.Service}/{Method}
+	currentPage = 0
+	operationsOnPage = ServiceOperationPageSize
+	for operationsOnPage >= ServiceOperationPageSize {
+		contents, errGetOps := rep.apihubClient.GetVersionProtobufOperationsWithData(
+			rep.apihubClient.GetSystemCtx(), serviceId, rq.ServiceVersion, ServiceOperationPageSize, currentPage)
+		if errGetOps != nil {
+			// not every APIHUB installation supports protobuf API type
+			log.Warnf("unable to request protobuf operations from APIHUB: %v", errGetOps)
+			break
+		}
+		if contents == nil {
+			break
+		}
+		operationsOnPage = len(contents.Operations)
+		currentPage++
+		if operationsOnPage < 1 {
+			break
+		}
+		for _, op := range contents.Operations {
+			opCount++
+			tmpOpStat := entities.NewReportServiceOperation(reportId, op.OperationId, grpcOperationPath(op.Method), http.MethodPost, view.OperationNotFound)
+			err = rep.cacheServiceOperation(rq, &tmpOpStat)
+			if err == nil {
 				cachedOpCount++
 			}
 		}
@@ -209,30 +243,36 @@ func (rep *ServiceOperationsImpl) queryServiceOperations(rq view.ServiceReportRe
 		if err != nil {
 			return fmt.Errorf("unable to insert affected rows for packets in Db: %v", err)
 		}
+		err = rep.queryParameterCoverage(rq, reportId)
+		if err != nil {
+			return err
+		}
+	}
+	// GraphQL operations are reported by root fields instead of the endpoint requests
+	err = rep.queryGraphqlOperations(rq, serviceId, reportId)
+	if err != nil {
+		return err
 	}
 	// insert packets which are not listed in service operations into output table
 	sql3 := `insert into report_service_operations2
     	(report_id, src_peer, dst_peer, operation_title, operation_path, 
-    	 operation_method, operation_status, hit_count)
-	select ? as report_id, src_peer, dst_peer, '' as op_title, request_path, request_method, ? as op_status, sum(hit_count) as hit_count from 
+    	 operation_method, operation_status, hit_count, response_codes)
+	select ? as report_id, src_peer, dst_peer, '' as op_title, request_path, request_method, ? as op_status, sum(hit_count) as hit_count,
+		string_agg(distinct status_code::text, ',' order by status_code::text) as response_codes from 
 		(select 
-		case
-			when length(coalesce(sas.service_name,''))<1 
-			then concat(coalesce(sas.ip_address,''),':',to_char(source_port,'FM99999'))
-			else sas.service_name end as src_peer,
-		case
-			when length(coalesce(sad.service_name, ''))<1 
-			then concat(coalesce(sad.ip_address,''),':',to_char(source_port,'FM99999'))
-			else sad.service_name end as dst_peer,
-		request_path, 
-		request_method, 
+		` + peersSql("rsp") + `,
+		rsp.request_path, 
+		rsp.request_method, 
+		se.status_code,
 		1 as hit_count
 		from service_packets rsp
 		left join service_addresses sas on sas.address_id = source_id
 		left join service_addresses sad on sad.address_id = dest_id
+		left join service_exchanges se on se.request_packet_id = rsp.packet_id
 		where rsp.capture_id = ?
 			and not exists (select null from report_affected_rows where report_id = ? and reference_id=packet_id and reference_type = ?)
-			and not request_path is null) t2
+			and not exists (select null from service_exchanges where response_packet_id = rsp.packet_id)
+			and not rsp.request_path is null) t2
 		group by
 			src_peer, dst_peer, request_path, request_method`
 	_, err = rep.db.GetConnection().Exec(sql3, reportId, view.OperationExtra, rq.CaptureId, reportId, entities.ReportAffectedPacket)
@@ -243,23 +283,14 @@ func (rep *ServiceOperationsImpl) queryServiceOperations(rq view.ServiceReportRe
 	// add previously collected operations
 	sqlOp := `insert into report_service_operations2
     	(report_id, src_peer, dst_peer, operation_title, operation_path, 
-    	 operation_method, operation_status, hit_count)
+    	 operation_method, operation_status, hit_count, response_codes)
 	select report_id, src_peer, dst_peer, operation_title, operation_path, operation_method, 
-	       operation_status, sum(hit_count) as hit_count from (
-		select report_id,	
-		case
-			when length(coalesce(sas.service_name,''))<1 
-			then concat(coalesce(sas.ip_address,''),':',to_char(source_port,'FM99999'))
-			else sas.service_name end as src_peer,
-		case
-			when length(coalesce(sad.service_name, ''))<1 
-			then concat(coalesce(sad.ip_address,''),':',to_char(source_port,'FM99999'))
-			else sad.service_name end as dst_peer,
+	       operation_status, count(packet_id) as hit_count,
+	       string_agg(distinct status_code::text, ',' order by status_code::text) as response_codes from (
+		select rps.report_id,	
+		` + peersSql("sp") + `,
 		operation_title, operation_path, operation_method, operation_status,
-		hit_count from 
-	(select report_id, source_id, source_port, dest_id, dest_port,
-		operation_title, operation_path, operation_method, operation_status,
-		case when source_id is null then 0 else count(sp.*) end as hit_count
+		sp.packet_id, se.status_code
 	from
 		report_service_operations rps
 	left JOIN service_packets sp
@@ -267,13 +298,11 @@ func (rep *ServiceOperationsImpl) queryServiceOperations(rq view.ServiceReportRe
 			or request_path = rps.operation_path) 
 			and rps.operation_method=sp.request_method
 			and sp.capture_id = ?
+	left join service_exchanges se on se.request_packet_id = sp.packet_id
+	left join service_addresses sas on sas.address_id = sp.source_id
+	left join service_addresses sad on sad.address_id = sp.dest_id
 	where
-		rps.report_id = ?
-	group by
-	    report_id, source_id, source_port, dest_id, dest_port,
-		operation_title, operation_path, operation_method, operation_status) rsp
-	left join service_addresses sas on sas.address_id = rsp.source_id
-	left join service_addresses sad on sad.address_id = rsp.dest_id) t2
+		rps.report_id = ?) t2
 	group by report_id, src_peer, dst_peer, operation_title, operation_path, 
 	         operation_method, operation_status`
 	_, err = rep.db.GetConnection().Exec(sqlOp, rq.CaptureId, reportId)
@@ -284,6 +313,11 @@ func (rep *ServiceOperationsImpl) queryServiceOperations(rq view.ServiceReportRe
 			return fmt.Errorf("unable to select service operations into report: %v", err)
 		}
 	}
+	// Kafka topics against AsyncAPI channels
+	err = rep.queryAsyncOperations(rq, serviceId, reportId)
+	if err != nil {
+		return err
+	}
 	// store report data
 	reportData := make([]entities.ReportServiceOperationWithPeers, 0)
 	// building query
@@ -323,6 +357,378 @@ func (rep *ServiceOperationsImpl) queryServiceOperations(rq view.ServiceReportRe
 	return nil
 }
 
+// queryGraphqlOperations
+// requests GraphQL operations of the service and counts requests executing their root fields,
+// root fields not listed in the operations are reported as extra, GraphQL requests are excluded from the packet extras
+func (rep *ServiceOperationsImpl) queryGraphqlOperations(rq view.ServiceReportRequest, serviceId string, reportId int) error {
+	currentPage := 0
+	operationsOnPage := ServiceOperationPageSize
+	for operationsOnPage >= ServiceOperationPageSize {
+		contents, errGetOps := rep.apihubClient.GetVersionGraphqlOperationsWithData(
+			rep.apihubClient.GetSystemCtx(), serviceId, rq.ServiceVersion, ServiceOperationPageSize, currentPage)
+		if errGetOps != nil {
+			log.Warnf("unable to request GraphQL operations from APIHUB: %v", errGetOps)
+			break
+		}
+		if contents == nil {
+			break
+		}
+		operationsOnPage = len(contents.Operations)
+		currentPage++
+		if operationsOnPage < 1 {
+			break
+		}
+		for _, op := range contents.Operations {
+			err := rep.insertGraphqlOperation(rq, reportId, op.OperationId, strings.ToLower(op.Type), op.Method)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	sqlExtra := `insert into report_service_operations2
+		(report_id, src_peer, dst_peer, operation_title, operation_path,
+		 operation_method, operation_status, hit_count, response_codes)
+	select ? as report_id, src_peer, dst_peer, '' as op_title, root_field, upper(operation_type), ? as op_status,
+		count(distinct packet_id) as hit_count,
+		string_agg(distinct status_code::text, ',' order by status_code::text) as response_codes from
+		(select ` + peersSql("sp") + `, sgo.root_field, sgo.operation_type, sgo.packet_id, se.status_code
+		from service_graphql_operations sgo
+		join service_packets sp on sp.capture_id = sgo.capture_id and sp.packet_id = sgo.packet_id
+		left join service_exchanges se on se.request_packet_id = sgo.packet_id
+		left join service_addresses sas on sas.address_id = sp.source_id
+		left join service_addresses sad on sad.address_id = sp.dest_id
+		where sgo.capture_id = ?
+			and not exists (select null from report_affected_rows where report_id = ? and reference_id = sgo.operation_id and reference_type = ?)) t2
+	group by src_peer, dst_peer, root_field, operation_type`
+	_, err := rep.db.GetConnection().Exec(sqlExtra, reportId, view.OperationExtra, rq.CaptureId, reportId, entities.ReportAffectedGraphqlOperation)
+	if err != nil {
+		return fmt.Errorf("unable to insert GraphQL operations not belong service in Db: %v", err)
+	}
+	_, err = rep.db.GetConnection().Exec(`
+		insert into report_affected_rows (report_id, reference_id, reference_type, hit_count)
+		(select distinct ?, packet_id, ?, 1 from service_graphql_operations where capture_id = ?)
+		on conflict (report_id, reference_id, reference_type) do nothing`,
+		reportId, entities.ReportAffectedPacket, rq.CaptureId)
+	if err != nil {
+		return fmt.Errorf("unable to insert affected rows for GraphQL requests in Db: %v", err)
+	}
+	return nil
+}
+
+// insertGraphqlOperation
+// counts requests executing the root field by peers and marks the root field rows as affected by the report
+func (rep *ServiceOperationsImpl) insertGraphqlOperation(rq view.ServiceReportRequest, reportId int, title, operationType, rootField string) error {
+	_, err := rep.db.GetConnection().Exec(`
+		insert into report_affected_rows (report_id, reference_id, reference_type, hit_count)
+		(select ?, operation_id, ?, 1 from service_graphql_operations
+		where capture_id = ? and operation_type = ? and root_field = ?)
+		on conflict (report_id, reference_id, reference_type) do nothing`,
+		reportId, entities.ReportAffectedGraphqlOperation, rq.CaptureId, operationType, rootField)
+	if err != nil {
+		return fmt.Errorf("unable to insert affected rows for GraphQL operations in Db: %v", err)
+	}
+	sqlOp := `insert into report_service_operations2
+		(report_id, src_peer, dst_peer, operation_title, operation_path,
+		 operation_method, operation_status, hit_count, response_codes)
+	select ? as report_id, src_peer, dst_peer, ? as op_title, ? as op_path, ? as op_method,
+		case when count(packet_id) > 0 then ? else ? end as op_status, count(distinct packet_id) as hit_count,
+		string_agg(distinct status_code::text, ',' order by status_code::text) as response_codes from
+		(select ` + peersSql("sp") + `, sp.packet_id, se.status_code
+		from (select 1) op
+		left join service_graphql_operations sgo
+			on sgo.capture_id = ? and sgo.operation_type = ? and sgo.root_field = ?
+		left join service_packets sp on sp.capture_id = ? and sp.packet_id = sgo.packet_id
+		left join service_exchanges se on se.request_packet_id = sgo.packet_id
+		left join service_addresses sas on sas.address_id = sp.source_id
+		left join service_addresses sad on sad.address_id = sp.dest_id) t2
+	group by src_peer, dst_peer`
+	_, err = rep.db.GetConnection().Exec(sqlOp, reportId, title, rootField, strings.ToUpper(operationType),
+		view.OperationFound, view.OperationNotFound, rq.CaptureId, operationType, rootField, rq.CaptureId)
+	if err != nil {
+		return fmt.Errorf("unable to insert GraphQL operation %s into report: %v", title, err)
+	}
+	return nil
+}
+
+// queryAsyncOperations
+// requests AsyncAPI operations of the service and counts Kafka records produced or consumed on their channels,
+// topics of the capture not listed in the operations are reported as extra
+func (rep *ServiceOperationsImpl) queryAsyncOperations(rq view.ServiceReportRequest, serviceId string, reportId int) error {
+	currentPage := 0
+	operationsOnPage := ServiceOperationPageSize
+	for operationsOnPage >= ServiceOperationPageSize {
+		contents, errGetOps := rep.apihubClient.GetVersionAsyncApiOperationsWithData(
+			rep.apihubClient.GetSystemCtx(), serviceId, rq.ServiceVersion, ServiceOperationPageSize, currentPage)
+		if errGetOps != nil {
+			// not every APIHUB installation supports AsyncAPI type
+			log.Warnf("unable to request AsyncAPI operations from APIHUB: %v", errGetOps)
+			break
+		}
+		if contents == nil {
+			break
+		}
+		operationsOnPage = len(contents.Operations)
+		currentPage++
+		if operationsOnPage < 1 {
+			break
+		}
+		for _, op := range contents.Operations {
+			if op.Protocol != view.EmptyString && !strings.Contains(strings.ToLower(op.Protocol), asyncKafkaProtocol) {
+				continue
+			}
+			method := kafkaOperation(op.Action)
+			if method == view.EmptyString {
+				log.Debugf("unknown action %s of AsyncAPI operation %s", op.Action, op.OperationId)
+				continue
+			}
+			err := rep.insertAsyncOperation(rq, reportId, op.OperationId, op.Channel, method)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	sqlExtra := `insert into report_service_operations2
+		(report_id, src_peer, dst_peer, operation_title, operation_path,
+		 operation_method, operation_status, hit_count, response_codes)
+	select ? as report_id, src_peer, dst_peer, '' as op_title, topic, operation, ? as op_status, count(event_id) as hit_count, null from
+		(select ` + peersSql("kme") + `, kme.topic, kme.operation, kme.event_id
+		from kafka_message_events kme
+		left join service_addresses sas on sas.address_id = kme.source_id
+		left join service_addresses sad on sad.address_id = kme.dest_id
+		where kme.capture_id = ?
+			and not exists (select null from report_affected_rows where report_id = ? and reference_id = kme.event_id and reference_type = ?)) t2
+	group by src_peer, dst_peer, topic, operation`
+	_, err := rep.db.GetConnection().Exec(sqlExtra, reportId, view.OperationExtra, rq.CaptureId, reportId, entities.ReportAffectedKafkaEvent)
+	if err != nil {
+		return fmt.Errorf("unable to insert Kafka topics not belong service in Db: %v", err)
+	}
+	return nil
+}
+
+// insertAsyncOperation
+// counts Kafka records of the channel by peers and marks them as affected by the report
+func (rep *ServiceOperationsImpl) insertAsyncOperation(rq view.ServiceReportRequest, reportId int, title, channel, method string) error {
+	channelRe := asyncChannelRegexp(channel)
+	_, err := rep.db.GetConnection().Exec(`
+		insert into report_affected_rows (report_id, reference_id, reference_type, hit_count)
+		(select ?, event_id, ?, 1 from kafka_message_events
+		where capture_id = ? and operation = ? and not regexp_match(topic, ?) is null)
+		on conflict (report_id, reference_id, reference_type) do nothing`,
+		reportId, entities.ReportAffectedKafkaEvent, rq.CaptureId, method, channelRe)
+	if err != nil {
+		return fmt.Errorf("unable to insert affected rows for Kafka events in Db: %v", err)
+	}
+	sqlOp := `insert into report_service_operations2
+		(report_id, src_peer, dst_peer, operation_title, operation_path,
+		 operation_method, operation_status, hit_count, response_codes)
+	select ? as report_id, src_peer, dst_peer, ? as op_title, ? as op_path, ? as op_method,
+		case when count(event_id) > 0 then ? else ? end as op_status, count(event_id) as hit_count, null from
+		(select ` + peersSql("kme") + `, kme.event_id
+		from (select 1) op
+		left join kafka_message_events kme
+			on kme.capture_id = ? and kme.operation = ? and not regexp_match(kme.topic, ?) is null
+		left join service_addresses sas on sas.address_id = kme.source_id
+		left join service_addresses sad on sad.address_id = kme.dest_id) t2
+	group by src_peer, dst_peer`
+	_, err = rep.db.GetConnection().Exec(sqlOp, reportId, title, channel, method, view.OperationFound, view.OperationNotFound,
+		rq.CaptureId, method, channelRe)
+	if err != nil {
+		return fmt.Errorf("unable to insert AsyncAPI operation %s into report: %v", title, err)
+	}
+	return nil
+}
+
+// kafkaOperation
+// returns the Kafka operation of the service for AsyncAPI action, empty string for unknown action.
+// AsyncAPI 2 publish operation is the one the application receives
+func kafkaOperation(action string) string {
+	switch strings.ToLower(action) {
+	case "send", "subscribe":
+		return entities.KafkaOperationProduce
+	case "receive", "publish":
+		return entities.KafkaOperationConsume
+	}
+	return view.EmptyString
+}
+
+// asyncChannelRegexp
+// makes regular expression matching the whole topic name, channel parameters match any text
+func asyncChannelRegexp(channel string) string {
+	parts := asyncChannelParamRe.Split(channel, -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return "^" + strings.Join(parts, ".+") + "$"
+}
+
+// cacheServiceOperation
+// counts operation occurrences in the capture and stores the operation into DB
+func (rep *ServiceOperationsImpl) cacheServiceOperation(rq view.ServiceReportRequest, tmpOpStat *entities.ReportServiceOperation) error {
+	tmpOptUpd := new(entities.ServicePacket)
+	whereClause := "capture_id=? and request_method=? and "
+	pathParam := tmpOpStat.Path
+	if tmpOpStat.Regexp != view.EmptyString {
+		whereClause += "not regexp_match(request_path, ?) is null "
+		pathParam = tmpOpStat.Regexp
+	} else {
+		whereClause += "request_path = ? "
+	}
+	var err error
+	tmpOpStat.HitCount, err = rep.db.GetConnection().Model(tmpOptUpd).
+		Where(whereClause, rq.CaptureId, tmpOpStat.Method, pathParam).Count()
+	if err == nil {
+		if tmpOpStat.HitCount > 0 {
+			tmpOpStat.Status = view.OperationFound
+		}
+	} else {
+		if !errors.Is(err, pg.ErrNoRows) {
+			log.Debugf("unable to get hit count %s for service operation %s to report id %d: %v", whereClause, tmpOpStat.Path, tmpOpStat.ReportId, err)
+		}
+	}
+	err = entities.InsertReportServiceOperation(rep.db, tmpOpStat)
+	if err != nil {
+		log.Debugf("unable to store service operation %s in Db: %v", tmpOpStat.Path, err)
+	}
+	return err
+}
+
+// openApiParameter
+// an operation parameter or a reference to the shared one
+type openApiParameter struct {
+	Ref      string `json:"$ref"`
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+}
+
+// openApiParameters
+// parameters of the operation specification (OpenAPI 3 components or Swagger 2 shared parameters)
+type openApiParameters struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Parameters map[string]openApiParameter           `json:"parameters"`
+	Components struct {
+		Parameters map[string]openApiParameter `json:"parameters"`
+	} `json:"components"`
+}
+
+// documentedQueryParameters
+// collects query parameters of the operation specification, operation parameters override path item ones
+func documentedQueryParameters(data *orderedmap.OrderedMap, method string) map[string]bool {
+	params := make(map[string]bool)
+	if data == nil {
+		return params
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return params
+	}
+	var spec openApiParameters
+	err = json.Unmarshal(raw, &spec)
+	if err != nil {
+		log.Debugf("unable to read operation parameters: %v", err)
+		return params
+	}
+	resolve := func(param openApiParameter) openApiParameter {
+		switch {
+		case strings.HasPrefix(param.Ref, "#/components/parameters/"):
+			return spec.Components.Parameters[strings.TrimPrefix(param.Ref, "#/components/parameters/")]
+		case strings.HasPrefix(param.Ref, "#/parameters/"):
+			return spec.Parameters[strings.TrimPrefix(param.Ref, "#/parameters/")]
+		}
+		return param
+	}
+	collect := func(raw json.RawMessage) {
+		list := make([]openApiParameter, 0)
+		if json.Unmarshal(raw, &list) != nil {
+			return
+		}
+		for _, param := range list {
+			param = resolve(param)
+			if param.In == "query" && param.Name != view.EmptyString {
+				params[param.Name] = param.Required
+			}
+		}
+	}
+	for _, pathItem := range spec.Paths {
+		operation, found := pathItem[strings.ToLower(method)]
+		if !found {
+			continue
+		}
+		collect(pathItem["parameters"])
+		var operationParams struct {
+			Parameters json.RawMessage `json:"parameters"`
+		}
+		if json.Unmarshal(operation, &operationParams) == nil {
+			collect(operationParams.Parameters)
+		}
+	}
+	return params
+}
+
+// cacheQueryParameters
+// stores documented query parameters of the operation to count their usage
+func (rep *ServiceOperationsImpl) cacheQueryParameters(operation entities.ReportServiceOperation, params map[string]bool) {
+	if len(params) == 0 {
+		return
+	}
+	rows := make([]entities.ReportQueryParameter, 0, len(params))
+	for name, required := range params {
+		rows = append(rows, entities.ReportQueryParameter{
+			ReportId:   operation.ReportId,
+			Title:      operation.Title,
+			Path:       operation.Path,
+			Method:     operation.Method,
+			Name:       name,
+			Documented: true,
+			Required:   required,
+		})
+	}
+	_, err := rep.db.GetConnection().Model(&rows).OnConflict("DO NOTHING").Insert()
+	if err != nil {
+		log.Debugf("unable to store query parameters of service operation %s in Db: %v", operation.Path, err)
+	}
+}
+
+// queryParameterCoverage
+// counts requests of the service operations sending each query parameter,
+// parameters not listed in the operation are stored as undocumented
+func (rep *ServiceOperationsImpl) queryParameterCoverage(rq view.ServiceReportRequest, reportId int) error {
+	_, err := rep.db.GetConnection().Exec(`
+		insert into report_query_parameters
+			(report_id, operation_title, operation_path, operation_method, param_name, documented, hit_count)
+		select rso.report_id, min(rso.operation_title), rso.operation_path, rso.operation_method, sqp.param_name, false,
+			count(distinct sp.packet_id)
+		from report_service_operations rso
+		join service_packets sp
+			on ((not regexp_match(sp.request_path, rso.operation_path_re) is null) or sp.request_path = rso.operation_path)
+				and sp.request_method = rso.operation_method
+				and sp.capture_id = ?
+		join service_query_params sqp on sqp.packet_id = sp.packet_id
+		where rso.report_id = ?
+		group by rso.report_id, rso.operation_path, rso.operation_method, sqp.param_name
+		on conflict (report_id, operation_path, operation_method, param_name) do update set hit_count = excluded.hit_count`,
+		rq.CaptureId, reportId)
+	if err != nil {
+		return fmt.Errorf("unable to insert query parameter coverage in Db: %v", err)
+	}
+	return nil
+}
+
+// grpcOperationPath
+// makes request path for gRPC method, service part is a wildcard when the method is not qualified
+func grpcOperationPath(method string) string {
+	switch {
+	case strings.HasPrefix(method, "/"):
+		return method
+	case strings.Contains(method, "/"):
+		return "/" + method
+	}
+	if pos := strings.LastIndex(method, "."); pos > 0 {
+		return "/" + method[:pos] + "/" + method[pos+1:]
+	}
+	return "/" + entities.ServiceOperationStar + "/" + method
+}
+
 // insertReportData
 // copy selected into report data table
 func insertReportData(db db.ConnectionProvider, reportData []entities.ReportServiceOperationWithPeers, reportId int, serviceName string) (int, int, error) {
@@ -348,8 +754,9 @@ func insertReportData(db db.ConnectionProvider, reportData []entities.ReportServ
 				HitCount: reportOperationRow.Occurrences,
 				Peers:    nil,
 			},
-			Source:      senderService,
-			Destination: receiverService,
+			Source:        senderService,
+			Destination:   receiverService,
+			ResponseCodes: reportOperationRow.ResponseCodes,
 		}
 		reportRow := new(entities.ReportDataRow)
 		reportRow.ReportId = reportId
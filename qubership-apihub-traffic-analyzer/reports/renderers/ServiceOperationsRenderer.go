@@ -61,8 +61,8 @@ const (
 
 var (
 	sheets        = []string{"Parameters", "Data"}
-	columnHeaders = []string{"Sender", "Receiver", "Method", "Path", "Operation-id", "Count", "Comment"}
-	colWidths     = []float64{20., 20., 10., 75., 70., 10., 15.}
+	columnHeaders = []string{"Sender", "Receiver", "Method", "Path", "Operation-id", "Count", "Comment", "Response codes"}
+	colWidths     = []float64{20., 20., 10., 75., 70., 10., 15., 15.}
 
 	//byteArrayBegin  = []byte(jsonArrayBegin)
 	//byteArrayEnd    = []byte(jsonArrayEnd)
@@ -250,6 +250,7 @@ func (srr *ServiceOperationsRenderer) RenderRow(dataRow *entities.ReportDataRow)
 		colValues[fmt.Sprintf("E%d", srr.currentDataRow)] = data.Id
 		colValues[fmt.Sprintf("F%d", srr.currentDataRow)] = data.HitCount
 		colValues[fmt.Sprintf("G%d", srr.currentDataRow)] = data.Status
+		colValues[fmt.Sprintf("H%d", srr.currentDataRow)] = data.ResponseCodes
 		err = srr.xl.SetCellsValues(sheets[dataSheetIndex], colValues)
 		if err == nil {
 			srr.currentDataRow++
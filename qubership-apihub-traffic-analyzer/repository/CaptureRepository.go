@@ -0,0 +1,473 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+	"github.com/go-pg/pg/v10"
+)
+
+const (
+	// httpHeadersLock advisory lock name of HTTP headers storing and purging
+	httpHeadersLock = "http_headers"
+	// packetPartitionPrefix name prefix of service_packets partitions
+	packetPartitionPrefix = "service_packets_"
+)
+
+// ErrCaptureLoading
+// the capture is not purged while its load job is not finished
+var ErrCaptureLoading = errors.New("capture is being loaded")
+
+// packetPartition
+// returns name of the capture packets partition, capture id is hashed to fit the identifier length
+func packetPartition(captureId string) string {
+	hash := md5.Sum([]byte(captureId))
+	return packetPartitionPrefix + hex.EncodeToString(hash[:])
+}
+
+// createPacketPartition
+// creates service_packets partition of the capture if it does not exist
+func createPacketPartition(tx *pg.Tx, captureId string) error {
+	_, err := tx.Exec("CREATE TABLE if not exists ? PARTITION OF service_packets FOR VALUES IN (?)",
+		pg.Ident(packetPartition(captureId)), captureId)
+	if err != nil {
+		return fmt.Errorf("unable to create packets partition of capture '%s': %v", captureId, err)
+	}
+	return nil
+}
+
+// deletePacketRows
+// deletes the rows referencing packets of the capture, the partition is not detached while referenced
+func deletePacketRows(tx *pg.Tx, captureId string) error {
+	for _, model := range []interface{}{
+		(*entities.PacketHeader)(nil),
+		(*entities.ServiceExchange)(nil),
+		(*entities.ServiceWebSocketMessage)(nil),
+		(*entities.ServiceGraphqlOperation)(nil),
+		(*entities.ServiceQueryParam)(nil),
+	} {
+		_, err := deleteCaptureRows(tx, model, captureId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropPacketPartition
+// detaches service_packets partition of the capture without blocking the packets of other captures and drops it,
+// returns number of the dropped packets. DETACH CONCURRENTLY can not run inside a transaction
+func dropPacketPartition(conn *pg.Conn, captureId string) (int, error) {
+	partition := pg.Ident(packetPartition(captureId))
+	var exists bool
+	_, err := conn.QueryOne(pg.Scan(&exists), "select to_regclass(?) is not null", packetPartition(captureId))
+	if err != nil {
+		return 0, fmt.Errorf("unable to check packets partition: %v", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+	packets := 0
+	_, err = conn.QueryOne(pg.Scan(&packets), "select count(*) from ?", partition)
+	if err != nil {
+		return 0, fmt.Errorf("unable to count capture packets: %v", err)
+	}
+	var attached, pending bool
+	_, err = conn.QueryOne(pg.Scan(&attached, &pending),
+		"select count(*) > 0, coalesce(bool_or(inhdetachpending), false) from pg_inherits where inhrelid = to_regclass(?)",
+		packetPartition(captureId))
+	if err != nil {
+		return 0, fmt.Errorf("unable to check packets partition: %v", err)
+	}
+	if attached {
+		detach := "ALTER TABLE service_packets DETACH PARTITION ? CONCURRENTLY"
+		if pending {
+			// the detach was interrupted before
+			detach = "ALTER TABLE service_packets DETACH PARTITION ? FINALIZE"
+		}
+		_, err = conn.Exec(detach, partition)
+		if err != nil {
+			return 0, fmt.Errorf("unable to detach packets partition: %v", err)
+		}
+	}
+	_, err = conn.Exec("drop table ?", partition)
+	if err != nil {
+		return 0, fmt.Errorf("unable to drop packets partition: %v", err)
+	}
+	return packets, nil
+}
+
+// StartCaptureLoad
+// registers the capture load (reload) start
+func StartCaptureLoad(db db.ConnectionProvider, captureId string) error {
+	capture := entities.StoredCapture{CaptureId: captureId}
+	_, err := db.GetConnection().Model(&capture).
+		OnConflict("(capture_id) DO UPDATE").
+		Set("load_started_at=now()").
+		Insert()
+	if err != nil {
+		return fmt.Errorf("unable to register capture '%s' load: %v", captureId, err)
+	}
+	return nil
+}
+
+// FinishCaptureLoad
+// registers the capture load finish
+func FinishCaptureLoad(db db.ConnectionProvider, captureId string) error {
+	_, err := db.GetConnection().Model((*entities.StoredCapture)(nil)).
+		Set("loaded_at=now()").
+		Where("capture_id=?", captureId).
+		Update()
+	if err != nil {
+		return fmt.Errorf("unable to register capture '%s' load finish: %v", captureId, err)
+	}
+	return nil
+}
+
+// PurgeCapture
+// deletes the aggregated data of the capture and the reports generated from it, then purges orphaned HTTP headers
+func PurgeCapture(db db.ConnectionProvider, captureId string) (view.CapturePurgeResult, error) {
+	result := view.CapturePurgeResult{CaptureId: captureId}
+	err := purgeCapture(db.GetConnection().Conn(), captureId, &result)
+	if err != nil {
+		return result, fmt.Errorf("unable to purge capture '%s': %w", captureId, err)
+	}
+	result.Headers, err = PurgeOrphanedHeaders(db)
+	return result, err
+}
+
+// purgeCapture
+// deletes the capture rows holding the capture packets lock for the session, the packets partition
+// is detached between the transactions deleting the rows referencing the packets and the packet addresses.
+// Load jobs are enqueued and taken under the same lock, so no load starts after the job check
+func purgeCapture(conn *pg.Conn, captureId string, result *view.CapturePurgeResult) error {
+	defer conn.Close()
+	// capture packets are not stored meanwhile
+	_, err := conn.Exec("SELECT pg_advisory_lock(hashtext(?))", captureId)
+	if err != nil {
+		return fmt.Errorf("unable to lock capture packets: %v", err)
+	}
+	defer func() {
+		_, _ = conn.Exec("SELECT pg_advisory_unlock(hashtext(?))", captureId)
+	}()
+	loading, err := conn.Model((*entities.LoadPacketJob)(nil)).
+		Where("capture_id=? and finished is null", captureId).Exists()
+	if err != nil {
+		return fmt.Errorf("unable to check capture load jobs: %v", err)
+	}
+	if loading {
+		return ErrCaptureLoading
+	}
+	err = conn.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		var reportIds []int
+		err := tx.Model((*entities.ReportEntity)(nil)).Column("report_id").
+			Where("report_parameters->>'capture_id'=?", captureId).Select(&reportIds)
+		if err != nil && !errors.Is(err, pg.ErrNoRows) {
+			return fmt.Errorf("unable to select capture reports: %v", err)
+		}
+		result.Reports, err = purgeReports(tx, reportIds)
+		if err != nil {
+			return err
+		}
+		return deletePacketRows(tx, captureId)
+	})
+	if err != nil {
+		return err
+	}
+	result.Packets, err = dropPacketPartition(conn, captureId)
+	if err != nil {
+		return err
+	}
+	return conn.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		var err error
+		result.KafkaEvents, err = deleteCaptureRows(tx, (*entities.KafkaMessageEvent)(nil), captureId)
+		if err != nil {
+			return err
+		}
+		result.Addresses, err = deleteCaptureRows(tx, (*entities.ServiceAddress)(nil), captureId)
+		if err != nil {
+			return err
+		}
+		result.Metadata, err = deleteCaptureRows(tx, (*entities.CaptureMetadata)(nil), captureId)
+		if err != nil {
+			return err
+		}
+		_, err = deleteCaptureRows(tx, (*entities.StoredCapture)(nil), captureId)
+		return err
+	})
+}
+
+// PurgeReport
+// deletes the report and its data, returns false when the report was not found
+func PurgeReport(db db.ConnectionProvider, reportUuid string) (bool, error) {
+	deleted := 0
+	err := db.GetConnection().RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		var reportIds []int
+		err := tx.Model((*entities.ReportEntity)(nil)).Column("report_id").Where("report_uuid=?", reportUuid).Select(&reportIds)
+		if err != nil && !errors.Is(err, pg.ErrNoRows) {
+			return err
+		}
+		deleted, err = purgeReports(tx, reportIds)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to purge report '%s': %v", reportUuid, err)
+	}
+	return deleted > 0, nil
+}
+
+// PurgeOrphanedHeaders
+// deletes HTTP headers not referenced by any packet, returns number of the deleted headers
+func PurgeOrphanedHeaders(db db.ConnectionProvider) (int, error) {
+	deleted := 0
+	err := db.GetConnection().RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		// headers being stored by the packet batches are linked before the lock is released
+		_, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", httpHeadersLock)
+		if err != nil {
+			return err
+		}
+		res, err := tx.Exec(`delete from http_headers h
+			where not exists (select null from service_packet_headers sph where sph.header_id = h.header_id)
+			and not exists (select null from packet_headers ph where ph.header_id = h.header_id)`)
+		if err != nil {
+			return err
+		}
+		deleted = res.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to purge orphaned HTTP headers: %v", err)
+	}
+	return deleted, nil
+}
+
+// ExpiredCaptures
+// returns captures loaded before the maximal age and captures exceeding the maximal number (the oldest first),
+// captures with a queued or running load job are kept, not finished loads without a job expire by the load start
+func ExpiredCaptures(db db.ConnectionProvider, policy view.RetentionPolicy) ([]string, error) {
+	captureIds := make([]string, 0)
+	if policy.CaptureMaxAge <= 0 && policy.MaxCaptures <= 0 {
+		return captureIds, nil
+	}
+	maxAge := policy.CaptureMaxAge.Seconds()
+	_, err := db.GetConnection().Query(&captureIds, `select capture_id from (
+			select capture_id, loaded_at, load_started_at,
+				row_number() over (order by coalesce(loaded_at, load_started_at) desc) as recent
+			from stored_captures) t
+		where ((loaded_at is not null and loaded_at >= load_started_at
+				and ((? > 0 and loaded_at < now() - make_interval(secs => ?)) or (? > 0 and recent > ?)))
+			or ((loaded_at is null or loaded_at < load_started_at)
+				and ? > 0 and load_started_at < now() - make_interval(secs => ?)))
+			and not exists (select 1 from load_packet_jobs j where j.capture_id = t.capture_id and j.finished is null)
+		order by coalesce(loaded_at, load_started_at)`,
+		maxAge, maxAge, policy.MaxCaptures, policy.MaxCaptures, maxAge, maxAge)
+	if err != nil {
+		return nil, fmt.Errorf("unable to select expired captures: %v", err)
+	}
+	return captureIds, nil
+}
+
+// ExpiredReports
+// returns reports created before the maximal age
+func ExpiredReports(db db.ConnectionProvider, maxAge time.Duration) ([]string, error) {
+	reportUuids := make([]string, 0)
+	if maxAge <= 0 {
+		return reportUuids, nil
+	}
+	err := db.GetConnection().Model((*entities.ReportEntity)(nil)).Column("report_uuid").
+		Where("created_at < now() - make_interval(secs => ?)", maxAge.Seconds()).Order("created_at").Select(&reportUuids)
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, fmt.Errorf("unable to select expired reports: %v", err)
+	}
+	return reportUuids, nil
+}
+
+// purgeReports
+// deletes the reports, report data rows are deleted by cascade
+func purgeReports(tx *pg.Tx, reportIds []int) (int, error) {
+	if len(reportIds) == 0 {
+		return 0, nil
+	}
+	for _, table := range []string{"report_affected_rows", "report_service_operations"} {
+		_, err := tx.Exec("delete from "+table+" where report_id in (?)", pg.In(reportIds))
+		if err != nil {
+			return 0, fmt.Errorf("unable to delete %s of reports: %v", table, err)
+		}
+	}
+	res, err := tx.Model((*entities.ReportEntity)(nil)).Where("report_id in (?)", pg.In(reportIds)).Delete()
+	if err != nil {
+		return 0, fmt.Errorf("unable to delete reports: %v", err)
+	}
+	return res.RowsAffected(), nil
+}
+
+// deleteCaptureRows
+// deletes rows of the model table belonging to the capture, returns number of the deleted rows
+func deleteCaptureRows(tx *pg.Tx, model interface{}, captureId string) (int, error) {
+	res, err := tx.Model(model).Where("capture_id=?", captureId).Delete()
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+// GetCaptures
+// returns the page of captures loaded into DB, the most recently loaded first
+func GetCaptures(db db.ConnectionProvider, limit, page int) ([]view.CaptureInfo, error) {
+	captures := make([]entities.StoredCapture, 0)
+	err := db.GetConnection().Model(&captures).Order("load_started_at DESC", "capture_id").
+		Limit(limit).Offset(limit * page).Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, fmt.Errorf("unable to select captures: %v", err)
+	}
+	return makeCaptureInfos(db, captures)
+}
+
+// GetCapture
+// returns the capture loaded into DB, nil when the capture is unknown
+func GetCapture(db db.ConnectionProvider, captureId string) (*view.CaptureInfo, error) {
+	capture := entities.StoredCapture{}
+	err := db.GetConnection().Model(&capture).Where("capture_id=?", captureId).Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to select capture '%s': %v", captureId, err)
+	}
+	infos, err := makeCaptureInfos(db, []entities.StoredCapture{capture})
+	if err != nil {
+		return nil, err
+	}
+	return &infos[0], nil
+}
+
+// captureCounts
+// stored data summary of a capture
+type captureCounts struct {
+	CaptureId     string
+	PacketCount   int
+	ExchangeCount int
+	FirstPacketAt *time.Time
+	LastPacketAt  *time.Time
+}
+
+// captureService
+// a service of a capture address list
+type captureService struct {
+	CaptureId string
+	view.CaptureService
+}
+
+// captureReport
+// a report generated from a capture
+type captureReport struct {
+	CaptureId string
+	view.CaptureReport
+}
+
+// makeCaptureInfos
+// collects metadata, stored data summary, services and reports of the captures, each kind of data is
+// selected by a single query for all the captures
+func makeCaptureInfos(db db.ConnectionProvider, captures []entities.StoredCapture) ([]view.CaptureInfo, error) {
+	result := make([]view.CaptureInfo, 0, len(captures))
+	if len(captures) == 0 {
+		return result, nil
+	}
+	captureIds := make([]string, 0, len(captures))
+	infos := make(map[string]*view.CaptureInfo, len(captures))
+	for _, capture := range captures {
+		result = append(result, view.CaptureInfo{
+			CaptureId:     capture.CaptureId,
+			LoadState:     view.CaptureLoadState(capture.LoadStartedAt, capture.LoadedAt),
+			CreatedAt:     capture.CreatedAt,
+			LoadStartedAt: capture.LoadStartedAt,
+			LoadedAt:      capture.LoadedAt,
+			Services:      make([]view.CaptureService, 0),
+			Reports:       make([]view.CaptureReport, 0),
+		})
+		captureIds = append(captureIds, capture.CaptureId)
+	}
+	for i := range result {
+		infos[result[i].CaptureId] = &result[i]
+	}
+	conn := db.GetConnection()
+	metadata := make([]entities.CaptureMetadata, 0)
+	err := conn.Model(&metadata).Where("capture_id in (?)", pg.In(captureIds)).Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, fmt.Errorf("unable to select captures metadata: %v", err)
+	}
+	for _, item := range metadata {
+		if item.Metadata != view.EmptyString {
+			infos[item.CaptureId].Metadata = []byte(item.Metadata)
+		}
+	}
+	counts := make([]captureCounts, 0)
+	_, err = conn.Query(&counts, `select c.capture_id, coalesce(p.packet_count, 0) as packet_count,
+			coalesce(e.exchange_count, 0) as exchange_count, p.first_packet_at, p.last_packet_at
+		from unnest(?::varchar[]) as c(capture_id)
+		left join (select capture_id, count(*) as packet_count, min(time_stamp) as first_packet_at,
+				max(time_stamp) as last_packet_at
+			from service_packets where capture_id in (?) group by capture_id) p on p.capture_id = c.capture_id
+		left join (select capture_id, count(*) as exchange_count
+			from service_exchanges where capture_id in (?) group by capture_id) e on e.capture_id = c.capture_id`,
+		pg.Array(captureIds), pg.In(captureIds), pg.In(captureIds))
+	if err != nil {
+		return nil, fmt.Errorf("unable to count captures packets: %v", err)
+	}
+	for _, item := range counts {
+		info := infos[item.CaptureId]
+		info.PacketCount, info.ExchangeCount = item.PacketCount, item.ExchangeCount
+		info.FirstPacketAt, info.LastPacketAt = item.FirstPacketAt, item.LastPacketAt
+	}
+	services := make([]captureService, 0)
+	_, err = conn.Query(&services, `select distinct capture_id, service_name as name,
+			coalesce(service_version, '') as version
+		from service_addresses where capture_id in (?) and coalesce(service_name, '') <> ''
+		order by capture_id, name, version`, pg.In(captureIds))
+	if err != nil {
+		return nil, fmt.Errorf("unable to select captures services: %v", err)
+	}
+	for _, item := range services {
+		info := infos[item.CaptureId]
+		info.Services = append(info.Services, item.CaptureService)
+	}
+	reports := make([]captureReport, 0)
+	_, err = conn.Query(&reports, `select sr.report_parameters->>'capture_id' as capture_id,
+			sr.report_uuid as report_id, rt.report_type, rs.report_status as status,
+			sr.report_parameters->>'service_name' as service_name, sr.report_parameters->>'service_version' as service_version,
+			sr.created_at, sr.completed_at
+		from stored_reports sr
+		left join report_types rt on rt.report_type_id = sr.report_type_id
+		left join report_status rs on rs.report_status_id = sr.report_status_id
+		where sr.report_parameters->>'capture_id' in (?)
+		order by sr.created_at`, pg.In(captureIds))
+	if err != nil {
+		return nil, fmt.Errorf("unable to select captures reports: %v", err)
+	}
+	for _, item := range reports {
+		info := infos[item.CaptureId]
+		info.Reports = append(info.Reports, item.CaptureReport)
+	}
+	return result, nil
+}
@@ -34,6 +34,7 @@ const (
 
 type HttpHeadersCache interface {
 	GetPrimaryKeyValue(key, value string) (string, error)
+	StoreHeaders(headers []entities.HttpHeaderItem) (map[string]bool, error)
 	Close()
 }
 type httpHeadersCache struct {
@@ -68,6 +69,35 @@ func (hc *httpHeadersCache) GetPrimaryKeyValue(key, value string) (string, error
 	return view.EmptyString, fmt.Errorf("unable to get header id for: %s : %v", key, err)
 }
 
+// StoreHeaders
+// stores the headers not cached yet by a single insert, returns ids of the headers known to be stored
+func (hc *httpHeadersCache) StoreHeaders(headers []entities.HttpHeaderItem) (map[string]bool, error) {
+	known := make(map[string]bool, len(headers))
+	missing := make([]entities.HttpHeaderItem, 0)
+	for _, h := range headers {
+		if _, found := known[h.Id]; found {
+			continue
+		}
+		_, exists := hc.instance.Load(h.Id)
+		known[h.Id] = exists
+		if !exists {
+			missing = append(missing, h)
+		}
+	}
+	if len(missing) == 0 {
+		return known, nil
+	}
+	_, err := hc.db.GetConnection().Model(&missing).OnConflict("DO NOTHING").Insert()
+	if err != nil {
+		return known, fmt.Errorf("unable to insert %d headers: %v", len(missing), err)
+	}
+	for _, h := range missing {
+		known[h.Id] = true
+		hc.instance.Store(h.Id, h)
+	}
+	return known, nil
+}
+
 func (hc *httpHeadersCache) Close() {
 	hc.instance.Purge()
 }
@@ -0,0 +1,303 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+	"github.com/go-pg/pg/v10"
+)
+
+// EnqueueLoadJob
+// creates a load job of the capture, returns the not finished job and false when the capture is being loaded.
+// The job is created holding the capture lock, so the capture is not purged meanwhile
+func EnqueueLoadJob(db db.ConnectionProvider, captureId string) (*entities.LoadPacketJob, bool, error) {
+	job := &entities.LoadPacketJob{CaptureId: captureId}
+	created := false
+	err := db.GetConnection().RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		_, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", captureId)
+		if err != nil {
+			return fmt.Errorf("unable to lock capture '%s': %v", captureId, err)
+		}
+		res, err := tx.Model(job).
+			ExcludeColumn("job_id", "created", "attempts", "files").
+			OnConflict("(capture_id) WHERE finished is null DO NOTHING").
+			Returning("*").
+			Insert()
+		if err != nil {
+			return fmt.Errorf("unable to create load job of capture '%s': %v", captureId, err)
+		}
+		if res.RowsAffected() > 0 {
+			created = true
+			return nil
+		}
+		job = &entities.LoadPacketJob{}
+		err = tx.Model(job).Where("capture_id=? and finished is null", captureId).Select()
+		if err != nil {
+			return fmt.Errorf("unable to get load job of capture '%s': %v", captureId, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return job, created, nil
+}
+
+// TakeLoadJob
+// takes the oldest job not held by a worker, the job is held until the TTL expires. Jobs taken maxAttempts times
+// are finished with error. Returns nil when no job is waiting
+func TakeLoadJob(db db.ConnectionProvider, workerId string, ttl time.Duration, maxAttempts int) (*entities.LoadPacketJob, error) {
+	_, err := db.GetConnection().Exec(`update load_packet_jobs
+		set finished = now(), error = 'load abandoned after ' || attempts || ' attempt(s)'
+		where finished is null and job_ttl < now() and attempts >= ?`, maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to finish abandoned load jobs: %v", err)
+	}
+	jobs := make([]entities.LoadPacketJob, 0)
+	err = db.GetConnection().RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		var waiting []entities.LoadPacketJob
+		_, err := tx.Query(&waiting, `select job_id, capture_id from load_packet_jobs
+			where finished is null and (job_ttl is null or job_ttl < now())
+			order by created limit 1 for update skip locked`)
+		if err != nil || len(waiting) == 0 {
+			return err
+		}
+		// the capture is not purged while the job is taken
+		_, err = tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", waiting[0].CaptureId)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Query(&jobs, `update load_packet_jobs
+			set started = coalesce(started, now()), job_ttl = now() + make_interval(secs => ?), worker_id = ?,
+				attempts = attempts + 1
+			where job_id = ? and finished is null
+			returning *`, ttl.Seconds(), workerId, waiting[0].JobId)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to take load job: %v", err)
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return &jobs[0], nil
+}
+
+// ExtendLoadJob
+// prolongs the job hold, returns false when the job is not held by the worker any more
+func ExtendLoadJob(db db.ConnectionProvider, jobId int, workerId string, ttl time.Duration) (bool, error) {
+	res, err := db.GetConnection().Model((*entities.LoadPacketJob)(nil)).
+		Set("job_ttl = now() + make_interval(secs => ?)", ttl.Seconds()).
+		Where("job_id=? and worker_id=? and finished is null", jobId, workerId).
+		Update()
+	if err != nil {
+		return false, fmt.Errorf("unable to extend load job %d: %v", jobId, err)
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+// FinishLoadJob
+// finishes the job held by the worker, the load error is stored with the job
+func FinishLoadJob(db db.ConnectionProvider, jobId int, workerId string, loadErr error) error {
+	var errText *string
+	if loadErr != nil {
+		text := loadErr.Error()
+		errText = &text
+	}
+	res, err := db.GetConnection().Model((*entities.LoadPacketJob)(nil)).
+		Set("finished = now()").
+		Set("error = ?", errText).
+		Where("job_id=? and worker_id=? and finished is null", jobId, workerId).
+		Update()
+	if err != nil {
+		return fmt.Errorf("unable to finish load job %d: %v", jobId, err)
+	}
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("load job %d is not held by worker %s", jobId, workerId)
+	}
+	return nil
+}
+
+// GetLastLoadJob
+// returns the most recent load job of the capture, nil when the capture was never loaded by a job
+func GetLastLoadJob(db db.ConnectionProvider, captureId string) (*entities.LoadPacketJob, error) {
+	job := &entities.LoadPacketJob{}
+	err := db.GetConnection().Model(job).Where("capture_id=?", captureId).Order("created DESC", "job_id DESC").First()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get load job of capture '%s': %v", captureId, err)
+	}
+	return job, nil
+}
+
+// GetLastLoadJobs
+// returns the last load job of each capture having jobs
+func GetLastLoadJobs(db db.ConnectionProvider, captureIds []string) (map[string]*entities.LoadPacketJob, error) {
+	result := make(map[string]*entities.LoadPacketJob, len(captureIds))
+	if len(captureIds) == 0 {
+		return result, nil
+	}
+	jobs := make([]entities.LoadPacketJob, 0)
+	_, err := db.GetConnection().Query(&jobs, `select distinct on (capture_id) * from load_packet_jobs
+		where capture_id in (?)
+		order by capture_id, created desc, job_id desc`, pg.In(captureIds))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get load jobs of captures: %v", err)
+	}
+	for i := range jobs {
+		result[jobs[i].CaptureId] = &jobs[i]
+	}
+	return result, nil
+}
+
+// GetLoadJobFiles
+// returns the files read by the job
+func GetLoadJobFiles(db db.ConnectionProvider, jobId int) ([]entities.LoadPacketJobFile, error) {
+	files := make([]entities.LoadPacketJobFile, 0)
+	err := db.GetConnection().Model(&files).Where("job_id=?", jobId).Order("file_name").Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, fmt.Errorf("unable to get files of load job %d: %v", jobId, err)
+	}
+	return files, nil
+}
+
+// StartLoadJobFile
+// registers the file reading start of the job held by the worker, the file read by an interrupted attempt is restarted
+func StartLoadJobFile(db db.ConnectionProvider, jobId int, workerId string, fileName string) error {
+	res, err := db.GetConnection().Exec(`insert into load_packet_job_files (job_id, file_name, started, packets)
+		select ?, ?, now(), 0 where exists (
+			select null from load_packet_jobs where job_id=? and worker_id=? and finished is null)
+		on conflict (job_id, file_name) do update
+			set started = now(), finished = null, packets = 0, error = null, counters = null`,
+		jobId, fileName, jobId, workerId)
+	if err != nil {
+		return fmt.Errorf("unable to register file '%s' of load job %d: %v", fileName, jobId, err)
+	}
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("load job %d is not held by worker %s", jobId, workerId)
+	}
+	return nil
+}
+
+// FinishLoadJobFile
+// registers the file reading finish of the job held by the worker with the file counters
+func FinishLoadJobFile(db db.ConnectionProvider, jobId int, workerId string, fileName string, packets int, counters *view.IngestCounters, readErr error) error {
+	var errText *string
+	if readErr != nil {
+		text := readErr.Error()
+		errText = &text
+	}
+	res, err := db.GetConnection().Model((*entities.LoadPacketJobFile)(nil)).
+		Set("finished = now()").
+		Set("packets = ?", packets).
+		Set("error = ?", errText).
+		Set("counters = ?", counters).
+		Where("job_id=? and file_name=?", jobId, fileName).
+		Where(`exists (select null from load_packet_jobs j
+			where j.job_id = load_packet_job_files.job_id and j.worker_id=? and j.finished is null)`, workerId).
+		Update()
+	if err != nil {
+		return fmt.Errorf("unable to register file '%s' finish of load job %d: %v", fileName, jobId, err)
+	}
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("load job %d is not held by worker %s", jobId, workerId)
+	}
+	return nil
+}
+
+// SetLoadJobFiles
+// stores the number of the capture files to read by the job held by the worker
+func SetLoadJobFiles(db db.ConnectionProvider, jobId int, workerId string, files int) error {
+	res, err := db.GetConnection().Model((*entities.LoadPacketJob)(nil)).
+		Set("files = ?", files).
+		Where("job_id=? and worker_id=? and finished is null", jobId, workerId).
+		Update()
+	if err != nil {
+		return fmt.Errorf("unable to set files of load job %d: %v", jobId, err)
+	}
+	if res.RowsAffected() == 0 {
+		return fmt.Errorf("load job %d is not held by worker %s", jobId, workerId)
+	}
+	return nil
+}
+
+// GetCaptureLoadStatus
+// returns progress of the last load of the capture, nil when the capture was never loaded by a job
+func GetCaptureLoadStatus(db db.ConnectionProvider, captureId string) (*view.CaptureLoadStatus, error) {
+	job, err := GetLastLoadJob(db, captureId)
+	if err != nil || job == nil {
+		return nil, err
+	}
+	files, err := GetLoadJobFiles(db, job.JobId)
+	if err != nil {
+		return nil, err
+	}
+	return makeCaptureLoadStatus(captureId, job, files), nil
+}
+
+// makeCaptureLoadStatus
+// makes the load progress of the job, the counters are summed over the job files
+func makeCaptureLoadStatus(captureId string, job *entities.LoadPacketJob, files []entities.LoadPacketJobFile) *view.CaptureLoadStatus {
+	status := &view.CaptureLoadStatus{
+		CaptureId:  captureId,
+		JobId:      job.JobId,
+		Attempts:   job.Attempts,
+		CreatedAt:  job.Created,
+		StartedAt:  job.Started,
+		FinishedAt: job.Finished,
+		FilesTotal: job.Files,
+		Files:      make([]view.CaptureFileLoadStatus, 0, len(files)),
+	}
+	switch {
+	case job.Finished != nil && job.Error != nil:
+		status.LoadState = view.CaptureLoadFailed
+		status.LoadError = *job.Error
+	case job.Finished != nil:
+		status.LoadState = view.CaptureLoaded
+	case job.Started != nil:
+		status.LoadState = view.CaptureLoading
+	default:
+		status.LoadState = view.CaptureLoadQueued
+	}
+	for _, file := range files {
+		fileStatus := view.CaptureFileLoadStatus{
+			FileName:   file.FileName,
+			StartedAt:  file.Started,
+			FinishedAt: file.Finished,
+			Counters:   file.Counters,
+		}
+		if file.Error != nil {
+			fileStatus.Error = *file.Error
+		}
+		if file.Finished != nil {
+			status.FilesRead++
+		}
+		if file.Counters != nil {
+			status.Counters.Add(*file.Counters)
+		}
+		status.Files = append(status.Files, fileStatus)
+	}
+	status.PercentComplete = view.LoadPercent(status.FilesRead, status.FilesTotal, status.LoadState == view.CaptureLoaded)
+	return status
+}
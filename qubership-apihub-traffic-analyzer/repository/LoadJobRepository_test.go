@@ -0,0 +1,125 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+)
+
+func TestMakeCaptureLoadStatus(t *testing.T) {
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	started := created.Add(time.Minute)
+	finished := started.Add(time.Minute)
+	loadErr := "unable to read capture file"
+	readErr := "unexpected EOF"
+	counters := func(packets, stored int, errors map[string]int) *view.IngestCounters {
+		return &view.IngestCounters{Packets: packets, Stored: stored, Errors: errors}
+	}
+	files := []entities.LoadPacketJobFile{
+		{FileName: "a.pcap", Started: started, Finished: &finished, Packets: 10,
+			Counters: counters(10, 4, map[string]int{view.IngestErrorTcpLayer: 1})},
+		{FileName: "b.pcap", Started: started, Finished: &finished, Error: &readErr,
+			Counters: counters(5, 2, map[string]int{view.IngestErrorTcpLayer: 2, view.IngestErrorRead: 1})},
+		{FileName: "c.pcap", Started: started},
+	}
+	tests := []struct {
+		name        string
+		job         entities.LoadPacketJob
+		files       []entities.LoadPacketJobFile
+		wantState   string
+		wantError   string
+		wantRead    int
+		wantPercent float64
+		wantCounter view.IngestCounters
+	}{
+		{
+			name:      "queued",
+			job:       entities.LoadPacketJob{JobId: 1, Created: created},
+			wantState: view.CaptureLoadQueued,
+		},
+		{
+			name:        "loading",
+			job:         entities.LoadPacketJob{JobId: 1, Created: created, Started: &started, Files: 3, Attempts: 2},
+			files:       files,
+			wantState:   view.CaptureLoading,
+			wantRead:    2,
+			wantPercent: 66.7,
+			wantCounter: view.IngestCounters{Packets: 15, Stored: 6,
+				Errors: map[string]int{view.IngestErrorTcpLayer: 3, view.IngestErrorRead: 1}},
+		},
+		{
+			name:        "loaded",
+			job:         entities.LoadPacketJob{JobId: 1, Created: created, Started: &started, Finished: &finished, Files: 1},
+			files:       files[:1],
+			wantState:   view.CaptureLoaded,
+			wantRead:    1,
+			wantPercent: 100,
+			wantCounter: *counters(10, 4, map[string]int{view.IngestErrorTcpLayer: 1}),
+		},
+		{
+			name: "failed",
+			job: entities.LoadPacketJob{JobId: 1, Created: created, Started: &started, Finished: &finished, Files: 4,
+				Error: &loadErr},
+			files:       files[1:],
+			wantState:   view.CaptureLoadFailed,
+			wantError:   loadErr,
+			wantRead:    1,
+			wantPercent: 25,
+			wantCounter: *counters(5, 2, map[string]int{view.IngestErrorTcpLayer: 2, view.IngestErrorRead: 1}),
+		},
+		{
+			name:      "finished without files",
+			job:       entities.LoadPacketJob{JobId: 1, Created: created, Started: &started, Finished: &finished},
+			wantState: view.CaptureLoaded,
+			// a successful load is complete even when the files were not counted
+			wantPercent: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := makeCaptureLoadStatus("capture", &tt.job, tt.files)
+			if status.LoadState != tt.wantState || status.LoadError != tt.wantError {
+				t.Errorf("state %s (%s), want %s (%s)", status.LoadState, status.LoadError, tt.wantState, tt.wantError)
+			}
+			if status.FilesRead != tt.wantRead || status.PercentComplete != tt.wantPercent {
+				t.Errorf("%d files read, %v%% complete, want %d and %v%%",
+					status.FilesRead, status.PercentComplete, tt.wantRead, tt.wantPercent)
+			}
+			if !reflect.DeepEqual(status.Counters, tt.wantCounter) {
+				t.Errorf("counters %v, want %v", status.Counters, tt.wantCounter)
+			}
+			if len(status.Files) != len(tt.files) {
+				t.Fatalf("%d files, want %d", len(status.Files), len(tt.files))
+			}
+			for i, file := range status.Files {
+				if file.FileName != tt.files[i].FileName || (file.Error != "") != (tt.files[i].Error != nil) {
+					t.Errorf("file %+v does not match %+v", file, tt.files[i])
+				}
+			}
+			if status.CaptureId != "capture" || status.Attempts != tt.job.Attempts || status.FilesTotal != tt.job.Files {
+				t.Errorf("status %+v does not match job %+v", status, tt.job)
+			}
+		})
+	}
+	// the file counters are summed into a new map
+	if files[0].Counters.Errors[view.IngestErrorTcpLayer] != 1 {
+		t.Errorf("file counters are changed: %v", files[0].Counters)
+	}
+}
@@ -0,0 +1,602 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/redaction"
+	"github.com/go-pg/pg/v10"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefPacketBatchSize packets stored by a single batch by default
+const DefPacketBatchSize = 500
+
+// PacketRef
+// a reference to the packet queued for storing, packet id is known after the batch is flushed (zero if not stored)
+type PacketRef struct {
+	PacketId int
+}
+
+// PacketBatch
+// collects packets with their headers and exchanges, stores them by multi-row inserts when the batch is full,
+// everything queued is redacted first
+type PacketBatch interface {
+	// AddPacket queues the packet, the returned reference gets packet id when the batch is flushed
+	AddPacket(packet entities.ParsedPacket) *PacketRef
+	// AddExchange queues the exchange, packet ids are taken from the references when the batch is flushed
+	AddExchange(exchange entities.ServiceExchange, request, response *PacketRef)
+	// AddWebSocketMessage queues the WebSocket message, handshake packet id is taken from the reference when the batch is flushed
+	AddWebSocketMessage(message entities.ServiceWebSocketMessage, handshake *PacketRef)
+	// AddGraphqlOperations queues GraphQL operations of the request, packet id is taken from the reference when the batch is flushed
+	AddGraphqlOperations(operations []entities.ServiceGraphqlOperation, request *PacketRef)
+	// AddKafkaEvents queues Kafka records of a topic partition
+	AddKafkaEvents(events []entities.KafkaMessageEvent)
+	// Flush stores the queued packets and exchanges
+	Flush() error
+	// Stored returns number of the packets stored (duplicates of the stored packets included)
+	Stored() int
+	// Duplicates returns number of the stored packets found stored before
+	Duplicates() int
+	// Dropped returns number of the packets which could not be stored
+	Dropped() int
+	// Err returns the first error of the full batches flushed while queueing, nil when all of them were stored
+	Err() error
+}
+
+// pendingPacket
+// a packet queued for storing
+type pendingPacket struct {
+	packet      entities.ServicePacket
+	headers     []entities.HttpHeaderItem
+	queryParams []entities.ServiceQueryParam
+	ref         *PacketRef
+}
+
+// pendingExchange
+// an exchange queued for storing
+type pendingExchange struct {
+	exchange entities.ServiceExchange
+	request  *PacketRef
+	response *PacketRef
+}
+
+// pendingWebSocketMessage
+// a WebSocket message queued for storing
+type pendingWebSocketMessage struct {
+	message   entities.ServiceWebSocketMessage
+	handshake *PacketRef
+}
+
+// pendingGraphqlOperations
+// GraphQL operations of a request queued for storing
+type pendingGraphqlOperations struct {
+	operations []entities.ServiceGraphqlOperation
+	request    *PacketRef
+}
+
+type packetBatchImpl struct {
+	db          db.ConnectionProvider
+	captureId   string
+	batchSize   int
+	redactor    redaction.Redactor
+	packets     []pendingPacket
+	exchanges   []pendingExchange
+	wsMessages  []pendingWebSocketMessage
+	kafkaEvents []entities.KafkaMessageEvent
+	graphqlOps  []pendingGraphqlOperations
+	stored      int
+	duplicates  int
+	dropped     int
+	// failure the first error of the full batches flushed while queueing
+	failure error
+	// partitioned the capture packets partition is known to exist
+	partitioned bool
+	// insert stores the rows by a single transaction
+	insert func(rows batchRows) (int, error)
+}
+
+func (pb *packetBatchImpl) AddPacket(packet entities.ParsedPacket) *PacketRef {
+	pb.redactor.Packet(&packet)
+	pending := pendingPacket{
+		packet:      entities.MakeDbPacket(packet, pb.captureId),
+		headers:     make([]entities.HttpHeaderItem, 0, len(packet.Headers)),
+		queryParams: entities.ParseQueryParams(packet.RequestQuery, pb.captureId),
+		ref:         &PacketRef{},
+	}
+	for k, v := range packet.Headers {
+		pending.headers = append(pending.headers, entities.NewHttpHeader(k, v))
+	}
+	pb.packets = append(pb.packets, pending)
+	if len(pb.packets) >= pb.batchSize {
+		pb.flushLogged()
+	}
+	return pending.ref
+}
+
+func (pb *packetBatchImpl) AddExchange(exchange entities.ServiceExchange, request, response *PacketRef) {
+	pb.redactor.Exchange(&exchange)
+	pb.exchanges = append(pb.exchanges, pendingExchange{
+		exchange: exchange,
+		request:  request,
+		response: response,
+	})
+	if len(pb.exchanges) >= pb.batchSize {
+		pb.flushLogged()
+	}
+}
+
+func (pb *packetBatchImpl) AddWebSocketMessage(message entities.ServiceWebSocketMessage, handshake *PacketRef) {
+	pb.redactor.WebSocketMessage(&message)
+	pb.wsMessages = append(pb.wsMessages, pendingWebSocketMessage{
+		message:   message,
+		handshake: handshake,
+	})
+	if len(pb.wsMessages) >= pb.batchSize {
+		pb.flushLogged()
+	}
+}
+
+func (pb *packetBatchImpl) AddGraphqlOperations(operations []entities.ServiceGraphqlOperation, request *PacketRef) {
+	for i := range operations {
+		pb.redactor.GraphqlOperation(&operations[i])
+	}
+	pb.graphqlOps = append(pb.graphqlOps, pendingGraphqlOperations{
+		operations: operations,
+		request:    request,
+	})
+	if len(pb.graphqlOps) >= pb.batchSize {
+		pb.flushLogged()
+	}
+}
+
+func (pb *packetBatchImpl) AddKafkaEvents(events []entities.KafkaMessageEvent) {
+	for i := range events {
+		pb.redactor.KafkaEvent(&events[i])
+	}
+	pb.kafkaEvents = append(pb.kafkaEvents, events...)
+	if len(pb.kafkaEvents) >= pb.batchSize {
+		pb.flushLogged()
+	}
+}
+
+func (pb *packetBatchImpl) Stored() int {
+	return pb.stored
+}
+
+func (pb *packetBatchImpl) Duplicates() int {
+	return pb.duplicates
+}
+
+func (pb *packetBatchImpl) Dropped() int {
+	return pb.dropped
+}
+
+func (pb *packetBatchImpl) Err() error {
+	return pb.failure
+}
+
+// flushLogged
+// flushes the full batch, the first error is kept for Err
+func (pb *packetBatchImpl) flushLogged() {
+	err := pb.Flush()
+	if err != nil {
+		log.Errorf("unable to store packet batch for capture %s: %v", pb.captureId, err)
+		if pb.failure == nil {
+			pb.failure = err
+		}
+	}
+}
+
+// batchRows
+// rows stored by a single transaction
+type batchRows struct {
+	packets     []pendingPacket
+	exchanges   []pendingExchange
+	wsMessages  []pendingWebSocketMessage
+	kafkaEvents []entities.KafkaMessageEvent
+	graphqlOps  []pendingGraphqlOperations
+}
+
+// empty
+// checks nothing is queued
+func (br *batchRows) empty() bool {
+	return len(br.packets) == 0 && len(br.exchanges) == 0 && len(br.wsMessages) == 0 &&
+		len(br.kafkaEvents) == 0 && len(br.graphqlOps) == 0
+}
+
+// split
+// makes a single row batch of each row, packets go first as the other rows reference them
+func (br *batchRows) split() []batchRows {
+	rows := make([]batchRows, 0, len(br.packets)+len(br.exchanges)+len(br.wsMessages)+len(br.kafkaEvents)+len(br.graphqlOps))
+	for i := range br.packets {
+		rows = append(rows, batchRows{packets: br.packets[i : i+1]})
+	}
+	for i := range br.exchanges {
+		rows = append(rows, batchRows{exchanges: br.exchanges[i : i+1]})
+	}
+	for i := range br.wsMessages {
+		rows = append(rows, batchRows{wsMessages: br.wsMessages[i : i+1]})
+	}
+	for i := range br.graphqlOps {
+		rows = append(rows, batchRows{graphqlOps: br.graphqlOps[i : i+1]})
+	}
+	for i := range br.kafkaEvents {
+		rows = append(rows, batchRows{kafkaEvents: br.kafkaEvents[i : i+1]})
+	}
+	return rows
+}
+
+func (pb *packetBatchImpl) Flush() error {
+	rows := batchRows{
+		packets:     pb.packets,
+		exchanges:   pb.exchanges,
+		wsMessages:  pb.wsMessages,
+		kafkaEvents: pb.kafkaEvents,
+		graphqlOps:  pb.graphqlOps,
+	}
+	pb.packets = make([]pendingPacket, 0, pb.batchSize)
+	pb.exchanges = make([]pendingExchange, 0)
+	pb.wsMessages = make([]pendingWebSocketMessage, 0)
+	pb.kafkaEvents = make([]entities.KafkaMessageEvent, 0)
+	pb.graphqlOps = make([]pendingGraphqlOperations, 0)
+	if rows.empty() {
+		return nil
+	}
+	err := pb.storeRows(rows)
+	if err == nil {
+		return nil
+	}
+	if !rowError(err) {
+		pb.dropped += len(rows.packets)
+		return err
+	}
+	// a single invalid row fails the multi-row inserts, the rows are stored one by one to drop the invalid ones only
+	log.Warnf("unable to store packet batch for capture %s, storing the rows one by one: %v", pb.captureId, err)
+	return pb.storeEach(rows)
+}
+
+// storeEach
+// stores the rows one by one, a row rejected by DB as invalid is dropped alone.
+// Other errors stop storing and the rest of the rows is dropped
+func (pb *packetBatchImpl) storeEach(rows batchRows) error {
+	singles := rows.split()
+	for i, single := range singles {
+		err := pb.storeRows(single)
+		if err == nil {
+			continue
+		}
+		if !rowError(err) {
+			for _, rest := range singles[i:] {
+				pb.dropped += len(rest.packets)
+			}
+			return err
+		}
+		log.Warnf("row of capture %s is not stored: %v", pb.captureId, err)
+		pb.dropped += len(single.packets)
+	}
+	return nil
+}
+
+// rowError
+// checks the error is caused by the row data: SQLSTATE class 22 (data exception) or 23 (integrity constraint violation)
+func rowError(err error) bool {
+	var pgErr pg.Error
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	class := pgErr.Field('C')
+	return strings.HasPrefix(class, "22") || strings.HasPrefix(class, "23")
+}
+
+// storeRows
+// stores the rows by a single transaction, the counters are updated when stored
+func (pb *packetBatchImpl) storeRows(rows batchRows) error {
+	duplicates, err := pb.insert(rows)
+	if err != nil {
+		// ids given inside the rolled back transaction were never committed,
+		// rows queued later for these packets are skipped
+		for _, pending := range rows.packets {
+			pending.ref.PacketId = 0
+		}
+		return err
+	}
+	pb.partitioned = true
+	pb.stored += len(rows.packets)
+	pb.duplicates += duplicates
+	return nil
+}
+
+// store
+// inserts the rows in a transaction, returns number of the packets stored before
+func (pb *packetBatchImpl) store(rows batchRows) (int, error) {
+	headers := make([]entities.HttpHeaderItem, 0)
+	for _, pending := range rows.packets {
+		headers = append(headers, pending.headers...)
+	}
+	duplicates := 0
+	err := pb.db.GetConnection().RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		// packets of the capture are deduplicated by one writer at a time
+		_, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", pb.captureId)
+		if err != nil {
+			return fmt.Errorf("unable to lock capture packets: %w", err)
+		}
+		if !pb.partitioned {
+			err = createPacketPartition(tx, pb.captureId)
+			if err != nil {
+				return err
+			}
+		}
+		known, err := storeHeaders(tx, headers)
+		if err != nil {
+			return err
+		}
+		duplicates, err = pb.storePackets(tx, rows.packets)
+		if err != nil {
+			return err
+		}
+		err = pb.storePacketHeaders(tx, rows.packets, known)
+		if err != nil {
+			return err
+		}
+		err = pb.storeQueryParams(tx, rows.packets)
+		if err != nil {
+			return err
+		}
+		err = pb.storeExchanges(tx, rows.exchanges)
+		if err != nil {
+			return err
+		}
+		err = pb.storeWebSocketMessages(tx, rows.wsMessages)
+		if err != nil {
+			return err
+		}
+		err = pb.storeGraphqlOperations(tx, rows.graphqlOps)
+		if err != nil {
+			return err
+		}
+		return pb.storeKafkaEvents(tx, rows.kafkaEvents)
+	})
+	return duplicates, err
+}
+
+// packetKey
+// packet identity used to suppress duplicates, time stamp is compared with the database precision
+func packetKey(p *entities.ServicePacket) string {
+	return fmt.Sprintf("%d:%d:%d:%d:%d:%d:%d", p.SourceId, p.SourcePort, p.DestId, p.DestPort, p.SeqNo, p.AckNo, p.TimeStamp.UnixMicro())
+}
+
+// storePackets
+// inserts the packets not stored yet, references get ids of the inserted or already stored packets.
+// Returns number of the packets stored before or queued twice
+func (pb *packetBatchImpl) storePackets(tx *pg.Tx, packets []pendingPacket) (int, error) {
+	if len(packets) == 0 {
+		return 0, nil
+	}
+	ids := make(map[string]int, len(packets))
+	keys := make([]interface{}, 0, len(packets))
+	for i := range packets {
+		p := &packets[i].packet
+		key := packetKey(p)
+		if _, found := ids[key]; found {
+			continue
+		}
+		ids[key] = 0
+		keys = append(keys, []interface{}{p.SourceId, p.SourcePort, p.DestId, p.DestPort, p.SeqNo, p.AckNo, p.TimeStamp})
+	}
+	existing := make([]entities.ServicePacket, 0)
+	err := tx.Model(&existing).
+		Column("packet_id", "source_id", "source_port", "dest_id", "dest_port", "seq_no", "ack_no", "time_stamp").
+		Where("capture_id=?", pb.captureId).
+		Where("(source_id, source_port, dest_id, dest_port, seq_no, ack_no, time_stamp) in (?)", pg.InMulti(keys...)).
+		Select()
+	if err != nil {
+		return 0, fmt.Errorf("unable to get packets: %w", err)
+	}
+	for i := range existing {
+		ids[packetKey(&existing[i])] = existing[i].PacketId
+	}
+	inserted := make([]entities.ServicePacket, 0, len(keys))
+	for i := range packets {
+		key := packetKey(&packets[i].packet)
+		if ids[key] == 0 {
+			inserted = append(inserted, packets[i].packet)
+			ids[key] = -1 // queued for insert
+		}
+	}
+	if len(inserted) > 0 {
+		_, err = tx.Model(&inserted).Returning("packet_id").Insert()
+		if err != nil {
+			return 0, fmt.Errorf("unable to insert packets: %w", err)
+		}
+		for i := range inserted {
+			ids[packetKey(&inserted[i])] = inserted[i].PacketId
+		}
+	}
+	for i := range packets {
+		packets[i].ref.PacketId = ids[packetKey(&packets[i].packet)]
+	}
+	return len(packets) - len(inserted), nil
+}
+
+// storeHeaders
+// inserts the headers missing in DB, returns ids of the stored headers. Headers are stored and linked under
+// the shared headers lock, the orphaned headers are purged under the exclusive one
+func storeHeaders(tx *pg.Tx, headers []entities.HttpHeaderItem) (map[string]bool, error) {
+	known := make(map[string]bool, len(headers))
+	distinct := make([]entities.HttpHeaderItem, 0, len(headers))
+	for _, h := range headers {
+		if !known[h.Id] {
+			known[h.Id] = true
+			distinct = append(distinct, h)
+		}
+	}
+	if len(distinct) == 0 {
+		return known, nil
+	}
+	// concurrent writers insert overlapping headers in the same order to avoid deadlocks on the unique index
+	sort.Slice(distinct, func(i, j int) bool {
+		return distinct[i].Id < distinct[j].Id
+	})
+	_, err := tx.Exec("SELECT pg_advisory_xact_lock_shared(hashtext(?))", httpHeadersLock)
+	if err != nil {
+		return nil, fmt.Errorf("unable to lock HTTP headers: %w", err)
+	}
+	_, err = tx.Model(&distinct).OnConflict("DO NOTHING").Insert()
+	if err != nil {
+		return nil, fmt.Errorf("unable to insert %d headers: %w", len(distinct), err)
+	}
+	return known, nil
+}
+
+// storePacketHeaders
+// links the stored packets with their known headers
+func (pb *packetBatchImpl) storePacketHeaders(tx *pg.Tx, packets []pendingPacket, known map[string]bool) error {
+	links := make([]entities.PacketHeader, 0)
+	linked := make(map[entities.PacketHeader]bool)
+	for _, pending := range packets {
+		for _, header := range pending.headers {
+			link := entities.PacketHeader{HeaderId: header.Id, PacketId: pending.ref.PacketId, CaptureId: pb.captureId}
+			if !known[header.Id] || linked[link] {
+				continue
+			}
+			linked[link] = true
+			links = append(links, link)
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&links).OnConflict("DO NOTHING").Insert()
+	if err != nil {
+		return fmt.Errorf("unable to insert packet headers: %w", err)
+	}
+	return nil
+}
+
+// storeQueryParams
+// inserts query parameters of the stored requests, parameters of already stored requests are skipped
+func (pb *packetBatchImpl) storeQueryParams(tx *pg.Tx, packets []pendingPacket) error {
+	rows := make([]entities.ServiceQueryParam, 0)
+	for _, pending := range packets {
+		if pending.ref.PacketId == 0 {
+			continue // request not stored
+		}
+		for _, param := range pending.queryParams {
+			param.PacketId = pending.ref.PacketId
+			rows = append(rows, param)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&rows).OnConflict("(packet_id, param_index) DO NOTHING").Insert()
+	if err != nil {
+		return fmt.Errorf("unable to insert query parameters: %w", err)
+	}
+	return nil
+}
+
+// storeExchanges
+// inserts exchanges of the stored requests, the exchange is stored once per request
+func (pb *packetBatchImpl) storeExchanges(tx *pg.Tx, exchanges []pendingExchange) error {
+	rows := make([]entities.ServiceExchange, 0, len(exchanges))
+	requests := make(map[int]bool, len(exchanges))
+	for _, pending := range exchanges {
+		if pending.request == nil || pending.request.PacketId == 0 || requests[pending.request.PacketId] {
+			continue // request not stored
+		}
+		requests[pending.request.PacketId] = true
+		exchange := pending.exchange
+		exchange.RequestPacketId = pending.request.PacketId
+		if pending.response != nil {
+			exchange.ResponsePacketId = pending.response.PacketId
+		}
+		rows = append(rows, exchange)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&rows).OnConflict("(request_packet_id) DO NOTHING").Insert()
+	if err != nil {
+		return fmt.Errorf("unable to insert exchanges: %w", err)
+	}
+	return nil
+}
+
+// storeWebSocketMessages
+// inserts messages of the stored handshakes, already stored messages are skipped
+func (pb *packetBatchImpl) storeWebSocketMessages(tx *pg.Tx, messages []pendingWebSocketMessage) error {
+	rows := make([]entities.ServiceWebSocketMessage, 0, len(messages))
+	for _, pending := range messages {
+		if pending.handshake == nil || pending.handshake.PacketId == 0 {
+			continue // handshake not stored
+		}
+		message := pending.message
+		message.HandshakePacketId = pending.handshake.PacketId
+		rows = append(rows, message)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&rows).OnConflict("(handshake_packet_id, from_client, seq_no) DO NOTHING").Insert()
+	if err != nil {
+		return fmt.Errorf("unable to insert WebSocket messages: %w", err)
+	}
+	return nil
+}
+
+// storeKafkaEvents
+// inserts Kafka records, already stored records are skipped
+func (pb *packetBatchImpl) storeKafkaEvents(tx *pg.Tx, events []entities.KafkaMessageEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&events).OnConflict("DO NOTHING").Insert()
+	if err != nil {
+		return fmt.Errorf("unable to insert Kafka events: %w", err)
+	}
+	return nil
+}
+
+// storeGraphqlOperations
+// inserts GraphQL operations of the stored requests, already stored operations are skipped
+func (pb *packetBatchImpl) storeGraphqlOperations(tx *pg.Tx, pending []pendingGraphqlOperations) error {
+	rows := make([]entities.ServiceGraphqlOperation, 0, len(pending))
+	for _, request := range pending {
+		if request.request == nil || request.request.PacketId == 0 {
+			continue // request not stored
+		}
+		for _, operation := range request.operations {
+			operation.PacketId = request.request.PacketId
+			rows = append(rows, operation)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	_, err := tx.Model(&rows).OnConflict("(packet_id, operation_index, root_field) DO NOTHING").Insert()
+	if err != nil {
+		return fmt.Errorf("unable to insert GraphQL operations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/redaction"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+)
+
+// pgError
+// a DB error with SQLSTATE code
+type pgError struct {
+	code string
+}
+
+func (e pgError) Error() string {
+	return "ERROR #" + e.code
+}
+
+func (e pgError) Field(field byte) string {
+	if field == 'C' {
+		return e.code
+	}
+	return ""
+}
+
+func (e pgError) IntegrityViolation() bool {
+	return strings.HasPrefix(e.code, "23")
+}
+
+// fakeStore
+// stores the rows in memory, a transaction with a packet body "bad" fails with the error
+type fakeStore struct {
+	err     error
+	nextId  int
+	stored  []string
+	inserts int
+}
+
+func (fs *fakeStore) insert(rows batchRows) (int, error) {
+	fs.inserts++
+	for _, pending := range rows.packets {
+		if pending.packet.Body == "bad" {
+			return 0, fmt.Errorf("unable to insert packets: %w", fs.err)
+		}
+	}
+	for _, pending := range rows.packets {
+		fs.nextId++
+		pending.ref.PacketId = fs.nextId
+		fs.stored = append(fs.stored, pending.packet.Body)
+	}
+	for _, pending := range rows.exchanges {
+		if pending.request.PacketId != 0 {
+			fs.stored = append(fs.stored, "exchange:"+pending.exchange.RequestPath)
+		}
+	}
+	return 0, nil
+}
+
+func newTestBatch(t *testing.T, store *fakeStore) *packetBatchImpl {
+	redactor, err := redaction.NewRedactor(view.RedactionRules{}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &packetBatchImpl{
+		captureId: "capture",
+		batchSize: 100,
+		redactor:  redactor,
+		insert:    store.insert,
+	}
+}
+
+func testPacket(body string, seqNo int) entities.ParsedPacket {
+	return entities.ParsedPacket{
+		Peers:      []entities.ServiceAddress{{Id: 1}, {Id: 2}},
+		Ports:      []int{40000, 8080},
+		Timestamp:  time.Unix(0, 0),
+		SeqNo:      seqNo,
+		StrPayload: body,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+	}
+}
+
+func TestPacketBatchFlush(t *testing.T) {
+	tests := []struct {
+		name       string
+		bodies     []string
+		err        error
+		wantErr    bool
+		wantStored []string
+		wantCount  int
+		wantDrops  int
+	}{
+		{
+			name:       "all rows stored by one transaction",
+			bodies:     []string{"a", "b", "c"},
+			wantStored: []string{"a", "b", "c", "exchange:/a", "exchange:/b", "exchange:/c"},
+			wantCount:  3,
+		},
+		{
+			name:       "invalid row is dropped alone",
+			bodies:     []string{"a", "bad", "c"},
+			err:        pgError{code: "22021"},
+			wantStored: []string{"a", "c", "exchange:/a", "exchange:/c"},
+			wantCount:  2,
+			wantDrops:  1,
+		},
+		{
+			name:       "constraint violation is dropped alone",
+			bodies:     []string{"bad", "b"},
+			err:        pgError{code: "23503"},
+			wantStored: []string{"b", "exchange:/b"},
+			wantCount:  1,
+			wantDrops:  1,
+		},
+		{
+			name:      "connection error drops the rest of the rows",
+			bodies:    []string{"a", "bad", "c"},
+			err:       errors.New("connection reset"),
+			wantErr:   true,
+			wantCount: 0,
+			wantDrops: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{err: tt.err}
+			batch := newTestBatch(t, store)
+			for i, body := range tt.bodies {
+				ref := batch.AddPacket(testPacket(body, i))
+				batch.AddExchange(entities.ServiceExchange{RequestPath: "/" + body}, ref, nil)
+			}
+			err := batch.Flush()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Flush() error = %v, want error %v", err, tt.wantErr)
+			}
+			if batch.Stored() != tt.wantCount || batch.Dropped() != tt.wantDrops {
+				t.Errorf("stored %d, dropped %d, want %d and %d", batch.Stored(), batch.Dropped(), tt.wantCount, tt.wantDrops)
+			}
+			if strings.Join(store.stored, ",") != strings.Join(tt.wantStored, ",") {
+				t.Errorf("stored rows %v, want %v", store.stored, tt.wantStored)
+			}
+		})
+	}
+}
+
+func TestPacketBatchCleansText(t *testing.T) {
+	batch := newTestBatch(t, &fakeStore{})
+	packet := testPacket("a\x00b\xffc", 0)
+	packet.RequestPath = "/p\x00ath"
+	packet.Headers["X-Binary"] = "v\x00\xfe"
+	batch.AddPacket(packet)
+	stored := batch.packets[0]
+	if stored.packet.Body != "ab�c" {
+		t.Errorf("body %q is not cleaned", stored.packet.Body)
+	}
+	if stored.packet.RequestPath != "/path" {
+		t.Errorf("path %q is not cleaned", stored.packet.RequestPath)
+	}
+	for _, header := range stored.headers {
+		if header.Key == "X-Binary" && header.Value != "v�" {
+			t.Errorf("header value %q is not cleaned", header.Value)
+		}
+	}
+}
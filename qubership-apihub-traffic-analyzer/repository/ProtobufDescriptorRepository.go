@@ -0,0 +1,65 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository
+
+import (
+	"fmt"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/decoders"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	log "github.com/sirupsen/logrus"
+)
+
+// StoreProtobufDescriptor
+// inserts or replaces the descriptor set with the same name
+func StoreProtobufDescriptor(db db.ConnectionProvider, descriptor entities.ProtobufDescriptor) error {
+	_, err := db.GetConnection().Model(&descriptor).
+		OnConflict("(descriptor_name) DO UPDATE").
+		Set("service_name=EXCLUDED.service_name, descriptor_set=EXCLUDED.descriptor_set, created_at=now()").
+		Insert()
+	if err != nil {
+		return fmt.Errorf("unable to store protobuf descriptor set '%s': %v", descriptor.Name, err)
+	}
+	return nil
+}
+
+// DeleteProtobufDescriptor
+// deletes the descriptor set by name, returns false when nothing was deleted
+func DeleteProtobufDescriptor(db db.ConnectionProvider, name string) (bool, error) {
+	result, err := db.GetConnection().Model((*entities.ProtobufDescriptor)(nil)).Where("descriptor_name=?", name).Delete()
+	if err != nil {
+		return false, fmt.Errorf("unable to delete protobuf descriptor set '%s': %v", name, err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// LoadProtobufRegistry
+// makes a registry of all the stored descriptor sets, broken sets are skipped
+func LoadProtobufRegistry(db db.ConnectionProvider) (*decoders.ProtobufRegistry, error) {
+	registry := decoders.NewProtobufRegistry()
+	var descriptors []entities.ProtobufDescriptor
+	err := db.GetConnection().Model(&descriptors).Order("created_at").Select()
+	if err != nil {
+		return registry, fmt.Errorf("unable to read protobuf descriptor sets: %v", err)
+	}
+	for _, descriptor := range descriptors {
+		err = registry.Add(descriptor.Name, descriptor.ServiceName, descriptor.DescriptorSet)
+		if err != nil {
+			log.Warnf("protobuf descriptor set '%s' skipped: %v", descriptor.Name, err)
+		}
+	}
+	return registry, nil
+}
@@ -15,11 +15,13 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/utils"
+	"github.com/go-pg/pg/v10"
 )
 
 func SetReportParameters(report *entities.ReportEntity, reportParameters interface{}) error {
@@ -77,3 +79,15 @@ func GetReport(db db.ConnectionProvider, reportUuid, reportTypeName string) (*en
 	}
 	return result, nil, fmt.Errorf("unable to query report parameters: %v", err)
 }
+
+// GetReportQueryParameters
+// returns query parameter coverage rows of the report ordered by operation
+func GetReportQueryParameters(db db.ConnectionProvider, reportId int) ([]entities.ReportQueryParameter, error) {
+	rows := make([]entities.ReportQueryParameter, 0)
+	err := db.GetConnection().Model(&rows).Where("report_id=?", reportId).
+		Order("operation_path", "operation_method", "documented DESC", "param_name").Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return nil, err
+	}
+	return rows, nil
+}
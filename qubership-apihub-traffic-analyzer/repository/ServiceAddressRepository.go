@@ -15,8 +15,8 @@
 package repository
 
 import (
-	"errors"
-	"fmt"
+	"sync"
+	"time"
 
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
@@ -27,107 +27,251 @@ import (
 )
 
 type ServiceAddressRepository interface {
-	GetServiceAddress(address, name, version, captureId string) (entities.ServiceAddress, error)
-	GetServiceAddressByIp(address string) (*entities.ServiceAddress, error)
+	GetServiceAddress(address, name, version, captureId string, validFrom, validTo *time.Time) (entities.ServiceAddress, error)
+	GetServiceAddressAt(address, captureId string, timestamp time.Time) (*entities.ServiceAddress, error)
+	NameServiceAddress(address, name, captureId string) (entities.ServiceAddress, error)
+	InferServiceAddressName(address, name, source, captureId string) error
 	Close()
 }
+
+// addressKey
+// identifies entries of a captured address
+type addressKey struct {
+	address   string
+	captureId string
+}
+
+// addressWindowConflict
+// conflict target of the unique index of address validity windows
+const addressWindowConflict = "(capture_id, ip_address, coalesce(valid_from, '-infinity'::timestamptz), " +
+	"coalesce(valid_to, 'infinity'::timestamptz))"
+
 type serviceAddressRepository struct {
 	//instance libcache.Cache
-	db      db.ConnectionProvider
-	addrMap map[string]entities.ServiceAddress
+	db db.ConnectionProvider
+	// lock guards the cached entries, capture files are read concurrently. DB is accessed without the lock,
+	// concurrent inserts of an entry are resolved by the unique index of address validity windows
+	lock sync.Mutex
+	// addrMap all the validity window entries of the address
+	addrMap map[addressKey][]entities.ServiceAddress
 }
 
 func NewPeersCache(db db.ConnectionProvider) ServiceAddressRepository {
 	//nc := serviceAddressRepository{instance: libcache.LRU.New(MinCacheSize), db: db}
 	//nc.instance.SetTTL(CachedRecAge)
 	//return &nc
-	return &serviceAddressRepository{db: db, addrMap: make(map[string]entities.ServiceAddress)}
+	return &serviceAddressRepository{db: db, lock: sync.Mutex{}, addrMap: make(map[addressKey][]entities.ServiceAddress)}
+}
+
+// GetServiceAddressAt
+// returns the address entry valid at the given time, an unnamed entry is created for address unknown at this time
+func (sar *serviceAddressRepository) GetServiceAddressAt(address, captureId string, timestamp time.Time) (*entities.ServiceAddress, error) {
+	entries, err := sar.loadEntries(address, captureId)
+	if err != nil {
+		return nil, err
+	}
+	serviceAddr := entities.ResolveServiceAddress(entries, timestamp)
+	if serviceAddr != nil {
+		result := *serviceAddr
+		return &result, nil
+	}
+	// the address is unknown at this time, names of other windows are not borrowed
+	result := entities.ServiceAddress{
+		Address:   address,
+		CaptureId: captureId,
+	}
+	log.Debugf("insertServiceAddress - Address=%s, captureId: %s", address, captureId)
+	err = sar.insertServiceAddress(&result, "ip_address = EXCLUDED.ip_address")
+	if err != nil {
+		return nil, err
+	}
+	sar.addCacheValue(result)
+	return &result, nil
 }
 
-func (sar *serviceAddressRepository) GetServiceAddressByIp(address string) (*entities.ServiceAddress, error) {
+// loadEntries
+// returns copy of all the entries of the address, the entries are cached on the first access
+func (sar *serviceAddressRepository) loadEntries(address, captureId string) ([]entities.ServiceAddress, error) {
+	key := addressKey{address: address, captureId: captureId}
 	//serviceAddr, exists := sar.instance.Load(address)
-	serviceAddr, exists := sar.addrMap[address]
-	if exists {
-		//if val, converted := serviceAddr.(entities.ServiceAddress); converted {
-		//	return &val, nil
-		//} else {
-		//	return nil, errors.New("unable to cast cache value for address: " + address)
-		//}
-		return &serviceAddr, nil
-	}
-	return nil, fmt.Errorf("service address not found for address %s", address)
+	if entries, exists := sar.cachedEntries(key); exists {
+		return entries, nil
+	}
+	entries := make([]entities.ServiceAddress, 0)
+	err := sar.db.GetConnection().Model(&entries).Where("ip_address=? and capture_id=?", address, captureId).Order("address_id").Select()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return entries, nil
+	}
+	sar.lock.Lock()
+	defer sar.lock.Unlock()
+	if cached, exists := sar.addrMap[key]; exists {
+		// loaded meanwhile, the cached entries may have been updated
+		return append([]entities.ServiceAddress(nil), cached...), nil
+	}
+	sar.addrMap[key] = append([]entities.ServiceAddress(nil), entries...)
+	return entries, nil
+}
+
+// cachedEntries
+// returns copy of the cached entries of the address
+func (sar *serviceAddressRepository) cachedEntries(key addressKey) ([]entities.ServiceAddress, bool) {
+	sar.lock.Lock()
+	defer sar.lock.Unlock()
+	entries, exists := sar.addrMap[key]
+	if !exists {
+		return nil, false
+	}
+	return append([]entities.ServiceAddress(nil), entries...), true
 }
 
+// addCacheValue
+// adds or replaces the cached entry
 func (sar *serviceAddressRepository) addCacheValue(serviceAddr entities.ServiceAddress) {
 	//if _, exists := sar.instance.Load(serviceAddr.Address); exists {
 	//	sar.instance.Delete(serviceAddr.Address)
 	//}
 	//sar.instance.Store(serviceAddr.Address, serviceAddr)
-	sar.addrMap[serviceAddr.Address] = serviceAddr
+	sar.lock.Lock()
+	defer sar.lock.Unlock()
+	key := addressKey{address: serviceAddr.Address, captureId: serviceAddr.CaptureId}
+	entries := sar.addrMap[key]
+	for i := range entries {
+		if entries[i].Id == serviceAddr.Id {
+			entries[i] = serviceAddr
+			return
+		}
+	}
+	sar.addrMap[key] = append(entries, serviceAddr)
 }
 
 func (sar *serviceAddressRepository) Close() {
+	sar.lock.Lock()
+	defer sar.lock.Unlock()
 	//sar.instance.Purge()
-	sar.addrMap = make(map[string]entities.ServiceAddress)
+	sar.addrMap = make(map[addressKey][]entities.ServiceAddress)
 }
 
-func (sar *serviceAddressRepository) GetServiceAddress(address, name, version, captureId string) (entities.ServiceAddress, error) {
+// GetServiceAddress
+// returns the address entry of the validity window (nil bounds for the whole capture),
+// the entry is created or its service name and version are updated
+func (sar *serviceAddressRepository) GetServiceAddress(address, name, version, captureId string, validFrom, validTo *time.Time) (entities.ServiceAddress, error) {
 	result := entities.ServiceAddress{
 		Address:   address,
 		Name:      name,
 		Version:   version,
 		CaptureId: captureId,
+		ValidFrom: validFrom,
+		ValidTo:   validTo,
 	}
-	var (
-		err error
-	)
-	if name == view.EmptyString {
-		err = sar.db.GetConnection().Model(&result).Where("ip_address=? and capture_id=?", address, captureId).First()
-	} else {
-		err = sar.db.GetConnection().Model(&result).Where("ip_address=? and service_name=? and capture_id=?", address, name, captureId).First()
+	log.Debugf("insertServiceAddress - Address=%s, Name=%s, Version=%s, captureId: %s", address, name, version, captureId)
+	err := sar.insertServiceAddress(&result, `service_name = coalesce(nullif(EXCLUDED.service_name, ''), service_addresses.service_name),
+		service_version = coalesce(nullif(EXCLUDED.service_version, ''), service_addresses.service_version)`)
+	if err != nil {
+		log.Debugf("upsert service address: %s", err.Error())
+		return result, err
 	}
+	sar.addCacheValue(result)
+	return result, nil
+}
+
+// NameServiceAddress
+// sets the name for the address entries unless the entry already has a name (from the address list)
+func (sar *serviceAddressRepository) NameServiceAddress(address, name, captureId string) (entities.ServiceAddress, error) {
+	entries, err := sar.loadEntries(address, captureId)
 	if err != nil {
-		if errors.Is(err, pg.ErrNoRows) {
-			result = entities.ServiceAddress{
-				Address:   address,
-				Name:      name,
-				Version:   version,
-				CaptureId: captureId,
-			}
-			log.Debugf("insertServiceAddress - Address=%s, Name=%s, Version=%s, captureId: %s", address, name, version, captureId)
-			err = sar.insertServiceAddress(&result)
-		} else {
-			log.Debugf("select service address Id: %s", err.Error())
+		return entities.ServiceAddress{Address: address, Name: name, CaptureId: captureId}, err
+	}
+	if len(entries) == 0 {
+		result := entities.ServiceAddress{
+			Address:   address,
+			Name:      name,
+			CaptureId: captureId,
 		}
+		log.Debugf("insertServiceAddress - Address=%s, Name=%s, captureId: %s", address, name, captureId)
+		err = sar.insertServiceAddress(&result, `service_name = coalesce(nullif(service_addresses.service_name, ''), EXCLUDED.service_name)`)
 		if err == nil {
 			sar.addCacheValue(result)
 		}
-	} else {
-		changed := false
-		if name != view.EmptyString && result.Name != name {
-			result.Name = name
-			changed = true
-		}
-		if version != view.EmptyString && result.Version != version {
-			result.Version = version
-			changed = true
+		return result, err
+	}
+	for i := range entries {
+		if entries[i].Name != view.EmptyString {
+			continue
 		}
-		if changed {
-			_, err := sar.db.GetConnection().Model(&result).Update()
-			if err == nil {
-				sar.addCacheValue(result)
-			}
+		entries[i].Name = name
+		// the entry named meanwhile keeps its name
+		_, err = sar.db.GetConnection().Model(&entries[i]).Column("service_name").WherePK().
+			Where("coalesce(service_name, '') = ''").Update()
+		if err != nil {
+			return entries[i], err
 		}
+		sar.addCacheValue(entries[i])
 	}
-	return result, err
+	return entries[0], nil
 }
 
-func (sar *serviceAddressRepository) insertServiceAddress(svcAddress *entities.ServiceAddress) error {
-	result := new(entities.ServiceAddress)
-	_, err := sar.db.GetConnection().Model(svcAddress).Returning("address_id").Insert(result)
-	if err == nil {
-		svcAddress.Id = result.Id
+// InferServiceAddressName
+// sets the name seen in the capture for the address entries, the name of a higher priority source replaces
+// the previous one, for the same source the first name is kept
+func (sar *serviceAddressRepository) InferServiceAddressName(address, name, source, captureId string) error {
+	entries, err := sar.loadEntries(address, captureId)
+	if err != nil {
+		return err
 	}
-	return err
+	if len(entries) == 0 {
+		result := entities.ServiceAddress{
+			Address:        address,
+			CaptureId:      captureId,
+			InferredName:   name,
+			InferredSource: source,
+		}
+		log.Debugf("insertServiceAddress - Address=%s, InferredName=%s (%s), captureId: %s", address, name, source, captureId)
+		err = sar.insertServiceAddress(&result, "ip_address = EXCLUDED.ip_address")
+		if err != nil {
+			return err
+		}
+		sar.addCacheValue(result)
+		if result.InferredSource == source {
+			return nil
+		}
+		// the entry was inserted meanwhile
+		entries = []entities.ServiceAddress{result}
+	}
+	priority := view.PeerNameSourcePriority(source)
+	// the name of the same or a higher priority source set meanwhile is kept
+	lowerSources := make([]string, 0)
+	for _, known := range []string{view.EmptyString, view.PeerNameSourceHost, view.PeerNameSourceSni, view.PeerNameSourceDns} {
+		if view.PeerNameSourcePriority(known) < priority {
+			lowerSources = append(lowerSources, known)
+		}
+	}
+	for i := range entries {
+		if view.PeerNameSourcePriority(entries[i].InferredSource) >= priority {
+			continue
+		}
+		entries[i].InferredName = name
+		entries[i].InferredSource = source
+		_, err = sar.db.GetConnection().Model(&entries[i]).Column("inferred_name", "inferred_source").WherePK().
+			Where("coalesce(inferred_source, '') in (?)", pg.In(lowerSources)).Update()
+		if err != nil {
+			return err
+		}
+		sar.addCacheValue(entries[i])
+	}
+	return nil
+}
 
+// insertServiceAddress
+// inserts the entry of the address validity window, the entry stored before is updated by the set clause instead.
+// The stored entry is returned into the argument
+func (sar *serviceAddressRepository) insertServiceAddress(svcAddress *entities.ServiceAddress, set string) error {
+	_, err := sar.db.GetConnection().Model(svcAddress).
+		OnConflict(addressWindowConflict + " DO UPDATE").
+		Set(set).
+		Returning("*").
+		Insert()
+	return err
 }
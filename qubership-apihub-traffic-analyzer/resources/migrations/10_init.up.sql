@@ -0,0 +1,39 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- service_graphql_operations GraphQL operations executed by the stored requests, a row per root field
+CREATE TABLE if not exists service_graphql_operations (
+    operation_id bigserial NOT NULL,
+    capture_id varchar NOT NULL,
+    packet_id int8 NOT NULL,
+    operation_index int4 NOT NULL,
+    operation_type varchar NOT NULL,
+    operation_name varchar NULL,
+    root_field varchar NOT NULL,
+    variables json NULL,
+    CONSTRAINT service_graphql_operations_pk PRIMARY KEY (operation_id),
+    CONSTRAINT service_graphql_operations_uk UNIQUE (packet_id, operation_index, root_field),
+    CONSTRAINT service_graphql_operations_packet_fk FOREIGN KEY (packet_id) REFERENCES service_packets(packet_id) ON DELETE CASCADE
+);
+-- service_graphql_operations indexes
+CREATE INDEX if not exists service_graphql_operations_capture_id_idx ON service_graphql_operations USING btree (capture_id);
+-- service_graphql_operations column comments
+COMMENT ON COLUMN service_graphql_operations.operation_id IS 'primary key';
+COMMENT ON COLUMN service_graphql_operations.capture_id IS 'capture identifier';
+COMMENT ON COLUMN service_graphql_operations.packet_id IS 'reference to the request in service packets';
+COMMENT ON COLUMN service_graphql_operations.operation_index IS 'operation position in the request, batched requests carry several operations';
+COMMENT ON COLUMN service_graphql_operations.operation_type IS 'query, mutation or subscription';
+COMMENT ON COLUMN service_graphql_operations.operation_name IS 'operation name, null for anonymous operation';
+COMMENT ON COLUMN service_graphql_operations.root_field IS 'root selection set field';
+COMMENT ON COLUMN service_graphql_operations.variables IS 'operation variables';
@@ -0,0 +1,62 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- service_packets raw query string, the request path holds the path only
+alter table service_packets add column if not exists request_query varchar NULL;
+COMMENT ON COLUMN service_packets.request_query IS 'raw request query string';
+
+-- service_query_params query parameters of the stored requests in the request order
+CREATE TABLE if not exists service_query_params (
+    param_id bigserial NOT NULL,
+    capture_id varchar NOT NULL,
+    packet_id int8 NOT NULL,
+    param_index int4 NOT NULL,
+    param_name varchar NOT NULL,
+    param_value varchar NULL,
+    CONSTRAINT service_query_params_pk PRIMARY KEY (param_id),
+    CONSTRAINT service_query_params_uk UNIQUE (packet_id, param_index),
+    CONSTRAINT service_query_params_packet_fk FOREIGN KEY (packet_id) REFERENCES service_packets(packet_id) ON DELETE CASCADE
+);
+-- service_query_params indexes
+CREATE INDEX if not exists service_query_params_capture_id_idx ON service_query_params USING btree (capture_id, param_name);
+-- service_query_params column comments
+COMMENT ON COLUMN service_query_params.param_id IS 'primary key';
+COMMENT ON COLUMN service_query_params.capture_id IS 'capture identifier';
+COMMENT ON COLUMN service_query_params.packet_id IS 'reference to the request in service packets';
+COMMENT ON COLUMN service_query_params.param_index IS 'parameter position in the query string';
+COMMENT ON COLUMN service_query_params.param_name IS 'decoded parameter name';
+COMMENT ON COLUMN service_query_params.param_value IS 'decoded parameter value';
+
+-- report_query_parameters query parameter coverage of the report REST operations
+CREATE TABLE if not exists report_query_parameters (
+    report_id int8 NOT NULL,
+    operation_title varchar NULL,
+    operation_path varchar NOT NULL,
+    operation_method varchar NOT NULL,
+    param_name varchar NOT NULL,
+    documented bool NOT NULL,
+    required bool DEFAULT false NOT NULL,
+    hit_count int8 DEFAULT 0 NOT NULL,
+    CONSTRAINT report_query_parameters_pk PRIMARY KEY (report_id, operation_path, operation_method, param_name),
+    CONSTRAINT report_query_parameters_stored_reports_fk FOREIGN KEY (report_id) REFERENCES stored_reports(report_id) ON DELETE CASCADE
+);
+-- report_query_parameters column comments
+COMMENT ON COLUMN report_query_parameters.report_id IS 'reference to report';
+COMMENT ON COLUMN report_query_parameters.operation_title IS 'operation''s title';
+COMMENT ON COLUMN report_query_parameters.operation_path IS 'operation''s request path';
+COMMENT ON COLUMN report_query_parameters.operation_method IS 'operation''s method (GET/POST/...)';
+COMMENT ON COLUMN report_query_parameters.param_name IS 'query parameter name';
+COMMENT ON COLUMN report_query_parameters.documented IS 'true when the parameter is listed in the operation, false when it was only sent';
+COMMENT ON COLUMN report_query_parameters.required IS 'true when the documented parameter is required';
+COMMENT ON COLUMN report_query_parameters.hit_count IS 'how many requests of the operation sent the parameter';
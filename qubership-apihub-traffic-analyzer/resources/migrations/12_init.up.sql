@@ -0,0 +1,21 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- service_packets tunnel metadata, source and destination are the innermost (pod) addresses
+alter table service_packets add column if not exists outer_source_ip varchar NULL;
+alter table service_packets add column if not exists outer_dest_ip varchar NULL;
+alter table service_packets add column if not exists encapsulation varchar NULL;
+COMMENT ON COLUMN service_packets.outer_source_ip IS 'source address of the outermost tunnel header, null for not tunneled packet';
+COMMENT ON COLUMN service_packets.outer_dest_ip IS 'destination address of the outermost tunnel header, null for not tunneled packet';
+COMMENT ON COLUMN service_packets.encapsulation IS 'removed VLAN and tunnel layers from the outermost one (vlan:100/vxlan:42/...)';
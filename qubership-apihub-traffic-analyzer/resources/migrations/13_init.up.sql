@@ -0,0 +1,20 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- service_addresses validity windows, an address may belong to different services during the capture
+alter table service_addresses add column if not exists valid_from timestamptz NULL;
+alter table service_addresses add column if not exists valid_to timestamptz NULL;
+CREATE INDEX if not exists service_addresses_validity_idx ON service_addresses USING btree (capture_id, ip_address, valid_from);
+COMMENT ON COLUMN service_addresses.valid_from IS 'start of the address validity window (address list snapshot time), null when valid from the capture start';
+COMMENT ON COLUMN service_addresses.valid_to IS 'end of the address validity window (exclusive), null when valid until the next snapshot or the capture end';
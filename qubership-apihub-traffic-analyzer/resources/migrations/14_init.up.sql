@@ -0,0 +1,19 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- service_addresses names inferred from the capture for addresses not named by the address list
+alter table service_addresses add column if not exists inferred_name varchar NULL;
+alter table service_addresses add column if not exists inferred_source varchar NULL;
+COMMENT ON COLUMN service_addresses.inferred_name IS 'a host name of the address seen in the capture (DNS response, TLS server name or Host header)';
+COMMENT ON COLUMN service_addresses.inferred_source IS 'a source of the inferred name: dns, sni or host';
@@ -0,0 +1,35 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- stored_captures captures loaded into DB, used by the retention policy
+CREATE TABLE if not exists stored_captures (
+    capture_id varchar NOT NULL,
+    created_at timestamptz DEFAULT now() NOT NULL,
+    load_started_at timestamptz DEFAULT now() NOT NULL,
+    loaded_at timestamptz NULL,
+    CONSTRAINT stored_captures_pk PRIMARY KEY (capture_id)
+);
+-- stored_captures indexes
+CREATE INDEX if not exists stored_captures_loaded_at_idx ON stored_captures USING btree (loaded_at);
+-- stored_captures column comments
+COMMENT ON COLUMN stored_captures.capture_id IS 'primary key, capture identifier';
+COMMENT ON COLUMN stored_captures.created_at IS 'the first load timestamp';
+COMMENT ON COLUMN stored_captures.load_started_at IS 'the last load start timestamp';
+COMMENT ON COLUMN stored_captures.loaded_at IS 'the last load finish timestamp, null while the first load is running';
+-- captures loaded before, the retention starts counting from the migration
+insert into stored_captures (capture_id, loaded_at)
+select capture_id, now() from service_addresses
+union select capture_id, now() from service_packets
+union select capture_id, now() from capture_metadata
+on conflict do nothing;
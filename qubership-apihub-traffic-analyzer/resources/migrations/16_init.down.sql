@@ -0,0 +1,66 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- service_packets back to a single table
+alter sequence service_packets_packet_id_seq owned by none;
+alter table service_packets rename to service_packets_partitioned;
+alter table service_packets_partitioned rename constraint service_packets_pk to service_packets_partitioned_pk;
+alter index service_packets_address_idx rename to service_packets_partitioned_address_idx;
+alter index service_packets_packet_idx rename to service_packets_partitioned_packet_idx;
+
+CREATE TABLE service_packets (
+    packet_id int8 DEFAULT nextval('service_packets_packet_id_seq') NOT NULL,
+    source_id int8 NOT NULL,
+    source_port int4 NOT NULL,
+    dest_id int8 NOT NULL,
+    dest_port int4 NOT NULL,
+    seq_no int8 NOT NULL,
+    ack_no int8 NOT NULL,
+    "time_stamp" timestamptz NOT NULL,
+    body text NULL,
+    capture_id varchar NOT NULL,
+    request_path text NULL,
+    request_method text NULL,
+    request_query varchar NULL,
+    outer_source_ip varchar NULL,
+    outer_dest_ip varchar NULL,
+    encapsulation varchar NULL,
+    CONSTRAINT service_packets_pk PRIMARY KEY (packet_id),
+    CONSTRAINT packets_source_svc_fk FOREIGN KEY (source_id) REFERENCES service_addresses(address_id),
+    CONSTRAINT packets_dest_svc_fk FOREIGN KEY (dest_id) REFERENCES service_addresses(address_id)
+);
+alter sequence service_packets_packet_id_seq owned by service_packets.packet_id;
+CREATE INDEX if not exists service_packets_address_idx ON service_packets USING btree (source_id, dest_id);
+insert into service_packets (packet_id, source_id, source_port, dest_id, dest_port, seq_no, ack_no, "time_stamp", body,
+    capture_id, request_path, request_method, request_query, outer_source_ip, outer_dest_ip, encapsulation)
+select packet_id, source_id, source_port, dest_id, dest_port, seq_no, ack_no, "time_stamp", body,
+    capture_id, request_path, request_method, request_query, outer_source_ip, outer_dest_ip, encapsulation
+from service_packets_partitioned;
+-- partitions are dropped with the partitioned table
+drop table service_packets_partitioned;
+
+drop index if exists service_packet_headers_packet_idx;
+-- references of the deleted packets are not restored
+delete from service_packet_headers sph where not exists (select null from service_packets sp where sp.packet_id = sph.packet_id);
+delete from service_exchanges se where not exists (select null from service_packets sp where sp.packet_id = se.request_packet_id)
+    or (se.response_packet_id is not null and not exists (select null from service_packets sp where sp.packet_id = se.response_packet_id));
+delete from service_websocket_messages swm where not exists (select null from service_packets sp where sp.packet_id = swm.handshake_packet_id);
+delete from service_graphql_operations sgo where not exists (select null from service_packets sp where sp.packet_id = sgo.packet_id);
+delete from service_query_params sqp where not exists (select null from service_packets sp where sp.packet_id = sqp.packet_id);
+alter table service_packet_headers add CONSTRAINT service_packet_headers_ip_packets_fk FOREIGN KEY (packet_id) REFERENCES service_packets(packet_id) ON DELETE CASCADE;
+alter table service_exchanges add CONSTRAINT service_exchanges_request_fk FOREIGN KEY (request_packet_id) REFERENCES service_packets(packet_id) ON DELETE CASCADE;
+alter table service_exchanges add CONSTRAINT service_exchanges_response_fk FOREIGN KEY (response_packet_id) REFERENCES service_packets(packet_id) ON DELETE CASCADE;
+alter table service_websocket_messages add CONSTRAINT service_websocket_messages_handshake_fk FOREIGN KEY (handshake_packet_id) REFERENCES service_packets(packet_id) ON DELETE CASCADE;
+alter table service_graphql_operations add CONSTRAINT service_graphql_operations_packet_fk FOREIGN KEY (packet_id) REFERENCES service_packets(packet_id) ON DELETE CASCADE;
+alter table service_query_params add CONSTRAINT service_query_params_packet_fk FOREIGN KEY (packet_id) REFERENCES service_packets(packet_id) ON DELETE CASCADE;
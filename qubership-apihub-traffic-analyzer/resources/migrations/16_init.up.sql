@@ -0,0 +1,90 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- service_packets is partitioned by capture, the capture packets are dropped with the partition.
+-- Packet references are not foreign keys any more, they are deleted by capture id with the partition
+alter table service_packet_headers drop constraint if exists service_packet_headers_ip_packets_fk;
+alter table service_exchanges drop constraint if exists service_exchanges_request_fk;
+alter table service_exchanges drop constraint if exists service_exchanges_response_fk;
+alter table service_websocket_messages drop constraint if exists service_websocket_messages_handshake_fk;
+alter table service_graphql_operations drop constraint if exists service_graphql_operations_packet_fk;
+alter table service_query_params drop constraint if exists service_query_params_packet_fk;
+CREATE INDEX if not exists service_packet_headers_packet_idx ON service_packet_headers USING btree (packet_id);
+
+-- the unpartitioned table is kept until its rows are copied, packet ids are preserved
+alter sequence service_packets_packet_id_seq owned by none;
+alter table service_packets rename to service_packets_unpartitioned;
+alter table service_packets_unpartitioned rename constraint service_packets_pk to service_packets_unpartitioned_pk;
+alter index service_packets_address_idx rename to service_packets_unpartitioned_address_idx;
+
+CREATE TABLE service_packets (
+    packet_id int8 DEFAULT nextval('service_packets_packet_id_seq') NOT NULL,
+    source_id int8 NOT NULL,
+    source_port int4 NOT NULL,
+    dest_id int8 NOT NULL,
+    dest_port int4 NOT NULL,
+    seq_no int8 NOT NULL,
+    ack_no int8 NOT NULL,
+    "time_stamp" timestamptz NOT NULL,
+    body text NULL,
+    capture_id varchar NOT NULL,
+    request_path text NULL,
+    request_method text NULL,
+    request_query varchar NULL,
+    outer_source_ip varchar NULL,
+    outer_dest_ip varchar NULL,
+    encapsulation varchar NULL,
+    CONSTRAINT service_packets_pk PRIMARY KEY (capture_id, packet_id),
+    CONSTRAINT packets_source_svc_fk FOREIGN KEY (source_id) REFERENCES service_addresses(address_id),
+    CONSTRAINT packets_dest_svc_fk FOREIGN KEY (dest_id) REFERENCES service_addresses(address_id)
+) PARTITION BY LIST (capture_id);
+alter sequence service_packets_packet_id_seq owned by service_packets.packet_id;
+-- service_packets indexes, created on every partition
+CREATE INDEX if not exists service_packets_address_idx ON service_packets USING btree (source_id, dest_id);
+CREATE INDEX if not exists service_packets_packet_idx ON service_packets USING btree (packet_id);
+-- service_packets column comments
+COMMENT ON TABLE service_packets IS 'captured packets, a partition per capture named service_packets_<md5 of capture id>';
+COMMENT ON COLUMN service_packets.packet_id IS 'packet identifier, unique across the captures';
+COMMENT ON COLUMN service_packets.source_id IS 'source IP address';
+COMMENT ON COLUMN service_packets.source_port IS 'source TCP port';
+COMMENT ON COLUMN service_packets.dest_id IS 'dest IP address';
+COMMENT ON COLUMN service_packets.dest_port IS 'dest TCP port';
+COMMENT ON COLUMN service_packets.seq_no IS 'TCP sequence number';
+COMMENT ON COLUMN service_packets.ack_no IS 'TCP acknowlege number';
+COMMENT ON COLUMN service_packets."time_stamp" IS 'packet time stamp';
+COMMENT ON COLUMN service_packets.body IS 'TCP packet payload';
+COMMENT ON COLUMN service_packets.capture_id IS 'capture identifier, the partition key';
+COMMENT ON COLUMN service_packets.request_path IS 'HTTP request path';
+COMMENT ON COLUMN service_packets.request_method IS 'HTTP request method';
+COMMENT ON COLUMN service_packets.request_query IS 'raw request query string';
+COMMENT ON COLUMN service_packets.outer_source_ip IS 'source address of the outermost tunnel header, null for not tunneled packet';
+COMMENT ON COLUMN service_packets.outer_dest_ip IS 'destination address of the outermost tunnel header, null for not tunneled packet';
+COMMENT ON COLUMN service_packets.encapsulation IS 'removed VLAN and tunnel layers from the outermost one (vlan:100/vxlan:42/...)';
+
+-- partitions of the loaded captures
+DO $$
+DECLARE
+    cid varchar;
+BEGIN
+    FOR cid IN SELECT DISTINCT capture_id FROM service_packets_unpartitioned LOOP
+        EXECUTE format('CREATE TABLE if not exists %I PARTITION OF service_packets FOR VALUES IN (%L)',
+            'service_packets_' || md5(cid), cid);
+    END LOOP;
+END $$;
+insert into service_packets (packet_id, source_id, source_port, dest_id, dest_port, seq_no, ack_no, "time_stamp", body,
+    capture_id, request_path, request_method, request_query, outer_source_ip, outer_dest_ip, encapsulation)
+select packet_id, source_id, source_port, dest_id, dest_port, seq_no, ack_no, "time_stamp", body,
+    capture_id, request_path, request_method, request_query, outer_source_ip, outer_dest_ip, encapsulation
+from service_packets_unpartitioned;
+drop table service_packets_unpartitioned;
@@ -0,0 +1,23 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+drop table if exists LOAD_PACKET_JOB_FILES;
+drop index if exists LOAD_PACKET_JOBS_CAPTURE_IDX;
+drop index if exists LOAD_PACKET_JOBS_ACTIVE_UK;
+alter table LOAD_PACKET_JOBS drop column if exists ERROR;
+alter table LOAD_PACKET_JOBS drop column if exists ATTEMPTS;
+alter table LOAD_PACKET_JOBS drop column if exists WORKER_ID;
+alter table LOAD_PACKET_JOBS alter column CREATED drop default;
+alter table LOAD_PACKET_JOBS alter column JOB_ID drop default;
+drop sequence if exists LOAD_PACKET_JOBS_SEQ;
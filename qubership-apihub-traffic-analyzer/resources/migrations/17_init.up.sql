@@ -0,0 +1,47 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- LOAD_PACKET_JOBS capture load queue shared by the service instances, a worker holds the job until JOB_TTL
+create sequence if not exists LOAD_PACKET_JOBS_SEQ owned by LOAD_PACKET_JOBS.JOB_ID;
+alter table LOAD_PACKET_JOBS alter column JOB_ID set default nextval('LOAD_PACKET_JOBS_SEQ');
+alter table LOAD_PACKET_JOBS alter column CREATED set default now();
+alter table LOAD_PACKET_JOBS add column if not exists WORKER_ID varchar NULL;
+alter table LOAD_PACKET_JOBS add column if not exists ATTEMPTS integer DEFAULT 0 NOT NULL;
+alter table LOAD_PACKET_JOBS add column if not exists ERROR text NULL;
+-- a single not finished job per capture
+CREATE UNIQUE INDEX if not exists LOAD_PACKET_JOBS_ACTIVE_UK ON LOAD_PACKET_JOBS USING btree (CAPTURE_ID) WHERE FINISHED is null;
+CREATE INDEX if not exists LOAD_PACKET_JOBS_CAPTURE_IDX ON LOAD_PACKET_JOBS USING btree (CAPTURE_ID, CREATED);
+COMMENT ON COLUMN LOAD_PACKET_JOBS.WORKER_ID IS 'service instance holding the job';
+COMMENT ON COLUMN LOAD_PACKET_JOBS.ATTEMPTS IS 'number of times the job was taken';
+COMMENT ON COLUMN LOAD_PACKET_JOBS.ERROR IS 'load error, null when the job finished successfully';
+
+-- LOAD_PACKET_JOB_FILES capture files read by the job, the read files are skipped when the job is resumed
+create table if not exists LOAD_PACKET_JOB_FILES
+(
+    JOB_ID    integer not null,
+    FILE_NAME varchar not null,
+    STARTED   timestamp DEFAULT now() NOT NULL,
+    FINISHED  timestamp NULL,
+    PACKETS   integer DEFAULT 0 NOT NULL,
+    ERROR     text NULL,
+    CONSTRAINT LOAD_PACKET_JOB_FILES_PK PRIMARY KEY (JOB_ID, FILE_NAME),
+    CONSTRAINT LOAD_PACKET_JOB_FILES_JOB_FK FOREIGN KEY (JOB_ID) REFERENCES LOAD_PACKET_JOBS(JOB_ID) ON DELETE CASCADE
+);
+comment on table LOAD_PACKET_JOB_FILES is 'Capture files read by the packet data loading jobs';
+COMMENT ON COLUMN LOAD_PACKET_JOB_FILES.JOB_ID IS 'reference to the job';
+COMMENT ON COLUMN LOAD_PACKET_JOB_FILES.FILE_NAME IS 'capture file (object) name';
+COMMENT ON COLUMN LOAD_PACKET_JOB_FILES.STARTED IS 'when the file reading started';
+COMMENT ON COLUMN LOAD_PACKET_JOB_FILES.FINISHED IS 'when the file was read, null while reading';
+COMMENT ON COLUMN LOAD_PACKET_JOB_FILES.PACKETS IS 'number of the packets read from the file';
+COMMENT ON COLUMN LOAD_PACKET_JOB_FILES.ERROR IS 'file reading error';
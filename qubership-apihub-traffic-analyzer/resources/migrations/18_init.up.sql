@@ -0,0 +1,18 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+-- load progress and ingest diagnostics
+alter table LOAD_PACKET_JOBS add column if not exists FILES integer DEFAULT 0 NOT NULL;
+COMMENT ON COLUMN LOAD_PACKET_JOBS.FILES IS 'number of the capture files to read, known when the load started';
+alter table LOAD_PACKET_JOB_FILES add column if not exists COUNTERS json NULL;
+COMMENT ON COLUMN LOAD_PACKET_JOB_FILES.COUNTERS IS 'packets and messages counted while reading the file, losses by reason';
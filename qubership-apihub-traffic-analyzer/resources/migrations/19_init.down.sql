@@ -0,0 +1,22 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+alter table service_query_params drop constraint if exists service_query_params_packet_fk;
+alter table service_graphql_operations drop constraint if exists service_graphql_operations_packet_fk;
+alter table service_websocket_messages drop constraint if exists service_websocket_messages_handshake_fk;
+alter table service_exchanges drop constraint if exists service_exchanges_response_fk;
+alter table service_exchanges drop constraint if exists service_exchanges_request_fk;
+alter table service_packet_headers drop constraint if exists service_packet_headers_packet_fk;
+drop index if exists service_packet_headers_packet_idx;
+CREATE INDEX if not exists service_packet_headers_packet_idx ON service_packet_headers USING btree (packet_id);
+alter table service_packet_headers drop column if exists capture_id;
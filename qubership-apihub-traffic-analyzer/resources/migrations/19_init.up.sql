@@ -0,0 +1,47 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+-- packet references are foreign keys to the partitioned service_packets, the key includes the capture id (partition key)
+alter table service_packet_headers add column if not exists capture_id varchar NULL;
+update service_packet_headers sph set capture_id = sp.capture_id
+    from service_packets sp where sp.packet_id = sph.packet_id and sph.capture_id is null;
+COMMENT ON COLUMN service_packet_headers.capture_id IS 'capture identifier of the packet';
+
+-- rows left from the packets dropped while the references were not foreign keys
+delete from service_packet_headers where capture_id is null;
+delete from service_exchanges e where not exists (
+        select null from service_packets sp where sp.capture_id = e.capture_id and sp.packet_id = e.request_packet_id)
+    or (e.response_packet_id is not null and not exists (
+        select null from service_packets sp where sp.capture_id = e.capture_id and sp.packet_id = e.response_packet_id));
+delete from service_websocket_messages m where not exists (
+    select null from service_packets sp where sp.capture_id = m.capture_id and sp.packet_id = m.handshake_packet_id);
+delete from service_graphql_operations o where not exists (
+    select null from service_packets sp where sp.capture_id = o.capture_id and sp.packet_id = o.packet_id);
+delete from service_query_params q where not exists (
+    select null from service_packets sp where sp.capture_id = q.capture_id and sp.packet_id = q.packet_id);
+
+alter table service_packet_headers alter column capture_id set not null;
+drop index if exists service_packet_headers_packet_idx;
+CREATE INDEX if not exists service_packet_headers_packet_idx ON service_packet_headers USING btree (capture_id, packet_id);
+alter table service_packet_headers add CONSTRAINT service_packet_headers_packet_fk
+    FOREIGN KEY (capture_id, packet_id) REFERENCES service_packets(capture_id, packet_id) ON DELETE CASCADE;
+alter table service_exchanges add CONSTRAINT service_exchanges_request_fk
+    FOREIGN KEY (capture_id, request_packet_id) REFERENCES service_packets(capture_id, packet_id) ON DELETE CASCADE;
+alter table service_exchanges add CONSTRAINT service_exchanges_response_fk
+    FOREIGN KEY (capture_id, response_packet_id) REFERENCES service_packets(capture_id, packet_id) ON DELETE CASCADE;
+alter table service_websocket_messages add CONSTRAINT service_websocket_messages_handshake_fk
+    FOREIGN KEY (capture_id, handshake_packet_id) REFERENCES service_packets(capture_id, packet_id) ON DELETE CASCADE;
+alter table service_graphql_operations add CONSTRAINT service_graphql_operations_packet_fk
+    FOREIGN KEY (capture_id, packet_id) REFERENCES service_packets(capture_id, packet_id) ON DELETE CASCADE;
+alter table service_query_params add CONSTRAINT service_query_params_packet_fk
+    FOREIGN KEY (capture_id, packet_id) REFERENCES service_packets(capture_id, packet_id) ON DELETE CASCADE;
@@ -0,0 +1,37 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+-- packet references are foreign keys to the partitioned service_packets, the key includes the capture id (partition key)
+-- entries of an address validity window inserted twice, references are moved to the first entry
+create temporary table service_address_duplicates on commit drop as
+select address_id, min(address_id) over (partition by capture_id, ip_address,
+        coalesce(valid_from, '-infinity'::timestamptz), coalesce(valid_to, 'infinity'::timestamptz)) as kept_id
+    from service_addresses;
+delete from service_address_duplicates where address_id = kept_id;
+update service_packets sp set source_id = d.kept_id from service_address_duplicates d where sp.source_id = d.address_id;
+update service_packets sp set dest_id = d.kept_id from service_address_duplicates d where sp.dest_id = d.address_id;
+update kafka_message_events e set source_id = d.kept_id from service_address_duplicates d where e.source_id = d.address_id
+    and not exists (select null from kafka_message_events k where k.capture_id = e.capture_id and k.source_id = d.kept_id
+        and k.source_port = e.source_port and k.dest_id = e.dest_id and k.dest_port = e.dest_port and k.seq_no = e.seq_no
+        and k.topic = e.topic and k.partition_index = e.partition_index and k.record_index = e.record_index);
+update kafka_message_events e set dest_id = d.kept_id from service_address_duplicates d where e.dest_id = d.address_id
+    and not exists (select null from kafka_message_events k where k.capture_id = e.capture_id and k.source_id = e.source_id
+        and k.source_port = e.source_port and k.dest_id = d.kept_id and k.dest_port = e.dest_port and k.seq_no = e.seq_no
+        and k.topic = e.topic and k.partition_index = e.partition_index and k.record_index = e.record_index);
+-- records stored for both entries
+delete from kafka_message_events e using service_address_duplicates d where e.source_id = d.address_id or e.dest_id = d.address_id;
+delete from service_addresses sa using service_address_duplicates d where sa.address_id = d.address_id;
+
+-- an address validity window has one entry, concurrent inserts of the entry take the stored one
+CREATE UNIQUE INDEX if not exists service_addresses_window_uk ON service_addresses USING btree
+    (capture_id, ip_address, coalesce(valid_from, '-infinity'::timestamptz), coalesce(valid_to, 'infinity'::timestamptz));
@@ -0,0 +1,59 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- service_exchanges requests paired with responses
+CREATE TABLE if not exists service_exchanges (
+    exchange_id bigserial NOT NULL,
+    capture_id varchar NOT NULL,
+    request_packet_id int8 NOT NULL,
+    response_packet_id int8 NULL,
+    request_method varchar NULL,
+    request_path text NULL,
+    status_code int4 NULL,
+    status_text varchar NULL,
+    request_headers json NULL,
+    response_headers json NULL,
+    request_body text NULL,
+    response_body text NULL,
+    request_time timestamptz NULL,
+    response_time timestamptz NULL,
+    time_to_first_byte int8 NULL,
+    CONSTRAINT service_exchanges_pk PRIMARY KEY (exchange_id),
+    CONSTRAINT service_exchanges_request_uk UNIQUE (request_packet_id),
+    CONSTRAINT service_exchanges_request_fk FOREIGN KEY (request_packet_id) REFERENCES service_packets(packet_id) ON DELETE CASCADE,
+    CONSTRAINT service_exchanges_response_fk FOREIGN KEY (response_packet_id) REFERENCES service_packets(packet_id) ON DELETE CASCADE
+);
+-- service_exchanges indexes
+CREATE INDEX if not exists service_exchanges_capture_id_idx ON service_exchanges USING btree (capture_id);
+CREATE INDEX if not exists service_exchanges_response_idx ON service_exchanges USING btree (response_packet_id);
+-- service_exchanges column comments
+COMMENT ON COLUMN service_exchanges.exchange_id IS 'primary key';
+COMMENT ON COLUMN service_exchanges.capture_id IS 'capture identifier';
+COMMENT ON COLUMN service_exchanges.request_packet_id IS 'reference to the request in service packets';
+COMMENT ON COLUMN service_exchanges.response_packet_id IS 'reference to the response in service packets, null when request not answered';
+COMMENT ON COLUMN service_exchanges.request_method IS 'HTTP request method';
+COMMENT ON COLUMN service_exchanges.request_path IS 'HTTP request path';
+COMMENT ON COLUMN service_exchanges.status_code IS 'HTTP response status code';
+COMMENT ON COLUMN service_exchanges.status_text IS 'HTTP response status line';
+COMMENT ON COLUMN service_exchanges.request_headers IS 'HTTP request headers';
+COMMENT ON COLUMN service_exchanges.response_headers IS 'HTTP response headers';
+COMMENT ON COLUMN service_exchanges.request_body IS 'decoded HTTP request body';
+COMMENT ON COLUMN service_exchanges.response_body IS 'decoded HTTP response body';
+COMMENT ON COLUMN service_exchanges.request_time IS 'request first byte time stamp';
+COMMENT ON COLUMN service_exchanges.response_time IS 'response first byte time stamp';
+COMMENT ON COLUMN service_exchanges.time_to_first_byte IS 'microseconds between the request last byte and the response first byte';
+
+-- response codes returned by operation
+alter table report_service_operations2 add column if not exists response_codes varchar NULL;
+COMMENT ON COLUMN report_service_operations2.response_codes IS 'comma separated HTTP response codes returned by operation';
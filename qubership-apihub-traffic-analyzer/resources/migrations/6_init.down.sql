@@ -0,0 +1,19 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+drop index if exists service_exchanges_grpc_idx;
+alter table service_exchanges drop column if exists grpc_message;
+alter table service_exchanges drop column if exists grpc_status;
+alter table service_exchanges drop column if exists grpc_method;
+alter table service_exchanges drop column if exists grpc_service;
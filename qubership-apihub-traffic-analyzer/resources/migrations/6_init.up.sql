@@ -0,0 +1,24 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- gRPC call details
+alter table service_exchanges add column if not exists grpc_service varchar NULL;
+alter table service_exchanges add column if not exists grpc_method varchar NULL;
+alter table service_exchanges add column if not exists grpc_status int4 NULL;
+alter table service_exchanges add column if not exists grpc_message varchar NULL;
+CREATE INDEX if not exists service_exchanges_grpc_idx ON service_exchanges USING btree (grpc_service, grpc_method);
+COMMENT ON COLUMN service_exchanges.grpc_service IS 'gRPC service name from the request path';
+COMMENT ON COLUMN service_exchanges.grpc_method IS 'gRPC method name from the request path';
+COMMENT ON COLUMN service_exchanges.grpc_status IS 'gRPC status code from the response trailers';
+COMMENT ON COLUMN service_exchanges.grpc_message IS 'gRPC status message from the response trailers';
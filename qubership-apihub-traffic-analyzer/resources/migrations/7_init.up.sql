@@ -0,0 +1,27 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- protobuf_descriptors uploaded FileDescriptorSets used to decode gRPC messages
+CREATE TABLE if not exists protobuf_descriptors (
+    descriptor_name varchar NOT NULL,
+    service_name varchar NULL,
+    descriptor_set bytea NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT protobuf_descriptors_pk PRIMARY KEY (descriptor_name)
+);
+CREATE INDEX if not exists protobuf_descriptors_service_idx ON protobuf_descriptors USING btree (service_name);
+COMMENT ON COLUMN protobuf_descriptors.descriptor_name IS 'descriptor set name';
+COMMENT ON COLUMN protobuf_descriptors.service_name IS 'service the descriptor set is bound to, null for any service';
+COMMENT ON COLUMN protobuf_descriptors.descriptor_set IS 'serialized google.protobuf.FileDescriptorSet';
+COMMENT ON COLUMN protobuf_descriptors.created_at IS 'upload time stamp';
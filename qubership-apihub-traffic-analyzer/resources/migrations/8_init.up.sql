@@ -0,0 +1,47 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- service_websocket_messages messages sent over WebSocket connections after the handshake
+CREATE TABLE if not exists service_websocket_messages (
+    message_id bigserial NOT NULL,
+    capture_id varchar NOT NULL,
+    handshake_packet_id int8 NOT NULL,
+    request_path text NULL,
+    from_client bool NOT NULL,
+    opcode int4 NOT NULL,
+    message_type varchar NOT NULL,
+    compressed bool NOT NULL DEFAULT false,
+    payload text NULL,
+    payload_size int4 NOT NULL,
+    time_stamp timestamptz NULL,
+    seq_no int8 NOT NULL,
+    CONSTRAINT service_websocket_messages_pk PRIMARY KEY (message_id),
+    CONSTRAINT service_websocket_messages_uk UNIQUE (handshake_packet_id, from_client, seq_no),
+    CONSTRAINT service_websocket_messages_handshake_fk FOREIGN KEY (handshake_packet_id) REFERENCES service_packets(packet_id) ON DELETE CASCADE
+);
+-- service_websocket_messages indexes
+CREATE INDEX if not exists service_websocket_messages_capture_id_idx ON service_websocket_messages USING btree (capture_id);
+-- service_websocket_messages column comments
+COMMENT ON COLUMN service_websocket_messages.message_id IS 'primary key';
+COMMENT ON COLUMN service_websocket_messages.capture_id IS 'capture identifier';
+COMMENT ON COLUMN service_websocket_messages.handshake_packet_id IS 'reference to the handshake request in service packets';
+COMMENT ON COLUMN service_websocket_messages.request_path IS 'handshake request path';
+COMMENT ON COLUMN service_websocket_messages.from_client IS 'true for messages sent by the client, false for messages sent by the server';
+COMMENT ON COLUMN service_websocket_messages.opcode IS 'WebSocket message opcode';
+COMMENT ON COLUMN service_websocket_messages.message_type IS 'text, binary, close, ping or pong';
+COMMENT ON COLUMN service_websocket_messages.compressed IS 'true when the message was compressed by permessage-deflate';
+COMMENT ON COLUMN service_websocket_messages.payload IS 'decoded payload, base64 encoded for not text messages';
+COMMENT ON COLUMN service_websocket_messages.payload_size IS 'decoded payload length in bytes';
+COMMENT ON COLUMN service_websocket_messages.time_stamp IS 'message first frame time stamp';
+COMMENT ON COLUMN service_websocket_messages.seq_no IS 'TCP sequence number of the message first frame';
@@ -0,0 +1,63 @@
+-- Copyright 2024-2025 NetCracker Technology Corporation
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--     http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+-- kafka_message_events records produced and consumed over Kafka connections
+CREATE TABLE if not exists kafka_message_events (
+    event_id bigserial NOT NULL,
+    capture_id varchar NOT NULL,
+    source_id int8 NULL,
+    source_port int4 NULL,
+    dest_id int8 NULL,
+    dest_port int4 NULL,
+    operation varchar NOT NULL,
+    api_version int4 NOT NULL,
+    client_id varchar NULL,
+    topic varchar NOT NULL,
+    topic_id varchar NULL,
+    partition_index int4 NOT NULL,
+    record_index int4 NOT NULL,
+    record_offset int8 NOT NULL,
+    record_key text NULL,
+    record_value text NULL,
+    record_headers json NULL,
+    record_time timestamptz NULL,
+    time_stamp timestamptz NULL,
+    seq_no int8 NOT NULL,
+    CONSTRAINT kafka_message_events_pk PRIMARY KEY (event_id)
+);
+-- kafka_message_events indexes
+CREATE UNIQUE INDEX if not exists kafka_message_events_uk ON kafka_message_events USING btree
+    (capture_id, source_id, source_port, dest_id, dest_port, seq_no, topic, partition_index, record_index);
+CREATE INDEX if not exists kafka_message_events_topic_idx ON kafka_message_events USING btree (capture_id, topic);
+-- kafka_message_events column comments
+COMMENT ON COLUMN kafka_message_events.event_id IS 'primary key';
+COMMENT ON COLUMN kafka_message_events.capture_id IS 'capture identifier';
+COMMENT ON COLUMN kafka_message_events.source_id IS 'reference to the sender in service addresses';
+COMMENT ON COLUMN kafka_message_events.source_port IS 'sender TCP port';
+COMMENT ON COLUMN kafka_message_events.dest_id IS 'reference to the receiver in service addresses';
+COMMENT ON COLUMN kafka_message_events.dest_port IS 'receiver TCP port';
+COMMENT ON COLUMN kafka_message_events.operation IS 'PRODUCE for records sent by a producer, CONSUME for records fetched by a consumer';
+COMMENT ON COLUMN kafka_message_events.api_version IS 'Produce or Fetch request version';
+COMMENT ON COLUMN kafka_message_events.client_id IS 'Kafka client id';
+COMMENT ON COLUMN kafka_message_events.topic IS 'topic name, topic id when the name is not known';
+COMMENT ON COLUMN kafka_message_events.topic_id IS 'topic id for requests addressing topics by id';
+COMMENT ON COLUMN kafka_message_events.partition_index IS 'topic partition';
+COMMENT ON COLUMN kafka_message_events.record_index IS 'record position in the partition data';
+COMMENT ON COLUMN kafka_message_events.record_offset IS 'record offset, relative to the batch for produced records';
+COMMENT ON COLUMN kafka_message_events.record_key IS 'record key, base64 encoded when not a text';
+COMMENT ON COLUMN kafka_message_events.record_value IS 'record value, base64 encoded when not a text';
+COMMENT ON COLUMN kafka_message_events.record_headers IS 'record headers';
+COMMENT ON COLUMN kafka_message_events.record_time IS 'record time stamp';
+COMMENT ON COLUMN kafka_message_events.time_stamp IS 'request or response first segment time stamp';
+COMMENT ON COLUMN kafka_message_events.seq_no IS 'TCP sequence number of the request or response';
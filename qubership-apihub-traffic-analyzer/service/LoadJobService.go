@@ -0,0 +1,288 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/readers"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/repository"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/utils"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefLoadJobWorkers default number of captures loaded in parallel by the instance
+	DefLoadJobWorkers = 1
+	// DefLoadJobTtl default duration the job is held by a worker without prolongation
+	DefLoadJobTtl = 5 * time.Minute
+	// DefLoadJobMaxAttempts default number of times the job is taken before it is abandoned
+	DefLoadJobMaxAttempts = 3
+	// loadJobPollInterval period of the job queue checks
+	loadJobPollInterval = 10 * time.Second
+)
+
+// LoadJobConfig
+// capture load workers parameters
+type LoadJobConfig struct {
+	WorkDir string
+	// LoadConcurrency number of capture files decoded in parallel
+	LoadConcurrency int
+	// Workers number of captures loaded in parallel
+	Workers int
+	// JobTtl duration the job is held by a worker, the hold is prolonged while the worker is loading
+	JobTtl time.Duration
+	// MaxAttempts number of times the job is taken before it is abandoned
+	MaxAttempts int
+}
+
+// LoadJobService
+// loads captures queued in LOAD_PACKET_JOBS, the queue is shared by the service instances.
+// A job interrupted by the instance stop is taken by another worker when the hold expires,
+// the capture files read before are skipped
+type LoadJobService interface {
+	Enqueue(captureId string) (*entities.LoadPacketJob, bool, error)
+	Start()
+	Stop()
+}
+
+type loadJobServiceImpl struct {
+	LoadJobConfig
+	db       db.ConnectionProvider
+	s3       CloudStorage
+	headers  repository.HttpHeadersCache
+	packets  repository.PacketCache
+	peers    repository.ServiceAddressRepository
+	workerId string
+	stop     chan struct{}
+}
+
+// NewLoadJobService
+// creates the capture load service, the workers are not running until Start
+func NewLoadJobService(cfg LoadJobConfig,
+	pdb db.ConnectionProvider,
+	s3 CloudStorage,
+	headers repository.HttpHeadersCache,
+	packets repository.PacketCache,
+	peers repository.ServiceAddressRepository) LoadJobService {
+	if cfg.Workers < 1 {
+		cfg.Workers = DefLoadJobWorkers
+	}
+	if cfg.JobTtl <= 0 {
+		cfg.JobTtl = DefLoadJobTtl
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = DefLoadJobMaxAttempts
+	}
+	hostName, err := os.Hostname()
+	if err != nil {
+		hostName = "analyzer"
+	}
+	return &loadJobServiceImpl{
+		LoadJobConfig: cfg,
+		db:            pdb,
+		s3:            s3,
+		headers:       headers,
+		packets:       packets,
+		peers:         peers,
+		workerId:      hostName + "/" + utils.MakeUniqueId(),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Enqueue
+// queues the capture load, returns the not finished job and false when the capture is being loaded
+func (ls *loadJobServiceImpl) Enqueue(captureId string) (*entities.LoadPacketJob, bool, error) {
+	job, created, err := repository.EnqueueLoadJob(ls.db, captureId)
+	if err == nil && created {
+		log.Printf("capture '%s' load queued, job %d", captureId, job.JobId)
+	}
+	return job, created, err
+}
+
+// Start
+// runs the workers taking the queued jobs
+func (ls *loadJobServiceImpl) Start() {
+	log.Printf("capture load worker %s: %d job(s) in parallel, job TTL %v", ls.workerId, ls.Workers, ls.JobTtl)
+	for i := 0; i < ls.Workers; i++ {
+		utils.SafeAsync(ls.work)
+	}
+}
+
+// Stop
+// stops taking the jobs, the jobs being loaded are resumed by other instances after their holds expire
+func (ls *loadJobServiceImpl) Stop() {
+	close(ls.stop)
+}
+
+// work
+// takes and runs the jobs until the service stops
+func (ls *loadJobServiceImpl) work() {
+	for {
+		select {
+		case <-ls.stop:
+			return
+		default:
+		}
+		job, err := repository.TakeLoadJob(ls.db, ls.workerId, ls.JobTtl, ls.MaxAttempts)
+		if err != nil {
+			log.Errorf("unable to take capture load job: %v", err)
+		}
+		if job != nil {
+			// a panic leaves the job to expire and to be taken again
+			done := make(chan struct{})
+			utils.SafeAsync(func() {
+				defer close(done)
+				ls.runJob(job)
+			})
+			<-done
+			continue
+		}
+		select {
+		case <-ls.stop:
+			return
+		case <-time.After(loadJobPollInterval):
+		}
+	}
+}
+
+// runJob
+// loads the capture, the job hold is prolonged while loading
+func (ls *loadJobServiceImpl) runJob(job *entities.LoadPacketJob) {
+	log.Printf("starting process files for capture %s, job %d attempt %d", job.CaptureId, job.JobId, job.Attempts)
+	err := repository.StartCaptureLoad(ls.db, job.CaptureId)
+	if err != nil {
+		log.Warnf("capture '%s' is not registered for the retention: %v", job.CaptureId, err)
+	}
+	// the load is cancelled when another worker takes the job
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	finished := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	utils.SafeAsync(func() {
+		defer wg.Done()
+		ls.holdJob(job, func() (bool, error) {
+			return repository.ExtendLoadJob(ls.db, job.JobId, ls.workerId, ls.JobTtl)
+		}, finished, cancel)
+	})
+	var loadErr error
+	if ls.s3 == nil {
+		loadErr = fmt.Errorf("cloud storage is not available")
+	} else {
+		rdr := readers.NewCaptureReader(ls.headers, ls.packets, ls.peers, ls.db, ls.WorkDir, ls.LoadConcurrency)
+		rdr.SetProgress(&jobProgress{db: ls.db, job: job, workerId: ls.workerId})
+		_, loadErr = ls.s3.ProcessCaptureFiles(ctx, job.CaptureId, rdr)
+		err = rdr.Close()
+		if err != nil {
+			log.Warnf("unable to close reader: %v", err)
+		}
+	}
+	close(finished)
+	wg.Wait()
+	err = repository.FinishLoadJob(ls.db, job.JobId, ls.workerId, loadErr)
+	if err != nil {
+		log.Errorf("capture '%s' load result is not stored: %v", job.CaptureId, err)
+		return
+	}
+	if loadErr != nil {
+		log.Errorf("capture '%s' load finished with error: %v", job.CaptureId, loadErr)
+		return
+	}
+	err = repository.FinishCaptureLoad(ls.db, job.CaptureId)
+	if err != nil {
+		log.Warnf("capture '%s' load finish is not registered for the retention: %v", job.CaptureId, err)
+	}
+	log.Printf("capture '%s' loaded successfully", job.CaptureId)
+}
+
+// holdJob
+// prolongs the job hold with extend until the load finishes, the load is cancelled when the hold is lost
+func (ls *loadJobServiceImpl) holdJob(job *entities.LoadPacketJob, extend func() (bool, error), finished chan struct{}, cancel context.CancelFunc) {
+	ticker := time.NewTicker(ls.JobTtl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-finished:
+			return
+		case <-ticker.C:
+			held, err := extend()
+			if err != nil {
+				log.Warnf("unable to prolong capture '%s' load job: %v", job.CaptureId, err)
+			} else if !held {
+				log.Warnf("capture '%s' load job %d was taken by another worker, the load is cancelled", job.CaptureId, job.JobId)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// jobProgress
+// tracks the capture files of the job in LOAD_PACKET_JOB_FILES
+type jobProgress struct {
+	db  db.ConnectionProvider
+	job *entities.LoadPacketJob
+	// workerId the files are tracked while the job is held by the worker
+	workerId string
+	once     sync.Once
+	// read files read by the previous attempts without error
+	read map[string]bool
+}
+
+func (jp *jobProgress) FileRead(name string) bool {
+	jp.once.Do(func() {
+		jp.read = make(map[string]bool)
+		files, err := repository.GetLoadJobFiles(jp.db, jp.job.JobId)
+		if err != nil {
+			log.Warnf("capture '%s' files are read from the beginning: %v", jp.job.CaptureId, err)
+			return
+		}
+		for _, file := range files {
+			if file.Finished != nil && file.Error == nil {
+				jp.read[file.FileName] = true
+			}
+		}
+	})
+	return jp.read[name]
+}
+
+func (jp *jobProgress) FilesFound(count int) {
+	err := repository.SetLoadJobFiles(jp.db, jp.job.JobId, jp.workerId, count)
+	if err != nil {
+		log.Warnf("capture load percentage is not tracked: %v", err)
+	}
+}
+
+func (jp *jobProgress) FileStarted(name string) {
+	err := repository.StartLoadJobFile(jp.db, jp.job.JobId, jp.workerId, name)
+	if err != nil {
+		log.Warnf("capture file progress is not tracked: %v", err)
+	}
+}
+
+func (jp *jobProgress) FileFinished(name string, packets int, counters *view.IngestCounters, err error) {
+	err = repository.FinishLoadJobFile(jp.db, jp.job.JobId, jp.workerId, name, packets, counters, err)
+	if err != nil {
+		log.Warnf("capture file progress is not tracked: %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/entities"
+)
+
+func TestNewLoadJobServiceDefaults(t *testing.T) {
+	ls := NewLoadJobService(LoadJobConfig{}, nil, nil, nil, nil, nil).(*loadJobServiceImpl)
+	if ls.Workers != DefLoadJobWorkers || ls.JobTtl != DefLoadJobTtl || ls.MaxAttempts != DefLoadJobMaxAttempts {
+		t.Errorf("config %+v, want the defaults", ls.LoadJobConfig)
+	}
+	other := NewLoadJobService(LoadJobConfig{}, nil, nil, nil, nil, nil).(*loadJobServiceImpl)
+	if !strings.Contains(ls.workerId, "/") || ls.workerId == other.workerId {
+		t.Errorf("worker ids %s and %s are expected to be unique", ls.workerId, other.workerId)
+	}
+}
+
+// holdResult
+// a result of the job hold prolongation
+type holdResult struct {
+	held bool
+	err  error
+}
+
+func TestHoldJob(t *testing.T) {
+	const ttl = 30 * time.Millisecond
+	tests := []struct {
+		name string
+		// results of the first hold prolongations, the next ones succeed
+		results       []holdResult
+		wantCancelled bool
+	}{
+		{
+			name:    "hold is prolonged until the load finishes",
+			results: []holdResult{{held: true}, {held: true}},
+		},
+		{
+			name:    "prolongation errors do not cancel the load",
+			results: []holdResult{{err: errors.New("connection refused")}, {held: true}},
+		},
+		{
+			name:          "load is cancelled when the job is taken by another worker",
+			results:       []holdResult{{held: true}, {held: false}},
+			wantCancelled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls := &loadJobServiceImpl{LoadJobConfig: LoadJobConfig{JobTtl: ttl}, workerId: "test"}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			finished := make(chan struct{})
+			extended := make(chan struct{})
+			mutex := sync.Mutex{}
+			calls := 0
+			extend := func() (bool, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				calls++
+				if calls == len(tt.results) {
+					close(extended)
+				}
+				if calls > len(tt.results) {
+					return true, nil
+				}
+				return tt.results[calls-1].held, tt.results[calls-1].err
+			}
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				ls.holdJob(&entities.LoadPacketJob{JobId: 1, CaptureId: "capture"}, extend, finished, cancel)
+			}()
+			select {
+			case <-extended:
+			case <-done:
+				t.Fatal("hold is stopped before the expected prolongations")
+			}
+			if tt.wantCancelled {
+				select {
+				case <-done:
+				case <-time.After(time.Second):
+					t.Fatal("hold is not stopped after the job is lost")
+				}
+			} else {
+				close(finished)
+				<-done
+			}
+			if cancelled := ctx.Err() != nil; cancelled != tt.wantCancelled {
+				t.Errorf("load cancelled %v, want %v", cancelled, tt.wantCancelled)
+			}
+		})
+	}
+}
@@ -37,7 +37,7 @@ import (
 const TableName = "PacketCaptures"
 
 type CloudStorage interface {
-	ProcessCaptureFiles(captureId string, rdr readers.CaptureReader) (int, error)
+	ProcessCaptureFiles(ctx context.Context, captureId string, rdr readers.CaptureReader) (int, error)
 	DeleteCaptureFiles(captureId string) (int, error)
 	CleanupCaptureFiles() (int, error)
 }
@@ -75,12 +75,12 @@ func NewCloudStorage(config view.MinioStorageCreds) (CloudStorage, error) {
 }
 
 // ProcessCaptureFiles
-// downloads capture files from S3/Minio to local
-func (s3 *cloudStorage) ProcessCaptureFiles(captureId string, rdr readers.CaptureReader) (int, error) {
+// downloads capture files from S3/Minio to local, the capture reading stops when the context is cancelled
+func (s3 *cloudStorage) ProcessCaptureFiles(ctx context.Context, captureId string, rdr readers.CaptureReader) (int, error) {
 	receivedCount := 0
-	ctx := context.Background()
 	var addressLists []minio.ObjectInfo
 	var captureFiles []minio.ObjectInfo
+	var keyLogs []minio.ObjectInfo
 	var captureMetadata minio.ObjectInfo
 	// iterate and sort objects
 	if s3.minioClient == nil || s3.minioClient.client == nil {
@@ -107,7 +107,7 @@ func (s3 *cloudStorage) ProcessCaptureFiles(captureId string, rdr readers.Captur
 		s3.bannerTime = time.Now().Add(time.Minute)
 	}
 	// receive a channel to objects
-	s3Objects := s3.minioClient.client.ListObjects(context.Background(), s3.config.BucketName, opts)
+	s3Objects := s3.minioClient.client.ListObjects(ctx, s3.config.BucketName, opts)
 	for objectInfo := range s3Objects {
 		if objectInfo.Err != nil {
 			log.Errorf("unable to list file %s from S3/minio: %v", objectInfo.Key, objectInfo.Err)
@@ -121,7 +121,7 @@ func (s3 *cloudStorage) ProcessCaptureFiles(captureId string, rdr readers.Captur
 			addressLists = append(addressLists, objectInfo)
 			continue
 		}
-		if strings.HasSuffix(name, view.CaptureSuffix) {
+		if strings.HasSuffix(name, view.CaptureSuffix) || strings.HasSuffix(name, view.CaptureNgSuffix) {
 			captureFiles = append(captureFiles, objectInfo)
 			continue
 		}
@@ -129,6 +129,10 @@ func (s3 *cloudStorage) ProcessCaptureFiles(captureId string, rdr readers.Captur
 			captureMetadata = objectInfo
 			continue
 		}
+		if strings.HasSuffix(name, view.KeyLogSuffix) {
+			keyLogs = append(keyLogs, objectInfo)
+			continue
+		}
 	}
 	if captureMetadata.Key == view.EmptyString || len(addressLists) == 0 || len(captureFiles) == 0 {
 		//return receivedCount, fmt.Errorf("capture data not found at S3/Minio")
@@ -174,23 +178,30 @@ func (s3 *cloudStorage) ProcessCaptureFiles(captureId string, rdr readers.Captur
 		}
 		receivedCount++
 	}
-	// loading capture data
-	packetCount := 0
-	for ci, captureFile := range captureFiles {
-		localFileName, err := s3.getObject(ctx, &captureFile)
+	// loading TLS secrets, secrets are streamed from S3/minio objects without local copy
+	for ki, keyLog := range keyLogs {
+		err := s3.readKeyLog(ctx, &keyLog, rdr)
 		if err != nil {
-			return receivedCount, fmt.Errorf("unable to load capture file %d.%s from S3/minio: %v", ci, captureFile.Key, err)
+			// connections of the missing secrets stay encrypted
+			log.Warnf("unable to read key log file %d.%s from S3/minio: %v", ki, keyLog.Key, err)
+			continue
 		}
-		// loads capture data from local file
-		tStart := time.Now()
-		count, err := rdr.ReadCaptureFile(captureId, localFileName)
-		tDiff := time.Now().Sub(tStart)
-		log.Debugf("Reading capture file %d in %v", ci+1, tDiff)
 		receivedCount++
-		if err != nil {
-			return receivedCount, fmt.Errorf("unable to process capture file %d.%s from S3/minio: %v", ci, captureFile.Key, err)
-		}
-		packetCount += count
+	}
+	// loading capture data, capture data is streamed from S3/minio objects without local copy
+	sources := make([]readers.CaptureSource, 0, len(captureFiles))
+	for _, captureFile := range captureFiles {
+		sources = append(sources, readers.CaptureSource{
+			Name: captureFile.Key,
+			Open: func() (io.ReadCloser, error) {
+				return s3.minioClient.client.GetObject(ctx, s3.config.BucketName, captureFile.Key, minio.GetObjectOptions{})
+			},
+		})
+	}
+	packetCount, err := rdr.ReadCaptures(ctx, captureId, sources)
+	receivedCount += len(captureFiles)
+	if err != nil {
+		return receivedCount, fmt.Errorf("unable to process capture files from S3/minio: %v", err)
 	}
 	log.Printf("files read: %d, packets processed: %d", receivedCount, packetCount)
 	return receivedCount, nil
@@ -304,7 +315,7 @@ func (s3 *cloudStorage) CleanupCaptureFiles() (int, error) {
 		}
 		if strings.HasSuffix(name, view.MetadataSuffix) {
 			capStat.Flags |= HasMetadata
-		} else if strings.HasSuffix(name, view.CaptureSuffix) {
+		} else if strings.HasSuffix(name, view.CaptureSuffix) || strings.HasSuffix(name, view.CaptureNgSuffix) {
 			capStat.Flags |= HasPackets
 		} else if strings.HasSuffix(name, view.AddressListSuffix) {
 			capStat.Flags |= HasAddresses
@@ -354,7 +365,7 @@ func createMinioClient(minioCredentials *view.MinioStorageCreds) *minioClient {
 	}
 	crt, err := os.CreateTemp("", "minio.cert")
 	if err != nil {
-		log.Warn("unable to create temporary certificate storage:%v", err)
+		log.Warnf("unable to create temporary certificate storage:%v", err)
 		client.error = err
 		return client
 	}
@@ -488,6 +499,19 @@ func (s3 *cloudStorage) getObject(ctx context.Context, csObjectInfo *minio.Objec
 	return fullPath, err
 }
 
+// readKeyLog
+// streams TLS secrets from S3/minio object into the capture reader
+func (s3 *cloudStorage) readKeyLog(ctx context.Context, keyLog *minio.ObjectInfo, rdr readers.CaptureReader) error {
+	object, err := s3.minioClient.client.GetObject(ctx, s3.config.BucketName, keyLog.Key, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer func(object *minio.Object) {
+		_ = object.Close()
+	}(object)
+	return rdr.ReadKeyLogStream(keyLog.Key, object)
+}
+
 func (s3 *cloudStorage) getMetadataDirect(ctx context.Context, csMdInfo *minio.ObjectInfo, rdr readers.MetadataReader) error {
 	csObject, err := s3.minioClient.client.GetObject(ctx, s3.config.BucketName, csMdInfo.Key, minio.GetObjectOptions{})
 	if err != nil {
@@ -0,0 +1,145 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/db"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/repository"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/utils"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefRetentionInterval default period of the retention checks
+	DefRetentionInterval = time.Hour
+)
+
+// RetentionService
+// purges aggregated capture and report data on demand and by the retention policy
+type RetentionService interface {
+	PurgeCapture(captureId string) (view.CapturePurgeResult, error)
+	PurgeReport(reportUuid string) (bool, error)
+	PurgeExpired() error
+	Start()
+	Stop()
+}
+
+type retentionServiceImpl struct {
+	db     db.ConnectionProvider
+	policy view.RetentionPolicy
+	stop   chan struct{}
+}
+
+// NewRetentionService
+// creates a retention service, the policy checks are not running until Start
+func NewRetentionService(pdb db.ConnectionProvider, policy view.RetentionPolicy) RetentionService {
+	if policy.Interval <= 0 {
+		policy.Interval = DefRetentionInterval
+	}
+	return &retentionServiceImpl{
+		db:     pdb,
+		policy: policy,
+		stop:   make(chan struct{}),
+	}
+}
+
+// PurgeCapture
+// deletes the aggregated data of the capture
+func (rs *retentionServiceImpl) PurgeCapture(captureId string) (view.CapturePurgeResult, error) {
+	result, err := repository.PurgeCapture(rs.db, captureId)
+	if err == nil {
+		log.Printf("capture '%s' purged: %d packets, %d addresses, %d reports, %d orphaned headers",
+			captureId, result.Packets, result.Addresses, result.Reports, result.Headers)
+	}
+	return result, err
+}
+
+// PurgeReport
+// deletes the report data, returns false when the report was not found
+func (rs *retentionServiceImpl) PurgeReport(reportUuid string) (bool, error) {
+	return repository.PurgeReport(rs.db, reportUuid)
+}
+
+// PurgeExpired
+// purges the captures and reports exceeding the retention policy limits
+func (rs *retentionServiceImpl) PurgeExpired() error {
+	failures := make([]error, 0)
+	captureIds, err := repository.ExpiredCaptures(rs.db, rs.policy)
+	if err != nil {
+		failures = append(failures, err)
+	}
+	for _, captureId := range captureIds {
+		_, err = rs.PurgeCapture(captureId)
+		if errors.Is(err, repository.ErrCaptureLoading) {
+			// the load was started after the expired captures were selected
+			log.Debugf("expired capture '%s' is being loaded, purge skipped", captureId)
+		} else if err != nil {
+			failures = append(failures, err)
+		}
+	}
+	reportUuids, err := repository.ExpiredReports(rs.db, rs.policy.ReportMaxAge)
+	if err != nil {
+		failures = append(failures, err)
+	}
+	purged := 0
+	for _, reportUuid := range reportUuids {
+		deleted, err := rs.PurgeReport(reportUuid)
+		if err != nil {
+			failures = append(failures, err)
+		} else if deleted {
+			purged++
+		}
+	}
+	if purged > 0 {
+		log.Printf("%d expired report(s) purged", purged)
+	}
+	return errors.Join(failures...)
+}
+
+// Start
+// runs the retention policy checks in background, nothing is started when the policy has no limits
+func (rs *retentionServiceImpl) Start() {
+	if !rs.policy.Enabled() {
+		log.Debugf("retention policy is not set, aggregated data is kept until purged explicitly")
+		return
+	}
+	log.Printf("retention policy: captures max age %v, max captures %d, reports max age %v, checked every %v",
+		rs.policy.CaptureMaxAge, rs.policy.MaxCaptures, rs.policy.ReportMaxAge, rs.policy.Interval)
+	utils.SafeAsync(func() {
+		ticker := time.NewTicker(rs.policy.Interval)
+		defer ticker.Stop()
+		for {
+			err := rs.PurgeExpired()
+			if err != nil {
+				log.Errorf("unable to apply retention policy: %v", err)
+			}
+			select {
+			case <-rs.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Stop
+// stops the retention policy checks
+func (rs *retentionServiceImpl) Stop() {
+	close(rs.stop)
+}
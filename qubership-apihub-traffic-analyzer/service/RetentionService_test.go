@@ -0,0 +1,79 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
+)
+
+func TestRetentionPolicyFromEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		want        view.RetentionPolicy
+		wantEnabled bool
+	}{
+		{
+			name: "no limits",
+			want: view.RetentionPolicy{Interval: DefRetentionInterval},
+		},
+		{
+			name: "all limits",
+			env: map[string]string{CaptureMaxAge: "72h", MaxCaptures: "10", ReportMaxAge: "720h",
+				RetentionInterval: "15m"},
+			want: view.RetentionPolicy{CaptureMaxAge: 72 * time.Hour, MaxCaptures: 10, ReportMaxAge: 720 * time.Hour,
+				Interval: 15 * time.Minute},
+			wantEnabled: true,
+		},
+		{
+			name: "invalid values are ignored",
+			env:  map[string]string{CaptureMaxAge: "3 days", MaxCaptures: "ten", RetentionInterval: "1d"},
+			want: view.RetentionPolicy{Interval: DefRetentionInterval},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{CaptureMaxAge, MaxCaptures, ReportMaxAge, RetentionInterval} {
+				t.Setenv(name, tt.env[name])
+			}
+			systemInfo, err := NewSystemInfoService()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := systemInfo.GetRetentionPolicy(); got != tt.want {
+				t.Errorf("GetRetentionPolicy() = %+v, want %+v", got, tt.want)
+			}
+			if got := systemInfo.GetRetentionPolicy().Enabled(); got != tt.wantEnabled {
+				t.Errorf("Enabled() = %v, want %v", got, tt.wantEnabled)
+			}
+		})
+	}
+}
+
+func TestRetentionWithoutLimits(t *testing.T) {
+	// nothing is selected from DB without limits, the nil DB connection fails the test when used
+	rs := NewRetentionService(nil, view.RetentionPolicy{Interval: -time.Second})
+	if interval := rs.(*retentionServiceImpl).policy.Interval; interval != DefRetentionInterval {
+		t.Errorf("check interval %v, want %v", interval, DefRetentionInterval)
+	}
+	if err := rs.PurgeExpired(); err != nil {
+		t.Errorf("PurgeExpired() error = %v", err)
+	}
+	rs.Start()
+	rs.Stop()
+}
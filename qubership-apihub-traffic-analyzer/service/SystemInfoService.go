@@ -18,7 +18,10 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/readers"
+	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/repository"
 	"github.com/Netcracker/qubership-apihub-traffic-analyzer/qubership-apihub-traffic-analyzer/view"
 	log "github.com/sirupsen/logrus"
 )
@@ -51,6 +54,18 @@ const (
 	SchemaName           = "PG_SCHEMA_NAME"
 	KubeNamespace        = "NAMESPACE"
 	WorkSpace            = "WORKSPACE"
+	PacketBatchSize      = "PACKET_BATCH_SIZE"
+	LoadConcurrency      = "CAPTURE_LOAD_CONCURRENCY"
+	RedactionRules       = "REDACTION_RULES"
+	RedactionRulesFile   = "REDACTION_RULES_FILE"
+	RedactionHashKey     = "REDACTION_HASH_KEY"
+	CaptureMaxAge        = "RETENTION_CAPTURE_MAX_AGE"
+	MaxCaptures          = "RETENTION_MAX_CAPTURES"
+	ReportMaxAge         = "RETENTION_REPORT_MAX_AGE"
+	RetentionInterval    = "RETENTION_CHECK_INTERVAL"
+	LoadJobWorkers       = "LOAD_JOB_WORKERS"
+	LoadJobTtl           = "LOAD_JOB_TTL"
+	LoadJobMaxAttempts   = "LOAD_JOB_MAX_ATTEMPTS"
 	paramError           = "mandatory parameter %s is empty"
 	defPgPort            = 5432
 	defDotDir            = "."
@@ -102,6 +117,13 @@ type SystemInfoService interface {
 	GetWorkspace() string
 	GetNamespace() string
 	GetAgentName() string
+	GetPacketBatchSize() int
+	GetLoadConcurrency() int
+	GetRedactionRules() string
+	GetRedactionRulesFile() string
+	GetRedactionHashKey() string
+	GetRetentionPolicy() view.RetentionPolicy
+	GetLoadJobConfig() LoadJobConfig
 }
 type systemInfoServiceImpl struct {
 	systemInfoMap map[string]interface{}
@@ -143,6 +165,14 @@ func (g *systemInfoServiceImpl) getInt(varName string, defVal int) int {
 
 }
 
+func (g *systemInfoServiceImpl) getDuration(varName string, defVal time.Duration) time.Duration {
+	v, found := g.systemInfoMap[varName]
+	if found {
+		return v.(time.Duration)
+	}
+	return defVal
+}
+
 func (g *systemInfoServiceImpl) getBool(varName string, defVal bool) bool {
 	v, found := g.systemInfoMap[varName]
 	if found {
@@ -269,13 +299,29 @@ func (g *systemInfoServiceImpl) fromEnvBool(envName string, defVal bool) {
 	g.systemInfoMap[envName] = defVal
 }
 
+// fromEnvDuration
+// extracts duration value (72h, 30m) from an environment variable
+func (g *systemInfoServiceImpl) fromEnvDuration(envName string, defVal time.Duration) {
+	sVal := os.Getenv(envName)
+	if sVal != view.EmptyString {
+		d, e := time.ParseDuration(sVal)
+		if e == nil {
+			defVal = d
+		} else {
+			log.Errorf("non duration value '%s' found in environment variable %s", sVal, envName)
+		}
+	}
+	g.systemInfoMap[envName] = defVal
+}
+
 // Init
 // read and interpret environment values
 func (g *systemInfoServiceImpl) Init() error {
 	// list of parameters
 	strValues := []string{CaptureId, PgUser, PgPassword, PgSslMode, PgDb, SchemaName, ListenAddress, APIkey,
 		LogLevel, OriginAllowed, MinioCrt, MinioAccessKeyId, MinioEndpoint, MinioBucketName, MinioSecretAccessKey,
-		ApiHubAccessToken, ApiHubUrl, KubeNamespace, WorkSpace, ApiHubAgentName}
+		ApiHubAccessToken, ApiHubUrl, KubeNamespace, WorkSpace, ApiHubAgentName, RedactionRules, RedactionRulesFile,
+		RedactionHashKey}
 	// those will be initialized as empty strings
 	for _, svn := range strValues {
 		g.fromEnv(svn, view.EmptyString)
@@ -294,6 +340,16 @@ func (g *systemInfoServiceImpl) Init() error {
 	g.fromEnv(PgSslMode, "off")
 	// numeric
 	g.fromEnvInt(PgPort, defPgPort)
+	g.fromEnvInt(PacketBatchSize, repository.DefPacketBatchSize)
+	g.fromEnvInt(LoadConcurrency, readers.DefLoadConcurrency)
+	g.fromEnvInt(MaxCaptures, 0)
+	g.fromEnvInt(LoadJobWorkers, DefLoadJobWorkers)
+	g.fromEnvInt(LoadJobMaxAttempts, DefLoadJobMaxAttempts)
+	// durations
+	g.fromEnvDuration(CaptureMaxAge, 0)
+	g.fromEnvDuration(ReportMaxAge, 0)
+	g.fromEnvDuration(RetentionInterval, DefRetentionInterval)
+	g.fromEnvDuration(LoadJobTtl, DefLoadJobTtl)
 	// booleans
 	g.fromEnvBool(ProductionMode, true)
 	g.fromEnvBool(InsecureProxy, false)
@@ -363,3 +419,56 @@ func (g *systemInfoServiceImpl) GetNamespace() string {
 func (g *systemInfoServiceImpl) GetAgentName() string {
 	return g.getString(ApiHubAgentName)
 }
+
+// GetPacketBatchSize
+// returns number of packets stored by a single batch
+func (g *systemInfoServiceImpl) GetPacketBatchSize() int {
+	return g.getInt(PacketBatchSize, repository.DefPacketBatchSize)
+}
+
+// GetLoadConcurrency
+// returns number of capture files decoded in parallel
+func (g *systemInfoServiceImpl) GetLoadConcurrency() int {
+	return g.getInt(LoadConcurrency, readers.DefLoadConcurrency)
+}
+
+// GetRedactionRules
+// returns redaction rules JSON text
+func (g *systemInfoServiceImpl) GetRedactionRules() string {
+	return g.getString(RedactionRules)
+}
+
+// GetRedactionRulesFile
+// returns a path to redaction rules JSON file, used when the rules text is empty
+func (g *systemInfoServiceImpl) GetRedactionRulesFile() string {
+	return g.getString(RedactionRulesFile)
+}
+
+// GetRedactionHashKey
+// returns a key of the redaction hashes
+func (g *systemInfoServiceImpl) GetRedactionHashKey() string {
+	return g.getString(RedactionHashKey)
+}
+
+// GetRetentionPolicy
+// returns limits of the aggregated data kept in DB
+func (g *systemInfoServiceImpl) GetRetentionPolicy() view.RetentionPolicy {
+	return view.RetentionPolicy{
+		CaptureMaxAge: g.getDuration(CaptureMaxAge, 0),
+		MaxCaptures:   g.getInt(MaxCaptures, 0),
+		ReportMaxAge:  g.getDuration(ReportMaxAge, 0),
+		Interval:      g.getDuration(RetentionInterval, DefRetentionInterval),
+	}
+}
+
+// GetLoadJobConfig
+// returns parameters of the capture load workers
+func (g *systemInfoServiceImpl) GetLoadJobConfig() LoadJobConfig {
+	return LoadJobConfig{
+		WorkDir:         g.GetWorkDir(),
+		LoadConcurrency: g.GetLoadConcurrency(),
+		Workers:         g.getInt(LoadJobWorkers, DefLoadJobWorkers),
+		JobTtl:          g.getDuration(LoadJobTtl, DefLoadJobTtl),
+		MaxAttempts:     g.getInt(LoadJobMaxAttempts, DefLoadJobMaxAttempts),
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package view
+
+type AsyncApiOperationMetadata struct {
+	// Action operation action: send or receive (AsyncAPI 3), publish or subscribe (AsyncAPI 2)
+	Action string `json:"action"`
+	// Channel channel address (Kafka topic), parameters are in curly brackets
+	Channel string `json:"channel"`
+	// Protocol server protocol (kafka, amqp, ...)
+	Protocol string   `json:"protocol,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+type AsyncApiOperationView struct {
+	OperationListView
+	AsyncApiOperationMetadata
+}
+
+type AsyncApiOperations struct {
+	Operations []AsyncApiOperationView      `json:"operations"`
+	Packages   map[string]PackageVersionRef `json:"packages,omitempty"`
+}
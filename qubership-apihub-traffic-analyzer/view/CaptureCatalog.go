@@ -0,0 +1,82 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package view
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	// CaptureLoading capture files are being loaded
+	CaptureLoading = "loading"
+	// CaptureLoaded the last load finished successfully
+	CaptureLoaded = "loaded"
+	// CaptureLoadFailed the last load finished with error
+	CaptureLoadFailed = "failed"
+	// CaptureLoadInterrupted the last load was not finished (the service was restarted while loading)
+	CaptureLoadInterrupted = "interrupted"
+	// CapturesPageSize default number of the listed captures
+	CapturesPageSize = 100
+	// CapturesMaxPageSize max number of the listed captures
+	CapturesMaxPageSize = 1000
+)
+
+// CaptureService
+// a service seen in the capture address list
+type CaptureService struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// CaptureReport
+// a report generated from the capture
+type CaptureReport struct {
+	ReportId       string     `json:"report_id"`
+	ReportType     string     `json:"report_type"`
+	Status         string     `json:"status"`
+	ServiceName    string     `json:"service_name,omitempty"`
+	ServiceVersion string     `json:"service_version,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// CaptureInfo
+// a capture known to the analyzer with its load state and the stored data summary
+type CaptureInfo struct {
+	CaptureId     string           `json:"capture_id"`
+	LoadState     string           `json:"load_state"`
+	LoadError     string           `json:"load_error,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	LoadStartedAt time.Time        `json:"load_started_at"`
+	LoadedAt      *time.Time       `json:"loaded_at,omitempty"`
+	Metadata      json.RawMessage  `json:"metadata,omitempty"`
+	PacketCount   int              `json:"packet_count"`
+	ExchangeCount int              `json:"exchange_count"`
+	FirstPacketAt *time.Time       `json:"first_packet_at,omitempty"`
+	LastPacketAt  *time.Time       `json:"last_packet_at,omitempty"`
+	Services      []CaptureService `json:"services"`
+	Reports       []CaptureReport  `json:"reports"`
+}
+
+// CaptureLoadState
+// makes the load state from the load timestamps, a not finished load is reported as interrupted
+// unless the service is loading the capture
+func CaptureLoadState(loadStartedAt time.Time, loadedAt *time.Time) string {
+	if loadedAt != nil && !loadedAt.Before(loadStartedAt) {
+		return CaptureLoaded
+	}
+	return CaptureLoadInterrupted
+}
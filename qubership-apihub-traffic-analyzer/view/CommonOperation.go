@@ -251,6 +251,8 @@ type ApiType string
 
 const RestApiType ApiType = "rest"
 const GraphqlApiType ApiType = "graphql"
+const ProtobufApiType ApiType = "protobuf"
+const AsyncApiType ApiType = "asyncapi"
 
 func ParseApiType(s string) (ApiType, error) {
 	switch s {
@@ -258,6 +260,10 @@ func ParseApiType(s string) (ApiType, error) {
 		return RestApiType, nil
 	case string(GraphqlApiType):
 		return GraphqlApiType, nil
+	case string(ProtobufApiType):
+		return ProtobufApiType, nil
+	case string(AsyncApiType):
+		return AsyncApiType, nil
 	default:
 		return "", fmt.Errorf("unknown API Type: %v", s)
 	}
@@ -269,6 +275,10 @@ func GetDocumentTypesForApiType(apiType string) []string {
 		return []string{OpenAPI20Type, OpenAPI30Type, OpenAPI31Type}
 	case string(GraphqlApiType):
 		return []string{GraphQLSchemaType, GraphAPIType, IntrospectionType}
+	case string(ProtobufApiType):
+		return []string{Protobuf3Type}
+	case string(AsyncApiType):
+		return []string{AsyncAPIType, AsyncAPI3Type}
 	default:
 		return []string{}
 	}
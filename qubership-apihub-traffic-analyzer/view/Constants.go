@@ -19,14 +19,28 @@ const (
 	// ApiKeyHeader - HTTP header name for API key
 	ApiKeyHeader                = "api-key"
 	MinioDeleteCapturePath      = "/api/v1/admin/capture/{captureId}/delete"
+	PurgeCapturePath            = "/api/v1/admin/capture/{captureId}/data"   // PurgeCapturePath delete aggregated capture data
+	PurgeReportPath             = "/api/v1/admin/report/{reportId}/data"     // PurgeReportPath delete report data
 	LoadPath                    = "/api/v1/admin/capture/{captureId}/load"   // LoadPath - request data load/update capture data
 	LoadStatusReportPath        = "/api/v1/admin/capture/{captureId}/status" // LoadStatusReportPath produce report, based on loaded data
 	ServiceOperationsReportPath = "/api/v1/report/service/operations/generate"
 	ServiceOperationsRenderPath = "/api/v1/report/service/operations/render"
+	QueryParametersReportPath   = "/api/v1/report/service/operations/{reportId}/query-parameters" // QueryParametersReportPath query parameter coverage of the report operations
 	MinioCleanupCapturePath     = "/api/v1/admin/capture/S3/cleanup"
+	CapturesPath                = "/api/v1/captures"                                    // CapturesPath captures loaded into DB
+	CapturePath                 = "/api/v1/captures/{captureId}"                        // CapturePath a capture loaded into DB
+	CaptureLoadPath             = "/api/v1/captures/{captureId}/load"                   // CaptureLoadPath progress and counters of the capture load
+	ProtobufDescriptorPath      = "/api/v1/admin/protobuf/descriptors/{descriptorName}" // ProtobufDescriptorPath upload/delete FileDescriptorSet
 	CaptureIdParam              = "captureId"
+	DescriptorNameParam         = "descriptorName"
+	ReportIdParam               = "reportId"
+	ServiceNameQueryParam       = "service"
+	LimitQueryParam             = "limit"
+	PageQueryParam              = "page"
 	CompressedSuffix            = ".gz"
 	AddressListSuffix           = "_address_list.txt"
 	CaptureSuffix               = ".pcap"
+	CaptureNgSuffix             = ".pcapng" // CaptureNgSuffix pcapng captures with per-interface link types
 	MetadataSuffix              = "_metadata.json"
+	KeyLogSuffix                = "_sslkeylog.log" // KeyLogSuffix TLS secrets in SSLKEYLOGFILE format
 )
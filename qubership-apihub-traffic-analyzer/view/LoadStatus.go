@@ -0,0 +1,139 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package view
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// CaptureLoadQueued the load is waiting for a worker
+const CaptureLoadQueued = "queued"
+
+// reasons of the packets and messages lost while loading a capture
+const (
+	// IngestErrorRead the capture file can not be read further
+	IngestErrorRead = "read"
+	// IngestErrorTruncated the packet is shorter than its link layer header
+	IngestErrorTruncated = "truncated"
+	// IngestErrorLinkLayer the link layer can not be decoded
+	IngestErrorLinkLayer = "link_layer"
+	// IngestErrorIpLayer the IP layer can not be decoded
+	IngestErrorIpLayer = "ip_layer"
+	// IngestErrorNoAddress the IP packet has no addresses
+	IngestErrorNoAddress = "no_address"
+	// IngestErrorTcpLayer the TCP segment can not be decoded
+	IngestErrorTcpLayer = "tcp_layer"
+	// IngestErrorUnknownPeer the message address is not found in the address list
+	IngestErrorUnknownPeer = "unknown_peer"
+	// IngestErrorStore the message is dropped with the batch which could not be stored
+	IngestErrorStore = "store"
+)
+
+// IngestCounters
+// packets and messages counted while a capture is loaded, losses are counted by reason
+type IngestCounters struct {
+	// Packets packets read from the capture
+	Packets int `json:"packets"`
+	// NonIp packets without IP layer
+	NonIp int `json:"non_ip"`
+	// NonTcp IP packets without TCP layer
+	NonTcp int `json:"non_tcp"`
+	// HttpRequests HTTP requests decoded from the TCP streams
+	HttpRequests int `json:"http_requests"`
+	// HttpResponses HTTP responses decoded from the TCP streams
+	HttpResponses int `json:"http_responses"`
+	// Stored messages stored to DB
+	Stored int `json:"stored"`
+	// Duplicates messages stored before, by another file or load
+	Duplicates int `json:"duplicates"`
+	// Errors losses by reason
+	Errors map[string]int `json:"errors,omitempty"`
+}
+
+// AddError
+// counts the losses of the reason
+func (ic *IngestCounters) AddError(reason string, count int) {
+	if count <= 0 {
+		return
+	}
+	if ic.Errors == nil {
+		ic.Errors = make(map[string]int)
+	}
+	ic.Errors[reason] += count
+}
+
+// Add
+// sums the counters
+func (ic *IngestCounters) Add(other IngestCounters) {
+	ic.Packets += other.Packets
+	ic.NonIp += other.NonIp
+	ic.NonTcp += other.NonTcp
+	ic.HttpRequests += other.HttpRequests
+	ic.HttpResponses += other.HttpResponses
+	ic.Stored += other.Stored
+	ic.Duplicates += other.Duplicates
+	for reason, count := range other.Errors {
+		ic.AddError(reason, count)
+	}
+}
+
+// String
+// makes a single line summary for the log
+func (ic IngestCounters) String() string {
+	return fmt.Sprintf("packets: %d, non-IP: %d, non-TCP: %d, HTTP requests: %d, HTTP responses: %d, stored: %d, duplicates: %d, errors: %v",
+		ic.Packets, ic.NonIp, ic.NonTcp, ic.HttpRequests, ic.HttpResponses, ic.Stored, ic.Duplicates, ic.Errors)
+}
+
+// CaptureFileLoadStatus
+// a capture file read by the load
+type CaptureFileLoadStatus struct {
+	FileName   string          `json:"file_name"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Counters   *IngestCounters `json:"counters,omitempty"`
+}
+
+// CaptureLoadStatus
+// progress of the last capture load, the counters are summed over the read files
+type CaptureLoadStatus struct {
+	CaptureId       string                  `json:"capture_id"`
+	JobId           int                     `json:"job_id"`
+	LoadState       string                  `json:"load_state"`
+	LoadError       string                  `json:"load_error,omitempty"`
+	Attempts        int                     `json:"attempts"`
+	CreatedAt       time.Time               `json:"created_at"`
+	StartedAt       *time.Time              `json:"started_at,omitempty"`
+	FinishedAt      *time.Time              `json:"finished_at,omitempty"`
+	FilesTotal      int                     `json:"files_total"`
+	FilesRead       int                     `json:"files_read"`
+	PercentComplete float64                 `json:"percent_complete"`
+	Counters        IngestCounters          `json:"counters"`
+	Files           []CaptureFileLoadStatus `json:"files"`
+}
+
+// LoadPercent
+// share of the read files, a successfully finished load is complete
+func LoadPercent(filesRead, filesTotal int, loaded bool) float64 {
+	if loaded {
+		return 100
+	}
+	if filesTotal <= 0 {
+		return 0
+	}
+	return math.Min(100, math.Round(float64(filesRead)*1000/float64(filesTotal))/10)
+}
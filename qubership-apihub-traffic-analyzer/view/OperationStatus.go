@@ -54,6 +54,8 @@ type OperationStatusWithPeers struct {
 	OperationStatus
 	Source      string `json:"source_service,omitempty"`
 	Destination string `json:"destination_service,omitempty"`
+	// ResponseCodes comma separated HTTP response codes returned by the operation
+	ResponseCodes string `json:"response_codes,omitempty"`
 }
 
 func DecodeOperationStatus(bytes []byte) (OperationStatus, error) {
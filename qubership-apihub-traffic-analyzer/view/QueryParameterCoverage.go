@@ -0,0 +1,56 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package view
+
+const (
+	// QueryParamUsed documented parameter sent by the captured requests
+	QueryParamUsed = "Captured query parameter"
+	// QueryParamUnused documented parameter not sent by any captured request
+	QueryParamUnused = "Query parameter not captured"
+	// QueryParamUndocumented parameter sent by the captured requests but not listed in the operation
+	QueryParamUndocumented = "Captured unknown query parameter"
+)
+
+type QueryParameterUsage struct {
+	Name       string `json:"name"`
+	Documented bool   `json:"documented"`
+	Required   bool   `json:"required,omitempty"`
+	HitCount   int    `json:"hitCount"`
+	Status     string `json:"status"`
+}
+
+type OperationQueryParameters struct {
+	OperationId string                `json:"operationId"`
+	Path        string                `json:"path"`
+	Method      string                `json:"method"`
+	Parameters  []QueryParameterUsage `json:"parameters"`
+}
+
+type QueryParameterCoverage struct {
+	ReportId   string                     `json:"report_id"`
+	Operations []OperationQueryParameters `json:"operations"`
+}
+
+// QueryParameterStatus
+// makes parameter usage status
+func QueryParameterStatus(documented bool, hitCount int) string {
+	switch {
+	case !documented:
+		return QueryParamUndocumented
+	case hitCount > 0:
+		return QueryParamUsed
+	}
+	return QueryParamUnused
+}
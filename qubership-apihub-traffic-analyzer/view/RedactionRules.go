@@ -0,0 +1,58 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package view
+
+const (
+	// RedactMask replaces the value with RedactedValue (default action)
+	RedactMask = "mask"
+	// RedactHash replaces the value with a keyed hash, equal values get equal hashes
+	RedactHash = "hash"
+	// RedactRemove removes the header, query parameter or JSON field, pattern matches are masked
+	RedactRemove = "remove"
+	// RedactedValue a replacement of the masked value
+	RedactedValue = "***"
+)
+
+// RedactionRule
+// a single redaction rule, header and query parameter rules use Name, JSON rules use Path, pattern rules use Regex
+type RedactionRule struct {
+	Name   string `json:"name,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Regex  string `json:"regex,omitempty"`
+	Action string `json:"action,omitempty"`
+}
+
+// RedactionRules
+// rules applied to the decoded traffic before it is stored
+type RedactionRules struct {
+	// Headers HTTP and Kafka record headers by name (case-insensitive)
+	Headers []RedactionRule `json:"headers,omitempty"`
+	// QueryParams query string and form body parameters by name (case-insensitive)
+	QueryParams []RedactionRule `json:"queryParams,omitempty"`
+	// JsonPaths fields of JSON bodies and messages: $.a.b, $.items[*].c, $.items[0], $..name
+	JsonPaths []RedactionRule `json:"jsonPaths,omitempty"`
+	// Patterns regular expressions applied to paths, header and parameter values, bodies and messages
+	Patterns []RedactionRule `json:"patterns,omitempty"`
+}
+
+// DefaultRedactionRules
+// rules used when no rules are configured: credentials headers are masked
+func DefaultRedactionRules() RedactionRules {
+	rules := RedactionRules{}
+	for _, name := range []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", ApiKeyHeader} {
+		rules.Headers = append(rules.Headers, RedactionRule{Name: name, Action: RedactMask})
+	}
+	return rules
+}
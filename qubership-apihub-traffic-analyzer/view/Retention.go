@@ -0,0 +1,50 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package view
+
+import (
+	"time"
+)
+
+// RetentionPolicy
+// limits of the aggregated data kept in DB, zero value disables the limit
+type RetentionPolicy struct {
+	// CaptureMaxAge captures loaded earlier are purged
+	CaptureMaxAge time.Duration
+	// MaxCaptures number of the most recently loaded captures to keep
+	MaxCaptures int
+	// ReportMaxAge reports created earlier are purged
+	ReportMaxAge time.Duration
+	// Interval period of the retention checks
+	Interval time.Duration
+}
+
+// Enabled
+// true when any limit is set
+func (rp RetentionPolicy) Enabled() bool {
+	return rp.CaptureMaxAge > 0 || rp.MaxCaptures > 0 || rp.ReportMaxAge > 0
+}
+
+// CapturePurgeResult
+// number of rows deleted with the capture data
+type CapturePurgeResult struct {
+	CaptureId   string `json:"capture_id"`
+	Reports     int    `json:"reports"`
+	Packets     int    `json:"packets"`
+	KafkaEvents int    `json:"kafka_events"`
+	Addresses   int    `json:"addresses"`
+	Metadata    int    `json:"metadata"`
+	Headers     int    `json:"orphaned_headers"`
+}
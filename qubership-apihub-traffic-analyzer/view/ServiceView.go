@@ -30,3 +30,27 @@ func UnmarshalServiceView(svcViewBytes []byte) (ServiceView, error) {
 	svc.Valid = (err == nil)
 	return *svc, err
 }
+
+const (
+	// PeerNameSourceHost name from the request Host header (HTTP/2 :authority) of the destination address
+	PeerNameSourceHost = "host"
+	// PeerNameSourceSni name from TLS server name indication of the destination address
+	PeerNameSourceSni = "sni"
+	// PeerNameSourceDns name from DNS response A or AAAA record
+	PeerNameSourceDns = "dns"
+)
+
+// PeerNameSourcePriority
+// returns priority of the inferred name source, DNS names are preferred to the names requested by the clients
+// as a client may connect a shared address (proxy, ingress) with different host names
+func PeerNameSourcePriority(source string) int {
+	switch source {
+	case PeerNameSourceDns:
+		return 3
+	case PeerNameSourceSni:
+		return 2
+	case PeerNameSourceHost:
+		return 1
+	}
+	return 0
+}
@@ -18,12 +18,14 @@ const OpenAPI31Type string = "openapi-3-1"
 const OpenAPI30Type string = "openapi-3-0"
 const OpenAPI20Type string = "openapi-2-0"
 const AsyncAPIType string = "asyncapi-2"
+const AsyncAPI3Type string = "asyncapi-3"
 const JsonSchemaType string = "json-schema"
 const MDType string = "markdown"
 const GraphQLSchemaType string = "graphql-schema"
 const GraphAPIType string = "graphapi"
 const GraphQLType string = "graphql"
 const IntrospectionType string = "introspection"
+const Protobuf3Type string = "protobuf-3"
 const UnknownType string = "unknown"
 
 type Specification struct {